@@ -0,0 +1,121 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// defaultAnomalySensitivity is the number of deviations from the baseline
+// above which a point is an anomaly when the sensitivity is not set.
+const defaultAnomalySensitivity = 3
+
+// AnomalyCommand is an expression command detecting anomalies in time series
+// with statistical baselines computed in-process. For each input series it
+// returns a series that is 1 for anomalous points and 0 for the others, and
+// optionally the upper and lower bands of expected values.
+type AnomalyCommand struct {
+	ReferenceVar string
+	Config       mathexp.AnomalyConfig
+	// IncludeBands adds the upper and lower band series to the results. They
+	// have the labels of the input series plus the anomaly_band label.
+	IncludeBands bool
+	refID        string
+}
+
+// AnomalyCommandConfig is the model of the anomaly command in a query.
+type AnomalyCommandConfig struct {
+	Expression  string  `json:"expression"`
+	Algorithm   string  `json:"algorithm"`
+	Window      string  `json:"window"`
+	Season      string  `json:"season"`
+	Sensitivity float64 `json:"sensitivity"`
+	Bands       bool    `json:"bands"`
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, referenceVar string, cfg mathexp.AnomalyConfig, includeBands bool) (*AnomalyCommand, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &AnomalyCommand{
+		ReferenceVar: referenceVar,
+		Config:       cfg,
+		IncludeBands: includeBands,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	cmdConfig := AnomalyCommandConfig{}
+	if err := json.Unmarshal(rn.QueryRaw, &cmdConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	referenceVar := strings.TrimPrefix(cmdConfig.Expression, "$")
+	if referenceVar == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+
+	cfg := mathexp.AnomalyConfig{
+		Algorithm:   cmdConfig.Algorithm,
+		Sensitivity: cmdConfig.Sensitivity,
+	}
+	if cfg.Sensitivity == 0 {
+		cfg.Sensitivity = defaultAnomalySensitivity
+	}
+	var err error
+	if cmdConfig.Window != "" {
+		if cfg.Window, err = gtime.ParseDuration(cmdConfig.Window); err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "window" duration field %q: %w`, cmdConfig.Window, err)
+		}
+	}
+	if cmdConfig.Season != "" {
+		if cfg.Season, err = gtime.ParseDuration(cmdConfig.Season); err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "season" duration field %q: %w`, cmdConfig.Season, err)
+		}
+	}
+	return NewAnomalyCommand(rn.RefID, referenceVar, cfg, cmdConfig.Bands)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+	span.SetAttributes(attribute.String("algorithm", ac.Config.Algorithm))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			anomalies, err := v.DetectAnomalies(ac.refID, ac.Config)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, anomalies.Indicator)
+			if ac.IncludeBands {
+				newRes.Values = append(newRes.Values, anomalies.Upper, anomalies.Lower)
+			}
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
@@ -0,0 +1,125 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		expected *AnomalyCommand
+		isError  bool
+	}{
+		{
+			name:  "zscore with default sensitivity",
+			query: `{ "expression": "$A", "algorithm": "zscore", "window": "1h" }`,
+			expected: &AnomalyCommand{
+				ReferenceVar: "A",
+				Config:       mathexp.AnomalyConfig{Algorithm: mathexp.AnomalyZScore, Window: time.Hour, Sensitivity: 3},
+				refID:        "B",
+			},
+		},
+		{
+			name:  "seasonal with bands",
+			query: `{ "expression": "A", "algorithm": "seasonal", "season": "1d", "sensitivity": 2.5, "bands": true }`,
+			expected: &AnomalyCommand{
+				ReferenceVar: "A",
+				Config:       mathexp.AnomalyConfig{Algorithm: mathexp.AnomalySeasonal, Season: 24 * time.Hour, Sensitivity: 2.5},
+				IncludeBands: true,
+				refID:        "B",
+			},
+		},
+		{
+			name:    "error when expression is missing",
+			query:   `{ "algorithm": "zscore", "window": "1h" }`,
+			isError: true,
+		},
+		{
+			name:    "error when window is invalid",
+			query:   `{ "expression": "$A", "algorithm": "mad", "window": "an hour" }`,
+			isError: true,
+		},
+		{
+			name:    "error when window is missing",
+			query:   `{ "expression": "$A", "algorithm": "mad" }`,
+			isError: true,
+		},
+		{
+			name:    "error when algorithm is unknown",
+			query:   `{ "expression": "$A", "algorithm": "prophet", "window": "1h" }`,
+			isError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &qmap))
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{
+				RefID:    "B",
+				Query:    qmap,
+				QueryRaw: []byte(tc.query),
+			})
+			if tc.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestAnomalyExecute(t *testing.T) {
+	series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 0)
+	for i, v := range []float64{10, 12, 10, 12, 10, 12, 40} {
+		series.AppendPoint(time.Unix(int64(i), 0), util.Pointer(v))
+	}
+
+	cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalyConfig{
+		Algorithm:   mathexp.AnomalyZScore,
+		Window:      time.Minute,
+		Sensitivity: 3,
+	}, true)
+	require.NoError(t, err)
+
+	t.Run("returns indicator and bands for each series", func(t *testing.T) {
+		results, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{series}},
+		}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, results.Values, 3)
+
+		indicator := results.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a"}, indicator.GetLabels())
+		require.Equal(t, 1.0, *indicator.GetValue(indicator.Len() - 1))
+		require.Equal(t, "upper", results.Values[1].GetLabels()[mathexp.AnomalyBandLabel])
+		require.Equal(t, "lower", results.Values[2].GetLabels()[mathexp.AnomalyBandLabel])
+	})
+
+	t.Run("returns NoData when no data", func(t *testing.T) {
+		results, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}},
+		}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, mathexp.Values{mathexp.NewNoData()}, results.Values)
+	})
+
+	t.Run("returns error when input is a number", func(t *testing.T) {
+		_, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
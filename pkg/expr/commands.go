@@ -207,11 +207,14 @@ type ResampleCommand struct {
 	Downsampler   string
 	Upsampler     string
 	TimeRange     TimeRange
-	refID         string
+	// AlignTo, when set, aligns the windows to calendar boundaries in the
+	// location instead of the start of the time range.
+	AlignTo *time.Location
+	refID   string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
-func NewResampleCommand(refID, rawWindow, varToResample string, downsampler string, upsampler string, tr TimeRange) (*ResampleCommand, error) {
+func NewResampleCommand(refID, rawWindow, varToResample string, downsampler string, upsampler string, tr TimeRange, alignTo *time.Location) (*ResampleCommand, error) {
 	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
@@ -223,6 +226,7 @@ func NewResampleCommand(refID, rawWindow, varToResample string, downsampler stri
 		Downsampler:   downsampler,
 		Upsampler:     upsampler,
 		TimeRange:     tr,
+		AlignTo:       alignTo,
 		refID:         refID,
 	}, nil
 }
@@ -270,7 +274,30 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
-	return NewResampleCommand(rn.RefID, window, varToResample, downsampler, upsampler, rn.TimeRange)
+	var alignTo *time.Location
+	settings, ok := rn.Query["settings"]
+	if ok {
+		s, ok := settings.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", settings, rn.RefID)
+		}
+		if align, ok := s["alignWindows"].(bool); ok && align {
+			timezone := ""
+			if rawTimezone, ok := s["timezone"]; ok {
+				timezone, ok = rawTimezone.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected resample timezone to be a string, got type %T", rawTimezone)
+				}
+			}
+			loc, err := loadLocation(timezone)
+			if err != nil {
+				return nil, err
+			}
+			alignTo = loc
+		}
+	}
+
+	return NewResampleCommand(rn.RefID, window, varToResample, downsampler, upsampler, rn.TimeRange, alignTo)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -286,13 +313,17 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 	defer span.End()
 	newRes := mathexp.Results{}
 	timeRange := gr.TimeRange.AbsoluteTime(now)
+	from := timeRange.From
+	if gr.AlignTo != nil {
+		from = alignTime(from, gr.Window, gr.AlignTo)
+	}
 	for _, val := range vars[gr.VarToResample].Values {
 		if val == nil {
 			continue
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, from, timeRange.To)
 			if err != nil {
 				return newRes, err
 			}
@@ -307,6 +338,30 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 	return newRes, nil
 }
 
+// alignTime returns the first time not before t that is a whole number of
+// intervals after the start of the day of t in the location. Windows of a day
+// or longer are aligned to the start of the day.
+func alignTime(t time.Time, interval time.Duration, loc *time.Location) time.Time {
+	lt := t.In(loc)
+	dayStart := time.Date(lt.Year(), lt.Month(), lt.Day(), 0, 0, 0, 0, loc)
+	aligned := dayStart.Add(lt.Sub(dayStart).Truncate(interval))
+	if aligned.Before(t) {
+		aligned = aligned.Add(interval)
+	}
+	return aligned.In(t.Location())
+}
+
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" || strings.EqualFold(timezone, "utc") {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resample timezone %q: %w", timezone, err)
+	}
+	return loc, nil
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -323,6 +378,12 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeSQL is the CMDType for running a SQL query over the results of other nodes.
+	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in time series.
+	TypeAnomaly
+	// TypeForecast is the CMDType for predicting the values of time series.
+	TypeForecast
 )
 
 func (gt CommandType) String() string {
@@ -335,6 +396,12 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeSQL:
+		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
 	default:
 		return "unknown"
 	}
@@ -353,6 +420,12 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "sql":
+		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -221,7 +221,7 @@ func TestResampleCommand_Execute(t *testing.T) {
 		From: -10 * time.Second,
 		To:   0,
 	}
-	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr)
+	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr, nil)
 	require.NoError(t, err)
 
 	var tests = []struct {
@@ -273,3 +273,145 @@ func TestResampleCommand_Execute(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func Test_UnmarshalResampleCommand_Settings(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name          string
+		querySettings string
+		isError       bool
+		expectedAlign *time.Location
+	}{
+		{
+			name:          "windows are not aligned when settings is not specified",
+			querySettings: ``,
+		},
+		{
+			name:          "windows are not aligned when alignWindows is false",
+			querySettings: `, "settings" : { "alignWindows": false, "timezone": "America/New_York" }`,
+		},
+		{
+			name:          "windows are aligned in UTC when timezone is not specified",
+			querySettings: `, "settings" : { "alignWindows": true }`,
+			expectedAlign: time.UTC,
+		},
+		{
+			name:          "windows are aligned in the timezone",
+			querySettings: `, "settings" : { "alignWindows": true, "timezone": "America/New_York" }`,
+			expectedAlign: newYork,
+		},
+		{
+			name:          "error when timezone is unknown",
+			querySettings: `, "settings" : { "alignWindows": true, "timezone": "Mars/Olympus" }`,
+			isError:       true,
+		},
+		{
+			name:          "error when settings is not object",
+			querySettings: `, "settings" : "align"`,
+			isError:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := fmt.Sprintf(`{ "expression" : "$A", "window": "5m", "downsampler": "mean", "upsampler": "fillna"%s }`, test.querySettings)
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(q), &qmap))
+
+			cmd, err := UnmarshalResampleCommand(&rawNode{
+				RefID:     "B",
+				Query:     qmap,
+				TimeRange: RelativeTimeRange{},
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expectedAlign, cmd.AlignTo)
+		})
+	}
+}
+
+func TestResampleCommand_AlignWindows(t *testing.T) {
+	to := time.Date(2023, 12, 1, 10, 31, 10, 0, time.UTC)
+	tr := AbsoluteTimeRange{
+		From: to.Add(-20 * time.Minute),
+		To:   to,
+	}
+	cmd, err := NewResampleCommand("B", "5m", "A", "mean", "fillna", tr, time.UTC)
+	require.NoError(t, err)
+
+	series := mathexp.NewSeries("A", nil, 0)
+	series.AppendPoint(time.Date(2023, 12, 1, 10, 14, 0, 0, time.UTC), util.Pointer(1.0))
+	series.AppendPoint(time.Date(2023, 12, 1, 10, 24, 0, 0, time.UTC), util.Pointer(2.0))
+
+	result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{series}},
+	}, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	require.Len(t, result.Values, 1)
+
+	resampled := result.Values[0].(mathexp.Series)
+	var times []time.Time
+	for i := 0; i < resampled.Len(); i++ {
+		times = append(times, resampled.GetTime(i))
+	}
+	require.Equal(t, []time.Time{
+		time.Date(2023, 12, 1, 10, 15, 0, 0, time.UTC),
+		time.Date(2023, 12, 1, 10, 20, 0, 0, time.UTC),
+		time.Date(2023, 12, 1, 10, 25, 0, 0, time.UTC),
+		time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC),
+	}, times)
+}
+
+func TestAlignTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name     string
+		t        time.Time
+		interval time.Duration
+		loc      *time.Location
+		expected time.Time
+	}{
+		{
+			name:     "rounds up to the next minute",
+			t:        time.Date(2023, 12, 1, 10, 31, 10, 0, time.UTC),
+			interval: time.Minute,
+			loc:      time.UTC,
+			expected: time.Date(2023, 12, 1, 10, 32, 0, 0, time.UTC),
+		},
+		{
+			name:     "keeps aligned time",
+			t:        time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC),
+			interval: 15 * time.Minute,
+			loc:      time.UTC,
+			expected: time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "aligns days to midnight in the location",
+			t:        time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC),
+			interval: 24 * time.Hour,
+			loc:      newYork,
+			expected: time.Date(2023, 12, 2, 0, 0, 0, 0, newYork).In(time.UTC),
+		},
+		{
+			name:     "aligns hours in location with half hour offset",
+			t:        time.Date(2023, 12, 1, 10, 10, 0, 0, time.UTC),
+			interval: time.Hour,
+			loc:      time.FixedZone("IST", 5*3600+1800),
+			expected: time.Date(2023, 12, 1, 10, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, alignTime(test.t, test.interval, test.loc))
+		})
+	}
+}
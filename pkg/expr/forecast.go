@@ -0,0 +1,139 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const (
+	// ForecastOutputValue returns the value predicted at the horizon for each series.
+	ForecastOutputValue = "value"
+	// ForecastOutputSeries returns the values predicted until the horizon for each series.
+	ForecastOutputSeries = "series"
+)
+
+// ForecastCommand is an expression command predicting the values of time
+// series with a model fitted with their points. The horizon is counted from
+// the time of the evaluation, so that the backtesting of alert rules
+// predicts from each of its evaluations.
+type ForecastCommand struct {
+	ReferenceVar string
+	Config       mathexp.ForecastConfig
+	Horizon      time.Duration
+	Output       string
+	refID        string
+}
+
+// ForecastCommandConfig is the model of the forecast command in a query.
+type ForecastCommandConfig struct {
+	Expression string `json:"expression"`
+	Model      string `json:"model"`
+	Horizon    string `json:"horizon"`
+	Season     string `json:"season"`
+	Output     string `json:"output"`
+}
+
+// NewForecastCommand creates a new ForecastCommand.
+func NewForecastCommand(refID, referenceVar string, cfg mathexp.ForecastConfig, horizon time.Duration, output string) (*ForecastCommand, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if horizon < 0 {
+		return nil, fmt.Errorf("forecast horizon must not be negative, got %s", horizon)
+	}
+	switch output {
+	case ForecastOutputValue, ForecastOutputSeries:
+	default:
+		return nil, fmt.Errorf("forecast output %q is not supported, expected one of [%s, %s]", output, ForecastOutputValue, ForecastOutputSeries)
+	}
+	return &ForecastCommand{
+		ReferenceVar: referenceVar,
+		Config:       cfg,
+		Horizon:      horizon,
+		Output:       output,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	cmdConfig := ForecastCommandConfig{}
+	if err := json.Unmarshal(rn.QueryRaw, &cmdConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse the forecast command: %w", err)
+	}
+	referenceVar := strings.TrimPrefix(cmdConfig.Expression, "$")
+	if referenceVar == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	if cmdConfig.Horizon == "" {
+		return nil, fmt.Errorf("no horizon specified in forecast command for refId %v", rn.RefID)
+	}
+	horizon, err := gtime.ParseDuration(cmdConfig.Horizon)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, cmdConfig.Horizon, err)
+	}
+
+	cfg := mathexp.ForecastConfig{Model: cmdConfig.Model}
+	if cfg.Model == "" {
+		cfg.Model = mathexp.ForecastLinear
+	}
+	if cmdConfig.Season != "" {
+		if cfg.Season, err = gtime.ParseDuration(cmdConfig.Season); err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "season" duration field %q: %w`, cmdConfig.Season, err)
+		}
+	}
+	output := cmdConfig.Output
+	if output == "" {
+		output = ForecastOutputValue
+	}
+	return NewForecastCommand(rn.RefID, referenceVar, cfg, horizon, output)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *ForecastCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteForecast")
+	defer span.End()
+	span.SetAttributes(attribute.String("model", fc.Config.Model), attribute.String("output", fc.Output))
+
+	at := now.Add(fc.Horizon)
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			var (
+				forecast mathexp.Value
+				err      error
+			)
+			if fc.Output == ForecastOutputSeries {
+				forecast, err = v.ForecastSeries(fc.refID, fc.Config, at)
+			} else {
+				forecast, err = v.Forecast(fc.refID, fc.Config, at)
+			}
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, forecast)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
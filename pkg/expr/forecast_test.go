@@ -0,0 +1,135 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalForecastCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		expected *ForecastCommand
+		isError  bool
+	}{
+		{
+			name:  "linear value by default",
+			query: `{ "expression": "$A", "horizon": "4h" }`,
+			expected: &ForecastCommand{
+				ReferenceVar: "A",
+				Config:       mathexp.ForecastConfig{Model: mathexp.ForecastLinear},
+				Horizon:      4 * time.Hour,
+				Output:       ForecastOutputValue,
+				refID:        "B",
+			},
+		},
+		{
+			name:  "seasonal holt-winters series",
+			query: `{ "expression": "A", "model": "holt_winters", "season": "1d", "horizon": "1w", "output": "series" }`,
+			expected: &ForecastCommand{
+				ReferenceVar: "A",
+				Config:       mathexp.ForecastConfig{Model: mathexp.ForecastHoltWinters, Season: 24 * time.Hour},
+				Horizon:      7 * 24 * time.Hour,
+				Output:       ForecastOutputSeries,
+				refID:        "B",
+			},
+		},
+		{
+			name:    "error when horizon is missing",
+			query:   `{ "expression": "$A" }`,
+			isError: true,
+		},
+		{
+			name:    "error when horizon is invalid",
+			query:   `{ "expression": "$A", "horizon": "soon" }`,
+			isError: true,
+		},
+		{
+			name:    "error when output is unknown",
+			query:   `{ "expression": "$A", "horizon": "4h", "output": "chart" }`,
+			isError: true,
+		},
+		{
+			name:    "error when linear model has a season",
+			query:   `{ "expression": "$A", "horizon": "4h", "season": "1d" }`,
+			isError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(tc.query), &qmap))
+			cmd, err := UnmarshalForecastCommand(&rawNode{
+				RefID:    "B",
+				Query:    qmap,
+				QueryRaw: []byte(tc.query),
+			})
+			if tc.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestForecastExecute(t *testing.T) {
+	// disk usage growing by 1 every minute
+	start := time.Date(2023, 12, 1, 10, 0, 0, 0, time.UTC)
+	series := mathexp.NewSeries("A", data.Labels{"disk": "sda"}, 0)
+	for i := 0; i < 60; i++ {
+		series.AppendPoint(start.Add(time.Duration(i)*time.Minute), util.Pointer(float64(i)))
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}}
+
+	cmd, err := NewForecastCommand("B", "A", mathexp.ForecastConfig{Model: mathexp.ForecastLinear}, 4*time.Hour, ForecastOutputValue)
+	require.NoError(t, err)
+
+	t.Run("predicts at the horizon from the evaluation time", func(t *testing.T) {
+		for _, now := range []time.Time{start.Add(time.Hour), start.Add(2 * time.Hour)} {
+			results, err := cmd.Execute(context.Background(), now, vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Len(t, results.Values, 1)
+			number := results.Values[0].(mathexp.Number)
+			require.Equal(t, data.Labels{"disk": "sda"}, number.GetLabels())
+			require.InDelta(t, now.Add(4*time.Hour).Sub(start).Minutes(), *number.GetFloat64Value(), 1e-6)
+		}
+	})
+
+	t.Run("returns projected series", func(t *testing.T) {
+		cmd, err := NewForecastCommand("B", "A", mathexp.ForecastConfig{Model: mathexp.ForecastLinear}, 10*time.Minute, ForecastOutputSeries)
+		require.NoError(t, err)
+		results, err := cmd.Execute(context.Background(), start.Add(time.Hour), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, results.Values, 1)
+		projected := results.Values[0].(mathexp.Series)
+		require.Equal(t, 11, projected.Len())
+		require.Equal(t, start.Add(70*time.Minute), projected.GetTime(projected.Len()-1))
+	})
+
+	t.Run("returns NoData when no data", func(t *testing.T) {
+		results, err := cmd.Execute(context.Background(), start, mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}},
+		}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Equal(t, mathexp.Values{mathexp.NewNoData()}, results.Values)
+	})
+
+	t.Run("returns error when input is a number", func(t *testing.T) {
+		_, err := cmd.Execute(context.Background(), start, mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
@@ -154,9 +154,37 @@ func (s *Service) buildDependencyGraph(req *Request) (*simple.DirectedGraph, err
 		return nil, err
 	}
 
+	markSQLInputs(graph)
+
 	return graph, nil
 }
 
+// markSQLInputs marks the datasource nodes which are only inputs to SQL
+// expressions, their responses are loaded as tables without converting them
+// to numbers or series.
+func markSQLInputs(dp *simple.DirectedGraph) {
+	nodeIt := dp.Nodes()
+	for nodeIt.Next() {
+		dsNode, ok := nodeIt.Node().(*DSNode)
+		if !ok {
+			continue
+		}
+		dependents := dp.From(dsNode.ID())
+		if dependents.Len() == 0 {
+			continue
+		}
+		onlySQL := true
+		for dependents.Next() {
+			cmdNode, ok := dependents.Node().(*CMDNode)
+			if !ok || cmdNode.CMDType != TypeSQL {
+				onlySQL = false
+				break
+			}
+		}
+		dsNode.isInputToSQLExpr = onlySQL
+	}
+}
+
 // buildExecutionOrder returns a sequence of nodes ordered by dependency.
 // Note: During execution, Datasource query nodes for the same datasource will
 // be grouped into one request and executed first as phase after this call.
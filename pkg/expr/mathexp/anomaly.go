@@ -0,0 +1,223 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// AnomalyZScore detects the points further from the mean of the previous
+	// points in the window than Sensitivity standard deviations.
+	AnomalyZScore = "zscore"
+	// AnomalyMAD detects the points further from the median of the previous
+	// points in the window than Sensitivity median absolute deviations. It is
+	// less sensitive than the z-score to the outliers in the window.
+	AnomalyMAD = "mad"
+	// AnomalySeasonal detects the points further from the value predicted by
+	// a Holt-Winters model of the series than Sensitivity standard deviations
+	// of the previous prediction errors.
+	AnomalySeasonal = "seasonal"
+
+	// AnomalyBandLabel is the label added to the upper and lower band series.
+	AnomalyBandLabel = "anomaly_band"
+)
+
+// madScale makes the median absolute deviation a consistent estimator of the
+// standard deviation of normally distributed values.
+const madScale = 1.4826
+
+// AnomalyConfig configures the detection of anomalies in series.
+type AnomalyConfig struct {
+	Algorithm string
+	// Window is the duration of the previous points used as baseline by the
+	// zscore and mad algorithms.
+	Window time.Duration
+	// Season is the duration of a season for the seasonal algorithm.
+	Season time.Duration
+	// Sensitivity is the number of deviations from the baseline above which
+	// a point is an anomaly.
+	Sensitivity float64
+}
+
+// Validate returns an error if the configuration is not valid.
+func (c AnomalyConfig) Validate() error {
+	switch c.Algorithm {
+	case AnomalyZScore, AnomalyMAD:
+		if c.Window <= 0 {
+			return fmt.Errorf("the %s algorithm requires a positive window", c.Algorithm)
+		}
+	case AnomalySeasonal:
+		if c.Season <= 0 {
+			return fmt.Errorf("the %s algorithm requires a positive season", c.Algorithm)
+		}
+	default:
+		return fmt.Errorf("anomaly detection algorithm %q is not supported, expected one of [%s, %s, %s]", c.Algorithm, AnomalyZScore, AnomalyMAD, AnomalySeasonal)
+	}
+	if c.Sensitivity <= 0 || math.IsNaN(c.Sensitivity) || math.IsInf(c.Sensitivity, 0) {
+		return fmt.Errorf("sensitivity must be a positive number, got %v", c.Sensitivity)
+	}
+	return nil
+}
+
+// Anomalies holds the result of the detection of anomalies in a series.
+type Anomalies struct {
+	// Indicator is 1 for the points that are anomalies and 0 for the others.
+	Indicator Series
+	// Upper and Lower are the bands of expected values.
+	Upper Series
+	Lower Series
+}
+
+// DetectAnomalies compares each point of the series with a baseline computed
+// from the previous points. The points for which there is not enough data to
+// compute a baseline are null in the results, null and NaN points are left out.
+func (s Series) DetectAnomalies(refID string, cfg AnomalyConfig) (Anomalies, error) {
+	if err := cfg.Validate(); err != nil {
+		return Anomalies{}, err
+	}
+	points := nonNullPoints(sortedPoints(s))
+
+	var bands [][2]*float64
+	switch cfg.Algorithm {
+	case AnomalyZScore:
+		bands = windowBands(points, cfg.Window, func(values []float64) (float64, float64, bool) {
+			if len(values) < 2 {
+				return 0, 0, false
+			}
+			m := mean(values)
+			var squareSum float64
+			for _, v := range values {
+				squareSum += (v - m) * (v - m)
+			}
+			return m, math.Sqrt(squareSum / float64(len(values))), true
+		}, cfg.Sensitivity)
+	case AnomalyMAD:
+		bands = windowBands(points, cfg.Window, func(values []float64) (float64, float64, bool) {
+			if len(values) < 2 {
+				return 0, 0, false
+			}
+			median := sortedMedian(values)
+			deviations := make([]float64, len(values))
+			for i, v := range values {
+				deviations[i] = math.Abs(v - median)
+			}
+			return median, madScale * sortedMedian(deviations), true
+		}, cfg.Sensitivity)
+	case AnomalySeasonal:
+		bands = seasonalBands(points, cfg.Season, cfg.Sensitivity)
+	}
+
+	labels := s.GetLabels()
+	result := Anomalies{
+		Indicator: NewSeries(refID, labels, 0),
+		Upper:     NewSeries(refID, bandLabels(labels, "upper"), 0),
+		Lower:     NewSeries(refID, bandLabels(labels, "lower"), 0),
+	}
+	for i, p := range points {
+		upper, lower := bands[i][0], bands[i][1]
+		var indicator *float64
+		if upper != nil && lower != nil {
+			v := 0.0
+			if *p.f > *upper || *p.f < *lower {
+				v = 1
+			}
+			indicator = &v
+		}
+		result.Indicator.AppendPoint(p.t, indicator)
+		result.Upper.AppendPoint(p.t, upper)
+		result.Lower.AppendPoint(p.t, lower)
+	}
+	return result, nil
+}
+
+// windowBands computes the bands of each point from the center and the
+// deviation of the values of the previous points in the window.
+func windowBands(points []point, window time.Duration, baseline func(values []float64) (center, deviation float64, ok bool), sensitivity float64) [][2]*float64 {
+	bands := make([][2]*float64, len(points))
+	start := 0
+	for i, p := range points {
+		for start < i && !points[start].t.After(p.t.Add(-window)) {
+			start++
+		}
+		values := make([]float64, 0, i-start)
+		for _, prev := range points[start:i] {
+			values = append(values, *prev.f)
+		}
+		center, deviation, ok := baseline(values)
+		if !ok {
+			continue
+		}
+		upper, lower := center+sensitivity*deviation, center-sensitivity*deviation
+		bands[i] = [2]*float64{&upper, &lower}
+	}
+	return bands
+}
+
+// seasonalBands computes the bands of each point from the value predicted by a
+// Holt-Winters model fitted with the previous points. The points are assumed
+// to be equally spaced, series with irregular points should be resampled
+// first. The model is initialized with the first two seasons, and the
+// deviation is estimated from the prediction errors of the second season
+// onwards, so bands start with the third season.
+func seasonalBands(points []point, season time.Duration, sensitivity float64) [][2]*float64 {
+	bands := make([][2]*float64, len(points))
+	m, _ := seasonLength(points, season)
+	if m < 2 {
+		return bands
+	}
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = *p.f
+	}
+	hw, err := newHoltWinters(values, m)
+	if err != nil {
+		return bands
+	}
+	var squareSum float64
+	for i := m; i < len(values); i++ {
+		predicted := hw.predict(1)
+		predictions := i - m
+		if predictions >= m {
+			deviation := math.Sqrt(squareSum / float64(predictions))
+			upper, lower := predicted+sensitivity*deviation, predicted-sensitivity*deviation
+			bands[i] = [2]*float64{&upper, &lower}
+		}
+		squareSum += (values[i] - predicted) * (values[i] - predicted)
+		hw.update(values[i])
+	}
+	return bands
+}
+
+func nonNullPoints(points []point) []point {
+	filtered := make([]point, 0, len(points))
+	for _, p := range points {
+		if p.f != nil && !math.IsNaN(*p.f) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+func sortedMedian(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	l := len(sorted)
+	if l%2 == 1 {
+		return sorted[l/2]
+	}
+	return (sorted[l/2-1] + sorted[l/2]) / 2
+}
+
+func bandLabels(labels data.Labels, band string) data.Labels {
+	l := data.Labels{}
+	for k, v := range labels {
+		l[k] = v
+	}
+	l[AnomalyBandLabel] = band
+	return l
+}
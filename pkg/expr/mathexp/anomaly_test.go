@@ -0,0 +1,119 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	var points []tp
+	for i := 0; i < 8; i++ {
+		v := 10.0
+		if i%2 == 1 {
+			v = 12
+		}
+		points = append(points, tp{time.Unix(int64(i), 0), float64Pointer(v)})
+	}
+	points = append(points,
+		tp{time.Unix(8, 0), nil},
+		tp{time.Unix(9, 0), float64Pointer(30)},
+		tp{time.Unix(10, 0), float64Pointer(11)},
+	)
+	withSpike := makeSeries("", data.Labels{"host": "a"}, points...)
+
+	indicator := func(s Series) []*float64 {
+		var values []*float64
+		for i := 0; i < s.Len(); i++ {
+			values = append(values, s.GetValue(i))
+		}
+		return values
+	}
+	zero, one := float64Pointer(0), float64Pointer(1)
+
+	t.Run("zscore", func(t *testing.T) {
+		res, err := withSpike.DetectAnomalies("B", AnomalyConfig{Algorithm: AnomalyZScore, Window: 10 * time.Second, Sensitivity: 3})
+		require.NoError(t, err)
+		require.Equal(t, []*float64{nil, nil, zero, zero, zero, zero, zero, zero, one, zero}, indicator(res.Indicator))
+		require.Equal(t, data.Labels{"host": "a"}, res.Indicator.GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "upper"}, res.Upper.GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "lower"}, res.Lower.GetLabels())
+		require.Equal(t, 14.0, *res.Upper.GetValue(2))
+		require.Equal(t, 8.0, *res.Lower.GetValue(2))
+	})
+
+	t.Run("window excludes older points", func(t *testing.T) {
+		res, err := withSpike.DetectAnomalies("B", AnomalyConfig{Algorithm: AnomalyZScore, Window: 2 * time.Second, Sensitivity: 3})
+		require.NoError(t, err)
+		// the point at 9s only has the point at 7s in its window
+		require.Nil(t, res.Indicator.GetValue(8))
+	})
+
+	t.Run("mad", func(t *testing.T) {
+		var points []tp
+		for i := 0; i < 8; i++ {
+			points = append(points, tp{time.Unix(int64(i), 0), float64Pointer(float64(10 + i%3))})
+		}
+		points = append(points, tp{time.Unix(8, 0), float64Pointer(30)})
+		res, err := makeSeries("", nil, points...).DetectAnomalies("B", AnomalyConfig{Algorithm: AnomalyMAD, Window: 10 * time.Second, Sensitivity: 3})
+		require.NoError(t, err)
+		require.Equal(t, []*float64{nil, nil, zero, zero, zero, zero, zero, zero, one}, indicator(res.Indicator))
+		require.Equal(t, 11+3*madScale, *res.Upper.GetValue(3))
+	})
+
+	t.Run("mad ignores outliers in the window", func(t *testing.T) {
+		mad, err := withSpike.DetectAnomalies("B", AnomalyConfig{Algorithm: AnomalyMAD, Window: 10 * time.Second, Sensitivity: 3})
+		require.NoError(t, err)
+		zscore, err := withSpike.DetectAnomalies("B", AnomalyConfig{Algorithm: AnomalyZScore, Window: 10 * time.Second, Sensitivity: 3})
+		require.NoError(t, err)
+		// the spike in the window of the last point widens the z-score bands
+		require.Less(t, *mad.Upper.GetValue(9), 20.0)
+		require.Greater(t, *zscore.Upper.GetValue(9), 30.0)
+	})
+
+	t.Run("seasonal", func(t *testing.T) {
+		pattern := []float64{0, 10, 0, -10}
+		var points []tp
+		for i := 0; i < 20; i++ {
+			v := pattern[i%4]
+			if i == 17 {
+				v = 50
+			}
+			points = append(points, tp{time.Unix(int64(i*60), 0), float64Pointer(v)})
+		}
+		res, err := makeSeries("", nil, points...).DetectAnomalies("B", AnomalyConfig{Algorithm: AnomalySeasonal, Season: 4 * time.Minute, Sensitivity: 3})
+		require.NoError(t, err)
+		values := indicator(res.Indicator)
+		for i := 0; i < 8; i++ {
+			require.Nil(t, values[i])
+		}
+		for i := 8; i < 17; i++ {
+			require.Equal(t, zero, values[i])
+		}
+		require.Equal(t, one, values[17])
+		require.Equal(t, 10.0, *res.Upper.GetValue(13))
+	})
+
+	t.Run("seasonal without enough seasons has no bands", func(t *testing.T) {
+		res, err := withSpike.DetectAnomalies("B", AnomalyConfig{Algorithm: AnomalySeasonal, Season: time.Hour, Sensitivity: 3})
+		require.NoError(t, err)
+		require.Equal(t, withSpike.Len()-1, res.Indicator.Len())
+		for i := 0; i < res.Indicator.Len(); i++ {
+			require.Nil(t, res.Indicator.GetValue(i))
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		for _, cfg := range []AnomalyConfig{
+			{Algorithm: "prophet", Window: time.Minute, Sensitivity: 3},
+			{Algorithm: AnomalyZScore, Sensitivity: 3},
+			{Algorithm: AnomalySeasonal, Window: time.Minute, Sensitivity: 3},
+			{Algorithm: AnomalyMAD, Window: time.Minute, Sensitivity: -1},
+		} {
+			_, err := withSpike.DetectAnomalies("B", cfg)
+			require.Error(t, err)
+		}
+	})
+}
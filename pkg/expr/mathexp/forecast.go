@@ -0,0 +1,176 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// ForecastLinear fits a least squares linear regression of the values
+	// over time, like predict_linear in Prometheus.
+	ForecastLinear = "linear"
+	// ForecastHoltWinters fits a Holt-Winters model of the values, with a
+	// seasonal component when a season is set.
+	ForecastHoltWinters = "holt_winters"
+)
+
+// maxForecastPoints is the maximum number of points of a projected series.
+const maxForecastPoints = 10000
+
+// ForecastConfig configures the forecast of series.
+type ForecastConfig struct {
+	Model string
+	// Season is the duration of a season of the holt_winters model, no
+	// seasonal component is used when it is 0.
+	Season time.Duration
+}
+
+// Validate returns an error if the configuration is not valid.
+func (c ForecastConfig) Validate() error {
+	switch c.Model {
+	case ForecastLinear:
+		if c.Season != 0 {
+			return fmt.Errorf("the %s model does not support seasons", c.Model)
+		}
+	case ForecastHoltWinters:
+		if c.Season < 0 {
+			return fmt.Errorf("the season must not be negative, got %s", c.Season)
+		}
+	default:
+		return fmt.Errorf("forecast model %q is not supported, expected one of [%s, %s]", c.Model, ForecastLinear, ForecastHoltWinters)
+	}
+	return nil
+}
+
+// forecaster predicts the value of a series at a given time.
+type forecaster interface {
+	predict(t time.Time) float64
+}
+
+// fitForecaster fits the model of the configuration with the non-null points
+// of the series. It returns a nil forecaster if the series does not have
+// enough points for the model. It also returns the median interval between
+// the points and the time of the last point.
+func (s Series) fitForecaster(cfg ForecastConfig) (forecaster, time.Duration, time.Time, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, 0, time.Time{}, err
+	}
+	points := nonNullPoints(sortedPoints(s))
+	m, step := seasonLength(points, cfg.Season)
+	if len(points) < 2 || step <= 0 {
+		return nil, step, time.Time{}, nil
+	}
+	last := points[len(points)-1].t
+	switch cfg.Model {
+	case ForecastHoltWinters:
+		if cfg.Season != 0 && m < 2 {
+			return nil, step, last, fmt.Errorf("the season %s must contain at least 2 points of the series, the series has a point every %s", cfg.Season, step)
+		}
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = *p.f
+		}
+		hw, err := newHoltWinters(values, m)
+		if err != nil {
+			// not enough points yet
+			return nil, step, last, nil
+		}
+		hw.fit(values)
+		return holtWintersForecaster{hw: hw, last: last, step: step}, step, last, nil
+	default:
+		return fitLinear(points), step, last, nil
+	}
+}
+
+// Forecast returns the value of the series predicted by the model at the
+// given time. The value is null if the series does not have enough points.
+func (s Series) Forecast(refID string, cfg ForecastConfig, at time.Time) (Number, error) {
+	var l data.Labels
+	if s.GetLabels() != nil {
+		l = s.GetLabels().Copy()
+	}
+	number := NewNumber(refID, l)
+	f, _, _, err := s.fitForecaster(cfg)
+	if err != nil {
+		return number, err
+	}
+	if f != nil {
+		v := f.predict(at)
+		number.SetValue(&v)
+	}
+	return number, nil
+}
+
+// ForecastSeries returns the series of the values predicted by the model
+// after the last point of the series until the given time, with the median
+// interval between the points of the series as step.
+func (s Series) ForecastSeries(refID string, cfg ForecastConfig, to time.Time) (Series, error) {
+	var l data.Labels
+	if s.GetLabels() != nil {
+		l = s.GetLabels().Copy()
+	}
+	projected := NewSeries(refID, l, 0)
+	f, step, last, err := s.fitForecaster(cfg)
+	if err != nil || f == nil {
+		return projected, err
+	}
+	if n := to.Sub(last) / step; n > maxForecastPoints {
+		return projected, fmt.Errorf("the forecast would contain %d points, which is more than the maximum of %d", n, maxForecastPoints)
+	}
+	for t := last.Add(step); !t.After(to); t = t.Add(step) {
+		v := f.predict(t)
+		projected.AppendPoint(t, &v)
+	}
+	return projected, nil
+}
+
+// linearForecaster is a linear regression of the values over time.
+type linearForecaster struct {
+	origin    time.Time
+	intercept float64
+	// slope is the change of the value per second.
+	slope float64
+}
+
+func fitLinear(points []point) linearForecaster {
+	origin := points[0].t
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.t.Sub(origin).Seconds()
+		sumX += x
+		sumY += *p.f
+		sumXY += x * *p.f
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	f := linearForecaster{origin: origin}
+	denominator := n*sumXX - sumX*sumX
+	if denominator != 0 {
+		f.slope = (n*sumXY - sumX*sumY) / denominator
+	}
+	f.intercept = (sumY - f.slope*sumX) / n
+	return f
+}
+
+func (f linearForecaster) predict(t time.Time) float64 {
+	return f.intercept + f.slope*t.Sub(f.origin).Seconds()
+}
+
+// holtWintersForecaster predicts with a Holt-Winters model fitted with
+// points every step until last.
+type holtWintersForecaster struct {
+	hw   *holtWinters
+	last time.Time
+	step time.Duration
+}
+
+func (f holtWintersForecaster) predict(t time.Time) float64 {
+	h := int(math.Round(float64(t.Sub(f.last)) / float64(f.step)))
+	if h < 1 {
+		h = 1
+	}
+	return f.hw.predict(h)
+}
@@ -0,0 +1,83 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecast(t *testing.T) {
+	var linearPoints []tp
+	for i := 0; i < 10; i++ {
+		linearPoints = append(linearPoints, tp{time.Unix(int64(i), 0), float64Pointer(float64(2 * i))})
+	}
+	linearPoints = append(linearPoints, tp{time.Unix(10, 0), nil})
+	linear := makeSeries("", data.Labels{"disk": "sda"}, linearPoints...)
+
+	t.Run("linear value", func(t *testing.T) {
+		res, err := linear.Forecast("B", ForecastConfig{Model: ForecastLinear}, time.Unix(20, 0))
+		require.NoError(t, err)
+		require.Equal(t, data.Labels{"disk": "sda"}, res.GetLabels())
+		require.InDelta(t, 40, *res.GetFloat64Value(), 1e-9)
+	})
+
+	t.Run("linear series", func(t *testing.T) {
+		res, err := linear.ForecastSeries("B", ForecastConfig{Model: ForecastLinear}, time.Unix(12, 0))
+		require.NoError(t, err)
+		require.Equal(t, 3, res.Len())
+		for i, expected := range []float64{20, 22, 24} {
+			ts, v := res.GetPoint(i)
+			require.Equal(t, time.Unix(int64(10+i), 0), ts)
+			require.InDelta(t, expected, *v, 1e-9)
+		}
+	})
+
+	t.Run("holt-winters without season follows the trend", func(t *testing.T) {
+		res, err := linear.Forecast("B", ForecastConfig{Model: ForecastHoltWinters}, time.Unix(20, 0))
+		require.NoError(t, err)
+		require.InDelta(t, 40, *res.GetFloat64Value(), 1e-9)
+	})
+
+	t.Run("holt-winters with season repeats the season", func(t *testing.T) {
+		pattern := []float64{0, 10, 0, -10}
+		var points []tp
+		for i := 0; i < 12; i++ {
+			points = append(points, tp{time.Unix(int64(i*60), 0), float64Pointer(pattern[i%4])})
+		}
+		res, err := makeSeries("", nil, points...).ForecastSeries("B", ForecastConfig{Model: ForecastHoltWinters, Season: 4 * time.Minute}, time.Unix(15*60, 0))
+		require.NoError(t, err)
+		require.Equal(t, 4, res.Len())
+		for i := 0; i < res.Len(); i++ {
+			require.InDelta(t, pattern[i], *res.GetValue(i), 1e-9)
+		}
+	})
+
+	t.Run("null when the series does not have enough points", func(t *testing.T) {
+		s := makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}, tp{time.Unix(1, 0), nil})
+		res, err := s.Forecast("B", ForecastConfig{Model: ForecastLinear}, time.Unix(20, 0))
+		require.NoError(t, err)
+		require.Nil(t, res.GetFloat64Value())
+
+		res, err = linear.Forecast("B", ForecastConfig{Model: ForecastHoltWinters, Season: 8 * time.Second}, time.Unix(20, 0))
+		require.NoError(t, err)
+		require.Nil(t, res.GetFloat64Value())
+	})
+
+	t.Run("error when the projected series is too long", func(t *testing.T) {
+		_, err := linear.ForecastSeries("B", ForecastConfig{Model: ForecastLinear}, time.Unix(20, 0).Add(24*time.Hour))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		for _, cfg := range []ForecastConfig{
+			{Model: "prophet"},
+			{Model: ForecastLinear, Season: time.Hour},
+			{Model: ForecastHoltWinters, Season: time.Millisecond},
+		} {
+			_, err := linear.Forecast("B", cfg, time.Unix(20, 0))
+			require.Error(t, err)
+		}
+	})
+}
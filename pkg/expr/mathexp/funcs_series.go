@@ -0,0 +1,278 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// Series functions use the timestamps of the points of each series. Null
+// values are skipped: the functions comparing consecutive points compare a
+// point with the previous non-null one, and return null for null points.
+// Functions comparing consecutive points drop the first point of each series.
+
+// rate returns the per-second rate of increase of counters, handling counter
+// resets: a value lower than the previous one is an increase of the value.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) (Series, error) {
+		return perPointPair(e, s, func(prev, cur float64, dt time.Duration) float64 {
+			return counterIncrease(prev, cur) / dt.Seconds()
+		}), nil
+	})
+}
+
+// increase returns the increase of counters between consecutive points,
+// handling counter resets like rate.
+func increase(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "increase", varSet, func(s Series) (Series, error) {
+		return perPointPair(e, s, func(prev, cur float64, _ time.Duration) float64 {
+			return counterIncrease(prev, cur)
+		}), nil
+	})
+}
+
+// delta returns the difference between consecutive points.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) (Series, error) {
+		return perPointPair(e, s, func(prev, cur float64, _ time.Duration) float64 {
+			return cur - prev
+		}), nil
+	})
+}
+
+// derivative returns the per-second rate of change between consecutive points.
+func derivative(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "derivative", varSet, func(s Series) (Series, error) {
+		return perPointPair(e, s, func(prev, cur float64, dt time.Duration) float64 {
+			return (cur - prev) / dt.Seconds()
+		}), nil
+	})
+}
+
+// movingAvg returns for each point the mean of the non-null values of the
+// points in the window ending at it, the window includes its end but not its
+// start.
+func movingAvg(e *State, varSet Results, window string) (Results, error) {
+	d, err := parseSeriesDuration(window)
+	if err != nil {
+		return Results{}, err
+	}
+	if d <= 0 {
+		return Results{}, fmt.Errorf("moving_avg window must be positive, got %s", window)
+	}
+	return perSeries(e, "moving_avg", varSet, func(s Series) (Series, error) {
+		points := sortedPoints(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), len(points))
+		start, sum, count := 0, 0.0, 0
+		for i, p := range points {
+			if p.f != nil {
+				sum += *p.f
+				count++
+			}
+			for ; !points[start].t.After(p.t.Add(-d)); start++ {
+				if points[start].f != nil {
+					sum -= *points[start].f
+					count--
+				}
+			}
+			var f *float64
+			if p.f != nil && count > 0 {
+				avg := sum / float64(count)
+				f = &avg
+			}
+			newSeries.SetPoint(i, p.t, f)
+		}
+		return newSeries, nil
+	})
+}
+
+// cumsum returns the running sum of the non-null values of each series.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) (Series, error) {
+		points := sortedPoints(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), len(points))
+		sum := 0.0
+		for i, p := range points {
+			var f *float64
+			if p.f != nil {
+				sum += *p.f
+				cur := sum
+				f = &cur
+			}
+			newSeries.SetPoint(i, p.t, f)
+		}
+		return newSeries, nil
+	})
+}
+
+// shift moves the points of each series forward in time by the duration, a
+// negative duration moves them backward. This allows, for example, comparing
+// a series with itself a day ago: $A - shift($A, "1d").
+func shift(e *State, varSet Results, duration string) (Results, error) {
+	d, err := parseSeriesDuration(duration)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSeries(e, "shift", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries, nil
+	})
+}
+
+// clampMin replaces the values lower than the minimum with the minimum for
+// each result in NumberSet, SeriesSet, or Scalar.
+func clampMin(e *State, varSet Results, minSet Results) (Results, error) {
+	minValue, err := scalarArg("clamp_min", minSet)
+	if err != nil {
+		return Results{}, err
+	}
+	return perValue(e, varSet, func(f float64) float64 {
+		return math.Max(f, minValue)
+	})
+}
+
+// clampMax replaces the values greater than the maximum with the maximum for
+// each result in NumberSet, SeriesSet, or Scalar.
+func clampMax(e *State, varSet Results, maxSet Results) (Results, error) {
+	maxValue, err := scalarArg("clamp_max", maxSet)
+	if err != nil {
+		return Results{}, err
+	}
+	return perValue(e, varSet, func(f float64) float64 {
+		return math.Min(f, maxValue)
+	})
+}
+
+func perValue(e *State, varSet Results, floatF func(x float64) float64) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perNullableFloat(e, res, func(f *float64) *float64 {
+			if f == nil {
+				return nil
+			}
+			nF := floatF(*f)
+			return &nF
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+func scalarArg(name string, res Results) (float64, error) {
+	if len(res.Values) != 1 || res.Values[0].Type() != parse.TypeScalar {
+		return 0, fmt.Errorf("%s expects a scalar argument", name)
+	}
+	f := res.Values[0].(Scalar).GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s expects a non-null scalar argument", name)
+	}
+	return *f, nil
+}
+
+// perSeries calls seriesF for each series of varSet. Values of other types
+// than series and no data are an error.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newSeries, err := seriesF(v)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, newSeries)
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s can only be applied to time series, got %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// perPointPair returns a series with a point for each point of s but the
+// first, whose value is the result of pairF for the value of the point and
+// the one of the previous non-null point.
+func perPointPair(e *State, s Series, pairF func(prev, cur float64, dt time.Duration) float64) Series {
+	points := sortedPoints(s)
+	if len(points) == 0 {
+		return NewSeries(e.RefID, s.GetLabels(), 0)
+	}
+	newSeries := NewSeries(e.RefID, s.GetLabels(), len(points)-1)
+	prev := points[0]
+	for i, p := range points[1:] {
+		var f *float64
+		switch {
+		case p.f == nil:
+		case prev.f == nil:
+			prev = p
+		default:
+			if dt := p.t.Sub(prev.t); dt > 0 {
+				v := pairF(*prev.f, *p.f, dt)
+				f = &v
+			}
+			prev = p
+		}
+		newSeries.SetPoint(i, p.t, f)
+	}
+	return newSeries
+}
+
+func counterIncrease(prev, cur float64) float64 {
+	if cur < prev {
+		// counter reset, it counted from 0 to cur since the previous point
+		return cur
+	}
+	return cur - prev
+}
+
+type point struct {
+	t time.Time
+	f *float64
+}
+
+// sortedPoints returns the points of s sorted by time, without changing the
+// order of the points of s which may be used by other expressions.
+func sortedPoints(s Series) []point {
+	points := make([]point, s.Len())
+	for i := range points {
+		points[i].t, points[i].f = s.GetPoint(i)
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].t.Before(points[j].t)
+	})
+	return points
+}
+
+func parseSeriesDuration(s string) (time.Duration, error) {
+	d, err := gtime.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// checkDurationArg checks at parse time that the argument at index i of the
+// function is a valid duration.
+func checkDurationArg(i int) func(t *parse.Tree, f *parse.FuncNode) error {
+	return func(t *parse.Tree, f *parse.FuncNode) error {
+		s, ok := f.Args[i].(*parse.StringNode)
+		if !ok {
+			return fmt.Errorf("%s expects a duration string as argument %d", f.Name, i+1)
+		}
+		_, err := parseSeriesDuration(s.Text)
+		return err
+	}
+}
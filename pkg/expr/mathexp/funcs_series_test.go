@@ -0,0 +1,205 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestSeriesFuncs(t *testing.T) {
+	counter := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", data.Labels{"host": "a"},
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(30)},
+				tp{time.Unix(20, 0), nil},
+				tp{time.Unix(30, 0), float64Pointer(70)},
+				tp{time.Unix(40, 0), float64Pointer(5)},
+			),
+		),
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "rate handles null values and counter resets",
+			expr:      "rate($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(2)},
+					tp{time.Unix(40, 0), float64Pointer(0.5)},
+				),
+			),
+		},
+		{
+			name:      "increase",
+			expr:      "increase($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(40)},
+					tp{time.Unix(40, 0), float64Pointer(5)},
+				),
+			),
+		},
+		{
+			name:      "delta",
+			expr:      "delta($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(40)},
+					tp{time.Unix(40, 0), float64Pointer(-65)},
+				),
+			),
+		},
+		{
+			name:      "derivative",
+			expr:      "derivative($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(2)},
+					tp{time.Unix(40, 0), float64Pointer(-6.5)},
+				),
+			),
+		},
+		{
+			name:      "moving_avg",
+			expr:      `moving_avg($A, "20s")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(70)},
+					tp{time.Unix(40, 0), float64Pointer(37.5)},
+				),
+			),
+		},
+		{
+			name:      "cumsum",
+			expr:      "cumsum($A)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(40)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(110)},
+					tp{time.Unix(40, 0), float64Pointer(115)},
+				),
+			),
+		},
+		{
+			name:      "shift and math on the overlapping points",
+			expr:      `$A - shift($A, "10s")`,
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(-65)},
+				),
+			),
+		},
+		{
+			name:      "clamp_min and clamp_max",
+			expr:      "clamp_max(clamp_min($A, 20), 50)",
+			vars:      counter,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(20)},
+					tp{time.Unix(10, 0), float64Pointer(30)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(50)},
+					tp{time.Unix(40, 0), float64Pointer(20)},
+				),
+			),
+		},
+		{
+			name: "clamp_min on number",
+			expr: "clamp_min($A, -1)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(-7))),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(makeNumber("", nil, float64Pointer(-1))),
+		},
+		{
+			name: "rate on number - should error",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(-7))),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:     "rate on scalar - should error",
+			expr:     "rate(1)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg with invalid window - should error",
+			expr:     `moving_avg($A, "soon")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "shift without duration - should error",
+			expr:     `shift($A)`,
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				tt.execErrIs(t, err)
+				if err == nil {
+					require.Equal(t, tt.results, res)
+				}
+			}
+		})
+	}
+}
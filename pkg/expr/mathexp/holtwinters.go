@@ -0,0 +1,119 @@
+package mathexp
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// Smoothing factors of the Holt-Winters model for the level, the trend and
+// the seasonal components.
+const (
+	holtWintersAlpha = 0.5
+	holtWintersBeta  = 0.1
+	holtWintersGamma = 0.3
+)
+
+// holtWinters is an additive Holt-Winters (triple exponential smoothing)
+// model of equally spaced values with a season of m values. When m is 0 the
+// model has no seasonal component, it is then Holt's linear trend model.
+type holtWinters struct {
+	m        int
+	level    float64
+	trend    float64
+	seasonal []float64
+	// n is the number of values the model was fitted with.
+	n int
+}
+
+// newHoltWinters initializes the model with the first two seasons of values,
+// it requires at least 2*m values, or 2 values without season.
+func newHoltWinters(values []float64, m int) (*holtWinters, error) {
+	if m == 0 {
+		if len(values) < 2 {
+			return nil, errors.New("the series must contain at least 2 points")
+		}
+		return &holtWinters{
+			level: values[0],
+			trend: values[1] - values[0],
+			n:     1,
+		}, nil
+	}
+	if m < 2 {
+		return nil, errors.New("the season must contain at least 2 points")
+	}
+	if len(values) < 2*m {
+		return nil, errors.New("the series must contain at least two seasons of points")
+	}
+	first, second := mean(values[:m]), mean(values[m:2*m])
+	hw := &holtWinters{
+		m:        m,
+		level:    first,
+		trend:    (second - first) / float64(m),
+		seasonal: make([]float64, m),
+		n:        m,
+	}
+	for i := 0; i < m; i++ {
+		hw.seasonal[i] = values[i] - first
+	}
+	return hw, nil
+}
+
+// fit updates the model with the values it was not fitted with yet.
+func (hw *holtWinters) fit(values []float64) {
+	for _, v := range values[hw.n:] {
+		hw.update(v)
+	}
+}
+
+// predict returns the value expected h values after the last fitted one.
+func (hw *holtWinters) predict(h int) float64 {
+	return hw.level + float64(h)*hw.trend + hw.season(hw.n+h-1)
+}
+
+// update fits the model with the next value.
+func (hw *holtWinters) update(x float64) {
+	lastLevel := hw.level
+	hw.level = holtWintersAlpha*(x-hw.season(hw.n)) + (1-holtWintersAlpha)*(hw.level+hw.trend)
+	hw.trend = holtWintersBeta*(hw.level-lastLevel) + (1-holtWintersBeta)*hw.trend
+	if hw.m > 0 {
+		s := hw.n % hw.m
+		hw.seasonal[s] = holtWintersGamma*(x-hw.level) + (1-holtWintersGamma)*hw.seasonal[s]
+	}
+	hw.n++
+}
+
+// season returns the seasonal component of the i-th value.
+func (hw *holtWinters) season(i int) float64 {
+	if hw.m == 0 {
+		return 0
+	}
+	return hw.seasonal[i%hw.m]
+}
+
+// seasonLength returns the number of points in a season, using the median
+// interval between the points as the step of the series.
+func seasonLength(points []point, season time.Duration) (int, time.Duration) {
+	if len(points) < 2 {
+		return 0, 0
+	}
+	steps := make([]time.Duration, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		steps = append(steps, points[i].t.Sub(points[i-1].t))
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i] < steps[j] })
+	step := steps[len(steps)/2]
+	if step <= 0 {
+		return 0, 0
+	}
+	return int(math.Round(float64(season) / float64(step))), step
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
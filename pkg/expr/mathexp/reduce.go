@@ -3,6 +3,8 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -81,6 +83,134 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a reducer of the p-th percentile, interpolating linearly
+// between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values, ok := numberValues(fv)
+		if !ok || len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		sort.Float64s(values)
+		rank := p / 100 * float64(len(values)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// Variance returns the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	values, ok := numberValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var squareSum float64
+	for _, v := range values {
+		squareSum += (v - mean) * (v - mean)
+	}
+	f := squareSum / float64(len(values))
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+func Range(fv *Float64Field) *float64 {
+	f := *Max(fv) - *Min(fv)
+	return &f
+}
+
+func Diff(fv *Float64Field) *float64 {
+	return firstLastDiff(fv, func(first, last float64) float64 {
+		return last - first
+	})
+}
+
+func DiffAbs(fv *Float64Field) *float64 {
+	return firstLastDiff(fv, func(first, last float64) float64 {
+		return math.Abs(last - first)
+	})
+}
+
+// PercentDiff returns the difference between the last and the first value as
+// a percentage of the first value.
+func PercentDiff(fv *Float64Field) *float64 {
+	return firstLastDiff(fv, func(first, last float64) float64 {
+		return (last - first) / math.Abs(first) * 100
+	})
+}
+
+func PercentDiffAbs(fv *Float64Field) *float64 {
+	return firstLastDiff(fv, func(first, last float64) float64 {
+		return math.Abs((last - first) / first * 100)
+	})
+}
+
+// CountNonNull returns the number of values that are neither null nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+func firstLastDiff(fv *Float64Field, diffF func(first, last float64) float64) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	first, last := fv.GetValue(0), fv.GetValue(fv.Len()-1)
+	if first == nil || last == nil || math.IsNaN(*first) || math.IsNaN(*last) {
+		nan := math.NaN()
+		return &nan
+	}
+	f := diffF(*first, *last)
+	return &f
+}
+
+// numberValues returns a copy of the values of the field, and false if any of
+// them is null or NaN.
+func numberValues(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 	switch strings.ToLower(rFunc) {
 	case "sum":
@@ -95,14 +225,51 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 		return Count, nil
 	case "last":
 		return Last, nil
+	case "first":
+		return First, nil
+	case "median":
+		return Median, nil
+	case "stddev":
+		return StdDev, nil
+	case "variance":
+		return Variance, nil
+	case "range":
+		return Range, nil
+	case "diff":
+		return Diff, nil
+	case "diff_abs":
+		return DiffAbs, nil
+	case "percent_diff":
+		return PercentDiff, nil
+	case "percent_diff_abs":
+		return PercentDiffAbs, nil
+	case "count_non_null":
+		return CountNonNull, nil
 	default:
+		if p, ok := parsePercentile(rFunc); ok {
+			return Percentile(p), nil
+		}
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// parsePercentile parses percentile reducers such as p95 or p99.9.
+func parsePercentile(rFunc string) (float64, bool) {
+	if len(rFunc) < 2 || (rFunc[0] != 'p' && rFunc[0] != 'P') {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(rFunc[1:], 64)
+	if err != nil || math.IsNaN(p) || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
+// GetSupportedReduceFuncs returns collection of supported function names.
+// Percentiles are also supported as pNN, for example p95.
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "first", "median", "stddev", "variance", "range",
+		"diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null"}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
@@ -386,3 +386,184 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	values := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("temp", nil,
+				tp{time.Unix(5, 0), float64Pointer(4)},
+				tp{time.Unix(10, 0), float64Pointer(1)},
+				tp{time.Unix(15, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), float64Pointer(8)}),
+		),
+	}
+	valuesWithNil := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("temp", nil,
+				tp{time.Unix(5, 0), nil},
+				tp{time.Unix(10, 0), float64Pointer(2)},
+				tp{time.Unix(15, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), NaN}),
+		),
+	}
+
+	var tests = []struct {
+		name    string
+		red     string
+		vars    Vars
+		mapper  ReduceMapper
+		errIs   require.ErrorAssertionFunc
+		results Results
+	}{
+		{
+			name:    "first",
+			red:     "first",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(4))),
+		},
+		{
+			name:    "first empty series",
+			red:     "first",
+			vars:    seriesEmpty,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:    "median of even number of values",
+			red:     "median",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(3.5))),
+		},
+		{
+			name:    "percentile interpolates between ranks",
+			red:     "p75",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(5))),
+		},
+		{
+			name:    "percentile with decimals",
+			red:     "p100.0",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(8))),
+		},
+		{
+			name:  "percentile out of range - should error",
+			red:   "p101",
+			vars:  values,
+			errIs: require.Error,
+		},
+		{
+			name:    "variance",
+			red:     "variance",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(6.5))),
+		},
+		{
+			name:    "stddev",
+			red:     "stddev",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(math.Sqrt(6.5)))),
+		},
+		{
+			name:    "range",
+			red:     "range",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(7))),
+		},
+		{
+			name:    "diff",
+			red:     "diff",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(4))),
+		},
+		{
+			name:    "percent_diff",
+			red:     "percent_diff",
+			vars:    values,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(100))),
+		},
+		{
+			name:    "count_non_null",
+			red:     "count_non_null",
+			vars:    valuesWithNil,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+		{
+			name:    "median series with a nil value",
+			red:     "median",
+			vars:    valuesWithNil,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:    "diff series with a nil value",
+			red:     "diff",
+			vars:    valuesWithNil,
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:    "dropNN: median series with a nil value",
+			red:     "median",
+			vars:    valuesWithNil,
+			mapper:  DropNonNumber{},
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(2.5))),
+		},
+		{
+			name:    "dropNN: diff series with a nil value",
+			red:     "diff",
+			vars:    valuesWithNil,
+			mapper:  DropNonNumber{},
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+		},
+		{
+			name:    "dropNN: stddev empty series",
+			red:     "stddev",
+			vars:    seriesEmpty,
+			mapper:  DropNonNumber{},
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, nil)),
+		},
+		{
+			name:    "replaceNN: range series with a nil value",
+			red:     "range",
+			vars:    valuesWithNil,
+			mapper:  ReplaceNonNumberWithValue{Value: 10},
+			errIs:   require.NoError,
+			results: resultValuesNoErr(makeNumber("", nil, float64Pointer(8))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Results{}
+			for _, series := range tt.vars["A"].Values {
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.mapper)
+				tt.errIs(t, err)
+				if err != nil {
+					return
+				}
+				results.Values = append(results.Values, ns)
+			}
+			opt := cmp.Comparer(func(x, y float64) bool {
+				return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+			})
+			options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+			if diff := cmp.Diff(tt.results, results, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
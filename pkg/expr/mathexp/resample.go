@@ -16,6 +16,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
+	var lastSeenTime time.Time
 	idx := 0
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
@@ -32,10 +33,11 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 			bookmark++
 			sIdx++
 			lastSeen = v
+			lastSeenTime = st
 			vals = append(vals, v)
 		}
 		var value *float64
-		if len(vals) == 0 { // upsampling
+		if len(vals) == 0 && downsampler != "count" { // upsampling, an empty window still has a count of 0
 			switch upsampler {
 			case "pad":
 				if lastSeen != nil {
@@ -51,10 +53,15 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 				}
 			case "fillna":
 				value = nil
+			case "linear":
+				if lastSeen != nil && sIdx < s.Len() {
+					nextTime, next := s.GetPoint(sIdx)
+					value = interpolate(lastSeenTime, *lastSeen, nextTime, next, t)
+				}
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
-		} else if len(vals) == 1 {
+		} else if len(vals) == 1 && downsampler != "count" { // a single value is kept as is, but still counted
 			value = vals[0]
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
@@ -71,6 +78,12 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 				tmp = Max(&ff)
 			case "last":
 				tmp = Last(&ff)
+			case "first":
+				tmp = First(&ff)
+			case "median":
+				tmp = Median(&ff)
+			case "count":
+				tmp = Count(&ff)
 			default:
 				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
 			}
@@ -82,3 +95,17 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	}
 	return resampled, nil
 }
+
+// interpolate returns the value at t on the line between the points
+// (prevTime, prev) and (nextTime, next), or nil if next is nil.
+func interpolate(prevTime time.Time, prev float64, nextTime time.Time, next *float64, t time.Time) *float64 {
+	if next == nil {
+		return nil
+	}
+	span := nextTime.Sub(prevTime)
+	if span <= 0 {
+		return next
+	}
+	f := prev + (*next-prev)*float64(t.Sub(prevTime))/float64(span)
+	return &f
+}
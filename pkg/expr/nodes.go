@@ -122,6 +122,15 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
 	case TypeThreshold:
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
+	case TypeSQL:
+		if !toggles.IsEnabledGlobally(featuremgmt.FlagSqlExpressions) {
+			return nil, fmt.Errorf("sql expressions are disabled, enable the %s feature toggle to use them", featuremgmt.FlagSqlExpressions)
+		}
+		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -149,6 +158,10 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// isInputToSQLExpr is set when the node is only an input to SQL
+	// expressions, its frames are then returned as tables.
+	isInputToSQLExpr bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -289,6 +302,12 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 					return
 				}
 
+				if dn.isInputToSQLExpr {
+					vars[dn.refID] = framesToTableResults(dataFrames)
+					instrument(nil, "table")
+					continue
+				}
+
 				var result mathexp.Results
 				responseType, result, err := convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
 				if err != nil {
@@ -357,6 +376,11 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 		return mathexp.Results{}, MakeQueryError(dn.refID, dn.datasource.UID, err)
 	}
 
+	if dn.isInputToSQLExpr {
+		responseType = "table"
+		return framesToTableResults(dataFrames), nil
+	}
+
 	var result mathexp.Results
 	responseType, result, err = convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
 	if err != nil {
@@ -365,6 +389,18 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	return result, err
 }
 
+// framesToTableResults returns the frames of a response as tables.
+func framesToTableResults(frames data.Frames) mathexp.Results {
+	if len(frames) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}
+	}
+	vals := make(mathexp.Values, 0, len(frames))
+	for _, frame := range frames {
+		vals = append(vals, mathexp.TableData{Frame: frame})
+	}
+	return mathexp.Results{Values: vals}
+}
+
 func getResponseFrame(resp *backend.QueryDataResponse, refID string) (data.Frames, error) {
 	response, ok := resp.Responses[refID]
 	if !ok {
@@ -561,7 +597,9 @@ func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 			}
 			key := stringFieldNames[i] // TODO check for duplicate string column names
 			val, _ := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
-			labels[key] = val.(string) // TODO check assertion / return error
+			if s, ok := val.(string); ok { // null values of nullable fields are not labels
+				labels[key] = s
+			}
 		}
 
 		n := mathexp.NewNumber(frame.Fields[numericField].Name, labels)
@@ -115,6 +115,35 @@ func (s *Service) ExecutePipeline(ctx context.Context, now time.Time, pipeline D
 	return res, nil
 }
 
+// WithQueryDataHandler returns a copy of the service which sends the queries
+// of data source nodes to handler instead of the data source plugins. The
+// plugin context of the queries only holds the settings of the data source.
+func (s *Service) WithQueryDataHandler(handler backend.QueryDataHandler) *Service {
+	c := *s
+	c.dataService = handler
+	c.pCtxProvider = dataSourcePluginContextProvider{}
+	return &c
+}
+
+// dataSourcePluginContextProvider returns plugin contexts built from the data
+// source model, without looking up the plugin.
+type dataSourcePluginContextProvider struct{}
+
+func (dataSourcePluginContextProvider) Get(_ context.Context, pluginID string, _ identity.Requester, orgID int64) (backend.PluginContext, error) {
+	return backend.PluginContext{OrgID: orgID, PluginID: pluginID}, nil
+}
+
+func (p dataSourcePluginContextProvider) GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error) {
+	pCtx, err := p.Get(ctx, pluginID, user, ds.OrgID)
+	pCtx.DataSourceInstanceSettings = &backend.DataSourceInstanceSettings{
+		ID:   ds.ID,
+		UID:  ds.UID,
+		Type: ds.Type,
+		Name: ds.Name,
+	}
+	return pCtx, err
+}
+
 // Create a datasources.DataSource struct from NodeType. Returns error if kind is TypeDatasourceNode or unknown one.
 func DataSourceModelFromNodeType(kind NodeType) (*datasources.DataSource, error) {
 	switch kind {
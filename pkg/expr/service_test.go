@@ -116,6 +116,42 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestServiceWithQueryDataHandler(t *testing.T) {
+	me := &mockEndpoint{
+		Responses: map[string]backend.DataResponse{
+			"A": {Frames: data.Frames{data.NewFrame("test",
+				data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+				data.NewField("value", nil, []*float64{fp(2)}))}},
+		},
+	}
+	s := (&Service{
+		cfg:      setting.NewCfg(),
+		features: &featuremgmt.FeatureManager{},
+		tracer:   tracing.InitializeTracerForTest(),
+		metrics:  newMetrics(nil),
+	}).WithQueryDataHandler(me)
+
+	pl, err := s.BuildPipeline(&Request{Queries: []Query{
+		{
+			RefID:      "A",
+			DataSource: &datasources.DataSource{OrgID: 1, UID: "unknown", Type: "unknown"},
+			JSON:       json.RawMessage(`{}`),
+			TimeRange:  AbsoluteTimeRange{},
+		},
+		{
+			RefID:      "B",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+		},
+	}, User: &user.SignedInUser{}})
+	require.NoError(t, err)
+
+	res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+	require.NoError(t, err)
+	require.NoError(t, res.Responses["B"].Error)
+	require.Equal(t, 4.0, *res.Responses["B"].Frames[0].Fields[1].At(0).(*float64))
+}
+
 func TestDSQueryError(t *testing.T) {
 	me := &mockEndpoint{
 		Responses: map[string]backend.DataResponse{
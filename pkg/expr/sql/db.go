@@ -0,0 +1,368 @@
+// Package sql runs SQL queries over data frames with an in-memory SQLite
+// database.
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// queryTimeout bounds the time a query runs, recursive common table
+	// expressions may never end.
+	queryTimeout = 10 * time.Second
+	// maxCells bounds the number of values of the result of a query.
+	maxCells = 1_000_000
+)
+
+// sqliteRecursive is the authorizer action code of recursive common table
+// expressions, go-sqlite3 does not define it.
+const sqliteRecursive = 33
+
+// ErrNotAllowed is returned for queries doing anything else than reading the
+// tables, e.g. attaching databases or modifying tables.
+var ErrNotAllowed = errors.New("only SELECT statements are allowed")
+
+// Query loads the frames of each table in a new in-memory database and
+// returns the result of query as a frame.
+//
+// The rows of all the frames of a table are loaded in it, the columns of the
+// table are the union of the fields of its frames. Fields are matched by name,
+// so the names of the fields of a frame must be unique.
+func Query(ctx context.Context, query string, tables map[string][]*data.Frame) (*data.Frame, error) {
+	if !isSelect(query) {
+		return nil, ErrNotAllowed
+	}
+
+	// Every connection to :memory: opens a separate database, so everything
+	// runs on a single connection.
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := loadTable(ctx, conn, name, tables[name]); err != nil {
+			return nil, fmt.Errorf("failed to load table %s: %w", name, err)
+		}
+	}
+
+	if err := conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		c.RegisterAuthorizer(readOnlyAuthorizer)
+		// VACUUM INTO is not checked by the authorizer, it fails to attach
+		// the database it writes to.
+		c.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// go-sqlite3 interrupts the query once the context is done.
+	queryCtx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	rows, err := conn.QueryContext(queryCtx, query)
+	if err != nil {
+		return nil, checkAuthorization(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	frame, err := readFrame(rows)
+	if err != nil {
+		return nil, checkAuthorization(err)
+	}
+	return frame, nil
+}
+
+// checkAuthorization returns ErrNotAllowed for the errors of the authorizer.
+// Statements denied when prepared fail with SQLITE_AUTH, virtual tables
+// checking the authorizer when they run fail with a generic error.
+func checkAuthorization(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrAuth || sqliteErr.Error() == "not authorized") {
+		return ErrNotAllowed
+	}
+	return err
+}
+
+func readOnlyAuthorizer(action int, _, _, _ string) int {
+	switch action {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqlite3.SQLITE_FUNCTION, sqliteRecursive:
+		return sqlite3.SQLITE_OK
+	default:
+		return sqlite3.SQLITE_DENY
+	}
+}
+
+type column struct {
+	name     string
+	declType string
+}
+
+func loadTable(ctx context.Context, conn *sql.Conn, name string, frames []*data.Frame) error {
+	var columns []column
+	seen := map[string]bool{}
+	for _, frame := range frames {
+		names := map[string]bool{}
+		for _, field := range frame.Fields {
+			if names[field.Name] {
+				return fmt.Errorf("duplicate column %q", field.Name)
+			}
+			names[field.Name] = true
+			if seen[field.Name] {
+				continue
+			}
+			seen[field.Name] = true
+			declType, err := columnType(field.Type())
+			if err != nil {
+				return fmt.Errorf("column %q: %w", field.Name, err)
+			}
+			columns = append(columns, column{name: field.Name, declType: declType})
+		}
+	}
+
+	definitions := make([]string, len(columns))
+	for i, c := range columns {
+		definitions[i] = quoteIdent(c.name) + " " + c.declType
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(name), strings.Join(definitions, ", "))); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		names := make([]string, len(frame.Fields))
+		placeholders := make([]string, len(frame.Fields))
+		for i, field := range frame.Fields {
+			names[i] = quoteIdent(field.Name)
+			placeholders[i] = "?"
+		}
+		stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdent(name), strings.Join(names, ", "), strings.Join(placeholders, ", ")))
+		if err != nil {
+			return err
+		}
+		args := make([]any, len(frame.Fields))
+		for row := 0; row < frame.Rows(); row++ {
+			for i, field := range frame.Fields {
+				args[i] = bindValue(field, row)
+			}
+			if _, err := stmt.ExecContext(ctx, args...); err != nil {
+				_ = stmt.Close()
+				return err
+			}
+		}
+		if err := stmt.Close(); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// columnType returns the declared type of the column of a field. Columns
+// declared DATETIME and BOOLEAN are read back as time.Time and bool.
+func columnType(t data.FieldType) (string, error) {
+	switch {
+	case t == data.FieldTypeUint64 || t == data.FieldTypeNullableUint64:
+		// may not fit in a signed 64-bit integer
+		return "REAL", nil
+	case t.Numeric() && (t.NonNullableType() == data.FieldTypeFloat32 || t.NonNullableType() == data.FieldTypeFloat64):
+		return "REAL", nil
+	case t.Numeric():
+		return "INTEGER", nil
+	case t.Time():
+		return "DATETIME", nil
+	}
+	switch t.NonNullableType() {
+	case data.FieldTypeString, data.FieldTypeJSON, data.FieldTypeEnum:
+		return "TEXT", nil
+	case data.FieldTypeBool:
+		return "BOOLEAN", nil
+	}
+	return "", fmt.Errorf("unsupported field type %s", t.ItemTypeString())
+}
+
+func bindValue(field *data.Field, row int) any {
+	v, ok := field.ConcreteAt(row)
+	if !ok {
+		return nil
+	}
+	switch v := v.(type) {
+	case uint64:
+		return float64(v)
+	case time.Time:
+		return v.UTC()
+	case json.RawMessage:
+		return string(v)
+	case data.EnumItemIndex:
+		if field.Config != nil && field.Config.TypeConfig != nil && field.Config.TypeConfig.Enum != nil &&
+			int(v) < len(field.Config.TypeConfig.Enum.Text) {
+			return field.Config.TypeConfig.Enum.Text[v]
+		}
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+// readFrame reads rows in a frame. SQLite columns have no fixed type, the
+// type of each field is the one of the non-null values of its column.
+// Integers are converted to floats in columns which hold both. It fails once
+// the rows hold more than maxCells values.
+func readFrame(rows *sql.Rows) (*data.Frame, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(columnTypes))
+	for i, c := range columnTypes {
+		names[i] = c.Name()
+	}
+
+	values := make([][]any, len(names))
+	scanned := make([]any, len(names))
+	dest := make([]any, len(names))
+	for i := range dest {
+		dest[i] = &scanned[i]
+	}
+	cells := 0
+	for rows.Next() {
+		if cells += len(names); cells > maxCells {
+			return nil, fmt.Errorf("query result exceeds the limit of %d values", maxCells)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, v := range scanned {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			values[i] = append(values[i], v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame("")
+	for i, name := range names {
+		field, err := newField(name, columnTypes[i].DatabaseTypeName(), values[i])
+		if err != nil {
+			return nil, err
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame, nil
+}
+
+func newField(name, declType string, values []any) (*data.Field, error) {
+	fieldType := data.FieldTypeUnknown
+	for _, v := range values {
+		var t data.FieldType
+		switch v.(type) {
+		case nil:
+			continue
+		case int64:
+			t = data.FieldTypeNullableInt64
+		case float64:
+			t = data.FieldTypeNullableFloat64
+		case string:
+			t = data.FieldTypeNullableString
+		case time.Time:
+			t = data.FieldTypeNullableTime
+		case bool:
+			t = data.FieldTypeNullableBool
+		default:
+			return nil, fmt.Errorf("column %q: unsupported value type %T", name, v)
+		}
+		switch {
+		case fieldType == data.FieldTypeUnknown || fieldType == t:
+			fieldType = t
+		case fieldType.Numeric() && t.Numeric():
+			fieldType = data.FieldTypeNullableFloat64
+		default:
+			return nil, fmt.Errorf("column %q holds values of different types: %s and %s", name, fieldType.ItemTypeString(), t.ItemTypeString())
+		}
+	}
+	if fieldType == data.FieldTypeUnknown {
+		// only nulls, the declared type is known for the columns of tables
+		fieldType = declaredFieldType(declType)
+	}
+
+	field := data.NewFieldFromFieldType(fieldType, len(values))
+	field.Name = name
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+			continue
+		case int64:
+			if fieldType == data.FieldTypeNullableFloat64 {
+				f := float64(v)
+				field.Set(i, &f)
+				continue
+			}
+			field.Set(i, &v)
+		case float64:
+			field.Set(i, &v)
+		case string:
+			field.Set(i, &v)
+		case time.Time:
+			field.Set(i, &v)
+		case bool:
+			field.Set(i, &v)
+		}
+	}
+	return field, nil
+}
+
+// declaredFieldType returns the field type of a column declared as declType,
+// following the type affinity rules of SQLite.
+func declaredFieldType(declType string) data.FieldType {
+	declType = strings.ToUpper(declType)
+	switch {
+	case declType == "DATETIME" || declType == "TIMESTAMP" || declType == "DATE":
+		return data.FieldTypeNullableTime
+	case declType == "BOOLEAN":
+		return data.FieldTypeNullableBool
+	case strings.Contains(declType, "INT"):
+		return data.FieldTypeNullableInt64
+	case strings.Contains(declType, "CHAR") || strings.Contains(declType, "CLOB") || strings.Contains(declType, "TEXT"):
+		return data.FieldTypeNullableString
+	default:
+		return data.FieldTypeNullableFloat64
+	}
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
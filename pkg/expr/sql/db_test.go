@@ -0,0 +1,146 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	metrics := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(10, 0), time.Unix(10, 0), time.Unix(20, 0)}),
+		data.NewField("host", nil, []string{"a", "b", "a"}),
+		data.NewField("value", nil, []*float64{fp(1), fp(2), nil}),
+	)
+	inventory := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("team", nil, []string{"red", "blue"}),
+		data.NewField("cores", nil, []int64{4, 8}),
+		data.NewField("active", nil, []bool{true, false}),
+	)
+	tables := map[string][]*data.Frame{
+		"A": {metrics},
+		"B": {inventory},
+	}
+
+	t.Run("join", func(t *testing.T) {
+		frame, err := Query(context.Background(), `
+			SELECT A.time, B.team, A.value * B.cores AS total, B.active
+			FROM A JOIN B ON A.host = B.host
+			ORDER BY A.time, B.team`, tables)
+		require.NoError(t, err)
+
+		require.Len(t, frame.Fields, 4)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, time.Unix(10, 0).UTC(), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, "blue", *frame.Fields[1].At(0).(*string))
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		require.Equal(t, 16.0, *frame.Fields[2].At(0).(*float64))
+		require.Nil(t, frame.Fields[2].At(2))
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[3].Type())
+		require.False(t, *frame.Fields[3].At(0).(*bool))
+	})
+
+	t.Run("group by", func(t *testing.T) {
+		frame, err := Query(context.Background(), `SELECT host, count(*) AS points FROM A GROUP BY host ORDER BY host`, tables)
+		require.NoError(t, err)
+		require.Equal(t, "points", frame.Fields[1].Name)
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[1].Type())
+		require.Equal(t, int64(2), *frame.Fields[1].At(0).(*int64))
+		require.Equal(t, int64(1), *frame.Fields[1].At(1).(*int64))
+	})
+
+	t.Run("integers and floats in the same column are read as floats", func(t *testing.T) {
+		frame, err := Query(context.Background(), `SELECT 1 AS v UNION ALL SELECT 1.5`, tables)
+		require.NoError(t, err)
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[0].Type())
+		require.Equal(t, 1.0, *frame.Fields[0].At(0).(*float64))
+	})
+
+	t.Run("frames of a table are unioned", func(t *testing.T) {
+		frame, err := Query(context.Background(), `SELECT count(*), count(team) FROM C`, map[string][]*data.Frame{
+			"C": {metrics, inventory},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(5), *frame.Fields[0].At(0).(*int64))
+		require.Equal(t, int64(2), *frame.Fields[1].At(0).(*int64))
+	})
+
+	t.Run("duplicate column names are rejected", func(t *testing.T) {
+		_, err := Query(context.Background(), `SELECT * FROM C`, map[string][]*data.Frame{
+			"C": {data.NewFrame("", data.NewField("v", nil, []int64{1}), data.NewField("v", nil, []int64{2}))},
+		})
+		require.ErrorContains(t, err, "duplicate column")
+	})
+
+	for _, query := range []string{
+		`ATTACH DATABASE '/tmp/grafana.db' AS other`,
+		`DELETE FROM A`,
+		`INSERT INTO A (host) VALUES ('c')`,
+		`CREATE TABLE D (v int)`,
+		`PRAGMA table_info(A)`,
+		`VACUUM INTO '/tmp/copy.db'`,
+		`SELECT 1; VACUUM INTO '/tmp/copy.db'`,
+		`WITH t AS (SELECT 1) DELETE FROM A`,
+		`SELECT * FROM pragma_table_info('A')`,
+	} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			_, err := Query(context.Background(), query, tables)
+			require.ErrorIs(t, err, ErrNotAllowed)
+		})
+	}
+
+	t.Run("recursive common table expressions are allowed", func(t *testing.T) {
+		frame, err := Query(context.Background(), `
+			WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 5)
+			SELECT sum(i) FROM n`, tables)
+		require.NoError(t, err)
+		require.Equal(t, int64(15), *frame.Fields[0].At(0).(*int64))
+	})
+
+	t.Run("results are limited", func(t *testing.T) {
+		_, err := Query(context.Background(), `
+			WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n)
+			SELECT i FROM n`, tables)
+		require.ErrorContains(t, err, "exceeds the limit")
+	})
+
+	t.Run("queries are interrupted when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := Query(ctx, `
+			WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n)
+			SELECT count(*) FROM n`, tables)
+		require.Error(t, err)
+	})
+}
+
+func TestTablesList(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{`SELECT * FROM A`, []string{"A"}},
+		{`select * from A a join "B" b on a.x = b.x left join [C] using (x)`, []string{"A", "B", "C"}},
+		{`SELECT * FROM A, B AS b, C WHERE A.x = b.x`, []string{"A", "B", "C"}},
+		{`SELECT * FROM (SELECT * FROM A) t JOIN B ON t.x = B.x`, []string{"A", "B"}},
+		{`WITH t AS (SELECT * FROM A), u(x) AS (SELECT x FROM B) SELECT * FROM t JOIN u`, []string{"A", "B"}},
+		{`SELECT value FROM A, json_each(A.tags)`, []string{"A"}},
+		{`SELECT 'from X' FROM A -- join Y`, []string{"A"}},
+		{`SELECT * FROM A UNION SELECT * FROM A`, []string{"A"}},
+		{`SELECT 1`, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			require.Equal(t, tc.expected, TablesList(tc.query))
+		})
+	}
+}
+
+func fp(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,203 @@
+package sql
+
+import (
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuotedIdent
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// words which end a table reference when found where an alias may be.
+var clauseKeywords = map[string]bool{
+	"where": true, "join": true, "inner": true, "left": true, "right": true, "full": true,
+	"outer": true, "cross": true, "natural": true, "on": true, "using": true, "group": true,
+	"order": true, "limit": true, "having": true, "union": true, "except": true,
+	"intersect": true, "window": true, "offset": true,
+}
+
+// TablesList returns the names of the tables read by query, in order of
+// appearance. Names of common table expressions and table-valued functions
+// are not included.
+func TablesList(query string) []string {
+	tokens := tokenize(query)
+
+	ctes := map[string]bool{}
+	for i, t := range tokens {
+		if !isIdent(t) {
+			continue
+		}
+		j := i + 1
+		if j < len(tokens) && tokens[j].value == "(" {
+			j = skipParens(tokens, j)
+		}
+		if j+1 < len(tokens) && isKeyword(tokens[j], "as") && tokens[j+1].value == "(" {
+			ctes[strings.ToLower(t.value)] = true
+		}
+	}
+
+	var tables []string
+	seen := map[string]bool{}
+	for i := 0; i < len(tokens); i++ {
+		isFrom := isKeyword(tokens[i], "from")
+		if !isFrom && !isKeyword(tokens[i], "join") {
+			continue
+		}
+		j := i + 1
+		for j < len(tokens) && isIdent(tokens[j]) {
+			name := tokens[j].value
+			j++
+			if j < len(tokens) && tokens[j].value == "." {
+				// schema qualified, the table name follows the dot
+				if j+1 >= len(tokens) || !isIdent(tokens[j+1]) {
+					break
+				}
+				name = tokens[j+1].value
+				j += 2
+			}
+			isFunction := j < len(tokens) && tokens[j].value == "("
+			if !isFunction && !ctes[strings.ToLower(name)] && !seen[name] {
+				seen[name] = true
+				tables = append(tables, name)
+			}
+			if isFunction {
+				j = skipParens(tokens, j)
+			}
+
+			// alias
+			if j < len(tokens) && isKeyword(tokens[j], "as") {
+				j += 2
+			} else if j < len(tokens) && isIdent(tokens[j]) && !(tokens[j].kind == tokenWord && clauseKeywords[strings.ToLower(tokens[j].value)]) {
+				j++
+			}
+
+			if !isFrom || j >= len(tokens) || tokens[j].value != "," {
+				break
+			}
+			j++
+		}
+	}
+	return tables
+}
+
+// isSelect reports whether query is a single SELECT statement.
+func isSelect(query string) bool {
+	tokens := tokenize(query)
+	for len(tokens) > 0 && tokens[len(tokens)-1].value == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return false
+	}
+	for _, t := range tokens {
+		if t.kind == tokenPunct && t.value == ";" {
+			return false
+		}
+	}
+	return isKeyword(tokens[0], "select") || isKeyword(tokens[0], "with") || isKeyword(tokens[0], "values")
+}
+
+func isIdent(t token) bool {
+	return t.kind == tokenWord || t.kind == tokenQuotedIdent
+}
+
+func isKeyword(t token, keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+// skipParens returns the index of the token following the parenthesis
+// closing the one at index i.
+func skipParens(tokens []token, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		if tokens[i].kind != tokenPunct {
+			continue
+		}
+		switch tokens[i].value {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// tokenize splits query in words, quoted identifiers, string literals and
+// punctuation, dropping the comments.
+func tokenize(query string) []token {
+	var tokens []token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			kind := tokenQuotedIdent
+			if c == '[' {
+				closing = ']'
+			}
+			if c == '\'' {
+				kind = tokenString
+			}
+			var value strings.Builder
+			j := i + 1
+			for j < len(query) {
+				if query[j] == closing {
+					// quotes are escaped by doubling them
+					if closing != ']' && j+1 < len(query) && query[j+1] == closing {
+						value.WriteByte(closing)
+						j += 2
+						continue
+					}
+					break
+				}
+				value.WriteByte(query[j])
+				j++
+			}
+			tokens = append(tokens, token{kind: kind, value: value.String()})
+			i = j + 1
+		case isWordChar(c):
+			j := i
+			for j < len(query) && isWordChar(query[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: query[i:j]})
+			i = j
+		default:
+			tokens = append(tokens, token{kind: tokenPunct, value: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
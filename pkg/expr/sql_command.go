@@ -0,0 +1,302 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// SQLCommand is an expression command running a SQL query over the results
+// of other queries and expressions. Each result is loaded as a table named
+// after its refId.
+type SQLCommand struct {
+	RawSQL      string
+	varsToQuery []string
+	refID       string
+}
+
+// NewSQLCommand creates a new SQLCommand.
+func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
+	if rawSQL == "" {
+		return nil, errors.New("sql expression is empty")
+	}
+	tables := sql.TablesList(rawSQL)
+	if len(tables) == 0 {
+		return nil, errors.New("sql expression does not read any query or expression")
+	}
+	return &SQLCommand{
+		RawSQL:      rawSQL,
+		varsToQuery: tables,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
+func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+	rawExpr, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("sql command is missing an expression")
+	}
+	expressionRaw, ok := rawExpr.(string)
+	if !ok {
+		return nil, fmt.Errorf("sql expression is expected to be a string, got %T", rawExpr)
+	}
+	return NewSQLCommand(rn.RefID, expressionRaw)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gr *SQLCommand) NeedsVars() []string {
+	return gr.varsToQuery
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gr *SQLCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	ctx, span := tracer.Start(ctx, "SSE.ExecuteSQL")
+	span.SetAttributes(attribute.String("expression", gr.RawSQL))
+	defer span.End()
+
+	tables := make(map[string][]*data.Frame, len(gr.varsToQuery))
+	for _, ref := range gr.varsToQuery {
+		frames, err := valuesToTable(vars[ref].Values)
+		if err != nil {
+			return mathexp.Results{}, fmt.Errorf("failed to load %s as a table: %w", ref, err)
+		}
+		tables[ref] = frames
+	}
+
+	frame, err := sql.Query(ctx, gr.RawSQL, tables)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute sql expression: %w", err)
+	}
+	frame.Name = gr.refID
+	frame.RefID = gr.refID
+
+	return tableToResults(frame)
+}
+
+// valuesToTable converts the values of a var to the frames of its table.
+//
+// Series are converted to long format, in a frame with time, value and one
+// column per label. Numbers are converted to a frame with a value and one
+// column per label. Tables are loaded as they are.
+func valuesToTable(values mathexp.Values) ([]*data.Frame, error) {
+	var series []mathexp.Series
+	var numbers []mathexp.Value
+	var frames []*data.Frame
+	for _, v := range values {
+		switch v := v.(type) {
+		case mathexp.Series:
+			series = append(series, v)
+		case mathexp.Number, mathexp.Scalar:
+			numbers = append(numbers, v)
+		case mathexp.TableData:
+			frame, err := tableDataToFrame(v.Frame)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, frame)
+		case mathexp.NoData:
+		default:
+			return nil, fmt.Errorf("unsupported value type %v", v.Type())
+		}
+	}
+
+	if len(series) > 0 {
+		keys := labelKeys(series)
+		timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+		timeField.Name = "time"
+		valueField := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+		valueField.Name = "value"
+		labelFields := newLabelFields(keys)
+		for _, s := range series {
+			labels := s.GetLabels()
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				timeField.Append(t)
+				valueField.Append(f)
+				appendLabels(labelFields, keys, labels)
+			}
+		}
+		frames = append(frames, data.NewFrame("", append([]*data.Field{timeField}, append(labelFields, valueField)...)...))
+	}
+
+	if len(numbers) > 0 {
+		keys := labelKeys(numbers)
+		valueField := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+		valueField.Name = "value"
+		labelFields := newLabelFields(keys)
+		for _, n := range numbers {
+			var f *float64
+			switch n := n.(type) {
+			case mathexp.Number:
+				f = n.GetFloat64Value()
+			case mathexp.Scalar:
+				f = n.GetFloat64Value()
+			}
+			valueField.Append(f)
+			appendLabels(labelFields, keys, n.GetLabels())
+		}
+		frames = append(frames, data.NewFrame("", append(labelFields, valueField)...))
+	}
+
+	return frames, nil
+}
+
+// tableDataToFrame converts wide time series to long format, so that the
+// labels of their fields are columns and the table has unique column names.
+func tableDataToFrame(frame *data.Frame) (*data.Frame, error) {
+	if frame.TimeSeriesSchema().Type != data.TimeSeriesTypeWide {
+		return frame, nil
+	}
+	hasLabels := false
+	for _, field := range frame.Fields {
+		hasLabels = hasLabels || len(field.Labels) > 0
+	}
+	if !hasLabels {
+		return frame, nil
+	}
+	return data.WideToLong(frame)
+}
+
+func labelKeys[T interface{ GetLabels() data.Labels }](values []T) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, v := range values {
+		for k := range v.GetLabels() {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newLabelFields(keys []string) []*data.Field {
+	fields := make([]*data.Field, len(keys))
+	for i, k := range keys {
+		fields[i] = data.NewFieldFromFieldType(data.FieldTypeNullableString, 0)
+		fields[i].Name = k
+	}
+	return fields
+}
+
+func appendLabels(fields []*data.Field, keys []string, labels data.Labels) {
+	for i, k := range keys {
+		if v, ok := labels[k]; ok {
+			fields[i].Append(&v)
+			continue
+		}
+		fields[i].Append(nil)
+	}
+}
+
+// tableToResults converts the result of a SQL query to the values of the
+// command. Tables of numbers with string columns are converted to numbers and
+// tables of a single time column with numeric and string columns are
+// converted to series, using the string columns as labels. Other tables are
+// returned as they are.
+func tableToResults(frame *data.Frame) (mathexp.Results, error) {
+	if len(frame.Fields) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{Frame: frame}}}, nil
+	}
+
+	if isNumberTable(frame) {
+		if frame.Rows() == 0 {
+			return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+		}
+		numbers, err := extractNumberSet(frame)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals := make(mathexp.Values, 0, len(numbers))
+		for _, n := range numbers {
+			vals = append(vals, n)
+		}
+		return mathexp.Results{Values: vals}, nil
+	}
+
+	if isSeriesTable(frame) {
+		if frame.Rows() == 0 {
+			return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+		}
+		wide := frame
+		if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+			var err error
+			wide, err = data.LongToWide(sortRowsByTime(frame), nil)
+			if err != nil {
+				return mathexp.Results{}, err
+			}
+		}
+		series, err := WideToMany(wide, nil)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals := make(mathexp.Values, 0, len(series))
+		for _, s := range series {
+			vals = append(vals, s)
+		}
+		return mathexp.Results{Values: vals}, nil
+	}
+
+	return mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}, nil
+}
+
+// isSeriesTable reports whether frame has a single time column without null
+// values, and otherwise only numeric and string columns, with at least one
+// numeric column.
+func isSeriesTable(frame *data.Frame) bool {
+	timeFields, numericFields := 0, 0
+	for _, field := range frame.Fields {
+		fType := field.Type()
+		switch {
+		case fType.Time():
+			timeFields++
+			for i := 0; i < field.Len(); i++ {
+				if _, ok := field.ConcreteAt(i); !ok {
+					return false
+				}
+			}
+		case fType.Numeric():
+			numericFields++
+		case fType == data.FieldTypeString || fType == data.FieldTypeNullableString:
+		default:
+			return false
+		}
+	}
+	return timeFields == 1 && numericFields > 0
+}
+
+// sortRowsByTime returns a copy of the frame with its rows sorted by time
+// ascending, as required to convert long series to wide.
+func sortRowsByTime(frame *data.Frame) *data.Frame {
+	timeIdx := frame.TimeSeriesSchema().TimeIndex
+	times := make([]time.Time, frame.Rows())
+	rows := make([]int, frame.Rows())
+	for i := range rows {
+		rows[i] = i
+		t, _ := frame.Fields[timeIdx].ConcreteAt(i)
+		times[i] = t.(time.Time)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return times[rows[i]].Before(times[rows[j]])
+	})
+
+	sorted := frame.EmptyCopy()
+	for _, row := range rows {
+		sorted.AppendRow(frame.RowCopy(row)...)
+	}
+	return sorted
+}
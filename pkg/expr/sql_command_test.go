@@ -0,0 +1,192 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/config"
+	"github.com/grafana/grafana/pkg/plugins/manager/fakes"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSQLCommandExecute(t *testing.T) {
+	series := func(host string, values ...float64) mathexp.Series {
+		s := mathexp.NewSeries("A", data.Labels{"host": host}, len(values))
+		for i, v := range values {
+			v := v
+			s.SetPoint(i, time.Unix(int64(i*10), 0), &v)
+		}
+		return s
+	}
+	inventory := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("team", nil, []string{"red", "blue"}),
+		data.NewField("since", nil, []time.Time{time.Unix(0, 0), time.Unix(0, 0)}),
+	)
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{series("a", 1, 3), series("b", 5, 7)}},
+		"B": mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: inventory}}},
+	}
+
+	t.Run("numbers", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `SELECT B.team, avg(A.value) AS value FROM A JOIN B ON A.host = B.host GROUP BY B.team ORDER BY B.team`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		n, ok := res.Values[0].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"team": "blue"}, n.GetLabels())
+		require.Equal(t, 6.0, *n.GetFloat64Value())
+	})
+
+	t.Run("series", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `SELECT A.time, B.team, A.value * 2 AS value FROM A JOIN B ON A.host = B.host ORDER BY A.time DESC`)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		for _, v := range res.Values {
+			s, ok := v.(mathexp.Series)
+			require.True(t, ok)
+			require.Equal(t, 2, s.Len())
+			require.Equal(t, time.Unix(0, 0).UTC(), s.GetTime(0))
+		}
+		require.Equal(t, data.Labels{"team": "blue"}, res.Values[0].GetLabels())
+		require.Equal(t, 10.0, *res.Values[0].(mathexp.Series).GetValue(0))
+	})
+
+	t.Run("table", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `SELECT host, team, since FROM B WHERE team = 'red'`)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		table, ok := res.Values[0].(mathexp.TableData)
+		require.True(t, ok)
+		require.Equal(t, "C", table.Frame.RefID)
+		require.Equal(t, 1, table.Frame.Rows())
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", `SELECT host, value FROM A WHERE value > 100`)
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, res.IsNoData())
+	})
+
+	t.Run("missing table", func(t *testing.T) {
+		_, err := NewSQLCommand("C", `SELECT 1`)
+		require.Error(t, err)
+	})
+}
+
+func TestSQLExpressionPipeline(t *testing.T) {
+	metrics := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{fp(2)}),
+		data.NewField("value", data.Labels{"host": "b"}, []*float64{fp(3)}))
+	inventory := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("team", nil, []string{"red", "blue"}))
+
+	me := &mockEndpoint{
+		Responses: map[string]backend.DataResponse{
+			"A": {Frames: data.Frames{metrics}},
+			"B": {Frames: data.Frames{inventory}},
+		},
+	}
+
+	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+		PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: "test"}},
+		},
+	}, &datafakes.FakeDataSourceService{}, nil, fakes.NewFakeLicensingService(), &config.Cfg{})
+
+	newService := func(features featuremgmt.FeatureToggles) *Service {
+		return &Service{
+			cfg:          setting.NewCfg(),
+			dataService:  me,
+			pCtxProvider: pCtxProvider,
+			features:     features,
+			tracer:       tracing.InitializeTracerForTest(),
+			metrics:      newMetrics(nil),
+		}
+	}
+
+	dsQuery := func(refID string) Query {
+		return Query{
+			RefID: refID,
+			DataSource: &datasources.DataSource{
+				OrgID: 1,
+				UID:   "test",
+				Type:  "test",
+			},
+			JSON:      json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange: AbsoluteTimeRange{},
+		}
+	}
+	queries := []Query{
+		dsQuery("A"),
+		dsQuery("B"),
+		{
+			RefID:      "C",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "sql", "expression": "SELECT B.team, A.value FROM A JOIN B ON A.host = B.host" }`),
+		},
+		{
+			RefID:      "D",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$C > 2" }`),
+		},
+	}
+	req := &Request{Queries: queries, User: &user.SignedInUser{}}
+
+	t.Run("disabled without the feature toggle", func(t *testing.T) {
+		_, err := newService(featuremgmt.WithFeatures()).BuildPipeline(req)
+		require.ErrorContains(t, err, featuremgmt.FlagSqlExpressions)
+	})
+
+	s := newService(featuremgmt.WithFeatures(featuremgmt.FlagSqlExpressions))
+	pl, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+
+	for _, node := range pl {
+		if dsNode, ok := node.(*DSNode); ok {
+			require.True(t, dsNode.isInputToSQLExpr, dsNode.RefID())
+		}
+	}
+
+	res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+	require.NoError(t, err)
+
+	require.NoError(t, res.Responses["D"].Error)
+	frames := res.Responses["D"].Frames
+	require.Len(t, frames, 2)
+	results := map[string]*float64{}
+	for _, frame := range frames {
+		results[frame.Fields[0].Labels["team"]] = frame.Fields[0].At(0).(*float64)
+	}
+	require.Equal(t, map[string]*float64{"red": fp(0), "blue": fp(1)}, results)
+}
@@ -1277,6 +1277,14 @@ var (
 			RequiresRestart:   true,
 			Created:           time.Date(2023, time.November, 30, 12, 0, 0, 0, time.UTC),
 		},
+		{
+			Name:         "sqlExpressions",
+			Description:  "Enables using SQL queries over the results of other queries and expressions in server-side expressions",
+			Stage:        FeatureStageExperimental,
+			FrontendOnly: false,
+			Owner:        grafanaObservabilityMetricsSquad,
+			Created:      time.Date(2023, time.December, 4, 12, 0, 0, 0, time.UTC),
+		},
 	}
 )
 
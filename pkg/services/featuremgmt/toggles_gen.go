@@ -614,4 +614,8 @@ const (
 	// FlagAlertStateHistoryAnnotationsFromLoki
 	// Enable using Loki as the source for panel annotations generated by alert rules
 	FlagAlertStateHistoryAnnotationsFromLoki = "alertStateHistoryAnnotationsFromLoki"
+
+	// FlagSqlExpressions
+	// Enables using SQL queries over the results of other queries and expressions in server-side expressions
+	FlagSqlExpressions = "sqlExpressions"
 )
@@ -459,7 +459,14 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
-	return eGroup.Wait()
+	err := eGroup.Wait()
+	if g.Pipeline != nil {
+		// flush the data buffered by the outputs of the pipeline
+		if closeErr := g.Pipeline.Close(); closeErr != nil {
+			logger.Error("Failed to close the live pipeline", "error", closeErr)
+		}
+	}
+	return err
 }
 
 func getCheckOriginFunc(appURL *url.URL, originPatterns []string, originGlobs []glob.Glob) func(r *http.Request) bool {
@@ -1131,6 +1138,7 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 		ChannelHandlerGetter: g,
 	}
 	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
+	defer func() { _ = channelRuleGetter.Close() }()
 	pipe, err := pipeline.New(channelRuleGetter)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Error creating pipeline", err)
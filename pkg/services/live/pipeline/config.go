@@ -79,6 +79,31 @@ type LokiOutputConfig struct {
 	UID string `json:"uid"`
 }
 
+// InfluxOutputConfig configures writing to InfluxDB. The endpoint and
+// credentials come from the write config referenced by UID, an API token
+// can be stored in the "token" secure setting of that write config.
+type InfluxOutputConfig struct {
+	UID string `json:"uid"`
+	// Version of the InfluxDB write API: "1" uses /write, "2" uses /api/v2/write.
+	Version string `json:"version,omitempty"`
+	// Database to write to with InfluxDB 1.x.
+	Database string `json:"database,omitempty"`
+	// Organization and Bucket to write to with InfluxDB 2.x.
+	Organization string `json:"organization,omitempty"`
+	Bucket       string `json:"bucket,omitempty"`
+	// Measurement overrides the frame name as measurement name.
+	Measurement string `json:"measurement,omitempty"`
+	// BatchSize is the number of lines which triggers a flush before
+	// the flush interval elapses.
+	BatchSize int `json:"batchSize,omitempty"`
+	// FlushIntervalMilliseconds is the maximum time lines are kept in
+	// the buffer before being sent.
+	FlushIntervalMilliseconds int64 `json:"flushIntervalMilliseconds,omitempty"`
+	// MaxBufferSize is the maximum number of lines kept in memory, new
+	// data is rejected while the buffer is full.
+	MaxBufferSize int `json:"maxBufferSize,omitempty"`
+}
+
 type MultipleSubscriberConfig struct {
 	Subscribers []SubscriberConfig `json:"subscribers"`
 }
@@ -97,6 +122,7 @@ type DataOutputterConfig struct {
 	Type                     string                    `json:"type" ts_type:"Omit<keyof DataOutputterConfig, 'type'>"`
 	RedirectDataOutputConfig *RedirectDataOutputConfig `json:"redirect,omitempty"`
 	LokiOutputConfig         *LokiOutputConfig         `json:"loki,omitempty"`
+	InfluxOutputConfig       *InfluxOutputConfig       `json:"influxdb,omitempty"`
 }
 
 type FrameOutputterConfig struct {
@@ -109,6 +135,7 @@ type FrameOutputterConfig struct {
 	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
 	LokiOutputConfig        *LokiOutputConfig          `json:"loki,omitempty"`
 	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	InfluxOutputConfig      *InfluxOutputConfig        `json:"influxdb,omitempty"`
 }
 
 type MultipleFrameConditionCheckerConfig struct {
@@ -0,0 +1,51 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+)
+
+// InfluxDataOutput can output raw data to InfluxDB. Data is expected to be
+// in Influx line protocol already (for example with the influxAuto converter
+// configured on the same channel).
+type InfluxDataOutput struct {
+	influxWriter *influxWriter
+}
+
+func NewInfluxDataOutput(endpoint string, basicAuth *BasicAuth, token string, config InfluxOutputConfig) (*InfluxDataOutput, error) {
+	w, err := newInfluxWriter(endpoint, basicAuth, token, config)
+	if err != nil {
+		return nil, err
+	}
+	return &InfluxDataOutput{
+		influxWriter: w,
+	}, nil
+}
+
+const DataOutputTypeInflux = "influxdb"
+
+func (out *InfluxDataOutput) Type() string {
+	return DataOutputTypeInflux
+}
+
+func (out *InfluxDataOutput) OutputData(_ context.Context, _ Vars, data []byte) ([]*ChannelData, error) {
+	if out.influxWriter.writeURL == "" {
+		logger.Debug("Skip sending to InfluxDB: no url")
+		return nil, nil
+	}
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		lines = append(lines, bytes.Clone(line))
+	}
+	return nil, out.influxWriter.write(lines)
+}
+
+// Close flushes the buffered points and stops the background flushes.
+func (out *InfluxDataOutput) Close() error {
+	out.influxWriter.Close()
+	return nil
+}
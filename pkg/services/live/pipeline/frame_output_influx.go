@@ -0,0 +1,458 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	lp "github.com/influxdata/line-protocol"
+)
+
+const (
+	influxDefaultBatchSize     = 1000
+	influxDefaultFlushInterval = 10 * time.Second
+	influxDefaultMaxBufferSize = 100000
+	influxMaxWriteAttempts     = 3
+	influxRetryBackoff         = 500 * time.Millisecond
+)
+
+// errInfluxBufferFull is returned to publishers while InfluxDB does not keep
+// up with incoming data, so they can slow down instead of data being dropped
+// silently.
+var errInfluxBufferFull = errors.New("influxdb output buffer is full")
+
+// errInfluxRejected is returned when InfluxDB refuses a batch, retrying
+// the same lines will not help in that case.
+var errInfluxRejected = errors.New("influxdb rejected the write")
+
+// InfluxFrameOutput writes frames to InfluxDB as line protocol. Each frame
+// row becomes a point per distinct set of field labels, labels are written
+// as tags and non-time fields as point fields.
+type InfluxFrameOutput struct {
+	influxWriter *influxWriter
+	measurement  string
+}
+
+func NewInfluxFrameOutput(endpoint string, basicAuth *BasicAuth, token string, config InfluxOutputConfig) (*InfluxFrameOutput, error) {
+	w, err := newInfluxWriter(endpoint, basicAuth, token, config)
+	if err != nil {
+		return nil, err
+	}
+	return &InfluxFrameOutput{
+		influxWriter: w,
+		measurement:  config.Measurement,
+	}, nil
+}
+
+const FrameOutputTypeInflux = "influxdb"
+
+func (out *InfluxFrameOutput) Type() string {
+	return FrameOutputTypeInflux
+}
+
+func (out *InfluxFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	if out.influxWriter.writeURL == "" {
+		logger.Debug("Skip sending to InfluxDB: no url")
+		return nil, nil
+	}
+	measurement := out.measurement
+	if measurement == "" {
+		measurement = frameMeasurement(vars, frame)
+	}
+	lines, err := frameToInfluxLines(measurement, frame)
+	if err != nil {
+		return nil, err
+	}
+	return nil, out.influxWriter.write(lines)
+}
+
+// Close flushes the buffered points and stops the background flushes.
+func (out *InfluxFrameOutput) Close() error {
+	out.influxWriter.Close()
+	return nil
+}
+
+// frameMeasurement uses the frame name as measurement name, falling back
+// to the last channel path segment for unnamed frames.
+func frameMeasurement(vars Vars, frame *data.Frame) string {
+	if frame.Name != "" {
+		return frame.Name
+	}
+	return vars.Channel[strings.LastIndex(vars.Channel, "/")+1:]
+}
+
+// frameToInfluxLines encodes frame rows as line protocol, one element per line.
+// Both wide frames and frames with a labels column (as produced by the
+// influxAuto converter) are supported. Frames without time field are skipped.
+func frameToInfluxLines(measurement string, frame *data.Frame) ([][]byte, error) {
+	timeIndices := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	if len(timeIndices) == 0 {
+		return nil, nil
+	}
+	timeField := frame.Fields[timeIndices[0]]
+
+	// Labels column frames have first column called "labels".
+	isLabelsColumnFrame := len(frame.Fields) > 0 && frame.Fields[0].Type() == data.FieldTypeString && frame.Fields[0].Name == "labels"
+
+	var buf bytes.Buffer
+	e := lp.NewEncoder(&buf)
+	e.FailOnFieldErr(true)
+	e.SetPrecision(time.Nanosecond)
+
+	var lines [][]byte
+	for i := 0; i < frame.Rows(); i++ {
+		tm, ok := timeField.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+
+		var rowLabels data.Labels
+		if isLabelsColumnFrame {
+			if v, ok := frame.Fields[0].ConcreteAt(i); ok {
+				rowLabels = parseLabelsColumnValue(v.(string))
+			}
+		}
+
+		// Fields with the same labels belong to the same series, keep the order
+		// in which series appear for a stable output.
+		var keys []string
+		points := map[string]*influxPointData{}
+		for fieldIdx, field := range frame.Fields {
+			if fieldIdx == timeIndices[0] || (isLabelsColumnFrame && fieldIdx == 0) {
+				continue
+			}
+			val, ok := influxFieldValue(field, i)
+			if !ok {
+				continue
+			}
+			tags := field.Labels
+			if isLabelsColumnFrame {
+				tags = rowLabels
+			}
+			key := tags.String()
+			p, ok := points[key]
+			if !ok {
+				p = &influxPointData{tags: tags, fields: map[string]any{}}
+				points[key] = p
+				keys = append(keys, key)
+			}
+			p.fields[field.Name] = val
+		}
+
+		for _, key := range keys {
+			p := points[key]
+			buf.Reset()
+			if _, err := e.Encode(write.NewPoint(measurement, p.tags, p.fields, tm.(time.Time))); err != nil {
+				return nil, fmt.Errorf("error encoding line protocol: %w", err)
+			}
+			lines = append(lines, bytes.Clone(buf.Bytes()))
+		}
+	}
+	return lines, nil
+}
+
+type influxPointData struct {
+	tags   data.Labels
+	fields map[string]any
+}
+
+func influxFieldValue(field *data.Field, i int) (any, bool) {
+	if field.Name == "" {
+		return nil, false
+	}
+	switch field.Type() {
+	case data.FieldTypeTime, data.FieldTypeNullableTime,
+		data.FieldTypeJSON, data.FieldTypeNullableJSON,
+		data.FieldTypeEnum, data.FieldTypeNullableEnum:
+		return nil, false
+	}
+	val, ok := field.ConcreteAt(i)
+	if !ok {
+		return nil, false
+	}
+	switch v := val.(type) {
+	case float64:
+		// line protocol has no representation for NaN and infinities.
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+// parseLabelsColumnValue parses labels in the "k1=v1, k2=v2" format of
+// labels column frames.
+func parseLabelsColumnValue(s string) data.Labels {
+	labels := data.Labels{}
+	for _, part := range strings.Split(s, ", ") {
+		labelParts := strings.SplitN(part, "=", 2)
+		if len(labelParts) != 2 {
+			continue
+		}
+		labels[labelParts[0]] = labelParts[1]
+	}
+	return labels
+}
+
+// influxWriter buffers line protocol and writes it in batches. A flush
+// happens every flush interval or as soon as a full batch is buffered.
+// Close flushes what is left, lines written after it are sent directly.
+type influxWriter struct {
+	mu         sync.Mutex
+	httpClient *http.Client
+	buffer     [][]byte
+	closed     bool
+	flushCh    chan struct{}
+	closeOnce  sync.Once
+	// closeCh stops the background flushes, which close done when they
+	// are finished. Both are nil when there is no endpoint.
+	closeCh chan struct{}
+	done    chan struct{}
+
+	// writeURL is the full write API URL including query parameters.
+	writeURL      string
+	basicAuth     *BasicAuth
+	token         string
+	batchSize     int
+	maxBufferSize int
+	flushInterval time.Duration
+	retryBackoff  time.Duration
+}
+
+func newInfluxWriter(endpoint string, basicAuth *BasicAuth, token string, config InfluxOutputConfig) (*influxWriter, error) {
+	w := &influxWriter{
+		basicAuth:     basicAuth,
+		token:         token,
+		batchSize:     config.BatchSize,
+		maxBufferSize: config.MaxBufferSize,
+		flushInterval: time.Duration(config.FlushIntervalMilliseconds) * time.Millisecond,
+		retryBackoff:  influxRetryBackoff,
+		flushCh:       make(chan struct{}, 1),
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = influxDefaultBatchSize
+	}
+	if w.maxBufferSize <= 0 {
+		w.maxBufferSize = influxDefaultMaxBufferSize
+	}
+	if w.maxBufferSize < w.batchSize {
+		w.maxBufferSize = w.batchSize
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = influxDefaultFlushInterval
+	}
+	if endpoint == "" {
+		return w, nil
+	}
+
+	writeURL, err := influxWriteURL(endpoint, config)
+	if err != nil {
+		return nil, err
+	}
+	w.writeURL = writeURL
+	w.closeCh = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.flushPeriodically()
+	return w, nil
+}
+
+func influxWriteURL(endpoint string, config InfluxOutputConfig) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid InfluxDB endpoint: %w", err)
+	}
+	values := url.Values{}
+	switch config.Version {
+	case "1":
+		if config.Database == "" {
+			return "", errors.New("database is required for InfluxDB 1.x")
+		}
+		u = u.JoinPath("/write")
+		values.Set("db", config.Database)
+		values.Set("precision", "n")
+	case "", "2":
+		if config.Organization == "" || config.Bucket == "" {
+			return "", errors.New("organization and bucket are required for InfluxDB 2.x")
+		}
+		u = u.JoinPath("/api/v2/write")
+		values.Set("org", config.Organization)
+		values.Set("bucket", config.Bucket)
+		values.Set("precision", "ns")
+	default:
+		return "", fmt.Errorf("unsupported InfluxDB version: %s", config.Version)
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+// write appends lines to the buffer. It fails with errInfluxBufferFull
+// instead of growing the buffer over maxBufferSize.
+func (w *influxWriter) write(lines [][]byte) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return w.flush(lines)
+	}
+	if len(w.buffer)+len(lines) > w.maxBufferSize {
+		w.mu.Unlock()
+		return errInfluxBufferFull
+	}
+	w.buffer = append(w.buffer, lines...)
+	full := len(w.buffer) >= w.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Close stops the background flushes and flushes the buffer a last time.
+func (w *influxWriter) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		if w.closeCh != nil {
+			close(w.closeCh)
+			<-w.done
+		}
+	})
+}
+
+func (w *influxWriter) flushPeriodically() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.flushCh:
+		case <-w.closeCh:
+			w.flushBuffer()
+			w.mu.Lock()
+			if len(w.buffer) > 0 {
+				logger.Error("Dropping lines which could not be flushed to InfluxDB on close", "numLines", len(w.buffer))
+				w.buffer = nil
+			}
+			w.mu.Unlock()
+			return
+		}
+		w.flushBuffer()
+	}
+}
+
+// flushBuffer sends the buffered lines in batches. Batches which could not
+// be delivered are put back in front of the buffer for the next flush.
+func (w *influxWriter) flushBuffer() {
+	for {
+		w.mu.Lock()
+		if len(w.buffer) == 0 {
+			w.mu.Unlock()
+			return
+		}
+		n := len(w.buffer)
+		if n > w.batchSize {
+			n = w.batchSize
+		}
+		batch := make([][]byte, n)
+		copy(batch, w.buffer[:n])
+		w.buffer = w.buffer[n:]
+		w.mu.Unlock()
+
+		err := w.flush(batch)
+		if errors.Is(err, errInfluxRejected) {
+			logger.Error("Dropping lines rejected by InfluxDB", "numLines", len(batch), "error", err)
+			continue
+		}
+		if err != nil {
+			logger.Error("Error flush to InfluxDB", "error", err)
+			w.mu.Lock()
+			w.buffer = append(batch, w.buffer...)
+			if len(w.buffer) > w.maxBufferSize {
+				// Publishers are already rejected when the buffer is full, this
+				// only happens for lines written while the batch was in flight.
+				dropped := len(w.buffer) - w.maxBufferSize
+				w.buffer = w.buffer[dropped:]
+				logger.Warn("Dropping oldest lines from InfluxDB buffer", "numLines", dropped)
+			}
+			w.mu.Unlock()
+			return
+		}
+	}
+}
+
+// flush sends lines to InfluxDB, retrying with a growing backoff on network
+// errors, rate limiting and server errors.
+func (w *influxWriter) flush(lines [][]byte) error {
+	logger.Debug("InfluxDB flush", "numLines", len(lines))
+	body := bytes.Join(lines, []byte("\n"))
+
+	var err error
+	for attempt := 0; attempt < influxMaxWriteAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(w.retryBackoff * time.Duration(1<<(attempt-1)))
+		}
+		err = w.send(body)
+		if err == nil || errors.Is(err, errInfluxRejected) {
+			return err
+		}
+		logger.Debug("Retrying InfluxDB write", "attempt", attempt+1, "error", err)
+	}
+	return err
+}
+
+func (w *influxWriter) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.writeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error constructing InfluxDB write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	} else if w.basicAuth != nil {
+		req.SetBasicAuth(w.basicAuth.User, w.basicAuth.Password)
+	}
+
+	started := time.Now()
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending to InfluxDB: %w", err)
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK:
+		logger.Debug("Successfully sent to InfluxDB", "elapsed", time.Since(started))
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected response code from InfluxDB endpoint: %d", resp.StatusCode)
+	default:
+		logger.Error("Unexpected response code from InfluxDB endpoint", "code", resp.StatusCode, "body", string(respBody))
+		return fmt.Errorf("%w: status %d", errInfluxRejected, resp.StatusCode)
+	}
+}
@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameToInfluxLines(t *testing.T) {
+	t.Run("wide frame", func(t *testing.T) {
+		frame := data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+			data.NewField("usage", data.Labels{"host": "a"}, []float64{1.5, 2.5}),
+			data.NewField("idle", data.Labels{"host": "a"}, []*int64{nil, ptrInt64(3)}),
+			data.NewField("usage", data.Labels{"host": "b"}, []float64{3.5, 4.5}),
+		)
+		lines, err := frameToInfluxLines("cpu", frame)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"cpu,host=a usage=1.5 1000000000\n",
+			"cpu,host=b usage=3.5 1000000000\n",
+			"cpu,host=a idle=3i,usage=2.5 2000000000\n",
+			"cpu,host=b usage=4.5 2000000000\n",
+		}, toStrings(lines))
+	})
+
+	t.Run("labels column frame", func(t *testing.T) {
+		frame := data.NewFrame("cpu",
+			data.NewField("labels", nil, []string{"cpu=cpu0, host=a", "cpu=cpu1, host=a"}),
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(1, 0)}),
+			data.NewField("usage", nil, []float64{1, 2}),
+			data.NewField("active", nil, []bool{true, false}),
+		)
+		lines, err := frameToInfluxLines("cpu", frame)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"cpu,cpu=cpu0,host=a active=true,usage=1 1000000000\n",
+			"cpu,cpu=cpu1,host=a active=false,usage=2 1000000000\n",
+		}, toStrings(lines))
+	})
+
+	t.Run("skips frames without time field", func(t *testing.T) {
+		frame := data.NewFrame("cpu", data.NewField("usage", nil, []float64{1}))
+		lines, err := frameToInfluxLines("cpu", frame)
+		require.NoError(t, err)
+		require.Empty(t, lines)
+	})
+}
+
+func TestInfluxWriteURL(t *testing.T) {
+	u, err := influxWriteURL("http://localhost:8086", InfluxOutputConfig{Version: "1", Database: "telegraf"})
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8086/write?db=telegraf&precision=n", u)
+
+	u, err = influxWriteURL("http://localhost:8086/", InfluxOutputConfig{Organization: "org", Bucket: "bucket"})
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8086/api/v2/write?bucket=bucket&org=org&precision=ns", u)
+
+	_, err = influxWriteURL("http://localhost:8086", InfluxOutputConfig{Version: "1"})
+	require.Error(t, err)
+}
+
+func TestInfluxWriter(t *testing.T) {
+	t.Run("rejects writes when buffer is full", func(t *testing.T) {
+		w, err := newInfluxWriter("", nil, "", InfluxOutputConfig{BatchSize: 2, MaxBufferSize: 2})
+		require.NoError(t, err)
+		require.NoError(t, w.write([][]byte{[]byte("a"), []byte("b")}))
+		require.ErrorIs(t, w.write([][]byte{[]byte("c")}), errInfluxBufferFull)
+	})
+
+	t.Run("retries server errors and sends token", func(t *testing.T) {
+		var calls atomic.Int32
+		var body string
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Token secret", r.Header.Get("Authorization"))
+			if calls.Add(1) == 1 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		w := &influxWriter{httpClient: srv.Client(), writeURL: srv.URL, token: "secret", batchSize: 10, maxBufferSize: 10}
+		require.NoError(t, w.flush([][]byte{[]byte("cpu v=1 1"), []byte("cpu v=2 2")}))
+		require.Equal(t, int32(2), calls.Load())
+		require.Equal(t, "cpu v=1 1\ncpu v=2 2", body)
+	})
+
+	t.Run("does not retry rejected writes", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			rw.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		w := &influxWriter{httpClient: srv.Client(), writeURL: srv.URL, batchSize: 10, maxBufferSize: 10}
+		w.buffer = [][]byte{[]byte("bad")}
+		w.flushBuffer()
+		require.Equal(t, int32(1), calls.Load())
+		require.Empty(t, w.buffer)
+	})
+}
+
+func TestInfluxWriterClose(t *testing.T) {
+	var bodies []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// the flush interval is long enough for the lines to stay buffered until Close
+	w, err := newInfluxWriter(srv.URL, nil, "", InfluxOutputConfig{Version: "1", Database: "db", FlushIntervalMilliseconds: 60000})
+	require.NoError(t, err)
+	require.NoError(t, w.write([][]byte{[]byte("cpu v=1 1")}))
+
+	w.Close()
+	mu.Lock()
+	require.Equal(t, []string{"cpu v=1 1"}, bodies)
+	mu.Unlock()
+
+	// lines written after Close are sent directly
+	require.NoError(t, w.write([][]byte{[]byte("cpu v=2 2")}))
+	mu.Lock()
+	require.Equal(t, []string{"cpu v=1 1", "cpu v=2 2"}, bodies)
+	mu.Unlock()
+	w.Close()
+}
+
+func toStrings(lines [][]byte) []string {
+	res := make([]string, 0, len(lines))
+	for _, l := range lines {
+		res = append(res, string(l))
+	}
+	return res
+}
+
+func ptrInt64(v int64) *int64 {
+	return &v
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -210,6 +211,15 @@ func New(ruleGetter ChannelRuleGetter) (*Pipeline, error) {
 	return p, nil
 }
 
+// Close releases the resources of the rules, like the data buffered by
+// their outputters.
+func (p *Pipeline) Close() error {
+	if closer, ok := p.ruleGetter.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func (p *Pipeline) Get(orgID int64, channel string) (*LiveChannelRule, bool, error) {
 	return p.ruleGetter.Get(orgID, channel)
 }
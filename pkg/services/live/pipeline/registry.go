@@ -48,6 +48,15 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeInflux,
+		Description: "output frame to InfluxDB as line protocol",
+		Example: InfluxOutputConfig{
+			Version:      "2",
+			Organization: "my-org",
+			Bucket:       "telemetry",
+		},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -94,4 +103,8 @@ var DataOutputsRegistry = []EntityInfo{
 		Type:        DataOutputTypeLoki,
 		Description: "output data to Loki as logs",
 	},
+	{
+		Type:        DataOutputTypeInflux,
+		Description: "output line protocol data to InfluxDB",
+	},
 }
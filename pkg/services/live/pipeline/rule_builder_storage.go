@@ -164,6 +164,17 @@ func (f *StorageRuleBuilder) constructBasicAuth(writeConfig WriteConfig) (*Basic
 	}, nil
 }
 
+func (f *StorageRuleBuilder) constructToken(writeConfig WriteConfig) (string, error) {
+	if len(writeConfig.SecureSettings["token"]) == 0 {
+		return "", nil
+	}
+	tokenBytes, err := f.SecretsService.Decrypt(context.Background(), writeConfig.SecureSettings["token"])
+	if err != nil {
+		return "", fmt.Errorf("token can't be decrypted: %w", err)
+	}
+	return string(tokenBytes), nil
+}
+
 func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig, writeConfigs []WriteConfig) (FrameOutputter, error) {
 	if config == nil {
 		return nil, nil
@@ -244,6 +255,28 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			writeConfig.Settings.Endpoint,
 			basicAuth,
 		), nil
+	case FrameOutputTypeInflux:
+		if config.InfluxOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		writeConfig, ok := f.getWriteConfig(config.InfluxOutputConfig.UID, writeConfigs)
+		if !ok {
+			return nil, fmt.Errorf("unknown influxdb backend uid: %s", config.InfluxOutputConfig.UID)
+		}
+		basicAuth, err := f.constructBasicAuth(writeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error getting password: %w", err)
+		}
+		token, err := f.constructToken(writeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error getting token: %w", err)
+		}
+		return NewInfluxFrameOutput(
+			writeConfig.Settings.Endpoint,
+			basicAuth,
+			token,
+			*config.InfluxOutputConfig,
+		)
 	case FrameOutputTypeChangeLog:
 		if config.ChangeLogOutputConfig == nil {
 			return nil, missingConfiguration
@@ -281,6 +314,28 @@ func (f *StorageRuleBuilder) extractDataOutputter(config *DataOutputterConfig, w
 			writeConfig.Settings.Endpoint,
 			basicAuth,
 		), nil
+	case DataOutputTypeInflux:
+		if config.InfluxOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		writeConfig, ok := f.getWriteConfig(config.InfluxOutputConfig.UID, writeConfigs)
+		if !ok {
+			return nil, fmt.Errorf("unknown influxdb backend uid: %s", config.InfluxOutputConfig.UID)
+		}
+		basicAuth, err := f.constructBasicAuth(writeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error constructing basicAuth: %w", err)
+		}
+		token, err := f.constructToken(writeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error constructing token: %w", err)
+		}
+		return NewInfluxDataOutput(
+			writeConfig.Settings.Endpoint,
+			basicAuth,
+			token,
+			*config.InfluxOutputConfig,
+		)
 	case DataOutputTypeBuiltin:
 		return NewBuiltinDataOutput(f.ChannelHandlerGetter), nil
 	case DataOutputTypeLocalSubscribers:
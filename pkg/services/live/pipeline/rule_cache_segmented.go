@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -10,21 +11,45 @@ import (
 )
 
 // CacheSegmentedTree provides a fast access to channel rule configuration.
+// The rules are rebuilt periodically, the outputters of the replaced rules
+// are closed.
 type CacheSegmentedTree struct {
 	radixMu     sync.RWMutex
 	radix       map[int64]*tree.Node
+	rules       map[int64][]*LiveChannelRule
 	ruleBuilder RuleBuilder
+	closeOnce   sync.Once
+	closeCh     chan struct{}
 }
 
 func NewCacheSegmentedTree(storage RuleBuilder) *CacheSegmentedTree {
 	s := &CacheSegmentedTree{
 		radix:       map[int64]*tree.Node{},
+		rules:       map[int64][]*LiveChannelRule{},
 		ruleBuilder: storage,
+		closeCh:     make(chan struct{}),
 	}
 	go s.updatePeriodically()
 	return s
 }
 
+// Close stops the updates of the rules and closes their outputters, so that
+// buffered data is flushed.
+func (s *CacheSegmentedTree) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+		s.radixMu.Lock()
+		rules := s.rules
+		s.radix = map[int64]*tree.Node{}
+		s.rules = map[int64][]*LiveChannelRule{}
+		s.radixMu.Unlock()
+		for _, orgRules := range rules {
+			closeChannelRules(orgRules)
+		}
+	})
+	return nil
+}
+
 func (s *CacheSegmentedTree) updatePeriodically() {
 	for {
 		var orgIDs []int64
@@ -39,7 +64,11 @@ func (s *CacheSegmentedTree) updatePeriodically() {
 				logger.Error("Error filling orgId", "error", err, "orgId", orgID)
 			}
 		}
-		time.Sleep(20 * time.Second)
+		select {
+		case <-time.After(20 * time.Second):
+		case <-s.closeCh:
+			return
+		}
 	}
 }
 
@@ -51,14 +80,53 @@ func (s *CacheSegmentedTree) fillOrg(orgID int64) error {
 		return err
 	}
 	s.radixMu.Lock()
-	defer s.radixMu.Unlock()
+	select {
+	case <-s.closeCh:
+		// the rules were built while closing
+		s.radixMu.Unlock()
+		closeChannelRules(channels)
+		return nil
+	default:
+	}
+	previous := s.rules[orgID]
 	s.radix[orgID] = tree.New()
 	for _, ch := range channels {
 		s.radix[orgID].AddRoute("/"+ch.Pattern, ch)
 	}
+	s.rules[orgID] = channels
+	s.radixMu.Unlock()
+	closeChannelRules(previous)
 	return nil
 }
 
+// closeChannelRules closes the outputters of rules which are not used
+// anymore.
+func closeChannelRules(rules []*LiveChannelRule) {
+	for _, rule := range rules {
+		for _, out := range rule.DataOutputters {
+			closeOutputter(out)
+		}
+		for _, out := range rule.FrameOutputters {
+			closeOutputter(out)
+		}
+	}
+}
+
+func closeOutputter(out any) {
+	switch o := out.(type) {
+	case *MultipleFrameOutput:
+		for _, child := range o.Outputters {
+			closeOutputter(child)
+		}
+	case *ConditionalOutput:
+		closeOutputter(o.Outputter)
+	case io.Closer:
+		if err := o.Close(); err != nil {
+			logger.Error("Error closing outputter", "error", err)
+		}
+	}
+}
+
 func (s *CacheSegmentedTree) Get(orgID int64, channel string) (*LiveChannelRule, bool, error) {
 	s.radixMu.RLock()
 	_, ok := s.radix[orgID]
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 )
 
@@ -53,6 +54,57 @@ func TestStorage_Get(t *testing.T) {
 	require.Equal(t, "stream/boom:er", rule.Pattern)
 }
 
+type closingOutput struct {
+	closed bool
+}
+
+func (o *closingOutput) Type() string { return "closing" }
+
+func (o *closingOutput) OutputFrame(_ context.Context, _ Vars, _ *data.Frame) ([]*ChannelFrame, error) {
+	return nil, nil
+}
+
+func (o *closingOutput) Close() error {
+	o.closed = true
+	return nil
+}
+
+type closingBuilder struct {
+	outputs []*closingOutput
+}
+
+func (b *closingBuilder) BuildRules(_ context.Context, _ int64) ([]*LiveChannelRule, error) {
+	out := &closingOutput{}
+	b.outputs = append(b.outputs, out)
+	return []*LiveChannelRule{
+		{
+			OrgId:           1,
+			Pattern:         "stream/telegraf/cpu",
+			FrameOutputters: []FrameOutputter{NewMultipleFrameOutput(NewConditionalOutput(nil, out))},
+		},
+	}, nil
+}
+
+func TestStorage_CloseOutputters(t *testing.T) {
+	b := &closingBuilder{}
+	s := NewCacheSegmentedTree(b)
+	_, ok, err := s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the outputters of replaced rules are closed
+	require.NoError(t, s.fillOrg(1))
+	require.Len(t, b.outputs, 2)
+	require.True(t, b.outputs[0].closed)
+	require.False(t, b.outputs[1].closed)
+
+	require.NoError(t, s.Close())
+	require.True(t, b.outputs[1].closed)
+	_, ok, err = s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
 func BenchmarkRuleGet(b *testing.B) {
 	s := NewCacheSegmentedTree(&testBuilder{})
 	for i := 0; i < b.N; i++ {
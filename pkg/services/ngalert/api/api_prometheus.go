@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -27,10 +28,16 @@ import (
 type PrometheusSrv struct {
 	log     log.Logger
 	manager state.AlertInstanceManager
+	acks    AlertInstanceAcknowledger
 	store   RuleStore
 	authz   RuleAccessControlService
 }
 
+// AlertInstanceAcknowledger changes the acknowledgements of alert instances.
+type AlertInstanceAcknowledger interface {
+	UpdateAcknowledgement(ctx context.Context, cmd ngmodels.UpdateAlertInstanceAcknowledgementCommand) (*ngmodels.AlertInstanceAcknowledgement, error)
+}
+
 const queryIncludeInternalLabels = "includeInternalLabels"
 
 func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) response.Response {
@@ -62,15 +69,94 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) respo
 
 			// TODO: or should we make this two fields? Using one field lets the
 			// frontend use the same logic for parsing text on annotations and this.
-			State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-			ActiveAt: &startsAt,
-			Value:    valString,
+			State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+			ActiveAt:        &startsAt,
+			Value:           valString,
+			Acknowledgement: toAlertAcknowledgement(alertState.GetAcknowledgement()),
 		})
 	}
 
 	return response.JSON(http.StatusOK, alertResponse)
 }
 
+// RoutePostGrafanaAlertAcknowledgement acknowledges, assigns or comments on an alert instance of the rule.
+func (srv PrometheusSrv) RoutePostGrafanaAlertAcknowledgement(c *contextmodel.ReqContext, body apimodels.PostableAlertAcknowledgement, ruleUID string) response.Response {
+	if len(body.Labels) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("labels of the alert instance must be specified"), "")
+	}
+	q := ngmodels.GetAlertRulesGroupByRuleUIDQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.GetOrgID(),
+	}
+	rules, err := srv.store.GetAlertRulesGroupByRuleUID(c.Req.Context(), &q)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the rule")
+	}
+	var rule *ngmodels.AlertRule
+	for _, r := range rules {
+		if r.UID == ruleUID {
+			rule = r
+		}
+	}
+	if rule == nil {
+		return ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleNotFound, "")
+	}
+	if _, err := srv.store.GetNamespaceByUID(c.Req.Context(), rule.NamespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, rules); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to rule group", err)
+	}
+
+	ack, err := srv.acks.UpdateAcknowledgement(c.Req.Context(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		RuleUID:      ruleUID,
+		Labels:       body.Labels,
+		User:         c.SignedInUser.GetLogin(),
+		Acknowledged: body.Acknowledged,
+		Assignee:     body.Assignee,
+		Comment:      body.Comment,
+	})
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertInstanceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		if errors.Is(err, ngmodels.ErrAlertInstanceNormal) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to update the acknowledgement of the alert instance")
+	}
+	result := toAlertAcknowledgement(ack)
+	if result == nil {
+		result = &apimodels.AlertAcknowledgement{Updated: ack.Updated}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// toAlertAcknowledgement returns nil if nobody acknowledged, assigned or commented on the alert instance.
+func toAlertAcknowledgement(ack *ngmodels.AlertInstanceAcknowledgement) *apimodels.AlertAcknowledgement {
+	if ack.IsEmpty() {
+		return nil
+	}
+	result := &apimodels.AlertAcknowledgement{
+		AcknowledgedBy: ack.AcknowledgedBy,
+		Assignee:       ack.Assignee,
+		Updated:        ack.Updated,
+	}
+	if ack.IsAcknowledged() {
+		acknowledgedAt := ack.AcknowledgedAt
+		result.AcknowledgedAt = &acknowledgedAt
+	}
+	for _, comment := range ack.Comments {
+		result.Comments = append(result.Comments, apimodels.AlertComment{
+			Author:  comment.Author,
+			Text:    comment.Text,
+			Created: comment.Created,
+		})
+	}
+	return result
+}
+
 func formatValues(alertState *state.State) string {
 	var fv string
 	values := alertState.GetLastEvaluationValuesForCondition()
@@ -326,6 +412,11 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		if rule.Type() == ngmodels.RuleTypeRecording {
+			// Recording rules do not have a state.
+			newRule.Type = apiv1.RuleTypeRecording
+			alertingRule.State = ""
+		}
 
 		states := srv.manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
 		totals := make(map[string]int64)
@@ -348,9 +439,10 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 
 				// TODO: or should we make this two fields? Using one field lets the
 				// frontend use the same logic for parsing text on annotations and this.
-				State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-				ActiveAt: &activeAt,
-				Value:    valString,
+				State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+				ActiveAt:        &activeAt,
+				Value:           valString,
+				Acknowledgement: toAlertAcknowledgement(alertState.GetAcknowledgement()),
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
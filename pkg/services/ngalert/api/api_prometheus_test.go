@@ -1252,6 +1252,92 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 	})
 }
 
+type fakeAlertInstanceAcknowledger struct {
+	cmd ngmodels.UpdateAlertInstanceAcknowledgementCommand
+	ack *ngmodels.AlertInstanceAcknowledgement
+	err error
+}
+
+func (f *fakeAlertInstanceAcknowledger) UpdateAcknowledgement(_ context.Context, cmd ngmodels.UpdateAlertInstanceAcknowledgementCommand) (*ngmodels.AlertInstanceAcknowledgement, error) {
+	f.cmd = cmd
+	return f.ack, f.err
+}
+
+func TestRoutePostGrafanaAlertAcknowledgement(t *testing.T) {
+	orgID := int64(1)
+	queryPermissions := map[int64]map[string][]string{1: {datasources.ActionQuery: {datasources.ScopeAll}}}
+	req, err := http.NewRequest("POST", "/api/prometheus/grafana/api/v1/rules/RuleUID/acknowledgement", nil)
+	require.NoError(t, err)
+	c := &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{OrgID: orgID, Login: "alice", Permissions: queryPermissions}}
+	body := apimodels.PostableAlertAcknowledgement{
+		Labels:       map[string]string{"job": "prometheus"},
+		Acknowledged: util.Pointer(true),
+		Comment:      "looking into it",
+	}
+
+	setup := func(t *testing.T) (*fakeAlertInstanceAcknowledger, PrometheusSrv) {
+		fakeStore, fakeAIM, api := setupAPI(t)
+		generateRuleAndInstanceWithQuery(t, orgID, fakeAIM, fakeStore, withClassicConditionSingleQuery())
+		acks := &fakeAlertInstanceAcknowledger{}
+		api.acks = acks
+		return acks, api
+	}
+
+	t.Run("updates the acknowledgement of the alert instance", func(t *testing.T) {
+		acks, api := setup(t)
+		acknowledgedAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+		acks.ack = &ngmodels.AlertInstanceAcknowledgement{
+			AcknowledgedBy: "alice",
+			AcknowledgedAt: acknowledgedAt,
+			Comments:       []ngmodels.AlertInstanceComment{{Author: "alice", Text: "looking into it", Created: acknowledgedAt}},
+			Updated:        acknowledgedAt,
+		}
+
+		r := api.RoutePostGrafanaAlertAcknowledgement(c, body, "RuleUID")
+		require.Equal(t, http.StatusOK, r.Status())
+		require.Equal(t, ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+			OrgID:        orgID,
+			RuleUID:      "RuleUID",
+			Labels:       body.Labels,
+			User:         "alice",
+			Acknowledged: body.Acknowledged,
+			Comment:      "looking into it",
+		}, acks.cmd)
+
+		var result apimodels.AlertAcknowledgement
+		require.NoError(t, json.Unmarshal(r.Body(), &result))
+		require.Equal(t, "alice", result.AcknowledgedBy)
+		require.Equal(t, acknowledgedAt, *result.AcknowledgedAt)
+		require.Len(t, result.Comments, 1)
+	})
+
+	t.Run("returns 400 without labels", func(t *testing.T) {
+		_, api := setup(t)
+		r := api.RoutePostGrafanaAlertAcknowledgement(c, apimodels.PostableAlertAcknowledgement{Acknowledged: util.Pointer(true)}, "RuleUID")
+		require.Equal(t, http.StatusBadRequest, r.Status())
+	})
+
+	t.Run("returns 404 if the rule does not exist", func(t *testing.T) {
+		_, api := setup(t)
+		r := api.RoutePostGrafanaAlertAcknowledgement(c, body, "unknown")
+		require.Equal(t, http.StatusNotFound, r.Status())
+	})
+
+	t.Run("returns 404 if the alert instance does not exist", func(t *testing.T) {
+		acks, api := setup(t)
+		acks.err = ngmodels.ErrAlertInstanceNotFound
+		r := api.RoutePostGrafanaAlertAcknowledgement(c, body, "RuleUID")
+		require.Equal(t, http.StatusNotFound, r.Status())
+	})
+
+	t.Run("returns 400 if the alert instance is Normal", func(t *testing.T) {
+		acks, api := setup(t)
+		acks.err = ngmodels.ErrAlertInstanceNormal
+		r := api.RoutePostGrafanaAlertAcknowledgement(c, body, "RuleUID")
+		require.Equal(t, http.StatusBadRequest, r.Status())
+	})
+}
+
 func setupAPI(t *testing.T) (*fakes.RuleStore, *fakeAlertInstanceManager, PrometheusSrv) {
 	fakeStore := fakes.NewRuleStore(t)
 	fakeAIM := NewFakeAlertInstanceManager(t)
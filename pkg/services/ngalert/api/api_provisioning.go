@@ -28,6 +28,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	silences            SilenceService
 }
 
 type ContactPointService interface {
@@ -69,6 +70,19 @@ type AlertRuleService interface {
 	GetAlertGroupsWithFolderTitle(ctx context.Context, orgID int64, folderUIDs []string) ([]alerting_models.AlertRuleGroupWithFolderTitle, error)
 }
 
+type SilenceService interface {
+	GetSilenceTemplates(ctx context.Context, orgID int64) ([]*alerting_models.SilenceTemplate, map[string]alerting_models.Provenance, error)
+	GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (alerting_models.SilenceTemplate, alerting_models.Provenance, error)
+	CreateSilenceTemplate(ctx context.Context, tmpl alerting_models.SilenceTemplate, provenance alerting_models.Provenance) (alerting_models.SilenceTemplate, error)
+	UpdateSilenceTemplate(ctx context.Context, tmpl alerting_models.SilenceTemplate, provenance alerting_models.Provenance) (alerting_models.SilenceTemplate, error)
+	DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance) error
+	GetRecurringSilences(ctx context.Context, orgID int64) ([]*alerting_models.RecurringSilence, map[string]alerting_models.Provenance, error)
+	GetRecurringSilence(ctx context.Context, orgID int64, uid string) (alerting_models.RecurringSilence, alerting_models.Provenance, error)
+	CreateRecurringSilence(ctx context.Context, s alerting_models.RecurringSilence, provenance alerting_models.Provenance) (alerting_models.RecurringSilence, error)
+	UpdateRecurringSilence(ctx context.Context, s alerting_models.RecurringSilence, provenance alerting_models.Provenance) (alerting_models.RecurringSilence, error)
+	DeleteRecurringSilence(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance) error
+}
+
 func (srv *ProvisioningSrv) RouteGetPolicyTree(c *contextmodel.ReqContext) response.Response {
 	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), c.SignedInUser.GetOrgID())
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -501,6 +515,13 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 	if err != nil {
 		ErrResp(http.StatusBadRequest, err, "")
 	}
+	rules := make(alerting_models.RulesGroup, 0, len(groupModel.Rules))
+	for i := range groupModel.Rules {
+		rules = append(rules, &groupModel.Rules[i])
+	}
+	if err := validateRuleDependencies(rules); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
 	provenance := determineProvenance(c)
 
 	userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
@@ -633,3 +654,121 @@ func exportHcl(download bool, body definitions.AlertingFileExport) response.Resp
 	}
 	return resp.SetHeader("Content-Type", "text/hcl")
 }
+
+func (srv *ProvisioningSrv) RouteGetSilenceTemplates(c *contextmodel.ReqContext) response.Response {
+	templates, provenances, err := srv.silences.GetSilenceTemplates(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, ApiSilenceTemplatesFromSilenceTemplates(templates, provenances))
+}
+
+func (srv *ProvisioningSrv) RouteGetSilenceTemplate(c *contextmodel.ReqContext, UID string) response.Response {
+	tmpl, provenance, err := srv.silences.GetSilenceTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), UID)
+	if err != nil {
+		if errors.Is(err, alerting_models.ErrSilenceTemplateNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, ApiSilenceTemplateFromSilenceTemplate(tmpl, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePostSilenceTemplate(c *contextmodel.ReqContext, tmpl definitions.SilenceTemplate) response.Response {
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	created, err := srv.silences.CreateSilenceTemplate(c.Req.Context(), SilenceTemplateFromApiSilenceTemplate(c.SignedInUser.GetOrgID(), tmpl), provenance)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, ApiSilenceTemplateFromSilenceTemplate(created, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePutSilenceTemplate(c *contextmodel.ReqContext, tmpl definitions.SilenceTemplate, UID string) response.Response {
+	tmpl.UID = UID
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	updated, err := srv.silences.UpdateSilenceTemplate(c.Req.Context(), SilenceTemplateFromApiSilenceTemplate(c.SignedInUser.GetOrgID(), tmpl), provenance)
+	if err != nil {
+		if errors.Is(err, alerting_models.ErrSilenceTemplateNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, ApiSilenceTemplateFromSilenceTemplate(updated, provenance))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteSilenceTemplate(c *contextmodel.ReqContext, UID string) response.Response {
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	err := srv.silences.DeleteSilenceTemplate(c.Req.Context(), c.SignedInUser.GetOrgID(), UID, provenance)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
+func (srv *ProvisioningSrv) RouteGetRecurringSilences(c *contextmodel.ReqContext) response.Response {
+	silences, provenances, err := srv.silences.GetRecurringSilences(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, ApiRecurringSilencesFromRecurringSilences(silences, provenances))
+}
+
+func (srv *ProvisioningSrv) RouteGetRecurringSilence(c *contextmodel.ReqContext, UID string) response.Response {
+	s, provenance, err := srv.silences.GetRecurringSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), UID)
+	if err != nil {
+		if errors.Is(err, alerting_models.ErrRecurringSilenceNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, ApiRecurringSilenceFromRecurringSilence(s, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePostRecurringSilence(c *contextmodel.ReqContext, s definitions.RecurringSilence) response.Response {
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	created, err := srv.silences.CreateRecurringSilence(c.Req.Context(), RecurringSilenceFromApiRecurringSilence(c.SignedInUser.GetOrgID(), s), provenance)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, ApiRecurringSilenceFromRecurringSilence(created, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePutRecurringSilence(c *contextmodel.ReqContext, s definitions.RecurringSilence, UID string) response.Response {
+	s.UID = UID
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	updated, err := srv.silences.UpdateRecurringSilence(c.Req.Context(), RecurringSilenceFromApiRecurringSilence(c.SignedInUser.GetOrgID(), s), provenance)
+	if err != nil {
+		if errors.Is(err, alerting_models.ErrRecurringSilenceNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, ApiRecurringSilenceFromRecurringSilence(updated, provenance))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteRecurringSilence(c *contextmodel.ReqContext, UID string) response.Response {
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	err := srv.silences.DeleteRecurringSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), UID, provenance)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
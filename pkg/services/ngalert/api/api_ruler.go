@@ -20,6 +20,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -42,6 +43,7 @@ type RulerSrv struct {
 	cfg                *setting.UnifiedAlertingSettings
 	conditionValidator ConditionValidator
 	authz              RuleAccessControlService
+	amConfigStore      AlertingStore
 }
 
 var (
@@ -296,6 +298,10 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return err
 		}
 
+		if err := validateNotificationSettings(c.Req.Context(), srv.amConfigStore, groupKey.OrgID, groupChanges); err != nil {
+			return err
+		}
+
 		if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
 			return err
 		}
@@ -429,29 +435,34 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 	}
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
-			ID:              r.ID,
-			OrgID:           r.OrgID,
-			Title:           r.Title,
-			Condition:       r.Condition,
-			Data:            ApiAlertQueriesFromAlertQueries(r.Data),
-			Updated:         r.Updated,
-			IntervalSeconds: r.IntervalSeconds,
-			Version:         r.Version,
-			UID:             r.UID,
-			NamespaceUID:    r.NamespaceUID,
-			NamespaceID:     namespaceID,
-			RuleGroup:       r.RuleGroup,
-			NoDataState:     apimodels.NoDataState(r.NoDataState),
-			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
-			Provenance:      apimodels.Provenance(provenance),
-			IsPaused:        r.IsPaused,
+			ID:                   r.ID,
+			OrgID:                r.OrgID,
+			Title:                r.Title,
+			Condition:            r.Condition,
+			Data:                 ApiAlertQueriesFromAlertQueries(r.Data),
+			Updated:              r.Updated,
+			IntervalSeconds:      r.IntervalSeconds,
+			Version:              r.Version,
+			UID:                  r.UID,
+			NamespaceUID:         r.NamespaceUID,
+			NamespaceID:          namespaceID,
+			RuleGroup:            r.RuleGroup,
+			NoDataState:          apimodels.NoDataState(r.NoDataState),
+			ExecErrState:         apimodels.ExecutionErrorState(r.ExecErrState),
+			Provenance:           apimodels.Provenance(provenance),
+			IsPaused:             r.IsPaused,
+			Record:               ApiRecordFromModelRecord(r.Record),
+			Dependencies:         ApiDependenciesFromModelDependencies(r.Dependencies),
+			NotificationSettings: ApiNotificationSettingsFromModelNotificationSettings(r.NotificationSettings),
 		},
 	}
 	forDuration := model.Duration(r.For)
+	keepFiringFor := model.Duration(r.KeepFiringFor)
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
-		For:         &forDuration,
-		Annotations: r.Annotations,
-		Labels:      r.Labels,
+		For:           &forDuration,
+		KeepFiringFor: &keepFiringFor,
+		Annotations:   r.Annotations,
+		Labels:        r.Labels,
 	}
 	return gettableExtendedRuleNode
 }
@@ -509,6 +520,58 @@ func validateQueries(ctx context.Context, groupChanges *store.GroupDelta, valida
 	return nil
 }
 
+// validateNotificationSettings checks that the receivers and mute timings used by the notification settings of the
+// created and updated rules exist in the current Alertmanager configuration of the organization.
+func validateNotificationSettings(ctx context.Context, amConfigStore AlertingStore, orgID int64, groupChanges *store.GroupDelta) error {
+	rules := make([]*ngmodels.AlertRule, 0, len(groupChanges.New)+len(groupChanges.Update))
+	for _, rule := range groupChanges.New {
+		if rule.NotificationSettings != nil {
+			rules = append(rules, rule)
+		}
+	}
+	for _, upd := range groupChanges.Update {
+		if upd.New.NotificationSettings != nil {
+			rules = append(rules, upd.New)
+		}
+	}
+	if len(rules) == 0 || amConfigStore == nil {
+		return nil
+	}
+
+	cfg, err := amConfigStore.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the Alertmanager configuration: %w", err)
+	}
+	amConfig, err := notifier.Load([]byte(cfg.AlertmanagerConfiguration))
+	if err != nil {
+		return fmt.Errorf("failed to load the Alertmanager configuration: %w", err)
+	}
+	receivers := make(map[string]struct{}, len(amConfig.AlertmanagerConfig.Receivers))
+	for _, r := range amConfig.AlertmanagerConfig.Receivers {
+		receivers[r.Name] = struct{}{}
+	}
+	muteTimings := make(map[string]struct{}, len(amConfig.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range amConfig.AlertmanagerConfig.MuteTimeIntervals {
+		muteTimings[mt.Name] = struct{}{}
+	}
+
+	for _, rule := range rules {
+		settings := rule.NotificationSettings
+		if _, ok := receivers[settings.Receiver]; !ok {
+			return fmt.Errorf("%w '%s': receiver '%s' does not exist", ngmodels.ErrAlertRuleFailedValidation, rule.Title, settings.Receiver)
+		}
+		for _, mt := range settings.MuteTimeIntervals {
+			if _, ok := muteTimings[mt]; !ok {
+				return fmt.Errorf("%w '%s': mute timing '%s' does not exist", ngmodels.ErrAlertRuleFailedValidation, rule.Title, mt)
+			}
+		}
+	}
+	return nil
+}
+
 // getAuthorizedRuleByUid fetches all rules in group to which the specified rule belongs, and checks whether the user is authorized to access the group.
 // A user is authorized to access a group of rules only when it has permission to query all data sources used by all rules in this group.
 // Returns rule identified by provided UID or ErrAuthorization if user is not authorized to access the rule.
@@ -1,28 +1,43 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 type Historian interface {
 	Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
+	QueryTransitions(ctx context.Context, query models.HistoryTransitionsQuery) (models.HistoryTransitions, error)
 }
 
 type HistorySrv struct {
 	logger log.Logger
 	hist   Historian
+	store  RuleStore
+	authz  RuleAccessControlService
 }
 
-const labelQueryPrefix = "labels_"
+const (
+	labelQueryPrefix = "labels_"
+
+	defaultHistoryRange     = 6 * time.Hour
+	defaultTopFlappingRules = 10
+)
 
 func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) response.Response {
 	from := c.QueryInt64("from")
@@ -32,13 +47,6 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 	dashUID := c.Query("dashboardUID")
 	panelID := c.QueryInt64("panelID")
 
-	labels := make(map[string]string)
-	for k, v := range c.Req.URL.Query() {
-		if strings.HasPrefix(k, labelQueryPrefix) {
-			labels[k[len(labelQueryPrefix):]] = v[0]
-		}
-	}
-
 	query := models.HistoryQuery{
 		RuleUID:      ruleUID,
 		OrgID:        c.SignedInUser.GetOrgID(),
@@ -48,7 +56,7 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 		From:         time.Unix(from, 0),
 		To:           time.Unix(to, 0),
 		Limit:        limit,
-		Labels:       labels,
+		Labels:       labelsFromQuery(c),
 	}
 	frame, err := srv.hist.Query(c.Req.Context(), query)
 	if err != nil {
@@ -56,3 +64,210 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 	}
 	return response.JSON(http.StatusOK, frame)
 }
+
+// RouteQueryStateHistorySummary summarizes the state history of the alert rules selected by the request.
+func (srv *HistorySrv) RouteQueryStateHistorySummary(c *contextmodel.ReqContext) response.Response {
+	top := c.QueryInt("top")
+	if top <= 0 {
+		top = defaultTopFlappingRules
+	}
+	query, rules, errResp := srv.queryTransitions(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	var transitions models.HistoryTransitions
+	if len(rules) > 0 {
+		var err error
+		transitions, err = srv.hist.QueryTransitions(c.Req.Context(), query)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to query state history")
+		}
+	}
+
+	from := query.From
+	if transitions.Truncated && len(transitions.Transitions) > 0 {
+		// the older transitions are missing, the states of the instances are only known from the oldest transition.
+		from = transitions.Transitions[0].Time
+	}
+	summary := summarizeStateHistory(transitions.Transitions, rules, from, query.To, time.Now(), top)
+	summary.Truncated = transitions.Truncated
+	return historyExportResponse(c, "summary", summary, func(w *csv.Writer) error {
+		return writeSummaryCSV(w, summary)
+	})
+}
+
+// RouteQueryStateHistoryTransitions returns the state transitions of alert instances of the alert rules selected by the request.
+func (srv *HistorySrv) RouteQueryStateHistoryTransitions(c *contextmodel.ReqContext) response.Response {
+	query, rules, errResp := srv.queryTransitions(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	result := apimodels.StateHistoryTransitions{Transitions: []apimodels.StateHistoryTransition{}}
+	if len(rules) > 0 {
+		transitions, err := srv.hist.QueryTransitions(c.Req.Context(), query)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to query state history")
+		}
+		result.Truncated = transitions.Truncated
+		for _, t := range transitions.Transitions {
+			rule, ok := rules[t.RuleUID]
+			if !ok {
+				continue
+			}
+			result.Transitions = append(result.Transitions, apimodels.StateHistoryTransition{
+				Time:           t.Time,
+				RuleUID:        t.RuleUID,
+				Title:          rule.Title,
+				FolderUID:      rule.NamespaceUID,
+				Fingerprint:    t.Fingerprint,
+				Labels:         t.Labels,
+				Previous:       t.Previous,
+				PreviousReason: t.PreviousReason,
+				Current:        t.Current,
+				CurrentReason:  t.CurrentReason,
+				AcknowledgedBy: t.AcknowledgedBy,
+				Assignee:       t.Assignee,
+			})
+		}
+	}
+
+	return historyExportResponse(c, "transitions", result, func(w *csv.Writer) error {
+		return writeTransitionsCSV(w, result)
+	})
+}
+
+// queryTransitions builds the query for state transitions from the request. The query is limited to the rules that the user
+// has access to, which are returned indexed by UID.
+func (srv *HistorySrv) queryTransitions(c *contextmodel.ReqContext) (models.HistoryTransitionsQuery, map[string]*models.AlertRule, response.Response) {
+	switch format := c.Query("format"); format {
+	case "", "json", "csv":
+	default:
+		return models.HistoryTransitionsQuery{}, nil, ErrResp(http.StatusBadRequest, fmt.Errorf("unsupported format '%s'", format), "")
+	}
+
+	to := time.Now()
+	if ts := c.QueryInt64("to"); ts > 0 {
+		to = time.Unix(ts, 0)
+	}
+	from := to.Add(-defaultHistoryRange)
+	if ts := c.QueryInt64("from"); ts > 0 {
+		from = time.Unix(ts, 0)
+	}
+	if from.After(to) {
+		return models.HistoryTransitionsQuery{}, nil, ErrResp(http.StatusBadRequest, fmt.Errorf("start time cannot be after end time"), "")
+	}
+
+	rules, err := srv.authorizedRules(c.Req.Context(), c.SignedInUser, c.QueryStrings("ruleUID"), c.QueryStrings("folderUID"))
+	if err != nil {
+		if errors.Is(err, models.ErrAlertRuleNotFound) {
+			return models.HistoryTransitionsQuery{}, nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return models.HistoryTransitionsQuery{}, nil, errorToResponse(err)
+	}
+	uids := make([]string, 0, len(rules))
+	for uid := range rules {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	return models.HistoryTransitionsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		RuleUIDs:     uids,
+		Labels:       labelsFromQuery(c),
+		From:         from,
+		To:           to,
+		Limit:        c.QueryInt("limit"),
+		SignedInUser: c.SignedInUser,
+	}, rules, nil
+}
+
+// authorizedRules returns the rules with the given UIDs in the given folders that the user has access to.
+// All rules of the organization are considered if no UIDs are specified. It fails if any rule that was requested by UID
+// does not exist or the user does not have access to it.
+func (srv *HistorySrv) authorizedRules(ctx context.Context, user identity.Requester, ruleUIDs []string, folderUIDs []string) (map[string]*models.AlertRule, error) {
+	rules, err := srv.store.ListAlertRules(ctx, &models.ListAlertRulesQuery{
+		OrgID:         user.GetOrgID(),
+		NamespaceUIDs: folderUIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+
+	namespaces, err := srv.store.GetUserVisibleNamespaces(ctx, user.GetOrgID(), user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespaces visible to the user: %w", err)
+	}
+
+	requested := make(map[string]struct{}, len(ruleUIDs))
+	for _, uid := range ruleUIDs {
+		requested[uid] = struct{}{}
+	}
+	selected := make(models.RulesGroup, 0, len(rules))
+	for _, rule := range rules {
+		if _, ok := namespaces[rule.NamespaceUID]; !ok {
+			continue
+		}
+		if _, ok := requested[rule.UID]; len(requested) == 0 || ok {
+			selected = append(selected, rule)
+		}
+	}
+
+	result := make(map[string]*models.AlertRule, len(selected))
+	for _, group := range models.GroupByAlertRuleGroupKey(selected) {
+		ok, err := srv.authz.HasAccessToRuleGroup(ctx, user, group)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for _, rule := range group {
+			result[rule.UID] = rule
+		}
+	}
+
+	for uid := range requested {
+		if _, ok := result[uid]; !ok {
+			return nil, fmt.Errorf("%w: rule %s", models.ErrAlertRuleNotFound, uid)
+		}
+	}
+	return result, nil
+}
+
+func labelsFromQuery(c *contextmodel.ReqContext) map[string]string {
+	labels := make(map[string]string)
+	for k, v := range c.Req.URL.Query() {
+		if strings.HasPrefix(k, labelQueryPrefix) {
+			labels[k[len(labelQueryPrefix):]] = v[0]
+		}
+	}
+	return labels
+}
+
+// historyExportResponse responds with the body as JSON or, if the request asks for the CSV format, with the CSV produced by writeCSV.
+func historyExportResponse(c *contextmodel.ReqContext, name string, body any, writeCSV func(w *csv.Writer) error) response.Response {
+	download := c.QueryBoolWithDefault("download", false)
+	if c.Query("format") != "csv" {
+		if download {
+			return response.JSONDownload(http.StatusOK, body, fmt.Sprintf("%s.json", name))
+		}
+		return response.JSON(http.StatusOK, body)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := writeCSV(w); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to write CSV")
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to write CSV")
+	}
+	resp := response.Respond(http.StatusOK, buf.Bytes()).SetHeader("Content-Type", "text/csv")
+	if download {
+		return resp.SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename=%s.csv`, name))
+	}
+	return resp
+}
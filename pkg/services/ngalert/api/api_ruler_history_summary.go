@@ -0,0 +1,271 @@
+package api
+
+import (
+	"encoding/csv"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// historyStates are the states reported in the CSV export of the summary, in the order of the columns.
+var historyStates = []string{eval.Normal.String(), eval.Pending.String(), eval.Alerting.String(), eval.NoData.String(), eval.Error.String()}
+
+// historyStats accumulates the statistics of the state history of an alert instance or of a rule.
+type historyStats struct {
+	durations     map[string]time.Duration
+	transitions   int
+	firings       int
+	resolved      int
+	timeToResolve time.Duration
+}
+
+func newHistoryStats() historyStats {
+	return historyStats{durations: make(map[string]time.Duration)}
+}
+
+func (s *historyStats) add(other historyStats) {
+	for st, d := range other.durations {
+		s.durations[st] += d
+	}
+	s.transitions += other.transitions
+	s.firings += other.firings
+	s.resolved += other.resolved
+	s.timeToResolve += other.timeToResolve
+}
+
+func (s *historyStats) toAPI() apimodels.StateHistoryStats {
+	result := apimodels.StateHistoryStats{
+		StateDurations: make(map[string]float64, len(s.durations)),
+		Transitions:    s.transitions,
+		Firings:        s.firings,
+	}
+	for st, d := range s.durations {
+		result.StateDurations[st] = d.Seconds()
+	}
+	if s.resolved > 0 {
+		mttr := (s.timeToResolve / time.Duration(s.resolved)).Seconds()
+		result.MTTR = &mttr
+	}
+	return result
+}
+
+// instanceHistory replays the transitions of an alert instance.
+type instanceHistory struct {
+	fingerprint string
+	labels      map[string]string
+	stats       historyStats
+
+	state string
+	since time.Time
+	// active is false when the instance does not exist, for example after it was resolved as stale.
+	active bool
+	// firingSince is the time the instance started firing, or zero if it is not firing or started firing before the time range.
+	firingSince time.Time
+}
+
+func isInactiveReason(reason string) bool {
+	return reason == models.StateReasonMissingSeries || reason == models.StateReasonRuleDeleted
+}
+
+func (h *instanceHistory) apply(t models.HistoryTransition, at time.Time) {
+	if h.active {
+		h.stats.durations[h.state] += at.Sub(h.since)
+	}
+	h.stats.transitions++
+
+	alerting := eval.Alerting.String()
+	switch {
+	case t.Current == alerting && t.Previous != alerting:
+		h.stats.firings++
+		h.firingSince = at
+	case t.Previous == alerting && t.Current != alerting:
+		if !h.firingSince.IsZero() {
+			h.stats.resolved++
+			h.stats.timeToResolve += at.Sub(h.firingSince)
+		}
+		h.firingSince = time.Time{}
+	}
+
+	h.state = t.Current
+	h.since = at
+	h.active = !isInactiveReason(t.CurrentReason)
+}
+
+// summarizeStateHistory calculates the statistics of the alert instances of the rules from their transitions in the time range.
+// The state of an instance before its first transition in the range is taken from the previous state of that transition.
+// The time in the current state of an instance is counted until the end of the time range or now, whichever is earlier.
+// Only rules that have transitions in the time range are included.
+func summarizeStateHistory(transitions []models.HistoryTransition, rules map[string]*models.AlertRule, from, to, now time.Time, top int) apimodels.StateHistorySummary {
+	end := to
+	if now.Before(end) {
+		end = now
+	}
+	clamp := func(t time.Time) time.Time {
+		if t.Before(from) {
+			return from
+		}
+		if t.After(end) {
+			return end
+		}
+		return t
+	}
+
+	byRule := make(map[string]map[string]*instanceHistory)
+	for _, t := range transitions {
+		if _, ok := rules[t.RuleUID]; !ok {
+			continue
+		}
+		instances, ok := byRule[t.RuleUID]
+		if !ok {
+			instances = make(map[string]*instanceHistory)
+			byRule[t.RuleUID] = instances
+		}
+		h, ok := instances[t.Fingerprint]
+		if !ok {
+			h = &instanceHistory{
+				fingerprint: t.Fingerprint,
+				labels:      t.Labels,
+				stats:       newHistoryStats(),
+				state:       t.Previous,
+				since:       from,
+				active:      !isInactiveReason(t.PreviousReason),
+			}
+			instances[t.Fingerprint] = h
+		}
+		h.apply(t, clamp(t.Time))
+	}
+
+	summary := apimodels.StateHistorySummary{
+		From:        from,
+		To:          to,
+		Rules:       make([]apimodels.StateHistoryRuleSummary, 0, len(byRule)),
+		TopFlapping: []apimodels.StateHistoryFlappingRule{},
+	}
+	for uid, instances := range byRule {
+		rule := rules[uid]
+		ruleStats := newHistoryStats()
+		ruleSummary := apimodels.StateHistoryRuleSummary{
+			RuleUID:   rule.UID,
+			Title:     rule.Title,
+			FolderUID: rule.NamespaceUID,
+			Instances: make([]apimodels.StateHistoryInstanceSummary, 0, len(instances)),
+		}
+		for _, h := range instances {
+			if h.active {
+				h.stats.durations[h.state] += end.Sub(h.since)
+			}
+			ruleStats.add(h.stats)
+			ruleSummary.Instances = append(ruleSummary.Instances, apimodels.StateHistoryInstanceSummary{
+				Fingerprint:       h.fingerprint,
+				Labels:            h.labels,
+				StateHistoryStats: h.stats.toAPI(),
+			})
+		}
+		sort.Slice(ruleSummary.Instances, func(i, j int) bool {
+			return ruleSummary.Instances[i].Fingerprint < ruleSummary.Instances[j].Fingerprint
+		})
+		ruleSummary.StateHistoryStats = ruleStats.toAPI()
+		summary.Rules = append(summary.Rules, ruleSummary)
+	}
+	sort.Slice(summary.Rules, func(i, j int) bool {
+		if summary.Rules[i].Title != summary.Rules[j].Title {
+			return summary.Rules[i].Title < summary.Rules[j].Title
+		}
+		return summary.Rules[i].RuleUID < summary.Rules[j].RuleUID
+	})
+
+	flapping := make([]apimodels.StateHistoryRuleSummary, 0, len(summary.Rules))
+	for _, r := range summary.Rules {
+		if r.Firings > 0 {
+			flapping = append(flapping, r)
+		}
+	}
+	sort.SliceStable(flapping, func(i, j int) bool {
+		if flapping[i].Firings != flapping[j].Firings {
+			return flapping[i].Firings > flapping[j].Firings
+		}
+		return flapping[i].Transitions > flapping[j].Transitions
+	})
+	for i := 0; i < len(flapping) && i < top; i++ {
+		summary.TopFlapping = append(summary.TopFlapping, apimodels.StateHistoryFlappingRule{
+			RuleUID:     flapping[i].RuleUID,
+			Title:       flapping[i].Title,
+			FolderUID:   flapping[i].FolderUID,
+			Firings:     flapping[i].Firings,
+			Transitions: flapping[i].Transitions,
+		})
+	}
+	return summary
+}
+
+// writeSummaryCSV writes a row per alert instance. Durations are in seconds.
+func writeSummaryCSV(w *csv.Writer, summary apimodels.StateHistorySummary) error {
+	header := []string{"ruleUID", "title", "folderUID", "fingerprint", "labels", "transitions", "firings", "mttr"}
+	header = append(header, historyStates...)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, rule := range summary.Rules {
+		for _, instance := range rule.Instances {
+			mttr := ""
+			if instance.MTTR != nil {
+				mttr = formatSeconds(*instance.MTTR)
+			}
+			row := []string{
+				rule.RuleUID,
+				rule.Title,
+				rule.FolderUID,
+				instance.Fingerprint,
+				data.Labels(instance.Labels).String(),
+				strconv.Itoa(instance.Transitions),
+				strconv.Itoa(instance.Firings),
+				mttr,
+			}
+			for _, st := range historyStates {
+				row = append(row, formatSeconds(instance.StateDurations[st]))
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeTransitionsCSV writes a row per transition.
+func writeTransitionsCSV(w *csv.Writer, transitions apimodels.StateHistoryTransitions) error {
+	header := []string{"time", "ruleUID", "title", "folderUID", "fingerprint", "labels", "previous", "previousReason", "current", "currentReason", "acknowledgedBy", "assignee"}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, t := range transitions.Transitions {
+		row := []string{
+			t.Time.UTC().Format(time.RFC3339Nano),
+			t.RuleUID,
+			t.Title,
+			t.FolderUID,
+			t.Fingerprint,
+			data.Labels(t.Labels).String(),
+			t.Previous,
+			t.PreviousReason,
+			t.Current,
+			t.CurrentReason,
+			t.AcknowledgedBy,
+			t.Assignee,
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', -1, 64)
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSummarizeStateHistory(t *testing.T) {
+	from := time.Unix(0, 0)
+	to := time.Unix(1000, 0)
+	at := func(s int64) time.Time { return time.Unix(s, 0) }
+	rule1 := models.AlertRuleGen(withOrgID(1), models.WithTitle("rule 1"))()
+	rule2 := models.AlertRuleGen(withOrgID(1), models.WithTitle("rule 2"))()
+	rules := map[string]*models.AlertRule{rule1.UID: rule1, rule2.UID: rule2}
+	transition := func(ruleUID, fp string, ts int64, previous, current string) models.HistoryTransition {
+		return models.HistoryTransition{Time: at(ts), RuleUID: ruleUID, Fingerprint: fp, Previous: previous, Current: current}
+	}
+
+	transitions := []models.HistoryTransition{
+		transition(rule1.UID, "a", 100, "Normal", "Pending"),
+		transition(rule1.UID, "a", 200, "Pending", "Alerting"),
+		transition(rule2.UID, "c", 250, "Alerting", "Normal"),
+		transition(rule1.UID, "a", 300, "Alerting", "Normal"),
+		transition(rule1.UID, "b", 400, "Normal", "Alerting"),
+		transition(rule1.UID, "a", 500, "Normal", "Alerting"),
+		transition(rule1.UID, "a", 800, "Alerting", "Normal"),
+		transition("unknown", "x", 100, "Normal", "Alerting"),
+	}
+	stale := transition(rule1.UID, "b", 600, "Alerting", "Normal")
+	stale.CurrentReason = models.StateReasonMissingSeries
+	transitions = append(transitions, stale)
+
+	summary := summarizeStateHistory(transitions, rules, from, to, at(900), 1)
+
+	require.Len(t, summary.Rules, 2)
+	r1 := summary.Rules[0]
+	require.Equal(t, rule1.UID, r1.RuleUID)
+	require.Equal(t, "rule 1", r1.Title)
+	require.Len(t, r1.Instances, 2)
+
+	a := r1.Instances[0]
+	require.Equal(t, "a", a.Fingerprint)
+	require.Equal(t, 5, a.Transitions)
+	require.Equal(t, 2, a.Firings)
+	// the instance is counted until now, because now is before the end of the time range.
+	require.Equal(t, map[string]float64{"Normal": 100 + 200 + 100, "Pending": 100, "Alerting": 100 + 300}, a.StateDurations)
+	require.NotNil(t, a.MTTR)
+	require.Equal(t, 200.0, *a.MTTR)
+
+	b := r1.Instances[1]
+	require.Equal(t, "b", b.Fingerprint)
+	// the instance is not counted after it was resolved as stale.
+	require.Equal(t, map[string]float64{"Normal": 400, "Alerting": 200}, b.StateDurations)
+	require.Equal(t, 200.0, *b.MTTR)
+
+	require.Equal(t, 7, r1.Transitions)
+	require.Equal(t, 3, r1.Firings)
+	require.Equal(t, 200.0, *r1.MTTR)
+	require.Equal(t, float64(800), r1.StateDurations["Normal"])
+
+	r2 := summary.Rules[1]
+	require.Equal(t, 0, r2.Firings)
+	// the firing started before the time range, therefore it does not count towards MTTR.
+	require.Nil(t, r2.MTTR)
+	require.Equal(t, map[string]float64{"Alerting": 250, "Normal": 650}, r2.Instances[0].StateDurations)
+
+	require.Equal(t, []apimodels.StateHistoryFlappingRule{
+		{RuleUID: rule1.UID, Title: "rule 1", FolderUID: rule1.NamespaceUID, Firings: 3, Transitions: 7},
+	}, summary.TopFlapping)
+}
+
+func TestRouteQueryStateHistorySummary(t *testing.T) {
+	orgID := rand.Int63()
+	f1 := randFolder()
+	f2 := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	rule1 := models.AlertRuleGen(withOrgID(orgID), withNamespace(f1), models.WithTitle("rule 1"))()
+	rule2 := models.AlertRuleGen(withOrgID(orgID), withNamespace(f2), models.WithTitle("rule 2"))()
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], f1, f2)
+	ruleStore.PutRule(context.Background(), rule1, rule2)
+
+	hist := &fakeHistorian{transitions: []models.HistoryTransition{
+		{Time: time.Unix(1100, 0), RuleUID: rule1.UID, Fingerprint: "a", Labels: data.Labels{"a": "b"}, Previous: "Normal", Current: "Alerting"},
+		{Time: time.Unix(1150, 0), RuleUID: rule2.UID, Fingerprint: "c", Labels: data.Labels{"c": "d"}, Previous: "Normal", Current: "Alerting"},
+		{Time: time.Unix(1200, 0), RuleUID: rule1.UID, Fingerprint: "a", Labels: data.Labels{"a": "b"}, Previous: "Alerting", Current: "Normal"},
+	}}
+	srv := &HistorySrv{
+		logger: log.NewNopLogger(),
+		hist:   hist,
+		store:  ruleStore,
+		authz:  accesscontrol.NewRuleService(acimpl.ProvideAccessControl(setting.NewCfg())),
+	}
+	allRules := []*models.AlertRule{rule1, rule2}
+	request := func(perms []*models.AlertRule, query url.Values) *contextmodel.ReqContext {
+		c := createRequestContextWithPerms(orgID, createPermissionsForRules(perms, orgID), nil)
+		c.Req.URL.RawQuery = query.Encode()
+		c.Req.Form = query
+		return c
+	}
+
+	t.Run("should summarize transitions of rules the user has access to", func(t *testing.T) {
+		resp := srv.RouteQueryStateHistorySummary(request([]*models.AlertRule{rule1}, url.Values{"from": {"1000"}, "to": {"1300"}}))
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		require.Equal(t, []string{rule1.UID}, hist.lastQuery.RuleUIDs)
+		require.Equal(t, time.Unix(1000, 0), hist.lastQuery.From)
+		require.Equal(t, time.Unix(1300, 0), hist.lastQuery.To)
+
+		var summary apimodels.StateHistorySummary
+		require.NoError(t, json.Unmarshal(resp.Body(), &summary))
+		require.Len(t, summary.Rules, 1)
+		require.Equal(t, rule1.UID, summary.Rules[0].RuleUID)
+		require.Equal(t, 100.0, *summary.Rules[0].MTTR)
+		require.Len(t, summary.TopFlapping, 1)
+	})
+
+	t.Run("should filter by folders and rules", func(t *testing.T) {
+		resp := srv.RouteQueryStateHistorySummary(request(allRules, url.Values{"folderUID": {f2.UID}, "labels_c": {"d"}}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, []string{rule2.UID}, hist.lastQuery.RuleUIDs)
+		require.Equal(t, map[string]string{"c": "d"}, hist.lastQuery.Labels)
+
+		resp = srv.RouteQueryStateHistorySummary(request(allRules, url.Values{"ruleUID": {rule1.UID}}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, []string{rule1.UID}, hist.lastQuery.RuleUIDs)
+	})
+
+	t.Run("should return 404 if the requested rule is not accessible", func(t *testing.T) {
+		resp := srv.RouteQueryStateHistorySummary(request([]*models.AlertRule{rule1}, url.Values{"ruleUID": {rule2.UID}}))
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("should return 400 if the request is invalid", func(t *testing.T) {
+		resp := srv.RouteQueryStateHistorySummary(request(allRules, url.Values{"format": {"xml"}}))
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+
+		resp = srv.RouteQueryStateHistorySummary(request(allRules, url.Values{"from": {"20"}, "to": {"10"}}))
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should export summary as CSV", func(t *testing.T) {
+		rc := request(allRules, url.Values{"from": {"1000"}, "to": {"1300"}, "format": {"csv"}, "download": {"true"}})
+		resp := srv.RouteQueryStateHistorySummary(rc)
+		resp.WriteTo(rc)
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, "text/csv", rc.Resp.Header().Get("Content-Type"))
+		require.Equal(t, "attachment;filename=summary.csv", rc.Resp.Header().Get("Content-Disposition"))
+
+		records, err := csv.NewReader(strings.NewReader(string(resp.Body()))).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		require.Equal(t, []string{"ruleUID", "title", "folderUID", "fingerprint", "labels", "transitions", "firings", "mttr", "Normal", "Pending", "Alerting", "NoData", "Error"}, records[0])
+		require.Equal(t, []string{rule1.UID, "rule 1", f1.UID, "a", "a=b", "2", "1", "100", "200", "0", "100", "0", "0"}, records[1])
+	})
+
+	t.Run("should return transitions as JSON and CSV", func(t *testing.T) {
+		resp := srv.RouteQueryStateHistoryTransitions(request(allRules, url.Values{"from": {"1000"}, "to": {"1300"}}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var result apimodels.StateHistoryTransitions
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Len(t, result.Transitions, 3)
+		require.Equal(t, "rule 2", result.Transitions[1].Title)
+		require.Equal(t, f2.UID, result.Transitions[1].FolderUID)
+
+		resp = srv.RouteQueryStateHistoryTransitions(request(allRules, url.Values{"format": {"csv"}}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		records, err := csv.NewReader(strings.NewReader(string(resp.Body()))).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		require.Equal(t, []string{"1970-01-01T00:18:20Z", rule1.UID, "rule 1", f1.UID, "a", "a=b", "Normal", "", "Alerting", "", "", ""}, records[1])
+	})
+
+	t.Run("should report truncated state history", func(t *testing.T) {
+		hist.truncated = true
+		t.Cleanup(func() { hist.truncated = false })
+
+		resp := srv.RouteQueryStateHistorySummary(request(allRules, url.Values{"from": {"1000"}, "to": {"1300"}}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var summary apimodels.StateHistorySummary
+		require.NoError(t, json.Unmarshal(resp.Body(), &summary))
+		require.True(t, summary.Truncated)
+		require.True(t, time.Unix(1100, 0).Equal(summary.From))
+
+		resp = srv.RouteQueryStateHistoryTransitions(request(allRules, url.Values{"from": {"1000"}, "to": {"1300"}}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var result apimodels.StateHistoryTransitions
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.True(t, result.Truncated)
+	})
+
+	t.Run("should not query the historian if the user has no access to rules", func(t *testing.T) {
+		hist.lastQuery = models.HistoryTransitionsQuery{}
+		resp := srv.RouteQueryStateHistoryTransitions(request(nil, url.Values{"folderUID": {"unknown"}}))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Zero(t, hist.lastQuery.OrgID)
+	})
+}
+
+type fakeHistorian struct {
+	transitions []models.HistoryTransition
+	truncated   bool
+	lastQuery   models.HistoryTransitionsQuery
+}
+
+func (f *fakeHistorian) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	return data.NewFrame("states"), nil
+}
+
+func (f *fakeHistorian) QueryTransitions(ctx context.Context, query models.HistoryTransitionsQuery) (models.HistoryTransitions, error) {
+	f.lastQuery = query
+	return models.HistoryTransitions{Transitions: f.transitions, Truncated: f.truncated}, nil
+}
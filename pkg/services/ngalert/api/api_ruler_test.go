@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
@@ -587,6 +588,56 @@ func TestValidateQueries(t *testing.T) {
 	})
 }
 
+func TestValidateNotificationSettings(t *testing.T) {
+	const orgID = 1
+	amConfig := `{
+		"alertmanager_config": {
+			"route": {"receiver": "default"},
+			"mute_time_intervals": [{"name": "weekends"}],
+			"receivers": [
+				{"name": "default", "grafana_managed_receiver_configs": []},
+				{"name": "team-a", "grafana_managed_receiver_configs": []}
+			]
+		}
+	}`
+	configStore := notifier.NewFakeConfigStore(t, map[int64]*models.AlertConfiguration{
+		orgID: {AlertmanagerConfiguration: amConfig, OrgID: orgID},
+	})
+
+	deltaWith := func(settings models.NotificationSettings) *store.GroupDelta {
+		return &store.GroupDelta{
+			New: []*models.AlertRule{models.AlertRuleGen(models.WithNotificationSettings(settings))()},
+		}
+	}
+
+	t.Run("should accept existing receiver and mute timings", func(t *testing.T) {
+		delta := deltaWith(models.NotificationSettings{Receiver: "team-a", MuteTimeIntervals: []string{"weekends"}})
+		require.NoError(t, validateNotificationSettings(context.Background(), configStore, orgID, delta))
+	})
+
+	t.Run("should reject unknown receiver", func(t *testing.T) {
+		delta := deltaWith(models.NotificationSettings{Receiver: "unknown"})
+		err := validateNotificationSettings(context.Background(), configStore, orgID, delta)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "receiver 'unknown' does not exist")
+	})
+
+	t.Run("should reject unknown mute timing of updated rule", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithNotificationSettings(models.NotificationSettings{Receiver: "team-a", MuteTimeIntervals: []string{"unknown"}}))()
+		delta := &store.GroupDelta{
+			Update: []store.RuleDelta{{Existing: models.CopyRule(rule), New: rule}},
+		}
+		err := validateNotificationSettings(context.Background(), configStore, orgID, delta)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "mute timing 'unknown' does not exist")
+	})
+
+	t.Run("should not check rules without settings", func(t *testing.T) {
+		delta := &store.GroupDelta{New: []*models.AlertRule{models.AlertRuleGen()()}}
+		require.NoError(t, validateNotificationSettings(context.Background(), configStore, 2, delta))
+	})
+}
+
 func createServiceWithProvenanceStore(store *fakes.RuleStore, provenanceStore provisioning.ProvisioningStore) *RulerSrv {
 	svc := createService(store)
 	svc.provenanceStore = provenanceStore
@@ -67,40 +67,89 @@ func validateRuleNode(
 		}
 	}
 
+	record := ModelRecordFromApiRecord(ruleNode.GrafanaManagedAlert.Record)
+	condition := ruleNode.GrafanaManagedAlert.Condition
+	if record != nil {
+		if !cfg.RecordingRules.Enabled {
+			return nil, fmt.Errorf("%w: recording rules are disabled", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if err := record.Validate(); err != nil {
+			return nil, err
+		}
+		// Recording rules do not have a condition. The recorded query or expression is evaluated instead.
+		if condition == "" {
+			condition = record.From
+		}
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) == 0 {
 		if canPatch {
 			if ruleNode.GrafanaManagedAlert.Condition != "" {
 				return nil, fmt.Errorf("%w: query is not specified by condition is. You must specify both query and condition to update existing alert rule", ngmodels.ErrAlertRuleFailedValidation)
 			}
+			if record != nil {
+				return nil, fmt.Errorf("%w: query is not specified but record is. You must specify both query and record to update existing recording rule", ngmodels.ErrAlertRuleFailedValidation)
+			}
 		} else {
 			return nil, fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation)
 		}
 	} else {
-		err = validateCondition(ruleNode.GrafanaManagedAlert.Condition, ruleNode.GrafanaManagedAlert.Data)
+		err = validateCondition(condition, ruleNode.GrafanaManagedAlert.Data)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
 		}
+		if record != nil && record.From != condition {
+			if err = validateCondition(record.From, ruleNode.GrafanaManagedAlert.Data); err != nil {
+				return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+			}
+		}
 	}
 
 	queries := AlertQueriesFromApiAlertQueries(ruleNode.GrafanaManagedAlert.Data)
 
 	newAlertRule := ngmodels.AlertRule{
-		OrgID:           orgId,
-		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
-		Data:            queries,
-		UID:             ruleNode.GrafanaManagedAlert.UID,
-		IntervalSeconds: intervalSeconds,
-		NamespaceUID:    namespace.UID,
-		RuleGroup:       groupName,
-		NoDataState:     noDataState,
-		ExecErrState:    errorState,
+		OrgID:                orgId,
+		Title:                ruleNode.GrafanaManagedAlert.Title,
+		Condition:            condition,
+		Data:                 queries,
+		UID:                  ruleNode.GrafanaManagedAlert.UID,
+		IntervalSeconds:      intervalSeconds,
+		NamespaceUID:         namespace.UID,
+		RuleGroup:            groupName,
+		NoDataState:          noDataState,
+		ExecErrState:         errorState,
+		Record:               record,
+		Dependencies:         ModelDependenciesFromApiDependencies(ruleNode.GrafanaManagedAlert.Dependencies),
+		NotificationSettings: ModelNotificationSettingsFromApiNotificationSettings(ruleNode.GrafanaManagedAlert.NotificationSettings),
 	}
 
 	newAlertRule.For, err = validateForInterval(ruleNode)
 	if err != nil {
 		return nil, err
 	}
+	newAlertRule.KeepFiringFor, err = validateKeepFiringFor(ruleNode)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		if newAlertRule.For > 0 {
+			return nil, fmt.Errorf("%w: field `for` is not supported by recording rules", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if newAlertRule.KeepFiringFor > 0 {
+			return nil, fmt.Errorf("%w: field `keep_firing_for` is not supported by recording rules", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if newAlertRule.NotificationSettings != nil {
+			return nil, fmt.Errorf("%w: field `notification_settings` is not supported by recording rules", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		newAlertRule.For = 0
+		newAlertRule.KeepFiringFor = 0
+	}
+
+	if newAlertRule.NotificationSettings != nil {
+		if err := newAlertRule.NotificationSettings.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: invalid notification settings: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+		}
+	}
 
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
@@ -173,6 +222,21 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringFor validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringFor(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
 // validateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
@@ -233,5 +297,52 @@ func validateRuleGroup(
 
 		result = append(result, &ruleWithOptionals)
 	}
+
+	group := make(ngmodels.RulesGroup, 0, len(result))
+	for _, r := range result {
+		group = append(group, &r.AlertRule)
+	}
+	if err := validateRuleDependencies(group); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
+
+// validateRuleDependencies validates that the rules of a group depend only on
+// other existing rules of the same group, and that their dependencies do not
+// form a cycle. New rules get their UID when they are saved, so rules can't
+// depend on rules created in the same request.
+func validateRuleDependencies(group ngmodels.RulesGroup) error {
+	if !group.HasDependencies() {
+		return nil
+	}
+	uids := make(map[string]struct{}, len(group))
+	hasNewRules := false
+	for _, r := range group {
+		if r.UID != "" {
+			uids[r.UID] = struct{}{}
+		} else {
+			hasNewRules = true
+		}
+	}
+	for _, r := range group {
+		for _, dep := range r.Dependencies {
+			if dep.RuleUID == "" {
+				return fmt.Errorf("%w: rule '%s' has a dependency without the UID of the rule to depend on", ngmodels.ErrAlertRuleFailedValidation, r.Title)
+			}
+			if dep.RuleUID == r.UID {
+				return fmt.Errorf("%w: rule '%s' cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation, r.Title)
+			}
+			if _, ok := uids[dep.RuleUID]; !ok && hasNewRules {
+				return fmt.Errorf("%w: rule '%s' depends on rule %s that does not belong to the group. Rules can't depend on new rules of the same request, save the new rules first", ngmodels.ErrAlertRuleFailedValidation, r.Title, dep.RuleUID)
+			}
+			if _, ok := uids[dep.RuleUID]; !ok {
+				return fmt.Errorf("%w: rule '%s' depends on rule %s that does not belong to the group", ngmodels.ErrAlertRuleFailedValidation, r.Title, dep.RuleUID)
+			}
+		}
+	}
+	if _, err := group.SortByDependencies(); err != nil {
+		return fmt.Errorf("%w: %w", ngmodels.ErrAlertRuleFailedValidation, err)
+	}
+	return nil
+}
@@ -210,6 +210,17 @@ func TestValidateRuleGroup(t *testing.T) {
 		}
 	})
 
+	t.Run("should accept dependencies on rules of the group", func(t *testing.T) {
+		upstream := validRule()
+		upstream.GrafanaManagedAlert.UID = util.GenerateShortUID()
+		dependent := validRule()
+		dependent.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: upstream.GrafanaManagedAlert.UID, Equal: []string{"instance"}}}
+		g := validGroup(cfg, upstream, dependent)
+		alerts, err := validateRuleGroup(&g, orgId, folder, cfg)
+		require.NoError(t, err)
+		require.Equal(t, []models.RuleDependency{{RuleUID: upstream.GrafanaManagedAlert.UID, Equal: []string{"instance"}}}, alerts[1].Dependencies)
+	})
+
 	t.Run("should show the payload has isPaused field", func(t *testing.T) {
 		for _, rule := range rules {
 			isPaused := true
@@ -284,6 +295,51 @@ func TestValidateRuleGroupFailures(t *testing.T) {
 				require.Contains(t, err.Error(), apiModel.Rules[0].GrafanaManagedAlert.UID)
 			},
 		},
+		{
+			name: "fail if rule depends on a rule of another group",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: util.GenerateShortUID()}}
+				g := validGroup(cfg, r)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.Contains(t, err.Error(), "does not belong to the group")
+			},
+		},
+		{
+			name: "fail if rule depends on a new rule of the same request",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				upstream := validRule()
+				upstream.GrafanaManagedAlert.UID = ""
+				dependent := validRule()
+				dependent.GrafanaManagedAlert.UID = util.GenerateShortUID()
+				dependent.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: util.GenerateShortUID()}}
+				g := validGroup(cfg, upstream, dependent)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.Contains(t, err.Error(), "save the new rules first")
+			},
+		},
+		{
+			name: "fail if dependencies form a cycle",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r2 := validRule()
+				r1.GrafanaManagedAlert.UID = "r1"
+				r2.GrafanaManagedAlert.UID = "r2"
+				r1.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: "r2"}}
+				r2.GrafanaManagedAlert.Dependencies = []apimodels.RuleDependency{{RuleUID: "r1"}}
+				g := validGroup(cfg, r1, r2)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrRuleDependencyCycle)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -337,6 +393,41 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Equal(t, api.ApiRuleNode.Labels, alert.Labels)
 			},
 		},
+		{
+			name: "converts keep_firing_for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(5 * time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, alert.KeepFiringFor)
+			},
+		},
+		{
+			name: "converts notification settings",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				groupWait := model.Duration(30 * time.Second)
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{
+					Receiver:          "team-a",
+					GroupBy:           []string{models.GroupByAll},
+					GroupWait:         &groupWait,
+					MuteTimeIntervals: []string{"weekends"},
+				}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				settings := api.GrafanaManagedAlert.NotificationSettings
+				require.Equal(t, &models.NotificationSettings{
+					Receiver:          settings.Receiver,
+					GroupBy:           settings.GroupBy,
+					GroupWait:         settings.GroupWait,
+					MuteTimeIntervals: settings.MuteTimeIntervals,
+				}, alert.NotificationSettings)
+			},
+		},
 		{
 			name: "coverts api without ApiRuleNode",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -346,6 +437,7 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 			},
 			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
 				require.Equal(t, time.Duration(0), alert.For)
+				require.Equal(t, time.Duration(0), alert.KeepFiringFor)
 				require.Nil(t, alert.Annotations)
 				require.Nil(t, alert.Labels)
 			},
@@ -543,6 +635,33 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if notification settings do not have receiver",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{}
+				return &r
+			},
+			assert: func(t *testing.T, model *apimodels.PostableExtendedRuleNode, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.ErrorContains(t, err, "invalid notification settings")
+			},
+		},
+		{
+			name: "fail if notification settings group by does not contain default labels",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{
+					Receiver: "team-a",
+					GroupBy:  []string{"cluster"},
+				}
+				return &r
+			},
+			assert: func(t *testing.T, model *apimodels.PostableExtendedRuleNode, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.ErrorContains(t, err, "group by must contain the label")
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -611,6 +730,17 @@ func TestValidateRuleNode_UID(t *testing.T) {
 				require.Equal(t, models.ExecutionErrorState(""), alert.ExecErrState)
 			},
 		},
+		{
+			name: "use -1 KeepFiringFor if it is not specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.ApiRuleNode.KeepFiringFor = nil
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, time.Duration(-1), alert.KeepFiringFor)
+			},
+		},
 		{
 			name: "use empty Condition and Data if they are empty",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -790,3 +920,122 @@ func TestValidateRuleNodeIntervalFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleNode_Recording(t *testing.T) {
+	cfg := config(t)
+	cfg.RecordingRules.Enabled = true
+	interval := cfg.BaseInterval * time.Duration(rand.Int63n(10)+1)
+
+	validRecordingRule := func() apimodels.PostableExtendedRuleNode {
+		r := validRule()
+		r.ApiRuleNode.For = nil
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "job:requests:rate5m", From: "A"}
+		return r
+	}
+
+	t.Run("converts record and uses it as condition", func(t *testing.T) {
+		r := validRecordingRule()
+		r.GrafanaManagedAlert.UID = ""
+		alert, err := validateRuleNode(&r, "group", interval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, &models.Record{Metric: "job:requests:rate5m", From: "A"}, alert.Record)
+		require.Equal(t, "A", alert.Condition)
+		require.Equal(t, time.Duration(0), alert.For)
+		require.Equal(t, models.RuleTypeRecording, alert.Type())
+	})
+
+	t.Run("does not patch for of recording rules", func(t *testing.T) {
+		r := validRecordingRule()
+		alert, err := validateRuleNode(&r, "group", interval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), alert.For)
+	})
+
+	testCases := []struct {
+		name   string
+		cfg    func(cfg setting.UnifiedAlertingSettings) setting.UnifiedAlertingSettings
+		rule   func() apimodels.PostableExtendedRuleNode
+		errMsg string
+	}{
+		{
+			name: "fails if recording rules are disabled",
+			cfg: func(cfg setting.UnifiedAlertingSettings) setting.UnifiedAlertingSettings {
+				cfg.RecordingRules.Enabled = false
+				return cfg
+			},
+			rule:   validRecordingRule,
+			errMsg: "recording rules are disabled",
+		},
+		{
+			name: "fails if metric is invalid",
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				r.GrafanaManagedAlert.Record.Metric = "invalid metric"
+				return r
+			},
+			errMsg: "not a valid metric name",
+		},
+		{
+			name: "fails if from does not exist",
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				r.GrafanaManagedAlert.Condition = "A"
+				r.GrafanaManagedAlert.Record.From = "B"
+				return r
+			},
+			errMsg: "condition B does not exist",
+		},
+		{
+			name: "fails if for is set",
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				forDuration := model.Duration(time.Minute)
+				r.ApiRuleNode.For = &forDuration
+				return r
+			},
+			errMsg: "field `for` is not supported by recording rules",
+		},
+		{
+			name: "fails if keep_firing_for is set",
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				keepFiringFor := model.Duration(time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return r
+			},
+			errMsg: "field `keep_firing_for` is not supported by recording rules",
+		},
+		{
+			name: "fails if notification settings are set",
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{Receiver: "team-a"}
+				return r
+			},
+			errMsg: "field `notification_settings` is not supported by recording rules",
+		},
+		{
+			name: "fails if record is patched without queries",
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := validRecordingRule()
+				r.GrafanaManagedAlert.Data = nil
+				return r
+			},
+			errMsg: "You must specify both query and record",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := *cfg
+			if testCase.cfg != nil {
+				c = testCase.cfg(c)
+			}
+			r := testCase.rule()
+			_, err := validateRuleNode(&r, "group", interval, rand.Int63(), randFolder(), &c)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, testCase.errMsg)
+		})
+	}
+}
@@ -41,6 +41,9 @@ type TestingApiSrv struct {
 	featureManager  featuremgmt.FeatureToggles
 	appUrl          *url.URL
 	tracer          tracing.Tracer
+	store           RuleStore
+	amConfigStore   AlertingStore
+	stateManager    state.AlertInstanceManager
 }
 
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
@@ -82,18 +85,7 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *contextmodel.ReqContext,
 		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries")
 	}
 
-	cfg := state.ManagerCfg{
-		Metrics:                 nil,
-		ExternalURL:             srv.appUrl,
-		InstanceStore:           nil,
-		Images:                  &backtesting.NoopImageService{},
-		Clock:                   clock.New(),
-		Historian:               nil,
-		MaxStateSaveConcurrency: 1,
-		Tracer:                  srv.tracer,
-		Log:                     log.New("ngalert.state.manager"),
-	}
-	manager := state.NewManager(cfg)
+	manager := srv.newStateManager()
 	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel)
 	transitions := manager.ProcessEvalResults(
 		c.Req.Context(),
@@ -111,6 +103,22 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *contextmodel.ReqContext,
 	return response.JSON(http.StatusOK, alerts)
 }
 
+// newStateManager creates a state manager that keeps the states in memory only.
+func (srv TestingApiSrv) newStateManager() *state.Manager {
+	cfg := state.ManagerCfg{
+		Metrics:                 nil,
+		ExternalURL:             srv.appUrl,
+		InstanceStore:           nil,
+		Images:                  &backtesting.NoopImageService{},
+		Clock:                   clock.New(),
+		Historian:               nil,
+		MaxStateSaveConcurrency: 1,
+		Tracer:                  srv.tracer,
+		Log:                     log.New("ngalert.state.manager"),
+	}
+	return state.NewManager(cfg)
+}
+
 func (srv TestingApiSrv) RouteTestRuleConfig(c *contextmodel.ReqContext, body apimodels.TestRulePayload, datasourceUID string) response.Response {
 	if body.Type() != apimodels.LoTexRulerBackend {
 		return errorToResponse(backendTypeDoesNotMatchPayloadTypeError(apimodels.LoTexRulerBackend, body.Type().String()))
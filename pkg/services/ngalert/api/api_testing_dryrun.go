@@ -0,0 +1,302 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// dryRunRule is a rule that is affected by the submitted rule group. Current is the stored version of the rule
+// and is nil if the rule is created. Proposed is the submitted version of the rule and is nil if the rule is deleted.
+type dryRunRule struct {
+	change   apimodels.DryRunRuleChange
+	current  *ngmodels.AlertRule
+	proposed *ngmodels.AlertRule
+}
+
+// RouteDryRunGrafanaRuleGroup evaluates the submitted rule group and returns the difference between the alert instances
+// that it produces and the live alert instances of the stored version of the group. Only the rules that are created,
+// updated or deleted by the submitted group are compared. Nothing is saved and no notifications are sent.
+func (srv TestingApiSrv) RouteDryRunGrafanaRuleGroup(c *contextmodel.ReqContext, ruleGroupConfig apimodels.PostableRuleGroupConfig, namespaceTitle string) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	rules, err := validateRuleGroup(&ruleGroupConfig, c.SignedInUser.GetOrgID(), namespace, srv.cfg)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	groupKey := ngmodels.AlertRuleGroupKey{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		NamespaceUID: namespace.UID,
+		RuleGroup:    ruleGroupConfig.Name,
+	}
+	groupChanges, err := store.CalculateChanges(c.Req.Context(), srv.store, groupKey, rules)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "failed to calculate changes of the rule group")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to calculate changes of the rule group")
+	}
+
+	affected := make([]dryRunRule, 0, len(groupChanges.New)+len(groupChanges.Update)+len(groupChanges.Delete))
+	var current, proposed ngmodels.RulesGroup
+	for _, rule := range groupChanges.New {
+		affected = append(affected, dryRunRule{change: apimodels.DryRunRuleCreated, proposed: rule})
+		proposed = append(proposed, rule)
+	}
+	for _, delta := range groupChanges.Update {
+		affected = append(affected, dryRunRule{change: apimodels.DryRunRuleUpdated, current: delta.Existing, proposed: delta.New})
+		current = append(current, delta.Existing)
+		proposed = append(proposed, delta.New)
+	}
+	for _, rule := range groupChanges.Delete {
+		affected = append(affected, dryRunRule{change: apimodels.DryRunRuleDeleted, current: rule})
+		current = append(current, rule)
+	}
+
+	// the alert instances of both versions of the rules are returned, so the user must be able to query the data sources
+	// of both.
+	for _, group := range []ngmodels.RulesGroup{current, proposed} {
+		if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, group); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to rule group", err)
+		}
+	}
+
+	folderTitles := map[string]string{namespace.UID: namespace.Title}
+	receivers := srv.getReceiversFunc(c.Req.Context(), c.SignedInUser.GetOrgID())
+	now := timeNow()
+
+	extraLabels := func(rule *ngmodels.AlertRule) (data.Labels, response.Response) {
+		folderTitle, ok := folderTitles[rule.NamespaceUID]
+		if !ok {
+			// the rule is moved from another folder.
+			f, err := srv.store.GetNamespaceByUID(c.Req.Context(), rule.NamespaceUID, rule.OrgID, c.SignedInUser)
+			if err != nil {
+				return nil, toNamespaceErrorResponse(err)
+			}
+			folderTitle = f.Title
+			folderTitles[rule.NamespaceUID] = folderTitle
+		}
+		includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel)
+		return state.GetRuleExtraLabels(rule, folderTitle, includeFolder), nil
+	}
+
+	// liveStates returns the states of the alert instances of the stored version of the rule in the scheduler.
+	liveStates := func(rule *ngmodels.AlertRule) (map[string]*state.State, response.Response) {
+		if rule == nil {
+			return nil, nil
+		}
+		extra, errResp := extraLabels(rule)
+		if errResp != nil {
+			return nil, errResp
+		}
+		live := srv.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		states := make(map[string]*state.State, len(live))
+		for _, s := range live {
+			states[dryRunInstanceKey(s, rule, extra)] = s
+		}
+		return states, nil
+	}
+
+	// evaluate evaluates the submitted version of the rule. The evaluation continues from the live states of the alert
+	// instances, so pending periods and the other timers of the states are the ones of the scheduler.
+	evaluate := func(rule *ngmodels.AlertRule, current map[string]*state.State) (map[string]*state.State, response.Response) {
+		// Paused and recording rules do not produce alerts.
+		if rule == nil || rule.IsPaused || rule.Type() != ngmodels.RuleTypeAlerting {
+			return nil, nil
+		}
+		extra, errResp := extraLabels(rule)
+		if errResp != nil {
+			return nil, errResp
+		}
+
+		if _, err := store.OptimizeAlertQueries(rule.Data); err != nil {
+			return nil, ErrResp(http.StatusInternalServerError, err, "Failed to optimize query")
+		}
+		evaluator, err := srv.evaluator.Create(eval.NewContext(c.Req.Context(), c.SignedInUser), rule.GetEvalCondition())
+		if err != nil {
+			return nil, ErrResp(http.StatusBadRequest, err, "Failed to build evaluator for queries and expressions of rule '%s'", rule.Title)
+		}
+		results, err := evaluator.Evaluate(c.Req.Context(), now)
+		if err != nil {
+			return nil, ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries of rule '%s'", rule.Title)
+		}
+
+		manager := srv.newStateManager()
+		seed := make([]*state.State, 0, len(current))
+		for _, s := range current {
+			// the state manager modifies the states it processes, the live states must not change.
+			seed = append(seed, s.Copy())
+		}
+		manager.Put(seed)
+		transitions := manager.ProcessEvalResults(c.Req.Context(), now, rule, results, extra)
+		states := make(map[string]*state.State, len(transitions))
+		for _, t := range transitions {
+			states[dryRunInstanceKey(t.State, rule, extra)] = t.State
+		}
+		return states, nil
+	}
+
+	result := apimodels.DryRunRuleGroupResult{Rules: make([]apimodels.DryRunRuleDiff, 0, len(affected))}
+	for _, r := range affected {
+		currentStates, errResp := liveStates(r.current)
+		if errResp != nil {
+			return errResp
+		}
+		proposedStates, errResp := evaluate(r.proposed, currentStates)
+		if errResp != nil {
+			return errResp
+		}
+
+		diff := apimodels.DryRunRuleDiff{Change: r.change}
+		if r.proposed != nil {
+			diff.UID, diff.Title = r.proposed.UID, r.proposed.Title
+		} else {
+			diff.UID, diff.Title = r.current.UID, r.current.Title
+		}
+		diff.Instances, diff.Unchanged = diffDryRunStates(currentStates, proposedStates, receivers)
+		result.Rules = append(result.Rules, diff)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// dryRunInstanceKey returns the key that matches the states of an alert instance of both versions of a rule. It is
+// the labels of the state without the labels that the rule adds, because the labels of the rule can be changed.
+func dryRunInstanceKey(s *state.State, rule *ngmodels.AlertRule, extraLabels data.Labels) string {
+	lbls := make(data.Labels, len(s.Labels))
+	for k, v := range s.Labels {
+		if _, ok := rule.Labels[k]; ok {
+			continue
+		}
+		if _, ok := extraLabels[k]; ok {
+			continue
+		}
+		lbls[k] = v
+	}
+	return lbls.String()
+}
+
+// diffDryRunStates compares the states of the alert instances of the stored and the submitted version of a rule.
+// It returns the instances that are different and the number of instances that are the same.
+func diffDryRunStates(current, proposed map[string]*state.State, receivers func(data.Labels) []string) ([]apimodels.DryRunInstanceDiff, int) {
+	keys := make([]string, 0, len(current)+len(proposed))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	for key := range proposed {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	diffs := make([]apimodels.DryRunInstanceDiff, 0, len(keys))
+	unchanged := 0
+	for _, key := range keys {
+		cur, prop := current[key], proposed[key]
+		diff := apimodels.DryRunInstanceDiff{
+			Current:  toDryRunInstance(cur, receivers),
+			Proposed: toDryRunInstance(prop, receivers),
+		}
+		switch {
+		case isFiring(prop) && !isFiring(cur):
+			diff.Change = apimodels.DryRunInstanceFiring
+		case isFiring(cur) && !isFiring(prop):
+			diff.Change = apimodels.DryRunInstanceResolved
+		case cur == nil:
+			diff.Change = apimodels.DryRunInstanceAdded
+		case prop == nil:
+			diff.Change = apimodels.DryRunInstanceRemoved
+		case !reflect.DeepEqual(diff.Current, diff.Proposed):
+			diff.Change = apimodels.DryRunInstanceChanged
+		default:
+			unchanged++
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, unchanged
+}
+
+// isFiring returns true if the state produces an alert that is sent to the Alertmanager.
+func isFiring(s *state.State) bool {
+	if s == nil {
+		return false
+	}
+	return s.State == eval.Alerting || s.State == eval.NoData || s.State == eval.Error
+}
+
+func toDryRunInstance(s *state.State, receivers func(data.Labels) []string) *apimodels.DryRunInstance {
+	if s == nil {
+		return nil
+	}
+	instance := &apimodels.DryRunInstance{
+		State:       s.State.String(),
+		StateReason: s.StateReason,
+		Labels:      s.Labels,
+		Annotations: s.Annotations,
+	}
+	if receivers != nil && isFiring(s) {
+		instance.Receivers = receivers(s.Labels)
+	}
+	return instance
+}
+
+// getReceiversFunc returns a function that returns the names of the receivers that an alert with the given labels is
+// routed to by the current notification policies of the organization, or by the notification settings of the rule.
+// It returns nil if the policies cannot be loaded.
+func (srv TestingApiSrv) getReceiversFunc(ctx context.Context, orgID int64) func(data.Labels) []string {
+	if srv.amConfigStore == nil {
+		return nil
+	}
+	cfg, err := srv.amConfigStore.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			srv.log.Warn("Failed to get the Alertmanager configuration, receivers of alerts are omitted", "error", err)
+		}
+		return nil
+	}
+	amConfig, err := notifier.Load([]byte(cfg.AlertmanagerConfiguration))
+	if err != nil || amConfig.AlertmanagerConfig.Route == nil {
+		srv.log.Warn("Failed to load the Alertmanager configuration, receivers of alerts are omitted", "error", err)
+		return nil
+	}
+	root := dispatch.NewRoute(amConfig.AlertmanagerConfig.Route.AsAMRoute(), nil)
+	return func(lbls data.Labels) []string {
+		// alerts of rules with notification settings are routed by the auto-generated routes, which always come first
+		// in the routing tree and do not continue.
+		if receiver, ok := lbls[ngmodels.AutogeneratedRouteReceiverNameLabel]; ok && lbls[ngmodels.AutogeneratedRouteLabel] == "true" {
+			return []string{receiver}
+		}
+		ls := make(model.LabelSet, len(lbls))
+		for k, v := range lbls {
+			ls[model.LabelName(k)] = model.LabelValue(v)
+		}
+		routes := root.Match(ls)
+		receivers := make([]string, 0, len(routes))
+		for _, r := range routes {
+			receivers = append(receivers, r.RouteOpts.Receiver)
+		}
+		return receivers
+	}
+}
@@ -1,12 +1,16 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"math/rand"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -21,6 +25,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	ngfakes "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -268,6 +274,202 @@ func TestRouteEvalQueries(t *testing.T) {
 	})
 }
 
+func TestRouteDryRunGrafanaRuleGroup(t *testing.T) {
+	orgID := rand.Int63()
+	f := randFolder()
+	ruleStore := ngfakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], f)
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = f.UID
+
+	gen := models.AlertRuleGen(withGroupKey(groupKey), models.WithFor(0), models.WithLabels(map[string]string{"team": "a"}))
+	updated, deleted := gen(), gen()
+	updated.IsPaused, deleted.IsPaused = false, false
+	updated.UID, updated.Condition, updated.Annotations = "updated", "current", nil
+	deleted.UID, deleted.Condition, deleted.Annotations = "deleted", "deleted", nil
+	ruleStore.PutRule(context.Background(), updated, deleted)
+
+	now := time.Now()
+	result := func(s eval.State, instance string) eval.Result {
+		return eval.Result{State: s, Instance: data.Labels{"instance": instance}, EvaluatedAt: now}
+	}
+	evaluator := conditionEvaluatorFactory{
+		"current":  {result(eval.Alerting, "1"), result(eval.Alerting, "2"), result(eval.Normal, "3"), result(eval.Normal, "5")},
+		"proposed": {result(eval.Normal, "1"), result(eval.Alerting, "2"), result(eval.Alerting, "3"), result(eval.Normal, "4"), result(eval.Normal, "5")},
+		"created":  {result(eval.Alerting, "x")},
+		"deleted":  {result(eval.Normal, "y")},
+	}
+
+	at := func(results eval.Results, evaluatedAt time.Time) eval.Results {
+		shifted := make(eval.Results, 0, len(results))
+		for _, r := range results {
+			r.EvaluatedAt = evaluatedAt
+			shifted = append(shifted, r)
+		}
+		return shifted
+	}
+
+	srv := createTestingApiSrv(t, nil, nil, evaluator)
+	srv.store = ruleStore
+	// the live states are the ones of the last evaluation of the stored rules by the scheduler.
+	live := srv.newStateManager()
+	for _, rule := range []*models.AlertRule{updated, deleted} {
+		live.ProcessEvalResults(context.Background(), now.Add(-time.Second), rule, at(evaluator[rule.Condition], now.Add(-time.Second)), state.GetRuleExtraLabels(rule, f.Title, true))
+	}
+	srv.stateManager = live
+
+	forDuration := model.Duration(0)
+	postable := func(uid, condition string) definitions.PostableExtendedRuleNode {
+		return definitions.PostableExtendedRuleNode{
+			ApiRuleNode: &definitions.ApiRuleNode{
+				For:    &forDuration,
+				Labels: map[string]string{"team": "b"},
+			},
+			GrafanaManagedAlert: &definitions.PostableGrafanaRule{
+				Title:        condition,
+				Condition:    condition,
+				Data:         []definitions.AlertQuery{{RefID: condition, DatasourceUID: "test", RelativeTimeRange: definitions.RelativeTimeRange{From: 10}}},
+				UID:          uid,
+				NoDataState:  definitions.NoData,
+				ExecErrState: definitions.AlertingErrState,
+			},
+		}
+	}
+	group := definitions.PostableRuleGroupConfig{
+		Name:     groupKey.RuleGroup,
+		Interval: model.Duration(srv.cfg.BaseInterval),
+		Rules:    []definitions.PostableExtendedRuleNode{postable("updated", "proposed"), postable("", "created")},
+	}
+
+	response := srv.RouteDryRunGrafanaRuleGroup(createRequestContext(orgID, nil), group, f.Title)
+	require.Equal(t, http.StatusOK, response.Status())
+
+	var actual definitions.DryRunRuleGroupResult
+	require.NoError(t, json.Unmarshal(response.Body(), &actual))
+	require.Len(t, actual.Rules, 3)
+
+	instanceChanges := func(diff definitions.DryRunRuleDiff) map[string]definitions.DryRunInstanceChange {
+		changes := make(map[string]definitions.DryRunInstanceChange, len(diff.Instances))
+		for _, instance := range diff.Instances {
+			if instance.Proposed != nil {
+				changes[instance.Proposed.Labels["instance"]] = instance.Change
+			} else {
+				changes[instance.Current.Labels["instance"]] = instance.Change
+			}
+		}
+		return changes
+	}
+
+	created := actual.Rules[0]
+	require.Equal(t, definitions.DryRunRuleCreated, created.Change)
+	require.Equal(t, "created", created.Title)
+	require.Equal(t, map[string]definitions.DryRunInstanceChange{"x": definitions.DryRunInstanceFiring}, instanceChanges(created))
+
+	update := actual.Rules[1]
+	require.Equal(t, definitions.DryRunRuleUpdated, update.Change)
+	require.Equal(t, "updated", update.UID)
+	require.Equal(t, map[string]definitions.DryRunInstanceChange{
+		"1": definitions.DryRunInstanceResolved,
+		"2": definitions.DryRunInstanceChanged,
+		"3": definitions.DryRunInstanceFiring,
+		"4": definitions.DryRunInstanceAdded,
+		"5": definitions.DryRunInstanceChanged,
+	}, instanceChanges(update))
+	require.Equal(t, 0, update.Unchanged)
+	for _, instance := range update.Instances {
+		if instance.Change == definitions.DryRunInstanceChanged {
+			require.Equal(t, "a", instance.Current.Labels["team"])
+			require.Equal(t, "b", instance.Proposed.Labels["team"])
+		}
+	}
+
+	deletedDiff := actual.Rules[2]
+	require.Equal(t, definitions.DryRunRuleDeleted, deletedDiff.Change)
+	require.Equal(t, "deleted", deletedDiff.UID)
+	require.Equal(t, map[string]definitions.DryRunInstanceChange{"y": definitions.DryRunInstanceRemoved}, instanceChanges(deletedDiff))
+
+	t.Run("should continue the evaluation from the live states", func(t *testing.T) {
+		pendingKey := groupKey
+		pendingKey.RuleGroup = "pending-" + groupKey.RuleGroup
+		pending := models.AlertRuleGen(withGroupKey(pendingKey), models.WithFor(time.Minute), models.WithLabels(map[string]string{"team": "b"}))()
+		pending.IsPaused, pending.UID, pending.Title, pending.Condition, pending.Annotations = false, "pending", "pending", "proposed", nil
+		ruleStore.PutRule(context.Background(), pending)
+		// the alerting instances are pending for 90 seconds.
+		live.ProcessEvalResults(context.Background(), now.Add(-90*time.Second), pending, at(evaluator["proposed"], now.Add(-90*time.Second)), state.GetRuleExtraLabels(pending, f.Title, true))
+
+		node := postable("pending", "proposed")
+		pendingFor := model.Duration(time.Minute)
+		node.For = &pendingFor
+		node.GrafanaManagedAlert.Title = "pending"
+		response := srv.RouteDryRunGrafanaRuleGroup(createRequestContext(orgID, nil), definitions.PostableRuleGroupConfig{
+			Name:     pendingKey.RuleGroup,
+			Interval: model.Duration(srv.cfg.BaseInterval),
+			Rules:    []definitions.PostableExtendedRuleNode{node},
+		}, f.Title)
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var actual definitions.DryRunRuleGroupResult
+		require.NoError(t, json.Unmarshal(response.Body(), &actual))
+		require.Len(t, actual.Rules, 1)
+		require.Equal(t, map[string]definitions.DryRunInstanceChange{
+			"2": definitions.DryRunInstanceFiring,
+			"3": definitions.DryRunInstanceFiring,
+		}, instanceChanges(actual.Rules[0]))
+		require.Equal(t, 3, actual.Rules[0].Unchanged)
+	})
+
+	t.Run("should return Forbidden if user cannot query data sources of the stored rules", func(t *testing.T) {
+		permissions := map[int64]map[string][]string{orgID: {
+			datasources.ActionQuery: []string{datasources.ScopeProvider.GetResourceScopeUID("test")},
+		}}
+		response := srv.RouteDryRunGrafanaRuleGroup(createRequestContextWithPerms(orgID, permissions, nil), group, f.Title)
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+}
+
+func TestDiffDryRunStates(t *testing.T) {
+	newState := func(s eval.State, labels data.Labels) *state.State {
+		return &state.State{State: s, Labels: labels}
+	}
+	receivers := func(lbls data.Labels) []string {
+		return []string{lbls["team"]}
+	}
+
+	current := map[string]*state.State{
+		"same":     newState(eval.Alerting, data.Labels{"team": "a"}),
+		"rerouted": newState(eval.Alerting, data.Labels{"team": "a"}),
+		"pending":  newState(eval.Pending, data.Labels{"team": "a"}),
+	}
+	proposed := map[string]*state.State{
+		"same":     newState(eval.Alerting, data.Labels{"team": "a"}),
+		"rerouted": newState(eval.Alerting, data.Labels{"team": "b"}),
+		"pending":  newState(eval.NoData, data.Labels{"team": "a"}),
+	}
+
+	diffs, unchanged := diffDryRunStates(current, proposed, receivers)
+	require.Equal(t, 1, unchanged)
+	require.Len(t, diffs, 2)
+	require.Equal(t, definitions.DryRunInstanceFiring, diffs[0].Change)
+	require.Equal(t, []string{"a"}, diffs[0].Proposed.Receivers)
+	require.Nil(t, diffs[0].Current.Receivers)
+	require.Equal(t, definitions.DryRunInstanceChanged, diffs[1].Change)
+	require.Equal(t, []string{"a"}, diffs[1].Current.Receivers)
+	require.Equal(t, []string{"b"}, diffs[1].Proposed.Receivers)
+}
+
+// conditionEvaluatorFactory creates evaluators that return the results by the condition of the rule.
+type conditionEvaluatorFactory map[string]eval.Results
+
+func (f conditionEvaluatorFactory) Validate(_ eval.EvaluationContext, _ models.Condition) error {
+	return nil
+}
+
+func (f conditionEvaluatorFactory) Create(_ eval.EvaluationContext, condition models.Condition) (eval.ConditionEvaluator, error) {
+	evaluator := &eval_mocks.ConditionEvaluatorMock{}
+	evaluator.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(f[condition.Condition], nil)
+	return evaluator, nil
+}
+
 func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator eval.EvaluatorFactory) *TestingApiSrv {
 	if ac == nil {
 		ac = acMock.New()
@@ -44,7 +44,9 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingRuleDelete, scope),
 		)
 	// Grafana rule state history paths
-	case http.MethodGet + "/api/v1/rules/history":
+	case http.MethodGet + "/api/v1/rules/history",
+		http.MethodGet + "/api/v1/rules/history/summary",
+		http.MethodGet + "/api/v1/rules/history/transitions":
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 
 	// Grafana, Prometheus-compatible Paths
@@ -56,6 +58,9 @@ func (api *API) authorize(method, path string) web.Handler {
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 	// Grafana Rules Testing Paths
+	case http.MethodPost + "/api/v1/rule/dryrun/grafana/{Namespace}":
+		// additional authorization is done in the request handler
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 	case http.MethodPost + "/api/v1/rule/backtest":
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
@@ -107,6 +112,8 @@ func (api *API) authorize(method, path string) web.Handler {
 	// Grafana Prometheus-compatible Paths
 	case http.MethodGet + "/api/prometheus/grafana/api/v1/alerts":
 		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
+	case http.MethodPost + "/api/prometheus/grafana/api/v1/rules/{RuleUID}/acknowledgement":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceUpdate)
 
 	// Silences. External AM.
 	case http.MethodDelete + "/api/alertmanager/{DatasourceUID}/api/v2/silence/{SilenceId}":
@@ -202,7 +209,11 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodGet + "/api/v1/provisioning/alert-rules/export",
 		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}/export",
 		http.MethodGet + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
-		http.MethodGet + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/export":
+		http.MethodGet + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/export",
+		http.MethodGet + "/api/v1/provisioning/silence-templates",
+		http.MethodGet + "/api/v1/provisioning/silence-templates/{UID}",
+		http.MethodGet + "/api/v1/provisioning/recurring-silences",
+		http.MethodGet + "/api/v1/provisioning/recurring-silences/{UID}":
 		eval = ac.EvalAny(ac.EvalPermission(ac.ActionAlertingProvisioningRead), ac.EvalPermission(ac.ActionAlertingProvisioningReadSecrets)) // organization scope
 
 	case http.MethodPut + "/api/v1/provisioning/policies",
@@ -218,7 +229,13 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodPost + "/api/v1/provisioning/alert-rules",
 		http.MethodPut + "/api/v1/provisioning/alert-rules/{UID}",
 		http.MethodDelete + "/api/v1/provisioning/alert-rules/{UID}",
-		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}":
+		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+		http.MethodPost + "/api/v1/provisioning/silence-templates",
+		http.MethodPut + "/api/v1/provisioning/silence-templates/{UID}",
+		http.MethodDelete + "/api/v1/provisioning/silence-templates/{UID}",
+		http.MethodPost + "/api/v1/provisioning/recurring-silences",
+		http.MethodPut + "/api/v1/provisioning/recurring-silences/{UID}",
+		http.MethodDelete + "/api/v1/provisioning/recurring-silences/{UID}":
 		eval = ac.EvalPermission(ac.ActionAlertingProvisioningWrite) // organization scope
 	}
 
@@ -15,43 +15,51 @@ import (
 // AlertRuleFromProvisionedAlertRule converts definitions.ProvisionedAlertRule to models.AlertRule
 func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (models.AlertRule, error) {
 	return models.AlertRule{
-		ID:           a.ID,
-		UID:          a.UID,
-		OrgID:        a.OrgID,
-		NamespaceUID: a.FolderUID,
-		RuleGroup:    a.RuleGroup,
-		Title:        a.Title,
-		Condition:    a.Condition,
-		Data:         AlertQueriesFromApiAlertQueries(a.Data),
-		Updated:      a.Updated,
-		NoDataState:  models.NoDataState(a.NoDataState),          // TODO there must be a validation
-		ExecErrState: models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
-		For:          time.Duration(a.For),
-		Annotations:  a.Annotations,
-		Labels:       a.Labels,
-		IsPaused:     a.IsPaused,
+		ID:                   a.ID,
+		UID:                  a.UID,
+		OrgID:                a.OrgID,
+		NamespaceUID:         a.FolderUID,
+		RuleGroup:            a.RuleGroup,
+		Title:                a.Title,
+		Condition:            a.Condition,
+		Data:                 AlertQueriesFromApiAlertQueries(a.Data),
+		Updated:              a.Updated,
+		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
+		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
+		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
+		Annotations:          a.Annotations,
+		Labels:               a.Labels,
+		IsPaused:             a.IsPaused,
+		Record:               ModelRecordFromApiRecord(a.Record),
+		Dependencies:         ModelDependenciesFromApiDependencies(a.Dependencies),
+		NotificationSettings: ModelNotificationSettingsFromApiNotificationSettings(a.NotificationSettings),
 	}, nil
 }
 
 // ProvisionedAlertRuleFromAlertRule converts models.AlertRule to definitions.ProvisionedAlertRule and sets provided provenance status
 func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.Provenance) definitions.ProvisionedAlertRule {
 	return definitions.ProvisionedAlertRule{
-		ID:           rule.ID,
-		UID:          rule.UID,
-		OrgID:        rule.OrgID,
-		FolderUID:    rule.NamespaceUID,
-		RuleGroup:    rule.RuleGroup,
-		Title:        rule.Title,
-		For:          model.Duration(rule.For),
-		Condition:    rule.Condition,
-		Data:         ApiAlertQueriesFromAlertQueries(rule.Data),
-		Updated:      rule.Updated,
-		NoDataState:  definitions.NoDataState(rule.NoDataState),          // TODO there may be a validation
-		ExecErrState: definitions.ExecutionErrorState(rule.ExecErrState), // TODO there may be a validation
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		Provenance:   definitions.Provenance(provenance), // TODO validate enum conversion?
-		IsPaused:     rule.IsPaused,
+		ID:                   rule.ID,
+		UID:                  rule.UID,
+		OrgID:                rule.OrgID,
+		FolderUID:            rule.NamespaceUID,
+		RuleGroup:            rule.RuleGroup,
+		Title:                rule.Title,
+		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
+		Condition:            rule.Condition,
+		Data:                 ApiAlertQueriesFromAlertQueries(rule.Data),
+		Updated:              rule.Updated,
+		NoDataState:          definitions.NoDataState(rule.NoDataState),          // TODO there may be a validation
+		ExecErrState:         definitions.ExecutionErrorState(rule.ExecErrState), // TODO there may be a validation
+		Annotations:          rule.Annotations,
+		Labels:               rule.Labels,
+		Provenance:           definitions.Provenance(provenance), // TODO validate enum conversion?
+		IsPaused:             rule.IsPaused,
+		Record:               ApiRecordFromModelRecord(rule.Record),
+		Dependencies:         ApiDependenciesFromModelDependencies(rule.Dependencies),
+		NotificationSettings: ApiNotificationSettingsFromModelNotificationSettings(rule.NotificationSettings),
 	}
 }
 
@@ -64,6 +72,88 @@ func ProvisionedAlertRuleFromAlertRules(rules []*models.AlertRule, provenances m
 	return result
 }
 
+// ModelRecordFromApiRecord converts definitions.Record to models.Record
+func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
+	if r == nil {
+		return nil
+	}
+	return &models.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ApiRecordFromModelRecord converts models.Record to definitions.Record
+func ApiRecordFromModelRecord(r *models.Record) *definitions.Record {
+	if r == nil {
+		return nil
+	}
+	return &definitions.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ModelNotificationSettingsFromApiNotificationSettings converts definitions.AlertRuleNotificationSettings to models.NotificationSettings
+func ModelNotificationSettingsFromApiNotificationSettings(s *definitions.AlertRuleNotificationSettings) *models.NotificationSettings {
+	if s == nil {
+		return nil
+	}
+	return &models.NotificationSettings{
+		Receiver:          s.Receiver,
+		GroupBy:           s.GroupBy,
+		GroupWait:         s.GroupWait,
+		GroupInterval:     s.GroupInterval,
+		RepeatInterval:    s.RepeatInterval,
+		MuteTimeIntervals: s.MuteTimeIntervals,
+	}
+}
+
+// ApiNotificationSettingsFromModelNotificationSettings converts models.NotificationSettings to definitions.AlertRuleNotificationSettings
+func ApiNotificationSettingsFromModelNotificationSettings(s *models.NotificationSettings) *definitions.AlertRuleNotificationSettings {
+	if s == nil {
+		return nil
+	}
+	return &definitions.AlertRuleNotificationSettings{
+		Receiver:          s.Receiver,
+		GroupBy:           s.GroupBy,
+		GroupWait:         s.GroupWait,
+		GroupInterval:     s.GroupInterval,
+		RepeatInterval:    s.RepeatInterval,
+		MuteTimeIntervals: s.MuteTimeIntervals,
+	}
+}
+
+// ModelDependenciesFromApiDependencies converts []definitions.RuleDependency to []models.RuleDependency
+func ModelDependenciesFromApiDependencies(deps []definitions.RuleDependency) []models.RuleDependency {
+	if len(deps) == 0 {
+		return nil
+	}
+	result := make([]models.RuleDependency, 0, len(deps))
+	for _, dep := range deps {
+		result = append(result, models.RuleDependency{
+			RuleUID: dep.RuleUID,
+			Equal:   dep.Equal,
+		})
+	}
+	return result
+}
+
+// ApiDependenciesFromModelDependencies converts []models.RuleDependency to []definitions.RuleDependency
+func ApiDependenciesFromModelDependencies(deps []models.RuleDependency) []definitions.RuleDependency {
+	if len(deps) == 0 {
+		return nil
+	}
+	result := make([]definitions.RuleDependency, 0, len(deps))
+	for _, dep := range deps {
+		result = append(result, definitions.RuleDependency{
+			RuleUID: dep.RuleUID,
+			Equal:   dep.Equal,
+		})
+	}
+	return result
+}
+
 // AlertQueriesFromApiAlertQueries converts a collection of definitions.AlertQuery to collection of models.AlertQuery
 func AlertQueriesFromApiAlertQueries(queries []definitions.AlertQuery) []models.AlertQuery {
 	result := make([]models.AlertQuery, 0, len(queries))
@@ -189,15 +279,53 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor > 0 {
+		keepFiringFor := model.Duration(rule.KeepFiringFor)
+		result.KeepFiringFor = &keepFiringFor
+		result.KeepFiringForString = util.Pointer(keepFiringFor.String())
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
 	if rule.Labels != nil {
 		result.Labels = &rule.Labels
 	}
+	if rule.Record != nil {
+		result.Record = &definitions.AlertRuleRecordExport{
+			Metric: rule.Record.Metric,
+			From:   rule.Record.From,
+		}
+	}
+	for _, dep := range rule.Dependencies {
+		result.Dependencies = append(result.Dependencies, definitions.AlertRuleDependencyExport{
+			RuleUID: dep.RuleUID,
+			Equal:   dep.Equal,
+		})
+	}
+	if rule.NotificationSettings != nil {
+		result.NotificationSettings = AlertRuleNotificationSettingsExportFromNotificationSettings(*rule.NotificationSettings)
+	}
 	return result, nil
 }
 
+// AlertRuleNotificationSettingsExportFromNotificationSettings creates a definitions.AlertRuleNotificationSettingsExport DTO from models.NotificationSettings.
+func AlertRuleNotificationSettingsExportFromNotificationSettings(s models.NotificationSettings) *definitions.AlertRuleNotificationSettingsExport {
+	toString := func(d *model.Duration) *string {
+		if d == nil {
+			return nil
+		}
+		return util.Pointer(d.String())
+	}
+	return &definitions.AlertRuleNotificationSettingsExport{
+		Receiver:          s.Receiver,
+		GroupBy:           s.GroupBy,
+		GroupWait:         toString(s.GroupWait),
+		GroupInterval:     toString(s.GroupInterval),
+		RepeatInterval:    toString(s.RepeatInterval),
+		MuteTimeIntervals: s.MuteTimeIntervals,
+	}
+}
+
 // AlertQueryExportFromAlertQuery creates a definitions.AlertQueryExport DTO from models.AlertQuery.
 func AlertQueryExportFromAlertQuery(query models.AlertQuery) (definitions.AlertQueryExport, error) {
 	// We unmarshal the json.RawMessage model into a map in order to facilitate yaml marshalling.
@@ -373,3 +501,103 @@ func MuteTimingIntervalToMuteTimeIntervalHclExport(m definitions.MuteTimeInterva
 	err = j.Unmarshal(mdata, &result)
 	return result, err
 }
+
+// SilenceMatchersFromObjectMatchers converts definitions.ObjectMatchers to models.SilenceMatchers
+func SilenceMatchersFromObjectMatchers(m definitions.ObjectMatchers) models.SilenceMatchers {
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(models.SilenceMatchers, 0, len(m))
+	for _, matcher := range m {
+		result = append(result, models.SilenceMatcher{Name: matcher.Name, Type: matcher.Type.String(), Value: matcher.Value})
+	}
+	return result
+}
+
+// ObjectMatchersFromSilenceMatchers converts models.SilenceMatchers to definitions.ObjectMatchers.
+// The matchers are validated when they are saved, so invalid matchers are omitted.
+func ObjectMatchersFromSilenceMatchers(m models.SilenceMatchers) definitions.ObjectMatchers {
+	result := make(definitions.ObjectMatchers, 0, len(m))
+	for _, matcher := range m {
+		if lm, err := matcher.Matcher(); err == nil {
+			result = append(result, lm)
+		}
+	}
+	return result
+}
+
+// SilenceTemplateFromApiSilenceTemplate converts definitions.SilenceTemplate to models.SilenceTemplate
+func SilenceTemplateFromApiSilenceTemplate(orgID int64, t definitions.SilenceTemplate) models.SilenceTemplate {
+	return models.SilenceTemplate{
+		OrgID:    orgID,
+		UID:      t.UID,
+		Name:     t.Name,
+		Matchers: SilenceMatchersFromObjectMatchers(t.Matchers),
+		Duration: time.Duration(t.Duration),
+		Comment:  t.Comment,
+	}
+}
+
+// ApiSilenceTemplateFromSilenceTemplate converts models.SilenceTemplate to definitions.SilenceTemplate
+func ApiSilenceTemplateFromSilenceTemplate(t models.SilenceTemplate, provenance models.Provenance) definitions.SilenceTemplate {
+	return definitions.SilenceTemplate{
+		UID:        t.UID,
+		Name:       t.Name,
+		Matchers:   ObjectMatchersFromSilenceMatchers(t.Matchers),
+		Duration:   model.Duration(t.Duration),
+		Comment:    t.Comment,
+		Updated:    t.Updated,
+		Provenance: definitions.Provenance(provenance),
+	}
+}
+
+// ApiSilenceTemplatesFromSilenceTemplates converts []*models.SilenceTemplate to definitions.SilenceTemplates
+func ApiSilenceTemplatesFromSilenceTemplates(templates []*models.SilenceTemplate, provenances map[string]models.Provenance) definitions.SilenceTemplates {
+	result := make(definitions.SilenceTemplates, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, ApiSilenceTemplateFromSilenceTemplate(*t, provenances[t.UID]))
+	}
+	return result
+}
+
+// RecurringSilenceFromApiRecurringSilence converts definitions.RecurringSilence to models.RecurringSilence
+func RecurringSilenceFromApiRecurringSilence(orgID int64, s definitions.RecurringSilence) models.RecurringSilence {
+	return models.RecurringSilence{
+		OrgID:            orgID,
+		UID:              s.UID,
+		Name:             s.Name,
+		TemplateUID:      s.TemplateUID,
+		Matchers:         SilenceMatchersFromObjectMatchers(s.Matchers),
+		Comment:          s.Comment,
+		Schedule:         s.Schedule,
+		Location:         s.Location,
+		Duration:         time.Duration(s.Duration),
+		MuteTimeInterval: s.MuteTimeInterval,
+	}
+}
+
+// ApiRecurringSilenceFromRecurringSilence converts models.RecurringSilence to definitions.RecurringSilence
+func ApiRecurringSilenceFromRecurringSilence(s models.RecurringSilence, provenance models.Provenance) definitions.RecurringSilence {
+	return definitions.RecurringSilence{
+		UID:              s.UID,
+		Name:             s.Name,
+		TemplateUID:      s.TemplateUID,
+		Matchers:         ObjectMatchersFromSilenceMatchers(s.Matchers),
+		Comment:          s.Comment,
+		Schedule:         s.Schedule,
+		Location:         s.Location,
+		Duration:         model.Duration(s.Duration),
+		MuteTimeInterval: s.MuteTimeInterval,
+		Updated:          s.Updated,
+		Provenance:       definitions.Provenance(provenance),
+	}
+}
+
+// ApiRecurringSilencesFromRecurringSilences converts []*models.RecurringSilence to definitions.RecurringSilences
+func ApiRecurringSilencesFromRecurringSilences(silences []*models.RecurringSilence, provenances map[string]models.Provenance) definitions.RecurringSilences {
+	result := make(definitions.RecurringSilences, 0, len(silences))
+	for _, s := range silences {
+		result = append(result, ApiRecurringSilenceFromRecurringSilence(*s, provenances[s.UID]))
+	}
+	return result
+}
@@ -2,10 +2,14 @@ package api
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestToModel(t *testing.T) {
@@ -35,3 +39,42 @@ func TestToModel(t *testing.T) {
 		require.Len(t, tm.Rules, 1)
 	})
 }
+
+func TestNotificationSettingsConversion(t *testing.T) {
+	groupWait := model.Duration(30 * time.Second)
+	settings := models.NotificationSettings{
+		Receiver:          "team-a",
+		GroupBy:           []string{models.FolderTitleLabel, model.AlertNameLabel, "cluster"},
+		GroupWait:         &groupWait,
+		MuteTimeIntervals: []string{"weekends"},
+	}
+
+	t.Run("should round-trip through the provisioning API model", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithNotificationSettings(settings))()
+		provisioned := ProvisionedAlertRuleFromAlertRule(*rule, models.ProvenanceNone)
+		require.NotNil(t, provisioned.NotificationSettings)
+		converted, err := AlertRuleFromProvisionedAlertRule(provisioned)
+		require.NoError(t, err)
+		require.Equal(t, &settings, converted.NotificationSettings)
+	})
+
+	t.Run("should export settings with formatted durations", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithNotificationSettings(settings))()
+		export, err := AlertRuleExportFromAlertRule(*rule)
+		require.NoError(t, err)
+		require.Equal(t, &definitions.AlertRuleNotificationSettingsExport{
+			Receiver:          "team-a",
+			GroupBy:           settings.GroupBy,
+			GroupWait:         util.Pointer("30s"),
+			MuteTimeIntervals: settings.MuteTimeIntervals,
+		}, export.NotificationSettings)
+	})
+
+	t.Run("should not export settings of rules without them", func(t *testing.T) {
+		rule := models.AlertRuleGen()()
+		rule.NotificationSettings = nil
+		export, err := AlertRuleExportFromAlertRule(*rule)
+		require.NoError(t, err)
+		require.Nil(t, export.NotificationSettings)
+	})
+}
@@ -46,6 +46,10 @@ func (f *PrometheusApiHandler) handleRouteGetGrafanaRuleStatuses(ctx *contextmod
 	return f.GrafanaSvc.RouteGetRuleStatuses(ctx)
 }
 
+func (f *PrometheusApiHandler) handleRoutePostGrafanaAlertAcknowledgement(ctx *contextmodel.ReqContext, body apimodels.PostableAlertAcknowledgement, ruleUID string) response.Response {
+	return f.GrafanaSvc.RoutePostGrafanaAlertAcknowledgement(ctx, body, ruleUID)
+}
+
 func (f *PrometheusApiHandler) getService(ctx *contextmodel.ReqContext) (*LotexProm, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
@@ -19,11 +19,19 @@ import (
 
 type HistoryApi interface {
 	RouteGetStateHistory(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistorySummary(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistoryTransitions(*contextmodel.ReqContext) response.Response
 }
 
 func (f *HistoryApiHandler) RouteGetStateHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetStateHistory(ctx)
 }
+func (f *HistoryApiHandler) RouteGetStateHistorySummary(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistorySummary(ctx)
+}
+func (f *HistoryApiHandler) RouteGetStateHistoryTransitions(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistoryTransitions(ctx)
+}
 
 func (api *API) RegisterHistoryApiEndpoints(srv HistoryApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -39,5 +47,29 @@ func (api *API) RegisterHistoryApiEndpoints(srv HistoryApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/summary"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/summary"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/summary",
+				api.Hooks.Wrap(srv.RouteGetStateHistorySummary),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/transitions"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/transitions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/transitions",
+				api.Hooks.Wrap(srv.RouteGetStateHistoryTransitions),
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
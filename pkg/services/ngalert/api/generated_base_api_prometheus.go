@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -23,6 +24,7 @@ type PrometheusApi interface {
 	RouteGetGrafanaAlertStatuses(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaRuleStatuses(*contextmodel.ReqContext) response.Response
 	RouteGetRuleStatuses(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaAlertAcknowledgement(*contextmodel.ReqContext) response.Response
 }
 
 func (f *PrometheusApiHandler) RouteGetAlertStatuses(ctx *contextmodel.ReqContext) response.Response {
@@ -41,6 +43,16 @@ func (f *PrometheusApiHandler) RouteGetRuleStatuses(ctx *contextmodel.ReqContext
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
 	return f.handleRouteGetRuleStatuses(ctx, datasourceUIDParam)
 }
+func (f *PrometheusApiHandler) RoutePostGrafanaAlertAcknowledgement(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	ruleUIDParam := web.Params(ctx.Req)[":RuleUID"]
+	// Parse Request Body
+	conf := apimodels.PostableAlertAcknowledgement{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaAlertAcknowledgement(ctx, conf, ruleUIDParam)
+}
 
 func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -92,5 +104,17 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/rules/{RuleUID}/acknowledgement"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/rules/{RuleUID}/acknowledgement"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/rules/{RuleUID}/acknowledgement",
+				api.Hooks.Wrap(srv.RoutePostGrafanaAlertAcknowledgement),
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -21,6 +21,7 @@ import (
 
 type TestingApi interface {
 	BacktestConfig(*contextmodel.ReqContext) response.Response
+	RouteDryRunGrafanaRuleGroup(*contextmodel.ReqContext) response.Response
 	RouteEvalQueries(*contextmodel.ReqContext) response.Response
 	RouteTestRuleConfig(*contextmodel.ReqContext) response.Response
 	RouteTestRuleGrafanaConfig(*contextmodel.ReqContext) response.Response
@@ -34,6 +35,16 @@ func (f *TestingApiHandler) BacktestConfig(ctx *contextmodel.ReqContext) respons
 	}
 	return f.handleBacktestConfig(ctx, conf)
 }
+func (f *TestingApiHandler) RouteDryRunGrafanaRuleGroup(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	namespaceParam := web.Params(ctx.Req)[":Namespace"]
+	// Parse Request Body
+	conf := apimodels.PostableRuleGroupConfig{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteDryRunGrafanaRuleGroup(ctx, conf, namespaceParam)
+}
 func (f *TestingApiHandler) RouteEvalQueries(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.EvalQueriesPayload{}
@@ -75,6 +86,18 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/dryrun/grafana/{Namespace}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/rule/dryrun/grafana/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/dryrun/grafana/{Namespace}",
+				api.Hooks.Wrap(srv.RouteDryRunGrafanaRuleGroup),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
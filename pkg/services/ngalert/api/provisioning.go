@@ -135,3 +135,43 @@ func (f *ProvisioningApiHandler) handleRouteExportMuteTiming(ctx *contextmodel.R
 func (f *ProvisioningApiHandler) handleRouteExportMuteTimings(ctx *contextmodel.ReqContext) response.Response {
 	return f.svc.RouteGetMuteTimingsExport(ctx)
 }
+
+func (f *ProvisioningApiHandler) handleRouteGetSilenceTemplates(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetSilenceTemplates(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetSilenceTemplate(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteGetSilenceTemplate(ctx, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostSilenceTemplate(ctx *contextmodel.ReqContext, tmpl apimodels.SilenceTemplate) response.Response {
+	return f.svc.RoutePostSilenceTemplate(ctx, tmpl)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutSilenceTemplate(ctx *contextmodel.ReqContext, tmpl apimodels.SilenceTemplate, UID string) response.Response {
+	return f.svc.RoutePutSilenceTemplate(ctx, tmpl, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteSilenceTemplate(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteDeleteSilenceTemplate(ctx, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetRecurringSilences(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetRecurringSilences(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetRecurringSilence(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteGetRecurringSilence(ctx, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostRecurringSilence(ctx *contextmodel.ReqContext, s apimodels.RecurringSilence) response.Response {
+	return f.svc.RoutePostRecurringSilence(ctx, s)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutRecurringSilence(ctx *contextmodel.ReqContext, s apimodels.RecurringSilence, UID string) response.Response {
+	return f.svc.RoutePutRecurringSilence(ctx, s, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteRecurringSilence(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteDeleteRecurringSilence(ctx, UID)
+}
@@ -18,3 +18,11 @@ func NewStateHistoryApi(svc *HistorySrv) *HistoryApiHandler {
 func (f *HistoryApiHandler) handleRouteGetStateHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.svc.RouteQueryStateHistory(ctx)
 }
+
+func (f *HistoryApiHandler) handleRouteGetStateHistorySummary(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteQueryStateHistorySummary(ctx)
+}
+
+func (f *HistoryApiHandler) handleRouteGetStateHistoryTransitions(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteQueryStateHistoryTransitions(ctx)
+}
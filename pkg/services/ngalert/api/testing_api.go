@@ -25,6 +25,10 @@ func (f *TestingApiHandler) handleRouteTestRuleGrafanaConfig(c *contextmodel.Req
 	return f.svc.RouteTestGrafanaRuleConfig(c, body)
 }
 
+func (f *TestingApiHandler) handleRouteDryRunGrafanaRuleGroup(c *contextmodel.ReqContext, body apimodels.PostableRuleGroupConfig, namespace string) response.Response {
+	return f.svc.RouteDryRunGrafanaRuleGroup(c, body, namespace)
+}
+
 func (f *TestingApiHandler) handleRouteEvalQueries(c *contextmodel.ReqContext, body apimodels.EvalQueriesPayload) response.Response {
 	return f.svc.RouteEvalQueries(c, body)
 }
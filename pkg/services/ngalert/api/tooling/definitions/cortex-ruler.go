@@ -164,7 +164,7 @@ import (
 //       403: ForbiddenError
 //       404: NotFound
 
-// swagger:parameters RoutePostNameRulesConfig RoutePostNameGrafanaRulesConfig RoutePostRulesGroupForExport
+// swagger:parameters RoutePostNameRulesConfig RoutePostNameGrafanaRulesConfig RoutePostRulesGroupForExport RouteDryRunGrafanaRuleGroup
 type NamespaceConfig struct {
 	// in:path
 	Namespace string
@@ -412,26 +412,90 @@ type PostableGrafanaRule struct {
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused     *bool               `json:"is_paused" yaml:"is_paused"`
+	Record       *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+	Dependencies []RuleDependency    `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	// NotificationSettings route the alerts of the rule directly to a receiver, bypassing the notification policies.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []AlertQuery        `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
-	Provenance      Provenance          `json:"provenance,omitempty" yaml:"provenance,omitempty"`
-	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`
+	ID                   int64                          `json:"id" yaml:"id"`
+	OrgID                int64                          `json:"orgId" yaml:"orgId"`
+	Title                string                         `json:"title" yaml:"title"`
+	Condition            string                         `json:"condition" yaml:"condition"`
+	Data                 []AlertQuery                   `json:"data" yaml:"data"`
+	Updated              time.Time                      `json:"updated" yaml:"updated"`
+	IntervalSeconds      int64                          `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version              int64                          `json:"version" yaml:"version"`
+	UID                  string                         `json:"uid" yaml:"uid"`
+	NamespaceUID         string                         `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID          int64                          `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup            string                         `json:"rule_group" yaml:"rule_group"`
+	NoDataState          NoDataState                    `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState         ExecutionErrorState            `json:"exec_err_state" yaml:"exec_err_state"`
+	Provenance           Provenance                     `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
+	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	Dependencies         []RuleDependency               `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+}
+
+// Record makes a Grafana rule a recording rule. The results of the query or
+// expression From are written as samples of the series Metric instead of
+// being evaluated as an alert condition.
+// swagger:model
+type Record struct {
+	// Name of the recorded series. It must be a valid Prometheus metric name.
+	// required: true
+	// example: job:http_requests:rate5m
+	Metric string `json:"metric" yaml:"metric"`
+	// RefID of the query or expression whose results are recorded.
+	// required: true
+	// example: A
+	From string `json:"from" yaml:"from"`
+}
+
+// RuleDependency makes a Grafana rule depend on the state of another rule of
+// the same group. The rule is inhibited while the upstream rule has instances
+// that are Alerting, NoData or Error. The instances of the upstream rule can't
+// be used as an input query of the rule. A rule can only depend on rules that
+// are already saved.
+// swagger:model
+type RuleDependency struct {
+	// UID of the upstream rule.
+	// required: true
+	RuleUID string `json:"ruleUid" yaml:"ruleUid"`
+	// Labels that an instance must share with an instance of the upstream rule to be inhibited.
+	// If empty, all instances are inhibited.
+	// example: ["cluster"]
+	Equal []string `json:"equal,omitempty" yaml:"equal,omitempty"`
+}
+
+// AlertRuleNotificationSettings are the notification settings of a Grafana rule.
+// The alerts of a rule with notification settings are sent to the receiver
+// directly, and are not routed by the notification policies. Timings that are
+// not specified are inherited from the default notification policy.
+// swagger:model
+type AlertRuleNotificationSettings struct {
+	// Name of the receiver to send notifications to.
+	// required: true
+	// example: grafana-default-email
+	Receiver string `json:"receiver" yaml:"receiver"`
+	// Labels to group alerts by. If specified, it must contain the labels
+	// alertname and grafana_folder, or be ["..."] to group by all labels.
+	// Defaults to ["alertname", "grafana_folder"].
+	// example: ["alertname", "grafana_folder", "cluster"]
+	GroupBy []string `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	// example: 30s
+	GroupWait *model.Duration `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	// example: 5m
+	GroupInterval *model.Duration `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	// example: 4h
+	RepeatInterval *model.Duration `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+	// Names of the mute timings that mute the notifications.
+	// example: ["maintenance"]
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
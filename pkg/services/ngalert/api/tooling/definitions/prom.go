@@ -39,6 +39,58 @@ import (
 //       200: AlertResponse
 //       404: NotFound
 
+// swagger:route POST /api/prometheus/grafana/api/v1/rules/{RuleUID}/acknowledgement prometheus RoutePostGrafanaAlertAcknowledgement
+//
+// acknowledges, assigns or comments on an alert instance of a rule
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: AlertAcknowledgement
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:parameters RoutePostGrafanaAlertAcknowledgement
+type AlertAcknowledgementParams struct {
+	// in:path
+	RuleUID string
+	// in:body
+	Body PostableAlertAcknowledgement
+}
+
+// PostableAlertAcknowledgement changes the acknowledgement of the alert instance with the labels. The fields that are
+// not specified do not change.
+// swagger:model
+type PostableAlertAcknowledgement struct {
+	// Labels of the alert instance. Internal labels can be omitted.
+	// required: true
+	Labels map[string]string `json:"labels"`
+	// Acknowledged acknowledges the alert instance if true, and removes the acknowledgement if false.
+	Acknowledged *bool `json:"acknowledged,omitempty"`
+	// Assignee is the login of the user who owns the alert instance. An empty assignee unassigns the alert instance.
+	Assignee *string `json:"assignee,omitempty"`
+	// Comment is added to the comments of the alert instance.
+	Comment string `json:"comment,omitempty"`
+}
+
+// AlertAcknowledgement records who acknowledged and who owns an alert instance, and the comments about it.
+// swagger:model
+type AlertAcknowledgement struct {
+	AcknowledgedBy string         `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt *time.Time     `json:"acknowledgedAt,omitempty"`
+	Assignee       string         `json:"assignee,omitempty"`
+	Comments       []AlertComment `json:"comments,omitempty"`
+	Updated        time.Time      `json:"updated"`
+}
+
+// swagger:model
+type AlertComment struct {
+	Author  string    `json:"author"`
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+}
+
 // swagger:model
 type RuleResponse struct {
 	// in: body
@@ -170,6 +222,8 @@ type Alert struct {
 	ActiveAt *time.Time `json:"activeAt"`
 	// required: true
 	Value string `json:"value"`
+	// Acknowledgement is set if the alert was acknowledged, assigned or commented on.
+	Acknowledgement *AlertAcknowledgement `json:"acknowledgement,omitempty"`
 }
 
 type StateByImportance int
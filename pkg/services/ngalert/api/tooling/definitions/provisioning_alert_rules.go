@@ -148,6 +148,8 @@ type ProvisionedAlertRule struct {
 	ExecErrState ExecutionErrorState `json:"execErrState"`
 	// required: true
 	For model.Duration `json:"for"`
+	// KeepFiringFor is how long the alerts of the rule keep firing after the condition stops being met.
+	KeepFiringFor model.Duration `json:"keepFiringFor,omitempty"`
 	// example: {"runbook_url": "https://supercoolrunbook.com/page/13"}
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
@@ -156,6 +158,12 @@ type ProvisionedAlertRule struct {
 	Provenance Provenance `json:"provenance,omitempty"`
 	// example: false
 	IsPaused bool `json:"isPaused"`
+	// Record makes the rule a recording rule.
+	Record *Record `json:"record,omitempty"`
+	// Dependencies are the rules of the same group that inhibit the rule.
+	Dependencies []RuleDependency `json:"dependencies,omitempty"`
+	// NotificationSettings route the alerts of the rule directly to a receiver, bypassing the notification policies.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty"`
 }
 
 // swagger:route GET /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -246,10 +254,38 @@ type AlertRuleExport struct {
 	// ForString is used to:
 	// - Only export the for field for HCL if it is non-zero.
 	// - Format the Prometheus model.Duration type properly for HCL.
-	ForString   *string            `json:"-" yaml:"-" hcl:"for"`
-	Annotations *map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
-	Labels      *map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
-	IsPaused    bool               `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
+	ForString     *string         `json:"-" yaml:"-" hcl:"for"`
+	KeepFiringFor *model.Duration `json:"keepFiringFor,omitempty" yaml:"keepFiringFor,omitempty"`
+	// KeepFiringForString is used to format the Prometheus model.Duration type properly for HCL.
+	KeepFiringForString  *string                              `json:"-" yaml:"-" hcl:"keep_firing_for"`
+	Annotations          *map[string]string                   `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
+	Labels               *map[string]string                   `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
+	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
+	Record               *AlertRuleRecordExport               `json:"record,omitempty" yaml:"record,omitempty" hcl:"record,block"`
+	Dependencies         []AlertRuleDependencyExport          `json:"dependencies,omitempty" yaml:"dependencies,omitempty" hcl:"dependency,block"`
+	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
+}
+
+// AlertRuleRecordExport is the provisioned export of models.Record.
+type AlertRuleRecordExport struct {
+	Metric string `json:"metric" yaml:"metric" hcl:"metric"`
+	From   string `json:"from" yaml:"from" hcl:"from"`
+}
+
+// AlertRuleNotificationSettingsExport is the provisioned export of models.NotificationSettings.
+type AlertRuleNotificationSettingsExport struct {
+	Receiver          string   `json:"receiver" yaml:"receiver" hcl:"contact_point"`
+	GroupBy           []string `json:"group_by,omitempty" yaml:"group_by,omitempty" hcl:"group_by"`
+	GroupWait         *string  `json:"group_wait,omitempty" yaml:"group_wait,omitempty" hcl:"group_wait"`
+	GroupInterval     *string  `json:"group_interval,omitempty" yaml:"group_interval,omitempty" hcl:"group_interval"`
+	RepeatInterval    *string  `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty" hcl:"repeat_interval"`
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty" hcl:"mute_timings"`
+}
+
+// AlertRuleDependencyExport is the provisioned export of models.RuleDependency.
+type AlertRuleDependencyExport struct {
+	RuleUID string   `json:"ruleUid" yaml:"ruleUid" hcl:"rule_uid"`
+	Equal   []string `json:"equal,omitempty" yaml:"equal,omitempty" hcl:"equal"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -0,0 +1,182 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/v1/provisioning/silence-templates provisioning stable RouteGetSilenceTemplates
+//
+// Get all the silence templates.
+//
+//     Responses:
+//       200: SilenceTemplates
+
+// swagger:route GET /api/v1/provisioning/silence-templates/{UID} provisioning stable RouteGetSilenceTemplate
+//
+// Get a silence template.
+//
+//     Responses:
+//       200: SilenceTemplate
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/silence-templates provisioning stable RoutePostSilenceTemplate
+//
+// Create a new silence template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: SilenceTemplate
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/silence-templates/{UID} provisioning stable RoutePutSilenceTemplate
+//
+// Update an existing silence template. Recurring silences that use the template replace the silences they created.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: SilenceTemplate
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/silence-templates/{UID} provisioning stable RouteDeleteSilenceTemplate
+//
+// Delete a silence template. Templates that are used by recurring silences cannot be deleted.
+//
+//     Responses:
+//       204: description: The silence template was deleted successfully.
+//       400: ValidationError
+
+// swagger:route GET /api/v1/provisioning/recurring-silences provisioning stable RouteGetRecurringSilences
+//
+// Get all the recurring silences.
+//
+//     Responses:
+//       200: RecurringSilences
+
+// swagger:route GET /api/v1/provisioning/recurring-silences/{UID} provisioning stable RouteGetRecurringSilence
+//
+// Get a recurring silence.
+//
+//     Responses:
+//       200: RecurringSilence
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/recurring-silences provisioning stable RoutePostRecurringSilence
+//
+// Create a new recurring silence.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: RecurringSilence
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/recurring-silences/{UID} provisioning stable RoutePutRecurringSilence
+//
+// Update an existing recurring silence. The silences created from the previous version are replaced.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: RecurringSilence
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/recurring-silences/{UID} provisioning stable RouteDeleteRecurringSilence
+//
+// Delete a recurring silence. The silences created from it are expired.
+//
+//     Responses:
+//       204: description: The recurring silence was deleted successfully.
+//       400: ValidationError
+
+// swagger:parameters RouteGetSilenceTemplate RoutePutSilenceTemplate RouteDeleteSilenceTemplate RouteGetRecurringSilence RoutePutRecurringSilence RouteDeleteRecurringSilence
+type SilenceUIDParam struct {
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostSilenceTemplate RoutePutSilenceTemplate
+type SilenceTemplatePayload struct {
+	// in:body
+	Body SilenceTemplate
+}
+
+// swagger:parameters RoutePostRecurringSilence RoutePutRecurringSilence
+type RecurringSilencePayload struct {
+	// in:body
+	Body RecurringSilence
+}
+
+// swagger:parameters RoutePostSilenceTemplate RoutePutSilenceTemplate RouteDeleteSilenceTemplate RoutePostRecurringSilence RoutePutRecurringSilence RouteDeleteRecurringSilence
+type SilenceHeaders struct {
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
+
+// swagger:model
+type SilenceTemplates []SilenceTemplate
+
+// SilenceTemplate is a reusable definition of a silence.
+// swagger:model
+type SilenceTemplate struct {
+	// example: maintenance
+	UID string `json:"uid"`
+	// required: true
+	// example: Database maintenance
+	Name string `json:"name"`
+	// required: true
+	// example: [["team", "=", "database"]]
+	Matchers ObjectMatchers `json:"matchers,omitempty"`
+	// Duration is the default duration of the silences created from the template.
+	// required: true
+	// example: 2h
+	Duration model.Duration `json:"duration"`
+	Comment  string         `json:"comment,omitempty"`
+	// readonly: true
+	Updated time.Time `json:"updated,omitempty"`
+	// readonly: true
+	Provenance Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type RecurringSilences []RecurringSilence
+
+// RecurringSilence is a silence that repeats on a schedule. Either Schedule or MuteTimeInterval must be specified.
+// swagger:model
+type RecurringSilence struct {
+	UID string `json:"uid"`
+	// required: true
+	// example: Nightly backup
+	Name string `json:"name"`
+	// TemplateUID is the UID of the silence template that provides the matchers, the duration and the comment
+	// if they are not specified.
+	TemplateUID string `json:"templateUID,omitempty"`
+	// example: [["job", "=", "backup"]]
+	Matchers ObjectMatchers `json:"matchers,omitempty"`
+	Comment  string         `json:"comment,omitempty"`
+	// Schedule is a standard cron expression for the start of the silences.
+	// example: 0 2 * * *
+	Schedule string `json:"schedule,omitempty"`
+	// Location is the time zone of the schedule. Defaults to UTC.
+	// example: Europe/Berlin
+	Location string `json:"location,omitempty"`
+	// Duration is the duration of the silences created from the schedule.
+	// example: 1h
+	Duration model.Duration `json:"duration,omitempty"`
+	// MuteTimeInterval is the name of a mute timing. A silence is created for each continuous range of time
+	// of the mute timing.
+	MuteTimeInterval string `json:"muteTimeInterval,omitempty"`
+	// readonly: true
+	Updated time.Time `json:"updated,omitempty"`
+	// readonly: true
+	Provenance Provenance `json:"provenance,omitempty"`
+}
@@ -1,6 +1,10 @@
 package definitions
 
-import "github.com/grafana/grafana-plugin-sdk-go/data"
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
 
 // swagger:route GET /api/v1/rules/history history RouteGetStateHistory
 //
@@ -17,3 +21,149 @@ type StateHistory struct {
 	// in:body
 	Results *data.Frame `json:"results"`
 }
+
+// swagger:route GET /api/v1/rules/history/summary history RouteGetStateHistorySummary
+//
+// Summarize the state history of alert rules and their alert instances. Instances can be filtered by labels with
+// query parameters that have the prefix "labels_", for example "labels_team=backend".
+//
+//     Produces:
+//     - application/json
+//     - text/csv
+//
+//     Responses:
+//       200: StateHistorySummary
+//       400: ValidationError
+
+// swagger:route GET /api/v1/rules/history/transitions history RouteGetStateHistoryTransitions
+//
+// Query state transitions of alert instances of many alert rules. Instances can be filtered by labels with
+// query parameters that have the prefix "labels_", for example "labels_team=backend".
+//
+//     Produces:
+//     - application/json
+//     - text/csv
+//
+//     Responses:
+//       200: StateHistoryTransitions
+//       400: ValidationError
+
+// swagger:parameters RouteGetStateHistorySummary RouteGetStateHistoryTransitions
+type StateHistoryAggregateParams struct {
+	// UIDs of the alert rules. All rules the user has access to are queried if neither rules nor folders are specified.
+	// in:query
+	// required:false
+	RuleUID []string `json:"ruleUID"`
+	// UIDs of the folders of the alert rules.
+	// in:query
+	// required:false
+	FolderUID []string `json:"folderUID"`
+	// Start of the time range as a unix timestamp in seconds. Defaults to 6 hours before the end of the time range.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// End of the time range as a unix timestamp in seconds. Defaults to now.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// Maximum number of transitions to read from the state history backend, at most 5000. The most recent transitions
+	// are read if there are more in the time range, and the response is marked as truncated.
+	// in:query
+	// default:1000
+	// required:false
+	Limit int `json:"limit"`
+	// Format of the response, json or csv.
+	// in:query
+	// required:false
+	// default:json
+	Format string `json:"format"`
+	// Whether to initiate a download of the file or not.
+	// in: query
+	// type: boolean
+	// required:false
+	// default:false
+	Download bool `json:"download"`
+}
+
+// swagger:parameters RouteGetStateHistorySummary
+type StateHistorySummaryParams struct {
+	// Number of the most flapping rules to return.
+	// in:query
+	// required:false
+	// default:10
+	Top int `json:"top"`
+}
+
+// swagger:model
+type StateHistorySummary struct {
+	From  time.Time                 `json:"from"`
+	To    time.Time                 `json:"to"`
+	Rules []StateHistoryRuleSummary `json:"rules"`
+	// TopFlapping are the rules with the most firings in the time range. Ties are broken by the number of transitions.
+	TopFlapping []StateHistoryFlappingRule `json:"topFlapping"`
+	// Truncated is true if the limit of transitions was reached. The summary then starts at the oldest transition
+	// that was read instead of the start of the requested time range.
+	Truncated bool `json:"truncated"`
+}
+
+// StateHistoryStats are the statistics of the state history of an alert instance, or of all alert instances of a rule.
+type StateHistoryStats struct {
+	// StateDurations is the time in seconds spent in each state.
+	StateDurations map[string]float64 `json:"stateDurations"`
+	// Transitions is the number of state transitions.
+	Transitions int `json:"transitions"`
+	// Firings is the number of transitions to the Alerting state.
+	Firings int `json:"firings"`
+	// MTTR is the mean time in seconds to resolve firings that started and ended in the time range.
+	MTTR *float64 `json:"mttr,omitempty"`
+}
+
+// swagger:model
+type StateHistoryRuleSummary struct {
+	RuleUID   string `json:"ruleUID"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUID"`
+	StateHistoryStats
+	Instances []StateHistoryInstanceSummary `json:"instances"`
+}
+
+// swagger:model
+type StateHistoryInstanceSummary struct {
+	Fingerprint string            `json:"fingerprint"`
+	Labels      map[string]string `json:"labels"`
+	StateHistoryStats
+}
+
+// swagger:model
+type StateHistoryFlappingRule struct {
+	RuleUID     string `json:"ruleUID"`
+	Title       string `json:"title"`
+	FolderUID   string `json:"folderUID"`
+	Firings     int    `json:"firings"`
+	Transitions int    `json:"transitions"`
+}
+
+// swagger:model
+type StateHistoryTransitions struct {
+	Transitions []StateHistoryTransition `json:"transitions"`
+	// Truncated is true if the limit of transitions was reached. Older transitions in the time range are missing.
+	Truncated bool `json:"truncated"`
+}
+
+// swagger:model
+type StateHistoryTransition struct {
+	Time           time.Time         `json:"time"`
+	RuleUID        string            `json:"ruleUID"`
+	Title          string            `json:"title"`
+	FolderUID      string            `json:"folderUID"`
+	Fingerprint    string            `json:"fingerprint"`
+	Labels         map[string]string `json:"labels"`
+	Previous       string            `json:"previous"`
+	PreviousReason string            `json:"previousReason,omitempty"`
+	Current        string            `json:"current"`
+	CurrentReason  string            `json:"currentReason,omitempty"`
+	// AcknowledgedBy is the user who had acknowledged the alert instance at the time of the transition.
+	AcknowledgedBy string `json:"acknowledgedBy,omitempty"`
+	// Assignee is the user who owned the alert instance at the time of the transition.
+	Assignee string `json:"assignee,omitempty"`
+}
@@ -68,6 +68,21 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /api/v1/rule/dryrun/grafana/{Namespace} testing RouteDryRunGrafanaRuleGroup
+//
+// Evaluate a rule group as if it was deployed and compare the resulting alerts with the alerts of the stored version of the group
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: DryRunRuleGroupResponse
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -120,6 +135,75 @@ func (n *PostableExtendedRuleNodeExtended) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// swagger:response DryRunRuleGroupResponse
+type DryRunRuleGroupResponse struct {
+	// in:body
+	Body DryRunRuleGroupResult
+}
+
+// swagger:model
+type DryRunRuleGroupResult struct {
+	// Rules contains the rules that are created, updated or deleted by the submitted rule group.
+	Rules []DryRunRuleDiff `json:"rules"`
+}
+
+// swagger:enum DryRunRuleChange
+type DryRunRuleChange string
+
+const (
+	DryRunRuleCreated DryRunRuleChange = "created"
+	DryRunRuleUpdated DryRunRuleChange = "updated"
+	DryRunRuleDeleted DryRunRuleChange = "deleted"
+)
+
+// swagger:enum DryRunInstanceChange
+type DryRunInstanceChange string
+
+const (
+	// DryRunInstanceFiring is an alert that fires with the submitted version of the rule but not with the stored one.
+	DryRunInstanceFiring DryRunInstanceChange = "firing"
+	// DryRunInstanceResolved is an alert that fires with the stored version of the rule but not with the submitted one.
+	DryRunInstanceResolved DryRunInstanceChange = "resolved"
+	// DryRunInstanceAdded is an instance that does not fire and exists only for the submitted version of the rule.
+	DryRunInstanceAdded DryRunInstanceChange = "added"
+	// DryRunInstanceRemoved is an instance that does not fire and exists only for the stored version of the rule.
+	DryRunInstanceRemoved DryRunInstanceChange = "removed"
+	// DryRunInstanceChanged is an instance that has different state, labels, annotations or receivers.
+	DryRunInstanceChanged DryRunInstanceChange = "changed"
+)
+
+// swagger:model
+type DryRunRuleDiff struct {
+	// example: okrd3I0Vz
+	UID    string           `json:"uid,omitempty"`
+	Title  string           `json:"title"`
+	Change DryRunRuleChange `json:"change"`
+	// Instances contains the alert instances that are different for the stored and the submitted version of the rule.
+	Instances []DryRunInstanceDiff `json:"instances"`
+	// Unchanged is the number of alert instances that are the same for both versions of the rule.
+	Unchanged int `json:"unchanged"`
+}
+
+// swagger:model
+type DryRunInstanceDiff struct {
+	Change DryRunInstanceChange `json:"change"`
+	// Current is the live instance of the stored version of the rule.
+	Current *DryRunInstance `json:"current,omitempty"`
+	// Proposed is the instance produced by the submitted version of the rule.
+	Proposed *DryRunInstance `json:"proposed,omitempty"`
+}
+
+// swagger:model
+type DryRunInstance struct {
+	// example: Alerting
+	State       string            `json:"state"`
+	StateReason string            `json:"stateReason,omitempty"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Receivers are the receivers that the alert is routed to by the notification policies of the organization.
+	Receivers []string `json:"receivers,omitempty"`
+}
+
 // swagger:parameters RouteEvalQueries
 type EvalQueriesRequest struct {
 	// in:body
@@ -12,12 +12,14 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -39,12 +41,14 @@ type stateManager interface {
 
 type Engine struct {
 	evalFactory        eval.EvaluatorFactory
+	dataEvalFactory    dataEvaluatorFactory
 	createStateManager func() stateManager
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, expressionService *expr.Service, cfg setting.UnifiedAlertingSettings, tracer tracing.Tracer) *Engine {
 	return &Engine{
-		evalFactory: evalFactory,
+		evalFactory:     evalFactory,
+		dataEvalFactory: newDataEvaluatorFactory(cfg, expressionService),
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:                 nil,
@@ -74,7 +78,7 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	}
 	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
 
-	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition())
+	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, e.dataEvalFactory, user, rule.GetEvalCondition())
 	if err != nil {
 		return nil, errors.Join(ErrInvalidInputData, err)
 	}
@@ -126,11 +130,11 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	return result, nil
 }
 
-func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition) (backtestingEvaluator, error) {
+func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, dataEvalFactory dataEvaluatorFactory, user identity.Requester, condition models.Condition) (backtestingEvaluator, error) {
 	for _, q := range condition.Data {
-		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
+		if isDataQuery(q) {
 			if len(condition.Data) != 1 {
-				return nil, errors.New("data queries are not supported with other expressions or data queries")
+				return newDataExpressionsEvaluator(ctx, dataEvalFactory, user, condition)
 			}
 			if condition.Condition == "" {
 				return nil, fmt.Errorf("condition must not be empty and be set to the data query %s", q.RefID)
@@ -138,17 +142,11 @@ func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFact
 			if condition.Condition != q.RefID {
 				return nil, fmt.Errorf("condition must be set to the data query %s", q.RefID)
 			}
-			model := struct {
-				DataFrame *data.Frame `json:"data"`
-			}{}
-			err := json.Unmarshal(q.Model, &model)
+			frame, err := parseDataFrame(q)
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse data frame: %w", err)
+				return nil, err
 			}
-			if model.DataFrame == nil {
-				return nil, errors.New("the data field must not be empty")
-			}
-			return newDataEvaluator(condition.Condition, model.DataFrame)
+			return newDataEvaluator(condition.Condition, frame)
 		}
 	}
 
@@ -165,6 +163,24 @@ func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFact
 	}, nil
 }
 
+func isDataQuery(q models.AlertQuery) bool {
+	return q.DatasourceUID == "__data__" || q.QueryType == "__data__"
+}
+
+func parseDataFrame(q models.AlertQuery) (*data.Frame, error) {
+	model := struct {
+		DataFrame *data.Frame `json:"data"`
+	}{}
+	err := json.Unmarshal(q.Model, &model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data frame: %w", err)
+	}
+	if model.DataFrame == nil {
+		return nil, errors.New("the data field must not be empty")
+	}
+	return model.DataFrame, nil
+}
+
 // NoopImageService is a no-op image service.
 type NoopImageService struct{}
 
@@ -145,7 +145,7 @@ func TestNewBacktestingEvaluator(t *testing.T) {
 
 		for _, testCase := range testCases {
 			t.Run(testCase.name, func(t *testing.T) {
-				e, err := newBacktestingEvaluator(context.Background(), evalFactory, nil, testCase.condition)
+				e, err := newBacktestingEvaluator(context.Background(), evalFactory, nil, nil, testCase.condition)
 				if testCase.error {
 					require.Error(t, err)
 					return
@@ -175,7 +175,7 @@ func TestEvaluatorTest(t *testing.T) {
 	}
 	manager := &fakeStateManager{}
 
-	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition) (backtestingEvaluator, error) {
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, dataEvalFactory dataEvaluatorFactory, user identity.Requester, condition models.Condition) (backtestingEvaluator, error) {
 		return evaluator, nil
 	}
 
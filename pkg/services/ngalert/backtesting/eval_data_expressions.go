@@ -0,0 +1,127 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// dataSourceUID is the UID of the data source the data queries of a condition
+// are sent to when it is evaluated with expressions.
+const dataSourceUID = "__data__"
+
+// dataEvaluatorFactory returns the factory of the evaluators of conditions
+// whose data source queries are answered by dataService.
+type dataEvaluatorFactory func(dataService *dataQueryService) eval.EvaluatorFactory
+
+func newDataEvaluatorFactory(cfg setting.UnifiedAlertingSettings, expressionService *expr.Service) dataEvaluatorFactory {
+	return func(dataService *dataQueryService) eval.EvaluatorFactory {
+		// the plugin store is only used to validate conditions
+		return eval.NewEvaluatorFactory(cfg, dataService, expressionService.WithQueryDataHandler(dataService), nil)
+	}
+}
+
+// newDataExpressionsEvaluator returns an evaluator of the expressions of
+// condition with the frames of its data queries. The condition is evaluated
+// by the expression service, like the ones of data source queries: at each
+// evaluation the data queries return the rows of their frame in their time
+// range relative to the evaluation time.
+func newDataExpressionsEvaluator(ctx context.Context, factory dataEvaluatorFactory, user identity.Requester, condition models.Condition) (*queryEvaluator, error) {
+	dataService := &dataQueryService{frames: map[string]*data.Frame{}}
+	queries := make([]models.AlertQuery, 0, len(condition.Data))
+	for _, q := range condition.Data {
+		switch {
+		case isDataQuery(q):
+			frame, err := parseDataFrame(q)
+			if err != nil {
+				return nil, err
+			}
+			dataService.frames[q.RefID] = frame
+			q.DatasourceUID = dataSourceUID
+		default:
+			if isExpr, _ := q.IsExpression(); !isExpr {
+				return nil, errors.New("data queries are not supported with data source queries")
+			}
+		}
+		queries = append(queries, q)
+	}
+
+	evaluator, err := factory(dataService).Create(eval.EvaluationContext{Ctx: ctx, User: user}, models.Condition{
+		Condition: condition.Condition,
+		Data:      queries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &queryEvaluator{eval: evaluator}, nil
+}
+
+// dataQueryService answers the data queries of a condition with the rows of
+// their frame in the time range of the query. It is the only data source of
+// the condition.
+type dataQueryService struct {
+	frames map[string]*data.Frame
+}
+
+func (s *dataQueryService) QueryData(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		frame, ok := s.frames[q.RefID]
+		if !ok {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: fmt.Errorf("no data for query %s", q.RefID)}
+			continue
+		}
+		rows, err := filterTimeRange(frame, q.TimeRange)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{rows}}
+	}
+	return resp, nil
+}
+
+func (s *dataQueryService) GetDatasource(_ context.Context, _ int64, _ identity.Requester, _ bool) (*datasources.DataSource, error) {
+	return nil, datasources.ErrDataSourceNotFound
+}
+
+func (s *dataQueryService) GetDatasourceByUID(_ context.Context, uid string, _ identity.Requester, _ bool) (*datasources.DataSource, error) {
+	if uid != dataSourceUID {
+		return nil, datasources.ErrDataSourceNotFound
+	}
+	return &datasources.DataSource{UID: dataSourceUID, Name: dataSourceUID, Type: dataSourceUID}, nil
+}
+
+// filterTimeRange returns the rows of frame whose time is in tr.
+func filterTimeRange(frame *data.Frame, tr backend.TimeRange) (*data.Frame, error) {
+	for i, f := range frame.Fields {
+		if !f.Type().Time() {
+			continue
+		}
+		return frame.FilterRowsByField(i, func(v any) (bool, error) {
+			var t time.Time
+			switch v := v.(type) {
+			case time.Time:
+				t = v
+			case *time.Time:
+				if v == nil {
+					return false, nil
+				}
+				t = *v
+			}
+			return !t.Before(tr.From) && !t.After(tr.To), nil
+		})
+	}
+	return nil, errors.New("the data frame must have a time field")
+}
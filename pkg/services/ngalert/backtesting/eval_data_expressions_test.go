@@ -0,0 +1,129 @@
+package backtesting
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func dataExpressionsCondition(t *testing.T, frame *data.Frame, condition string, expressions ...string) models.Condition {
+	t.Helper()
+	d, err := json.Marshal(struct {
+		Data *data.Frame `json:"data"`
+	}{Data: frame})
+	require.NoError(t, err)
+	queries := []models.AlertQuery{
+		{
+			RefID:             "A",
+			QueryType:         "__data__",
+			RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Minute)},
+			Model:             d,
+		},
+	}
+	for i, e := range expressions {
+		queries = append(queries, models.AlertQuery{
+			RefID:         string(rune('B' + i)),
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(e),
+		})
+	}
+	return models.Condition{Condition: condition, Data: queries}
+}
+
+func TestDataExpressionsEvaluator(t *testing.T) {
+	// a series growing by 1 every 10 seconds
+	start := time.Unix(0, 0)
+	size := 360
+	times := make([]time.Time, size)
+	values := make([]float64, size)
+	for i := range times {
+		times[i] = start.Add(time.Duration(i) * 10 * time.Second)
+		values[i] = float64(i)
+	}
+	frame := data.NewFrame("disk",
+		data.NewField("time", nil, times),
+		data.NewField("used", data.Labels{"host": "a"}, values),
+	)
+
+	dataEvalFactory := newDataEvaluatorFactory(
+		setting.UnifiedAlertingSettings{EvaluationTimeout: time.Minute},
+		expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, &featuremgmt.FeatureManager{}, nil, tracing.InitializeTracerForTest()),
+	)
+	u := &user.SignedInUser{OrgID: 1}
+	newEvaluator := func(t *testing.T, condition models.Condition) backtestingEvaluator {
+		t.Helper()
+		e, err := newBacktestingEvaluator(context.Background(), nil, dataEvalFactory, u, condition)
+		require.NoError(t, err)
+		require.IsType(t, &queryEvaluator{}, e)
+		return e
+	}
+
+	forecast := `{"type": "forecast", "expression": "$A", "model": "linear", "horizon": "10m"}`
+	threshold := `{"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [100]}}]}`
+
+	t.Run("should evaluate the expressions with the data before each evaluation", func(t *testing.T) {
+		e := newEvaluator(t, dataExpressionsCondition(t, frame, "C", forecast, threshold))
+
+		var states []eval.State
+		err := e.Eval(context.Background(), start.Add(5*time.Minute), time.Minute, 5, func(idx int, now time.Time, results eval.Results) error {
+			require.Len(t, results, 1)
+			require.Equal(t, data.Labels{"host": "a"}, results[0].Instance)
+			states = append(states, results[0].State)
+			return nil
+		})
+		require.NoError(t, err)
+		// the value forecast at now+10m is 90 at 5m, 96 at 6m, 102 at 7m...
+		require.Equal(t, []eval.State{eval.Normal, eval.Normal, eval.Alerting, eval.Alerting, eval.Alerting}, states)
+	})
+
+	t.Run("should evaluate any expression", func(t *testing.T) {
+		reduce := `{"type": "reduce", "expression": "A", "reducer": "max"}`
+		math := `{"type": "math", "expression": "$B > 20"}`
+		e := newEvaluator(t, dataExpressionsCondition(t, frame, "C", reduce, math))
+
+		var states []eval.State
+		err := e.Eval(context.Background(), start.Add(3*time.Minute), time.Minute, 2, func(idx int, now time.Time, results eval.Results) error {
+			require.Len(t, results, 1)
+			states = append(states, results[0].State)
+			return nil
+		})
+		require.NoError(t, err)
+		// the maximum is 18 at 3m and 24 at 4m
+		require.Equal(t, []eval.State{eval.Normal, eval.Alerting}, states)
+	})
+
+	t.Run("should be NoData without data in the time range", func(t *testing.T) {
+		e := newEvaluator(t, dataExpressionsCondition(t, frame, "C", forecast, threshold))
+
+		err := e.Eval(context.Background(), start.Add(-time.Hour), time.Minute, 1, func(idx int, now time.Time, results eval.Results) error {
+			require.Len(t, results, 1)
+			require.Equal(t, eval.NoData, results[0].State)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("should fail with data source queries", func(t *testing.T) {
+		condition := dataExpressionsCondition(t, frame, "A")
+		condition.Data = append(condition.Data, models.AlertQuery{RefID: "B", DatasourceUID: "prometheus", Model: json.RawMessage(`{}`)})
+		_, err := newBacktestingEvaluator(context.Background(), nil, dataEvalFactory, u, condition)
+		require.Error(t, err)
+	})
+
+	t.Run("should fail with invalid expressions", func(t *testing.T) {
+		_, err := newBacktestingEvaluator(context.Background(), nil, dataEvalFactory, u, dataExpressionsCondition(t, frame, "B", `{"type": "math", "expression": "$X * 2"}`))
+		require.Error(t, err)
+	})
+}
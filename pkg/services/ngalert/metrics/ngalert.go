@@ -30,6 +30,7 @@ type NGAlert struct {
 	multiOrgAlertmanagerMetrics *MultiOrgAlertmanager
 	apiMetrics                  *API
 	historianMetrics            *Historian
+	remoteWriterMetrics         *RemoteWriter
 }
 
 // NewNGAlert manages the metrics of all the alerting components.
@@ -41,6 +42,7 @@ func NewNGAlert(r prometheus.Registerer) *NGAlert {
 		multiOrgAlertmanagerMetrics: NewMultiOrgAlertmanagerMetrics(r),
 		apiMetrics:                  NewAPIMetrics(r),
 		historianMetrics:            NewHistorianMetrics(r, Subsystem),
+		remoteWriterMetrics:         NewRemoteWriterMetrics(r),
 	}
 }
 
@@ -63,3 +65,7 @@ func (ng *NGAlert) GetMultiOrgAlertmanagerMetrics() *MultiOrgAlertmanager {
 func (ng *NGAlert) GetHistorianMetrics() *Historian {
 	return ng.historianMetrics
 }
+
+func (ng *NGAlert) GetRemoteWriterMetrics() *RemoteWriter {
+	return ng.remoteWriterMetrics
+}
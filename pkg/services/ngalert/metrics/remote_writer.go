@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/instrument"
+)
+
+type RemoteWriter struct {
+	WritesTotal    *prometheus.CounterVec
+	WritesFailed   *prometheus.CounterVec
+	SamplesWritten *prometheus.CounterVec
+	WriteDuration  *instrument.HistogramCollector
+}
+
+func NewRemoteWriterMetrics(r prometheus.Registerer) *RemoteWriter {
+	return &RemoteWriter{
+		WritesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_writes_total",
+			Help:      "The total number of batches of recording rule samples that were attempted to be written.",
+		}, []string{"org", "backend"}),
+		WritesFailed: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_writes_failed_total",
+			Help:      "The total number of failed writes of batches of recording rule samples.",
+		}, []string{"org", "backend"}),
+		SamplesWritten: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_samples_written_total",
+			Help:      "The total number of recording rule samples that were written.",
+		}, []string{"org", "backend"}),
+		WriteDuration: instrument.NewHistogramCollector(promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_request_duration_seconds",
+			Help:      "Histogram of request durations to the target of recording rules.",
+			Buckets:   instrument.DefBuckets,
+		}, instrument.HistogramCollectorBuckets)),
+	}
+}
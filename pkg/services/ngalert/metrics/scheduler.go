@@ -20,6 +20,11 @@ type Scheduler struct {
 	EvalDuration                        *prometheus.HistogramVec
 	ProcessDuration                     *prometheus.HistogramVec
 	SendDuration                        *prometheus.HistogramVec
+	RecordingEvalTotal                  *prometheus.CounterVec
+	RecordingEvalFailures               *prometheus.CounterVec
+	RecordingEvalDuration               *prometheus.HistogramVec
+	RecordingWriteDuration              *prometheus.HistogramVec
+	EvalInhibited                       *prometheus.CounterVec
 	GroupRules                          *prometheus.GaugeVec
 	SchedulePeriodicDuration            prometheus.Histogram
 	SchedulableAlertRules               prometheus.Gauge
@@ -90,6 +95,53 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org"},
 		),
+		EvalInhibited: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluations_inhibited_total",
+				Help:      "The total number of rule evaluations skipped because the rule was inhibited by a rule it depends on.",
+			},
+			[]string{"org"},
+		),
+		RecordingEvalTotal: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "recording_rule_evaluations_total",
+				Help:      "The total number of recording rule evaluations.",
+			},
+			[]string{"org"},
+		),
+		RecordingEvalFailures: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "recording_rule_evaluation_failures_total",
+				Help:      "The total number of recording rule evaluations that failed, including the failures to write their results.",
+			},
+			[]string{"org"},
+		),
+		RecordingEvalDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "recording_rule_evaluation_duration_seconds",
+				Help:      "The time to evaluate a recording rule.",
+				Buckets:   []float64{.01, .1, .5, 1, 5, 10, 15, 30, 60, 120, 180, 240, 300},
+			},
+			[]string{"org"},
+		),
+		RecordingWriteDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "recording_rule_write_duration_seconds",
+				Help:      "The time to write the results of a recording rule.",
+				Buckets:   []float64{.01, .1, .5, 1, 5, 10, 15, 30, 60},
+			},
+			[]string{"org"},
+		),
 		// TODO: partition on rule group as well as tenant, similar to loki|cortex.
 		GroupRules: promauto.With(r).NewGaugeVec(
 			prometheus.GaugeOpts{
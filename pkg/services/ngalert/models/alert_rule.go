@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	alertingModels "github.com/grafana/alerting/models"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/setting"
@@ -19,6 +21,8 @@ import (
 )
 
 var (
+	// ErrRuleDependencyCycle is returned when the dependencies of the rules of a group form a cycle.
+	ErrRuleDependencyCycle = errors.New("rule dependencies form a cycle")
 	// ErrAlertRuleNotFound is an error for an unknown alert rule.
 	ErrAlertRuleNotFound = fmt.Errorf("could not find alert rule")
 	// ErrAlertRuleFailedGenerateUniqueUID is an error for failure to generate alert rule UID
@@ -105,6 +109,20 @@ const (
 
 	// StateReasonAnnotation is the name of the annotation that explains the difference between evaluation state and alert state (i.e. changing state when NoData or Error).
 	StateReasonAnnotation = GrafanaReservedLabelPrefix + "state_reason"
+
+	// FlappingAnnotation is the name of the annotation that is set to "true" on the alerts of flapping instances.
+	FlappingAnnotation = GrafanaReservedLabelPrefix + "flapping"
+
+	// AcknowledgedByAnnotation and AcknowledgedAtAnnotation are the names of the annotations that contain the login of the
+	// user who acknowledged the instance and the time of the acknowledgement.
+	AcknowledgedByAnnotation = GrafanaReservedLabelPrefix + "acknowledged_by"
+	AcknowledgedAtAnnotation = GrafanaReservedLabelPrefix + "acknowledged_at"
+
+	// AssigneeAnnotation is the name of the annotation that contains the login of the user who owns the instance.
+	AssigneeAnnotation = GrafanaReservedLabelPrefix + "assignee"
+
+	// CommentAnnotation is the name of the annotation that contains the latest comment about the instance.
+	CommentAnnotation = GrafanaReservedLabelPrefix + "comment"
 )
 
 const (
@@ -114,6 +132,9 @@ const (
 	StateReasonPaused        = "Paused"
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
+	StateReasonInhibited     = "Inhibited"
+	StateReasonKeepFiring    = "KeepFiring"
+	StateReasonFlapping      = "Flapping"
 )
 
 var (
@@ -202,10 +223,66 @@ type AlertRule struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For time.Duration
+	// KeepFiringFor is how long the alerts of the rule keep firing after the condition stops being met.
+	KeepFiringFor time.Duration `xorm:"keep_firing_for"`
+	Annotations   map[string]string
+	Labels        map[string]string
+	IsPaused      bool
+	// Record makes the rule a recording rule when it is set.
+	Record *Record `xorm:"record json"`
+	// Dependencies are the rules of the same group that inhibit the rule.
+	Dependencies []RuleDependency
+	// NotificationSettings route the alerts of the rule directly to a receiver when they are set.
+	NotificationSettings *NotificationSettings `xorm:"notification_settings json"`
+}
+
+// RuleType is the type of an alert rule, it is derived from its definition.
+type RuleType string
+
+const (
+	// RuleTypeAlerting rules evaluate a condition and produce alert states.
+	RuleTypeAlerting RuleType = "alerting"
+	// RuleTypeRecording rules evaluate an expression and write its results as a new time series.
+	RuleTypeRecording RuleType = "recording"
+)
+
+// Record is the definition of a recording rule. The numbers returned by the
+// query or expression From are written as samples of the series Metric, with
+// their labels plus the labels of the rule.
+type Record struct {
+	// Metric is the name of the series written by the rule.
+	Metric string `json:"metric"`
+	// From is the RefID of the query or expression whose results are recorded.
+	From string `json:"from"`
+}
+
+// Validate returns an error if the metric name is not a valid Prometheus
+// metric name or if From is empty.
+func (r *Record) Validate() error {
+	if !model.IsValidMetricName(model.LabelValue(r.Metric)) {
+		return fmt.Errorf("%w: %q is not a valid metric name", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	if r.From == "" {
+		return fmt.Errorf("%w: the query or expression to record must be specified", ErrAlertRuleFailedValidation)
+	}
+	return nil
+}
+
+// RuleDependency makes a rule depend on the state of another rule of the same
+// group. The upstream rule is evaluated first, and the dependent rule is
+// inhibited while the upstream rule has instances that are not Normal. If Equal
+// is set, only the instances of the dependent rule that have the same values of
+// these labels as an instance of the upstream rule are inhibited, similar to
+// the inhibition rules of Alertmanager.
+//
+// Dependencies only inhibit rules. Using the firing instances of the upstream
+// rule as an input query of the dependent rule is not supported.
+type RuleDependency struct {
+	// RuleUID is the UID of the upstream rule.
+	RuleUID string `json:"ruleUid"`
+	// Equal are the labels that an instance must share with an instance of the upstream rule to be inhibited.
+	Equal []string `json:"equal,omitempty"`
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -289,7 +366,23 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// Type returns RuleTypeRecording if the rule is a recording rule, and RuleTypeAlerting otherwise.
+func (alertRule *AlertRule) Type() RuleType {
+	if alertRule.Record != nil {
+		return RuleTypeRecording
+	}
+	return RuleTypeAlerting
+}
+
+// GetEvalCondition returns the condition to evaluate. For recording rules
+// it is the query or expression whose results are recorded.
 func (alertRule *AlertRule) GetEvalCondition() Condition {
+	if alertRule.Record != nil {
+		return Condition{
+			Condition: alertRule.Record.From,
+			Data:      alertRule.Data,
+		}
+	}
 	return Condition{
 		Condition: alertRule.Condition,
 		Data:      alertRule.Data,
@@ -457,6 +550,55 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
+
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.Record != nil {
+		if err := alertRule.Record.Validate(); err != nil {
+			return err
+		}
+		if alertRule.For != 0 {
+			return fmt.Errorf("%w: field `for` is not supported by recording rules", ErrAlertRuleFailedValidation)
+		}
+		if alertRule.KeepFiringFor != 0 {
+			return fmt.Errorf("%w: field `keep_firing_for` is not supported by recording rules", ErrAlertRuleFailedValidation)
+		}
+		if alertRule.NotificationSettings != nil {
+			return fmt.Errorf("%w: field `notification_settings` is not supported by recording rules", ErrAlertRuleFailedValidation)
+		}
+		found := false
+		for _, q := range alertRule.Data {
+			if q.RefID == alertRule.Record.From {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: the query or expression %s to record does not exist", ErrAlertRuleFailedValidation, alertRule.Record.From)
+		}
+	}
+
+	for _, dep := range alertRule.Dependencies {
+		if dep.RuleUID == "" {
+			return fmt.Errorf("%w: the UID of the rule to depend on must be specified", ErrAlertRuleFailedValidation)
+		}
+		if dep.RuleUID == alertRule.UID {
+			return fmt.Errorf("%w: rule cannot depend on itself", ErrAlertRuleFailedValidation)
+		}
+		for _, name := range dep.Equal {
+			if !model.LabelName(name).IsValid() {
+				return fmt.Errorf("%w: invalid label name %q in the dependency on rule %s", ErrAlertRuleFailedValidation, name, dep.RuleUID)
+			}
+		}
+	}
+
+	if alertRule.NotificationSettings != nil {
+		if err := alertRule.NotificationSettings.Validate(); err != nil {
+			return fmt.Errorf("%w: invalid notification settings: %s", ErrAlertRuleFailedValidation, err)
+		}
+	}
 	return nil
 }
 
@@ -493,10 +635,15 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For           time.Duration
+	KeepFiringFor time.Duration `xorm:"keep_firing_for"`
+	Annotations   map[string]string
+	Labels        map[string]string
+	IsPaused      bool
+	Record        *Record `xorm:"record json"`
+	Dependencies  []RuleDependency
+
+	NotificationSettings *NotificationSettings `xorm:"notification_settings json"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -583,16 +730,17 @@ func (c Condition) IsValid() bool {
 // There are several exceptions:
 // 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
 // 2. There are fields that are patched together:
-//   - AlertRule.Condition and AlertRule.Data
+//   - AlertRule.Condition, AlertRule.Data and AlertRule.Record
 //
-// If either of the pair is specified, neither is patched.
+// If the queries and either the condition or the record are specified, none of them is patched.
 func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOptionals) {
 	if ruleToPatch.Title == "" {
 		ruleToPatch.Title = existingRule.Title
 	}
-	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
+	if (ruleToPatch.Condition == "" && ruleToPatch.Record == nil) || len(ruleToPatch.Data) == 0 {
 		ruleToPatch.Condition = existingRule.Condition
 		ruleToPatch.Data = existingRule.Data
+		ruleToPatch.Record = existingRule.Record
 	}
 	if ruleToPatch.IntervalSeconds == 0 {
 		ruleToPatch.IntervalSeconds = existingRule.IntervalSeconds
@@ -612,6 +760,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -636,6 +787,79 @@ func (g RulesGroup) SortByGroupIndex() {
 	})
 }
 
+// HasDependencies returns true if any rule of the group depends on another rule.
+func (g RulesGroup) HasDependencies() bool {
+	for _, r := range g {
+		if len(r.Dependencies) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SortByDependencies returns the rules of the group in the order they are
+// evaluated: a rule comes after the rules it depends on, and otherwise in the
+// order of the index of the rules. Dependencies on rules that are not in the
+// group are ignored. It returns ErrRuleDependencyCycle if the dependencies of
+// the rules form a cycle.
+func (g RulesGroup) SortByDependencies() (RulesGroup, error) {
+	sorted := make(RulesGroup, len(g))
+	copy(sorted, g)
+	sorted.SortByGroupIndex()
+
+	byUID := make(map[string]*AlertRule, len(sorted))
+	for _, r := range sorted {
+		if r.UID != "" {
+			byUID[r.UID] = r
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := make(map[*AlertRule]int, len(sorted))
+	result := make(RulesGroup, 0, len(sorted))
+	var path []string
+	var visit func(r *AlertRule) error
+	visit = func(r *AlertRule) error {
+		switch marks[r] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i, uid := range path {
+				if uid == r.UID {
+					start = i
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), r.UID)
+			return fmt.Errorf("%w: %s", ErrRuleDependencyCycle, strings.Join(cycle, " -> "))
+		}
+		marks[r] = visiting
+		path = append(path, r.UID)
+		for _, dep := range r.Dependencies {
+			upstream, ok := byUID[dep.RuleUID]
+			if !ok {
+				continue
+			}
+			if err := visit(upstream); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[r] = visited
+		result = append(result, r)
+		return nil
+	}
+	for _, r := range sorted {
+		if err := visit(r); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
 func SortAlertRulesByGroupIndex(rules []AlertRule) {
 	sort.Slice(rules, func(i, j int) bool {
 		if rules[i].RuleGroupIndex == rules[j].RuleGroupIndex {
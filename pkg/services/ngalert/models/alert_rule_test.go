@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -244,6 +245,12 @@ func TestPatchPartialAlertRule(t *testing.T) {
 					r.For = -1
 				},
 			},
+			{
+				name: "KeepFiringFor is -1",
+				mutator: func(r *AlertRuleWithOptionals) {
+					r.KeepFiringFor = -1
+				},
+			},
 			{
 				name: "IsPaused did not come in request",
 				mutator: func(r *AlertRuleWithOptionals) {
@@ -716,6 +723,85 @@ func TestSortByGroupIndex(t *testing.T) {
 	})
 }
 
+func TestSortByDependencies(t *testing.T) {
+	newRule := func(uid string, index int, deps ...string) *AlertRule {
+		return AlertRuleGen(WithGroupIndex(index), WithDependencies(deps...), func(r *AlertRule) { r.UID = uid })()
+	}
+	uids := func(g RulesGroup) []string {
+		result := make([]string, 0, len(g))
+		for _, r := range g {
+			result = append(result, r.UID)
+		}
+		return result
+	}
+
+	t.Run("should sort rules after the rules they depend on", func(t *testing.T) {
+		group := RulesGroup{newRule("c", 3, "b"), newRule("a", 1, "c"), newRule("b", 2), newRule("d", 4)}
+		sorted, err := group.SortByDependencies()
+		require.NoError(t, err)
+		require.Equal(t, []string{"b", "c", "a", "d"}, uids(sorted))
+		require.Equal(t, []string{"c", "a", "b", "d"}, uids(group), "the group should not be modified")
+	})
+
+	t.Run("should ignore rules that are not in the group", func(t *testing.T) {
+		group := RulesGroup{newRule("b", 2), newRule("a", 1, "unknown")}
+		sorted, err := group.SortByDependencies()
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, uids(sorted))
+	})
+
+	t.Run("should fail if dependencies form a cycle", func(t *testing.T) {
+		group := RulesGroup{newRule("a", 1, "b"), newRule("b", 2, "c"), newRule("c", 3, "b")}
+		require.True(t, group.HasDependencies())
+		_, err := group.SortByDependencies()
+		require.ErrorIs(t, err, ErrRuleDependencyCycle)
+		require.ErrorContains(t, err, "b -> c -> b")
+	})
+}
+
+func TestValidateAlertRuleDependencies(t *testing.T) {
+	cfg := setting.UnifiedAlertingSettings{BaseInterval: time.Second}
+	testCases := []struct {
+		name   string
+		deps   []RuleDependency
+		errMsg string
+	}{
+		{
+			name: "valid dependencies",
+			deps: []RuleDependency{{RuleUID: "upstream", Equal: []string{"instance"}}},
+		},
+		{
+			name:   "missing UID",
+			deps:   []RuleDependency{{}},
+			errMsg: "UID of the rule to depend on must be specified",
+		},
+		{
+			name:   "depends on itself",
+			deps:   []RuleDependency{{RuleUID: "rule"}},
+			errMsg: "rule cannot depend on itself",
+		},
+		{
+			name:   "invalid label name",
+			deps:   []RuleDependency{{RuleUID: "upstream", Equal: []string{"in-valid"}}},
+			errMsg: "invalid label name",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := AlertRuleGen()()
+			rule.UID = "rule"
+			rule.Dependencies = tc.deps
+			err := rule.ValidateAlertRule(cfg)
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
+
 func TestTimeRangeYAML(t *testing.T) {
 	yamlRaw := "from: 600\nto: 0\n"
 	var rtr RelativeTimeRange
@@ -729,3 +815,69 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestRecordingRule(t *testing.T) {
+	cfg := setting.UnifiedAlertingSettings{BaseInterval: time.Second}
+
+	t.Run("alert rules are alerting rules", func(t *testing.T) {
+		rule := AlertRuleGen()()
+		require.Equal(t, RuleTypeAlerting, rule.Type())
+		require.Equal(t, rule.Condition, rule.GetEvalCondition().Condition)
+	})
+
+	t.Run("rules with record are recording rules", func(t *testing.T) {
+		rule := AlertRuleGen(WithRecord("job:requests:rate5m"))()
+		rule.Condition = "B"
+		require.Equal(t, RuleTypeRecording, rule.Type())
+		require.Equal(t, rule.Record.From, rule.GetEvalCondition().Condition)
+		require.NoError(t, rule.ValidateAlertRule(cfg))
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		testCases := []struct {
+			name    string
+			mutator func(r *AlertRule)
+			errMsg  string
+		}{
+			{
+				name:    "invalid metric name",
+				mutator: func(r *AlertRule) { r.Record.Metric = "1metric" },
+				errMsg:  "not a valid metric name",
+			},
+			{
+				name:    "empty from",
+				mutator: func(r *AlertRule) { r.Record.From = "" },
+				errMsg:  "the query or expression to record must be specified",
+			},
+			{
+				name:    "unknown from",
+				mutator: func(r *AlertRule) { r.Record.From = "unknown" },
+				errMsg:  "the query or expression unknown to record does not exist",
+			},
+			{
+				name:    "for is set",
+				mutator: func(r *AlertRule) { r.For = time.Minute },
+				errMsg:  "field `for` is not supported by recording rules",
+			},
+			{
+				name:    "keep_firing_for is set",
+				mutator: func(r *AlertRule) { r.KeepFiringFor = time.Minute },
+				errMsg:  "field `keep_firing_for` is not supported by recording rules",
+			},
+			{
+				name:    "notification_settings is set",
+				mutator: func(r *AlertRule) { r.NotificationSettings = &NotificationSettings{Receiver: "receiver"} },
+				errMsg:  "field `notification_settings` is not supported by recording rules",
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				rule := AlertRuleGen(WithRecord("job:requests:rate5m"))()
+				tc.mutator(rule)
+				err := rule.ValidateAlertRule(cfg)
+				require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+				require.ErrorContains(t, err, tc.errMsg)
+			})
+		}
+	})
+}
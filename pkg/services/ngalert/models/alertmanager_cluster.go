@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	// AlertmanagerClusterMessageUpdate is a message that contains a partial update of the state of a single key,
+	// for example a silence or an entry of the notification log.
+	AlertmanagerClusterMessageUpdate = "update"
+	// AlertmanagerClusterMessageFullState is a message that contains the full state of a peer.
+	AlertmanagerClusterMessageFullState = "full_state"
+)
+
+// AlertmanagerClusterPeer is a Grafana instance that is a member of the database-backed Alertmanager cluster.
+type AlertmanagerClusterPeer struct {
+	ID        int64     `xorm:"pk autoincr 'id'"`
+	Name      string    `xorm:"name"`
+	Heartbeat time.Time `xorm:"heartbeat"`
+}
+
+func (p *AlertmanagerClusterPeer) TableName() string {
+	return "alertmanager_cluster_peer"
+}
+
+// AlertmanagerClusterMessage is a message sent by a peer of the database-backed Alertmanager cluster to all other peers.
+type AlertmanagerClusterMessage struct {
+	ID          int64     `xorm:"pk autoincr 'id'"`
+	Peer        string    `xorm:"peer"`
+	MessageType string    `xorm:"message_type"`
+	Payload     []byte    `xorm:"payload"`
+	CreatedAt   time.Time `xorm:"created_at"`
+}
+
+func (m *AlertmanagerClusterMessage) TableName() string {
+	return "alertmanager_cluster_message"
+}
+
+// GetAlertmanagerClusterMessagesQuery is the query for messages of the database-backed Alertmanager cluster.
+type GetAlertmanagerClusterMessagesQuery struct {
+	// AfterID returns only messages with ID greater than AfterID.
+	AfterID int64
+	// ExcludePeer excludes the messages sent by the peer.
+	ExcludePeer string
+	// MessageType returns only messages of the type, if not empty.
+	MessageType string
+	// Limit is the maximum number of messages to return, if greater than zero.
+	Limit int
+}
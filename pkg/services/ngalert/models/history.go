@@ -18,3 +18,38 @@ type HistoryQuery struct {
 	Limit        int
 	SignedInUser identity.Requester
 }
+
+// HistoryTransitionsQuery represents a query for the state transitions of alert instances of one or many rules.
+type HistoryTransitionsQuery struct {
+	OrgID int64
+	// RuleUIDs limits the transitions to the given rules. Backends that can only be queried rule by rule require it.
+	RuleUIDs []string
+	Labels   map[string]string
+	From     time.Time
+	To       time.Time
+	// Limit is the maximum number of transitions to return. The most recent transitions are returned if the state
+	// history holds more transitions in the time range.
+	Limit        int
+	SignedInUser identity.Requester
+}
+
+// HistoryTransitions are the state transitions returned for a HistoryTransitionsQuery, sorted by time.
+type HistoryTransitions struct {
+	Transitions []HistoryTransition
+	// Truncated is true if the limit of the query was reached. Older transitions in the time range can be missing.
+	Truncated bool
+}
+
+// HistoryTransition is a single state transition of an alert instance read from the state history.
+type HistoryTransition struct {
+	Time           time.Time
+	RuleUID        string
+	Fingerprint    string
+	Labels         map[string]string
+	Previous       string
+	PreviousReason string
+	Current        string
+	CurrentReason  string
+	AcknowledgedBy string
+	Assignee       string
+}
@@ -14,6 +14,9 @@ type AlertInstance struct {
 	CurrentStateSince time.Time
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
+	// FlapTransitions are the times of the recent transitions of the instance
+	// between firing and not firing, used to detect flapping instances.
+	FlapTransitions []time.Time
 }
 
 type AlertInstanceKey struct {
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAlertInstanceNotFound is returned when the alert instance does not exist.
+	ErrAlertInstanceNotFound = errors.New("alert instance not found")
+	// ErrAlertInstanceNormal is returned when an alert instance in the Normal state is acknowledged.
+	ErrAlertInstanceNormal = errors.New("alert instances in the Normal state cannot be acknowledged")
+)
+
+// AlertInstanceAcknowledgement records the human response to an alert instance: who acknowledged it, who owns it,
+// and the comments about it. It is stored separately from AlertInstance because it is changed by users rather than
+// by the evaluation of the rule.
+type AlertInstanceAcknowledgement struct {
+	AlertInstanceKey `xorm:"extends"`
+	// AcknowledgedBy is the login of the user who acknowledged the instance. It is empty if the instance is not
+	// acknowledged. The acknowledgement is cleared when the instance leaves the Normal state, so that it only applies
+	// to the alert that was acknowledged.
+	AcknowledgedBy string
+	AcknowledgedAt time.Time
+	// Assignee is the login of the user who owns the instance.
+	Assignee string
+	Comments []AlertInstanceComment
+	Updated  time.Time
+}
+
+// AlertInstanceComment is a comment about an alert instance.
+type AlertInstanceComment struct {
+	Author  string    `json:"author"`
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+}
+
+// IsAcknowledged returns true if the instance is acknowledged.
+func (a *AlertInstanceAcknowledgement) IsAcknowledged() bool {
+	return a != nil && a.AcknowledgedBy != ""
+}
+
+// IsEmpty returns true if the instance is neither acknowledged nor assigned and does not have comments.
+func (a *AlertInstanceAcknowledgement) IsEmpty() bool {
+	return a == nil || (a.AcknowledgedBy == "" && a.Assignee == "" && len(a.Comments) == 0)
+}
+
+// Copy returns a deep copy of the acknowledgement.
+func (a *AlertInstanceAcknowledgement) Copy() *AlertInstanceAcknowledgement {
+	if a == nil {
+		return nil
+	}
+	result := *a
+	if a.Comments != nil {
+		result.Comments = make([]AlertInstanceComment, len(a.Comments))
+		copy(result.Comments, a.Comments)
+	}
+	return &result
+}
+
+// UpdateAlertInstanceAcknowledgementCommand changes the acknowledgement of an alert instance on behalf of User.
+type UpdateAlertInstanceAcknowledgementCommand struct {
+	OrgID   int64
+	RuleUID string
+	// Labels identify the instance of the rule.
+	Labels InstanceLabels
+	User   string
+	// Acknowledged acknowledges the instance if true, and removes the acknowledgement if false.
+	// The acknowledgement does not change if it is nil.
+	Acknowledged *bool
+	// Assignee assigns the instance to a user, or unassigns it if empty. The assignee does not change if it is nil.
+	Assignee *string
+	// Comment is added to the comments of the instance if it is not empty.
+	Comment string
+}
+
+// ListAlertInstanceAcknowledgementsQuery is the query for the acknowledgements of the alert instances of an organization.
+type ListAlertInstanceAcknowledgementsQuery struct {
+	RuleOrgID int64
+	RuleUID   string
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// AutogeneratedRouteLabel is a label that marks the alerts of the rules that have notification settings. Such alerts
+	// are routed by the auto-generated routes and bypass the notification policy tree defined by users.
+	AutogeneratedRouteLabel = "__grafana_autogenerated__"
+	// AutogeneratedRouteReceiverNameLabel is a label that contains the name of the receiver of the notification settings.
+	AutogeneratedRouteReceiverNameLabel = "__grafana_receiver__"
+	// AutogeneratedRouteSettingsHashLabel is a label that contains the fingerprint of the notification settings. It is
+	// used to route the alert to the auto-generated route with the same settings.
+	AutogeneratedRouteSettingsHashLabel = "__grafana_route_settings_hash__"
+
+	// GroupByAll is a special value of group by that groups alerts by all labels.
+	GroupByAll = "..."
+)
+
+var (
+	// DefaultNotificationSettingsGroupBy are the labels that are required in the group by of notification settings,
+	// so that alerts of different rules are not grouped together.
+	DefaultNotificationSettingsGroupBy = []string{FolderTitleLabel, model.AlertNameLabel}
+)
+
+// NotificationSettings are the settings of notifications of an alert rule. The alerts of a rule that has notification
+// settings are sent to the receiver directly, bypassing the notification policy tree of the organization. Timings that
+// are not specified are inherited from the root notification policy. If group by is not specified, the alerts are
+// grouped by DefaultNotificationSettingsGroupBy.
+type NotificationSettings struct {
+	Receiver          string          `json:"receiver"`
+	GroupBy           []string        `json:"group_by,omitempty"`
+	GroupWait         *model.Duration `json:"group_wait,omitempty"`
+	GroupInterval     *model.Duration `json:"group_interval,omitempty"`
+	RepeatInterval    *model.Duration `json:"repeat_interval,omitempty"`
+	MuteTimeIntervals []string        `json:"mute_time_intervals,omitempty"`
+}
+
+// Validate checks that the settings are consistent. It does not check that the receiver and mute timings exist.
+func (s *NotificationSettings) Validate() error {
+	if s.Receiver == "" {
+		return errors.New("receiver must be specified")
+	}
+	if len(s.GroupBy) > 0 {
+		seen := make(map[string]struct{}, len(s.GroupBy))
+		for _, name := range s.GroupBy {
+			if name == GroupByAll {
+				if len(s.GroupBy) > 1 {
+					return fmt.Errorf("group by '%s' must be the only element of group by", GroupByAll)
+				}
+				return s.validateTimings()
+			}
+			if !model.LabelName(name).IsValid() {
+				return fmt.Errorf("invalid label name %q in group by", name)
+			}
+			seen[name] = struct{}{}
+		}
+		for _, name := range DefaultNotificationSettingsGroupBy {
+			if _, ok := seen[name]; !ok {
+				return fmt.Errorf("group by must contain the label '%s'", name)
+			}
+		}
+	}
+	return s.validateTimings()
+}
+
+func (s *NotificationSettings) validateTimings() error {
+	if s.GroupWait != nil && *s.GroupWait < 0 {
+		return errors.New("group wait cannot be negative")
+	}
+	if s.GroupInterval != nil && *s.GroupInterval <= 0 {
+		return errors.New("group interval must be positive")
+	}
+	if s.RepeatInterval != nil && *s.RepeatInterval <= 0 {
+		return errors.New("repeat interval must be positive")
+	}
+	return nil
+}
+
+// ToLabels returns the labels that route the alerts of the rule to the auto-generated route of the settings.
+func (s *NotificationSettings) ToLabels() data.Labels {
+	return data.Labels{
+		AutogeneratedRouteLabel:             "true",
+		AutogeneratedRouteReceiverNameLabel: s.Receiver,
+		AutogeneratedRouteSettingsHashLabel: s.Fingerprint().String(),
+	}
+}
+
+// Fingerprint returns a fingerprint of the settings. Settings that route alerts in the same way have the same
+// fingerprint, regardless of the order of labels in group by and of mute timings.
+func (s *NotificationSettings) Fingerprint() data.Fingerprint {
+	h := fnv.New64()
+	tmp := make([]byte, 8)
+
+	writeString := func(s string) {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{255})
+	}
+	writeStrings := func(values []string) {
+		sorted := make([]string, len(values))
+		copy(sorted, values)
+		sort.Strings(sorted)
+		for _, v := range sorted {
+			writeString(v)
+		}
+		_, _ = h.Write([]byte{254})
+	}
+	writeDuration := func(d *model.Duration) {
+		if d == nil {
+			_, _ = h.Write([]byte{253})
+			return
+		}
+		binary.LittleEndian.PutUint64(tmp, uint64(time.Duration(*d)))
+		_, _ = h.Write(tmp)
+	}
+
+	writeString(s.Receiver)
+	writeStrings(s.GroupBy)
+	writeDuration(s.GroupWait)
+	writeDuration(s.GroupInterval)
+	writeDuration(s.RepeatInterval)
+	writeStrings(s.MuteTimeIntervals)
+	return data.Fingerprint(h.Sum64())
+}
+
+// ListNotificationSettingsQuery is the query for listing notification settings of alert rules.
+type ListNotificationSettingsQuery struct {
+	OrgID int64
+	// ReceiverName filters the settings by receiver. All settings are returned if it is empty.
+	ReceiverName string
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationSettingsValidate(t *testing.T) {
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+
+	testCases := []struct {
+		name     string
+		settings NotificationSettings
+		err      string
+	}{
+		{
+			name:     "only receiver",
+			settings: NotificationSettings{Receiver: "receiver"},
+		},
+		{
+			name: "all fields",
+			settings: NotificationSettings{
+				Receiver:          "receiver",
+				GroupBy:           []string{FolderTitleLabel, model.AlertNameLabel, "cluster"},
+				GroupWait:         duration(0),
+				GroupInterval:     duration(time.Minute),
+				RepeatInterval:    duration(time.Hour),
+				MuteTimeIntervals: []string{"weekends"},
+			},
+		},
+		{
+			name:     "group by all",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{GroupByAll}},
+		},
+		{
+			name:     "empty receiver",
+			settings: NotificationSettings{},
+			err:      "receiver must be specified",
+		},
+		{
+			name:     "group by all with other labels",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{GroupByAll, "cluster"}},
+			err:      "must be the only element",
+		},
+		{
+			name:     "group by without default labels",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{model.AlertNameLabel, "cluster"}},
+			err:      "group by must contain the label 'grafana_folder'",
+		},
+		{
+			name:     "group by with invalid label",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{FolderTitleLabel, model.AlertNameLabel, "1-invalid"}},
+			err:      "invalid label name",
+		},
+		{
+			name:     "negative group wait",
+			settings: NotificationSettings{Receiver: "receiver", GroupWait: duration(-time.Second)},
+			err:      "group wait cannot be negative",
+		},
+		{
+			name:     "zero group interval",
+			settings: NotificationSettings{Receiver: "receiver", GroupInterval: duration(0)},
+			err:      "group interval must be positive",
+		},
+		{
+			name:     "zero repeat interval",
+			settings: NotificationSettings{Receiver: "receiver", RepeatInterval: duration(0)},
+			err:      "repeat interval must be positive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestNotificationSettingsFingerprint(t *testing.T) {
+	wait := model.Duration(30 * time.Second)
+	settings := NotificationSettings{
+		Receiver:          "receiver",
+		GroupBy:           []string{FolderTitleLabel, model.AlertNameLabel, "cluster"},
+		GroupWait:         &wait,
+		MuteTimeIntervals: []string{"weekends", "nights"},
+	}
+
+	t.Run("should not depend on the order of group by and mute timings", func(t *testing.T) {
+		reordered := settings
+		reordered.GroupBy = []string{"cluster", model.AlertNameLabel, FolderTitleLabel}
+		reordered.MuteTimeIntervals = []string{"nights", "weekends"}
+		require.Equal(t, settings.Fingerprint(), reordered.Fingerprint())
+		// the slices of the settings must not be sorted in place.
+		require.Equal(t, []string{"cluster", model.AlertNameLabel, FolderTitleLabel}, reordered.GroupBy)
+	})
+
+	t.Run("should change if any field changes", func(t *testing.T) {
+		fp := settings.Fingerprint()
+		otherWait := model.Duration(time.Minute)
+		interval := model.Duration(time.Minute)
+		changes := []func(s *NotificationSettings){
+			func(s *NotificationSettings) { s.Receiver = "other" },
+			func(s *NotificationSettings) { s.GroupBy = []string{GroupByAll} },
+			func(s *NotificationSettings) { s.GroupWait = &otherWait },
+			func(s *NotificationSettings) { s.GroupWait = nil },
+			func(s *NotificationSettings) { s.GroupInterval = &interval },
+			func(s *NotificationSettings) { s.RepeatInterval = &interval },
+			func(s *NotificationSettings) { s.MuteTimeIntervals = nil },
+		}
+		for i, change := range changes {
+			changed := *CopyNotificationSettings(settings)
+			change(&changed)
+			require.NotEqualf(t, fp, changed.Fingerprint(), "change %d did not change the fingerprint", i)
+		}
+	})
+
+	t.Run("should not collide when durations move between fields", func(t *testing.T) {
+		a := NotificationSettings{Receiver: "receiver", GroupInterval: &wait}
+		b := NotificationSettings{Receiver: "receiver", RepeatInterval: &wait}
+		require.NotEqual(t, a.Fingerprint(), b.Fingerprint())
+	})
+}
+
+func TestNotificationSettingsToLabels(t *testing.T) {
+	settings := NotificationSettings{Receiver: "receiver"}
+	lbls := settings.ToLabels()
+	require.Equal(t, "true", lbls[AutogeneratedRouteLabel])
+	require.Equal(t, "receiver", lbls[AutogeneratedRouteReceiverNameLabel])
+	require.Equal(t, settings.Fingerprint().String(), lbls[AutogeneratedRouteSettingsHashLabel])
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrSilenceTemplateNotFound is returned when the silence template does not exist.
+	ErrSilenceTemplateNotFound = errors.New("silence template not found")
+	// ErrRecurringSilenceNotFound is returned when the recurring silence does not exist.
+	ErrRecurringSilenceNotFound = errors.New("recurring silence not found")
+)
+
+// SilenceMatcher matches the label with the name of an alert. The type is one of "=", "!=", "=~" and "!~".
+type SilenceMatcher struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Matcher returns the label matcher.
+func (m SilenceMatcher) Matcher() (*labels.Matcher, error) {
+	var t labels.MatchType
+	switch m.Type {
+	case labels.MatchEqual.String():
+		t = labels.MatchEqual
+	case labels.MatchNotEqual.String():
+		t = labels.MatchNotEqual
+	case labels.MatchRegexp.String():
+		t = labels.MatchRegexp
+	case labels.MatchNotRegexp.String():
+		t = labels.MatchNotRegexp
+	default:
+		return nil, fmt.Errorf("unsupported match type %q in matcher", m.Type)
+	}
+	if m.Name == "" {
+		return nil, errors.New("matcher must have a label name")
+	}
+	return labels.NewMatcher(t, m.Name, m.Value)
+}
+
+type SilenceMatchers []SilenceMatcher
+
+// Validate checks that all matchers are valid.
+func (m SilenceMatchers) Validate() error {
+	for _, matcher := range m {
+		if _, err := matcher.Matcher(); err != nil {
+			return fmt.Errorf("invalid matcher %s%s%q: %w", matcher.Name, matcher.Type, matcher.Value, err)
+		}
+	}
+	return nil
+}
+
+// SilenceTemplate is a reusable definition of a silence.
+type SilenceTemplate struct {
+	ID       int64           `xorm:"pk autoincr 'id'"`
+	OrgID    int64           `xorm:"org_id"`
+	UID      string          `xorm:"uid"`
+	Name     string          `xorm:"name"`
+	Matchers SilenceMatchers `xorm:"matchers json"`
+	// Duration is the default duration of silences created from the template.
+	Duration time.Duration `xorm:"duration"`
+	Comment  string        `xorm:"comment"`
+	Updated  time.Time     `xorm:"updated"`
+}
+
+func (t *SilenceTemplate) TableName() string {
+	return "alert_silence_template"
+}
+
+func (t *SilenceTemplate) ResourceType() string {
+	return "silenceTemplate"
+}
+
+func (t *SilenceTemplate) ResourceID() string {
+	return t.UID
+}
+
+func (t *SilenceTemplate) Validate() error {
+	if t.Name == "" {
+		return errors.New("name must be specified")
+	}
+	if len(t.Matchers) == 0 {
+		return errors.New("at least one matcher must be specified")
+	}
+	if err := t.Matchers.Validate(); err != nil {
+		return err
+	}
+	if t.Duration <= 0 {
+		return errors.New("duration must be greater than 0")
+	}
+	return nil
+}
+
+// RecurringSilence is a silence that repeats on a schedule. The occurrences of the schedule are created as silences
+// ahead of time. The schedule is either a cron expression or a mute timing of the organization, in which case a silence
+// is created for each continuous range of time of the mute timing.
+type RecurringSilence struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	OrgID int64  `xorm:"org_id"`
+	UID   string `xorm:"uid"`
+	Name  string `xorm:"name"`
+	// TemplateUID is the UID of the silence template that provides the matchers, the duration and the comment
+	// when they are not specified.
+	TemplateUID string          `xorm:"template_uid"`
+	Matchers    SilenceMatchers `xorm:"matchers json"`
+	Comment     string          `xorm:"comment"`
+	// Schedule is a standard cron expression for the start of silences, evaluated in Location.
+	Schedule string `xorm:"schedule"`
+	// Location is the name of the time zone of the schedule. UTC is used if it is empty.
+	Location string `xorm:"location"`
+	// Duration is the duration of the silences created from Schedule. The duration of the template is used if it is zero.
+	Duration time.Duration `xorm:"duration"`
+	// MuteTimeInterval is the name of the mute timing that defines the schedule.
+	MuteTimeInterval string `xorm:"mute_time_interval"`
+	// MaterializedUntil is the time in Unix seconds until which the occurrences were created as silences.
+	MaterializedUntil int64     `xorm:"materialized_until"`
+	Updated           time.Time `xorm:"updated"`
+}
+
+func (s *RecurringSilence) TableName() string {
+	return "alert_recurring_silence"
+}
+
+func (s *RecurringSilence) ResourceType() string {
+	return "recurringSilence"
+}
+
+func (s *RecurringSilence) ResourceID() string {
+	return s.UID
+}
+
+// Validate checks that the recurring silence is consistent. It does not check that the template and the mute timing exist.
+func (s *RecurringSilence) Validate() error {
+	if s.Name == "" {
+		return errors.New("name must be specified")
+	}
+	if err := s.Matchers.Validate(); err != nil {
+		return err
+	}
+	if len(s.Matchers) == 0 && s.TemplateUID == "" {
+		return errors.New("either matchers or a template must be specified")
+	}
+	if s.Duration < 0 {
+		return errors.New("duration must not be negative")
+	}
+	switch {
+	case s.Schedule != "" && s.MuteTimeInterval != "":
+		return errors.New("schedule and mute timing cannot both be specified")
+	case s.Schedule != "":
+		if _, err := s.CronSchedule(); err != nil {
+			return err
+		}
+		if _, err := s.TimeLocation(); err != nil {
+			return err
+		}
+		if s.Duration == 0 && s.TemplateUID == "" {
+			return errors.New("duration must be specified if the silence has a schedule and no template")
+		}
+	case s.MuteTimeInterval != "":
+		if s.Location != "" {
+			return errors.New("location cannot be specified for a mute timing, the time zone of the mute timing is used")
+		}
+		if s.Duration != 0 {
+			return errors.New("duration cannot be specified for a mute timing, the silences last as long as the time ranges of the mute timing")
+		}
+	default:
+		return errors.New("either a schedule or a mute timing must be specified")
+	}
+	return nil
+}
+
+// CronSchedule parses the schedule.
+func (s *RecurringSilence) CronSchedule() (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(s.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// TimeLocation returns the time zone of the schedule.
+func (s *RecurringSilence) TimeLocation() (*time.Location, error) {
+	if s.Location == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.Location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid location: %w", err)
+	}
+	return loc, nil
+}
+
+// ListRecurringSilencesQuery is the query for recurring silences. Recurring silences of all organizations are returned
+// if OrgID is zero.
+type ListRecurringSilencesQuery struct {
+	OrgID       int64
+	TemplateUID string
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -202,6 +203,12 @@ func WithForNTimes(timesOfInterval int64) AlertRuleMutator {
 	}
 }
 
+func WithKeepFiringFor(duration time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.KeepFiringFor = duration
+	}
+}
+
 func WithNoDataExecAs(nodata NoDataState) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.NoDataState = nodata
@@ -267,6 +274,37 @@ func WithQuery(query ...AlertQuery) AlertRuleMutator {
 	}
 }
 
+// WithRecord makes the rule a recording rule that records the results of its first query as the given metric.
+func WithRecord(metric string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		from := rule.Condition
+		if len(rule.Data) > 0 {
+			from = rule.Data[0].RefID
+		}
+		rule.Record = &Record{Metric: metric, From: from}
+		rule.For = 0
+		rule.KeepFiringFor = 0
+		rule.NotificationSettings = nil
+	}
+}
+
+// WithNotificationSettings makes the rule send its alerts directly to the receiver of the settings.
+func WithNotificationSettings(settings NotificationSettings) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.NotificationSettings = &settings
+	}
+}
+
+// WithDependencies makes the rule depend on the given rules.
+func WithDependencies(ruleUIDs ...string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Dependencies = make([]RuleDependency, 0, len(ruleUIDs))
+		for _, uid := range ruleUIDs {
+			rule.Dependencies = append(rule.Dependencies, RuleDependency{RuleUID: uid})
+		}
+	}
+}
+
 func WithGroupKey(groupKey AlertRuleGroupKey) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.RuleGroup = groupKey.RuleGroup
@@ -367,6 +405,7 @@ func CopyRule(r *AlertRule) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		KeepFiringFor:   r.KeepFiringFor,
 	}
 
 	if r.DashboardUID != nil {
@@ -404,6 +443,47 @@ func CopyRule(r *AlertRule) *AlertRule {
 		}
 	}
 
+	if r.Record != nil {
+		record := *r.Record
+		result.Record = &record
+	}
+
+	if r.Dependencies != nil {
+		result.Dependencies = make([]RuleDependency, 0, len(r.Dependencies))
+		for _, dep := range r.Dependencies {
+			result.Dependencies = append(result.Dependencies, RuleDependency{
+				RuleUID: dep.RuleUID,
+				Equal:   append([]string(nil), dep.Equal...),
+			})
+		}
+	}
+
+	if r.NotificationSettings != nil {
+		result.NotificationSettings = CopyNotificationSettings(*r.NotificationSettings)
+	}
+
+	return &result
+}
+
+// CopyNotificationSettings creates a deep copy of NotificationSettings.
+func CopyNotificationSettings(ns NotificationSettings) *NotificationSettings {
+	result := NotificationSettings{Receiver: ns.Receiver}
+	if ns.GroupBy != nil {
+		result.GroupBy = append([]string(nil), ns.GroupBy...)
+	}
+	if ns.MuteTimeIntervals != nil {
+		result.MuteTimeIntervals = append([]string(nil), ns.MuteTimeIntervals...)
+	}
+	copyDuration := func(d *model.Duration) *model.Duration {
+		if d == nil {
+			return nil
+		}
+		c := *d
+		return &c
+	}
+	result.GroupWait = copyDuration(ns.GroupWait)
+	result.GroupInterval = copyDuration(ns.GroupInterval)
+	result.RepeatInterval = copyDuration(ns.RepeatInterval)
 	return &result
 }
 
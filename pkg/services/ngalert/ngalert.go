@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
@@ -39,6 +40,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -149,6 +151,7 @@ type AlertNG struct {
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	AlertsRouter         *sender.AlertsRouter
+	recurringSilences    *notifier.RecurringSilenceMaterializer
 	accesscontrol        accesscontrol.AccessControl
 	accesscontrolService accesscontrol.Service
 	annotationsRepo      annotations.Repository
@@ -184,6 +187,9 @@ func (ng *AlertNG) init() error {
 
 		overrides = append(overrides, override)
 	}
+	if ng.Cfg.UnifiedAlerting.HADatabaseEnabled {
+		overrides = append(overrides, notifier.WithClusterStore(ng.store, serverlock.ProvideService(ng.SQLStore, ng.tracer)))
+	}
 	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, ng.store, ng.store, ng.KVStore, ng.store, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"), ng.SecretsService, overrides...)
 	if err != nil {
 		return err
@@ -217,6 +223,7 @@ func (ng *AlertNG) init() error {
 	}
 
 	ng.AlertsRouter = alertsRouter
+	ng.recurringSilences = notifier.NewRecurringSilenceMaterializer(ng.store, ng.MultiOrgAlertmanager, clk, log.New("ngalert.recurring-silences"))
 
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
 	schedCfg := schedule.SchedulerCfg{
@@ -245,6 +252,8 @@ func (ng *AlertNG) init() error {
 		Metrics:                        ng.Metrics.GetStateMetrics(),
 		ExternalURL:                    appUrl,
 		InstanceStore:                  ng.store,
+		AcknowledgementStore:           ng.store,
+		SyncAcknowledgements:           len(ng.Cfg.UnifiedAlerting.HAPeers) > 0 || ng.Cfg.UnifiedAlerting.HARedisAddr != "",
 		Images:                         ng.ImageService,
 		Clock:                          clk,
 		Historian:                      history,
@@ -254,7 +263,20 @@ func (ng *AlertNG) init() error {
 		Tracer:                         ng.tracer,
 		Log:                            log.New("ngalert.state.manager"),
 	}
+	if flapDetection := ng.Cfg.UnifiedAlerting.FlapDetection; flapDetection.Enabled {
+		cfg.FlapDetectionWindow = flapDetection.Window
+		cfg.FlapDetectionTransitions = flapDetection.Transitions
+	}
 	stateManager := state.NewManager(cfg)
+
+	if ng.Cfg.UnifiedAlerting.RecordingRules.Enabled {
+		recordingCfg := ng.Cfg.UnifiedAlerting.RecordingRules
+		recordingWriter, err := writer.New(recordingCfg, writer.NewRequester(recordingCfg.Timeout), ng.Metrics.GetRemoteWriterMetrics())
+		if err != nil {
+			return fmt.Errorf("invalid recording rules configuration: %w", err)
+		}
+		schedCfg.RecordingWriter = recordingWriter
+	}
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
 
 	// if it is required to include folder title to the alerts, we need to subscribe to changes of alert title
@@ -273,6 +295,7 @@ func (ng *AlertNG) init() error {
 	alertRuleService := provisioning.NewAlertRuleService(ng.store, ng.store, ng.dashboardService, ng.QuotaService, ng.store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
+	silenceService := provisioning.NewSilenceService(ng.store, ng.store, ng.store, ng.store, ng.Log)
 
 	ng.api = &api.API{
 		Cfg:                  ng.Cfg,
@@ -294,8 +317,10 @@ func (ng *AlertNG) init() error {
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		Silences:             silenceService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
+		ExpressionService:    ng.ExpressionService,
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
@@ -353,6 +378,9 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.recurringSilences.Run(subCtx)
+	})
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -442,6 +470,21 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		}
 		return backend, nil
 	}
+	if backend == historian.BackendTypeInflux {
+		icfg, err := historian.NewInfluxConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote influxdb configuration: %w", err)
+		}
+		req := historian.NewRequester()
+		backend := historian.NewInfluxBackend(icfg, req, met)
+
+		testConnCtx, cancelFunc := context.WithTimeout(ctx, 10*time.Second)
+		defer cancelFunc()
+		if err := backend.TestConnection(testConnCtx); err != nil {
+			l.Error("Failed to communicate with configured remote InfluxDB backend, state history may not be persisted", "error", err)
+		}
+		return backend, nil
+	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
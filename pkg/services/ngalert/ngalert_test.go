@@ -121,6 +121,38 @@ func TestConfigureHistorianBackend(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("do not fail initialization if pinging InfluxDB fails", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		logger := log.NewNopLogger()
+		cfg := setting.UnifiedAlertingStateHistorySettings{
+			Enabled:        true,
+			Backend:        "influxdb",
+			InfluxURL:      "http://gone.invalid",
+			InfluxVersion:  "1",
+			InfluxDatabase: "alerting",
+		}
+
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+
+		require.NotNil(t, h)
+		require.NoError(t, err)
+	})
+
+	t.Run("fail initialization if InfluxDB is misconfigured", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		logger := log.NewNopLogger()
+		cfg := setting.UnifiedAlertingStateHistorySettings{
+			Enabled:       true,
+			Backend:       "influxdb",
+			InfluxURL:     "http://gone.invalid",
+			InfluxVersion: "2",
+		}
+
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger)
+
+		require.ErrorContains(t, err, "invalid remote influxdb configuration")
+	})
+
 	t.Run("emit metric describing chosen backend", func(t *testing.T) {
 		reg := prometheus.NewRegistry()
 		met := metrics.NewHistorianMetrics(reg, metrics.Subsystem)
@@ -42,6 +42,7 @@ var silenceMaintenanceInterval = 15 * time.Minute
 type AlertingStore interface {
 	store.AlertingStore
 	store.ImageStore
+	autogenRuleStore
 }
 
 type alertmanager struct {
@@ -179,7 +180,7 @@ func (am *alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
+			_, err := am.applyConfig(ctx, cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration), true)
 			return err
 		})
 		if err != nil {
@@ -209,7 +210,8 @@ func (am *alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.P
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, rawConfig)
+			// the configuration is rejected if it breaks the notification settings of alert rules.
+			_, err := am.applyConfig(ctx, cfg, rawConfig, false)
 			return err
 		})
 		if err != nil {
@@ -279,9 +281,21 @@ func (am *alertmanager) aggregateInhibitMatchers(rules []config.InhibitRule, amu
 }
 
 // applyConfig applies a new configuration by re-initializing all components using the configuration provided.
+// The routes for notification settings of alert rules are added to the configuration before it is applied.
+// If skipInvalid is false, the configuration is not applied if any of the notification settings are invalid.
 // It returns a boolean indicating whether the user config was changed and an error.
 // It is not safe to call concurrently.
-func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig []byte) (bool, error) {
+func (am *alertmanager) applyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig, rawConfig []byte, skipInvalid bool) (bool, error) {
+	route := cfg.AlertmanagerConfig.Route
+	if err := AddAutogenConfig(ctx, am.logger, am.Store, am.orgID, &cfg.AlertmanagerConfig, skipInvalid); err != nil {
+		return false, err
+	}
+	if cfg.AlertmanagerConfig.Route != route {
+		// the raw configuration does not include the auto-generated routes, and would not change when only the
+		// notification settings of rules change.
+		rawConfig = nil
+	}
+
 	// First, let's make sure this config is not already loaded
 	var amConfigChanged bool
 	if rawConfig == nil {
@@ -332,7 +346,7 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 
 // applyAndMarkConfig applies a configuration and marks it as applied if no errors occur.
 func (am *alertmanager) applyAndMarkConfig(ctx context.Context, hash string, cfg *apimodels.PostableUserConfig, rawConfig []byte) error {
-	configChanged, err := am.applyConfig(cfg, rawConfig)
+	configChanged, err := am.applyConfig(ctx, cfg, rawConfig, true)
 	if err != nil {
 		return err
 	}
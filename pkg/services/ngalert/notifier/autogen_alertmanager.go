@@ -0,0 +1,158 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type autogenRuleStore interface {
+	ListNotificationSettings(ctx context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey]models.NotificationSettings, error)
+}
+
+// AddAutogenConfig creates the routes for the notification settings of the alert rules of the organization and adds them
+// to the routing tree of the configuration as the first child of the root route. The auto-generated routes do not
+// continue, and therefore the alerts of rules with notification settings bypass the notification policies defined by users.
+//
+// The generated tree has three levels:
+//   - a route that matches all alerts that have notification settings,
+//   - a route per receiver,
+//   - a route per unique combination of settings, which sets the grouping, timings and mute timings.
+//
+// If skipInvalid is true, settings that are invalid or reference receivers or mute timings that do not exist in the
+// configuration are skipped. Otherwise, an error is returned.
+func AddAutogenConfig(ctx context.Context, logger log.Logger, store autogenRuleStore, orgID int64, cfg *apimodels.PostableApiAlertingConfig, skipInvalid bool) error {
+	if cfg.Route == nil {
+		return nil
+	}
+	settings, err := store.ListNotificationSettings(ctx, models.ListNotificationSettingsQuery{OrgID: orgID})
+	if err != nil {
+		return fmt.Errorf("failed to list notification settings of alert rules: %w", err)
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+
+	receivers := make(map[string]struct{}, len(cfg.Receivers))
+	for _, r := range cfg.Receivers {
+		receivers[r.Name] = struct{}{}
+	}
+	muteTimings := make(map[string]struct{}, len(cfg.MuteTimeIntervals))
+	for _, mt := range cfg.MuteTimeIntervals {
+		muteTimings[mt.Name] = struct{}{}
+	}
+
+	// many rules can have the same settings, therefore the settings are deduplicated by their fingerprint.
+	unique := make(map[string]map[data.Fingerprint]models.NotificationSettings)
+	for key, s := range settings {
+		if err := validateAutogenSettings(s, receivers, muteTimings); err != nil {
+			if skipInvalid {
+				logger.Warn("Skipping invalid notification settings of alert rule", "rule_uid", key.UID, "error", err)
+				continue
+			}
+			return fmt.Errorf("invalid notification settings of alert rule %s: %w", key.UID, err)
+		}
+		byFingerprint, ok := unique[s.Receiver]
+		if !ok {
+			byFingerprint = make(map[data.Fingerprint]models.NotificationSettings)
+			unique[s.Receiver] = byFingerprint
+		}
+		byFingerprint[s.Fingerprint()] = s
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	autogenRoute := &apimodels.Route{
+		Receiver:       cfg.Route.Receiver,
+		ObjectMatchers: apimodels.ObjectMatchers{mustEqualMatcher(models.AutogeneratedRouteLabel, "true")},
+		Routes:         make([]*apimodels.Route, 0, len(unique)),
+	}
+	receiverNames := make([]string, 0, len(unique))
+	for name := range unique {
+		receiverNames = append(receiverNames, name)
+	}
+	sort.Strings(receiverNames)
+	for _, name := range receiverNames {
+		byFingerprint := unique[name]
+		fingerprints := make([]data.Fingerprint, 0, len(byFingerprint))
+		for fp := range byFingerprint {
+			fingerprints = append(fingerprints, fp)
+		}
+		sort.Slice(fingerprints, func(i, j int) bool { return fingerprints[i] < fingerprints[j] })
+
+		receiverRoute := &apimodels.Route{
+			Receiver:       name,
+			ObjectMatchers: apimodels.ObjectMatchers{mustEqualMatcher(models.AutogeneratedRouteReceiverNameLabel, name)},
+			Routes:         make([]*apimodels.Route, 0, len(fingerprints)),
+		}
+		for _, fp := range fingerprints {
+			receiverRoute.Routes = append(receiverRoute.Routes, newSettingsRoute(byFingerprint[fp], fp))
+		}
+		autogenRoute.Routes = append(autogenRoute.Routes, receiverRoute)
+	}
+
+	// copy the root route so the routes of the caller's configuration are not modified.
+	root := *cfg.Route
+	root.Routes = append([]*apimodels.Route{autogenRoute}, cfg.Route.Routes...)
+	cfg.Route = &root
+	return nil
+}
+
+func validateAutogenSettings(s models.NotificationSettings, receivers, muteTimings map[string]struct{}) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if _, ok := receivers[s.Receiver]; !ok {
+		return fmt.Errorf("receiver '%s' does not exist", s.Receiver)
+	}
+	for _, mt := range s.MuteTimeIntervals {
+		if _, ok := muteTimings[mt]; !ok {
+			return fmt.Errorf("mute time interval '%s' does not exist", mt)
+		}
+	}
+	return nil
+}
+
+func newSettingsRoute(s models.NotificationSettings, fp data.Fingerprint) *apimodels.Route {
+	groupBy := s.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = models.DefaultNotificationSettingsGroupBy
+	}
+	route := &apimodels.Route{
+		Receiver:          s.Receiver,
+		ObjectMatchers:    apimodels.ObjectMatchers{mustEqualMatcher(models.AutogeneratedRouteSettingsHashLabel, fp.String())},
+		GroupByStr:        append([]string(nil), groupBy...),
+		GroupWait:         s.GroupWait,
+		GroupInterval:     s.GroupInterval,
+		RepeatInterval:    s.RepeatInterval,
+		MuteTimeIntervals: s.MuteTimeIntervals,
+	}
+	// GroupBy and GroupByAll are not serialized and are populated when the configuration is parsed.
+	for _, l := range groupBy {
+		if l == models.GroupByAll {
+			route.GroupByAll = true
+			route.GroupBy = nil
+			break
+		}
+		route.GroupBy = append(route.GroupBy, model.LabelName(l))
+	}
+	return route
+}
+
+func mustEqualMatcher(name, value string) *labels.Matcher {
+	m, err := labels.NewMatcher(labels.MatchEqual, name, value)
+	if err != nil {
+		// equality matchers never fail to build.
+		panic(err)
+	}
+	return m
+}
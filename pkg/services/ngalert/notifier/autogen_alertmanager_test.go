@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestAddAutogenConfig(t *testing.T) {
+	const orgID = 1
+	groupWait := model.Duration(10 * time.Second)
+
+	newConfig := func() *apimodels.PostableApiAlertingConfig {
+		return &apimodels.PostableApiAlertingConfig{
+			Config: apimodels.Config{
+				Route: &apimodels.Route{
+					Receiver: "default",
+					Routes: []*apimodels.Route{
+						{Receiver: "user-policy"},
+					},
+				},
+				MuteTimeIntervals: []config.MuteTimeInterval{{Name: "weekends"}},
+			},
+			Receivers: []*apimodels.PostableApiReceiver{
+				{Receiver: config.Receiver{Name: "default"}},
+				{Receiver: config.Receiver{Name: "user-policy"}},
+				{Receiver: config.Receiver{Name: "team-a"}},
+				{Receiver: config.Receiver{Name: "team-b"}},
+			},
+		}
+	}
+	newStore := func(settings map[models.AlertRuleKey]models.NotificationSettings) *fakeConfigStore {
+		return &fakeConfigStore{
+			notificationSettings: map[int64]map[models.AlertRuleKey]models.NotificationSettings{orgID: settings},
+		}
+	}
+
+	teamA := models.NotificationSettings{Receiver: "team-a"}
+	teamAGrouped := models.NotificationSettings{
+		Receiver:          "team-a",
+		GroupBy:           []string{models.GroupByAll},
+		GroupWait:         &groupWait,
+		MuteTimeIntervals: []string{"weekends"},
+	}
+	teamB := models.NotificationSettings{Receiver: "team-b"}
+
+	t.Run("should not change the configuration if there are no settings", func(t *testing.T) {
+		cfg := newConfig()
+		route := cfg.Route
+		err := AddAutogenConfig(context.Background(), log.NewNopLogger(), newStore(nil), orgID, cfg, false)
+		require.NoError(t, err)
+		require.Same(t, route, cfg.Route)
+	})
+
+	t.Run("should add routes for unique settings", func(t *testing.T) {
+		cfg := newConfig()
+		original := cfg.Route
+		store := newStore(map[models.AlertRuleKey]models.NotificationSettings{
+			{OrgID: orgID, UID: "rule-1"}: teamA,
+			{OrgID: orgID, UID: "rule-2"}: teamA,
+			{OrgID: orgID, UID: "rule-3"}: teamAGrouped,
+			{OrgID: orgID, UID: "rule-4"}: teamB,
+		})
+		err := AddAutogenConfig(context.Background(), log.NewNopLogger(), store, orgID, cfg, false)
+		require.NoError(t, err)
+
+		// the original route must not be modified
+		require.Len(t, original.Routes, 1)
+
+		require.Len(t, cfg.Route.Routes, 2)
+		require.Equal(t, "user-policy", cfg.Route.Routes[1].Receiver)
+		autogen := cfg.Route.Routes[0]
+		require.False(t, autogen.Continue)
+		require.Len(t, autogen.Routes, 2)
+		require.Equal(t, "team-a", autogen.Routes[0].Receiver)
+		require.Len(t, autogen.Routes[0].Routes, 2)
+		require.Equal(t, "team-b", autogen.Routes[1].Receiver)
+		require.Len(t, autogen.Routes[1].Routes, 1)
+
+		root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+		match := func(settings models.NotificationSettings) *dispatch.Route {
+			ls := model.LabelSet{model.AlertNameLabel: "test"}
+			for k, v := range settings.ToLabels() {
+				ls[model.LabelName(k)] = model.LabelValue(v)
+			}
+			routes := root.Match(ls)
+			require.Len(t, routes, 1)
+			return routes[0]
+		}
+
+		r := match(teamA)
+		require.Equal(t, "team-a", r.RouteOpts.Receiver)
+		require.Equal(t, map[model.LabelName]struct{}{models.FolderTitleLabel: {}, model.AlertNameLabel: {}}, r.RouteOpts.GroupBy)
+
+		r = match(teamAGrouped)
+		require.Equal(t, "team-a", r.RouteOpts.Receiver)
+		require.True(t, r.RouteOpts.GroupByAll)
+		require.Equal(t, time.Duration(groupWait), r.RouteOpts.GroupWait)
+		require.Equal(t, []string{"weekends"}, r.RouteOpts.MuteTimeIntervals)
+
+		r = match(teamB)
+		require.Equal(t, "team-b", r.RouteOpts.Receiver)
+
+		// alerts without the settings are routed by the user-defined policies
+		routes := root.Match(model.LabelSet{model.AlertNameLabel: "test"})
+		require.Len(t, routes, 1)
+		require.Equal(t, "user-policy", routes[0].RouteOpts.Receiver)
+	})
+
+	invalid := map[string]models.NotificationSettings{
+		"unknown receiver":    {Receiver: "unknown"},
+		"unknown mute timing": {Receiver: "team-a", MuteTimeIntervals: []string{"unknown"}},
+		"invalid settings":    {Receiver: "team-a", GroupBy: []string{"cluster"}},
+	}
+	for name, settings := range invalid {
+		t.Run("should fail if "+name, func(t *testing.T) {
+			store := newStore(map[models.AlertRuleKey]models.NotificationSettings{
+				{OrgID: orgID, UID: "rule-1"}:  teamA,
+				{OrgID: orgID, UID: "invalid"}: settings,
+			})
+			err := AddAutogenConfig(context.Background(), log.NewNopLogger(), store, orgID, newConfig(), false)
+			require.ErrorContains(t, err, "invalid notification settings of alert rule invalid")
+		})
+
+		t.Run("should skip if "+name+" and skipInvalid is true", func(t *testing.T) {
+			cfg := newConfig()
+			store := newStore(map[models.AlertRuleKey]models.NotificationSettings{
+				{OrgID: orgID, UID: "rule-1"}:  teamA,
+				{OrgID: orgID, UID: "invalid"}: settings,
+			})
+			err := AddAutogenConfig(context.Background(), log.NewNopLogger(), store, orgID, cfg, true)
+			require.NoError(t, err)
+			require.Len(t, cfg.Route.Routes, 2)
+			autogen := cfg.Route.Routes[0]
+			require.Len(t, autogen.Routes, 1)
+			require.Len(t, autogen.Routes[0].Routes, 1)
+		})
+	}
+}
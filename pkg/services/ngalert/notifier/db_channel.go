@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/alertmanager/cluster"
+	"github.com/prometheus/alertmanager/cluster/clusterpb"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type DBChannel struct {
+	p       *dbPeer
+	key     string
+	msgType string
+	msgc    chan []byte
+}
+
+func newDBChannel(p *dbPeer, key, msgType string) cluster.ClusterChannel {
+	dbChannel := &DBChannel{
+		p:       p,
+		key:     key,
+		msgType: msgType,
+		// The buffer size of 200 was taken from the Memberlist implementation.
+		msgc: make(chan []byte, 200),
+	}
+	go dbChannel.handleMessages()
+	return dbChannel
+}
+
+func (c *DBChannel) handleMessages() {
+	for {
+		select {
+		case <-c.p.shutdownc:
+			return
+		case b := <-c.msgc:
+			ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+			err := c.p.store.SaveAlertmanagerClusterMessage(ctx, &models.AlertmanagerClusterMessage{
+				Peer:        c.p.name,
+				MessageType: models.AlertmanagerClusterMessageUpdate,
+				Payload:     b,
+			})
+			cancel()
+			// The state will eventually be propagated to other members by the full sync.
+			if err != nil {
+				c.p.messagesPublishFailures.WithLabelValues(c.msgType, reasonDatabaseIssue).Inc()
+				c.p.logger.Error("Error saving a message to the database", "err", err, "key", c.key)
+				continue
+			}
+			c.p.messagesSent.WithLabelValues(c.msgType).Inc()
+			c.p.messagesSentSize.WithLabelValues(c.msgType).Add(float64(len(b)))
+		}
+	}
+}
+
+func (c *DBChannel) Broadcast(b []byte) {
+	b, err := proto.Marshal(&clusterpb.Part{Key: c.key, Data: b})
+	if err != nil {
+		c.p.logger.Error("Error marshalling broadcast into proto", "err", err, "key", c.key)
+		return
+	}
+	select {
+	case c.msgc <- b:
+	default:
+		// This is not the end of the world, we will catch up when we do a full state sync.
+		c.p.messagesPublishFailures.WithLabelValues(c.msgType, reasonBufferOverflow).Inc()
+		c.p.logger.Warn("Buffer full, dropping message", "key", c.key)
+	}
+}
@@ -0,0 +1,582 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/prometheus/alertmanager/cluster"
+	"github.com/prometheus/alertmanager/cluster/clusterpb"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type dbConfig struct {
+	name         string
+	pollInterval time.Duration
+}
+
+const (
+	databaseServerLabel = "database"
+	reasonDatabaseIssue = "database_issue"
+	// The maximum number of messages fetched from the database in one query.
+	dbMessagesBatchSize = 100
+	// Peers that did not send a heartbeat for this long are removed from the database.
+	dbPeerExpiration = time.Minute * 5
+	// The minimum time messages are kept in the database. Messages are kept for at least three full state syncs,
+	// so that a peer that joins the cluster can always find the full state of the other peers.
+	dbMinMessageRetention = time.Minute * 5
+	dbCleanupInterval     = time.Minute
+	dbCleanupActionName   = "alertmanager cluster cleanup"
+	dbOperationTimeout    = time.Second * 10
+)
+
+// serverLock runs a function on a single Grafana instance at a time, see serverlock.ServerLockService.
+type serverLock interface {
+	LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+// dbPeer is a cluster peer that uses the Grafana database to exchange the state of silences and notification logs with
+// other Grafana instances. Messages are written to a table that every peer polls for messages of the other peers.
+// Members of the cluster are tracked with heartbeats.
+type dbPeer struct {
+	name      string
+	store     store.AlertmanagerClusterStore
+	lock      serverLock
+	logger    log.Logger
+	states    map[string]cluster.State
+	statesMtx sync.RWMutex
+
+	readyc    chan struct{}
+	shutdownc chan struct{}
+
+	pollInterval     time.Duration
+	pushPullInterval time.Duration
+
+	// The ID of the last message received from the database. Should only be accessed by the receive loop.
+	lastMessageID int64
+
+	messagesReceived        *prometheus.CounterVec
+	messagesReceivedSize    *prometheus.CounterVec
+	messagesSent            *prometheus.CounterVec
+	messagesSentSize        *prometheus.CounterVec
+	messagesPublishFailures *prometheus.CounterVec
+	nodePingDuration        *prometheus.HistogramVec
+	nodePingFailures        prometheus.Counter
+
+	// List of active members of the cluster. Should be accessed through the Members function.
+	members    []string
+	membersMtx sync.Mutex
+	// The time when we fetched the members from the database the last time successfully.
+	membersFetchedAt time.Time
+}
+
+func newDBPeer(cfg dbConfig, st store.AlertmanagerClusterStore, lock serverLock, logger log.Logger, reg prometheus.Registerer,
+	pushPullInterval time.Duration) (*dbPeer, error) {
+	name := "peer-" + uuid.New().String()
+	// If a specific name is provided, overwrite default one.
+	if cfg.name != "" {
+		name = cfg.name
+	}
+	p := &dbPeer{
+		name:             name,
+		store:            st,
+		lock:             lock,
+		logger:           logger,
+		states:           map[string]cluster.State{},
+		pollInterval:     cfg.pollInterval,
+		pushPullInterval: pushPullInterval,
+		readyc:           make(chan struct{}),
+		shutdownc:        make(chan struct{}),
+		members:          make([]string, 0),
+	}
+
+	// The metrics for the database peer are the same as for the redis peer.
+	messagesReceived := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "alertmanager_cluster_messages_received_total",
+		Help: "Total number of cluster messages received.",
+	}, []string{"msg_type"})
+	messagesReceivedSize := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "alertmanager_cluster_messages_received_size_total",
+		Help: "Total size of cluster messages received.",
+	}, []string{"msg_type"})
+	messagesSent := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "alertmanager_cluster_messages_sent_total",
+		Help: "Total number of cluster messages sent.",
+	}, []string{"msg_type"})
+	messagesSentSize := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "alertmanager_cluster_messages_sent_size_total",
+		Help: "Total size of cluster messages sent.",
+	}, []string{"msg_type"})
+	messagesPublishFailures := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "alertmanager_cluster_messages_publish_failures_total",
+		Help: "Total number of messages that failed to be published.",
+	}, []string{"msg_type", "reason"})
+	gossipClusterMembers := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "alertmanager_cluster_members",
+		Help: "Number indicating current number of members in cluster.",
+	}, func() float64 {
+		return float64(p.ClusterSize())
+	})
+	peerPosition := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "alertmanager_peer_position",
+		Help: "Position the Alertmanager instance believes it's in. The position determines a peer's behavior in the cluster.",
+	}, func() float64 {
+		return float64(p.Position())
+	})
+	healthScore := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "alertmanager_cluster_health_score",
+		Help: "Health score of the cluster. Lower values are better and zero means 'totally healthy'.",
+	}, func() float64 {
+		return float64(p.GetHealthScore())
+	})
+	nodePingDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "alertmanager_cluster_pings_seconds",
+		Help:    "Histogram of latencies for ping messages.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5},
+	}, []string{"peer"},
+	)
+	nodePingFailures := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "alertmanager_cluster_pings_failures_total",
+		Help: "Total number of failed pings.",
+	})
+
+	messagesReceived.WithLabelValues(fullState)
+	messagesReceivedSize.WithLabelValues(fullState)
+	messagesReceived.WithLabelValues(update)
+	messagesReceivedSize.WithLabelValues(update)
+	messagesSent.WithLabelValues(fullState)
+	messagesSentSize.WithLabelValues(fullState)
+	messagesSent.WithLabelValues(update)
+	messagesSentSize.WithLabelValues(update)
+	messagesPublishFailures.WithLabelValues(fullState, reasonDatabaseIssue)
+	messagesPublishFailures.WithLabelValues(update, reasonDatabaseIssue)
+	messagesPublishFailures.WithLabelValues(update, reasonBufferOverflow)
+
+	reg.MustRegister(messagesReceived, messagesReceivedSize, messagesSent, messagesSentSize,
+		gossipClusterMembers, peerPosition, healthScore, nodePingDuration, nodePingFailures,
+		messagesPublishFailures,
+	)
+
+	p.messagesReceived = messagesReceived
+	p.messagesReceivedSize = messagesReceivedSize
+	p.messagesSent = messagesSent
+	p.messagesSentSize = messagesSentSize
+	p.messagesPublishFailures = messagesPublishFailures
+	p.nodePingDuration = nodePingDuration
+	p.nodePingFailures = nodePingFailures
+
+	// Messages that were sent before the peer started are not replayed here because the states are not registered yet.
+	// They are merged once the peer settles.
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+	lastID, err := p.store.GetLastAlertmanagerClusterMessageID(ctx)
+	if err != nil {
+		p.logger.Error("Failed to get the last message from the database - messages of other peers are replayed", "err", err)
+	}
+	p.lastMessageID = lastID
+
+	p.heartbeat()
+	p.membersSync()
+
+	go p.heartbeatLoop()
+	go p.membersSyncLoop()
+	go p.receiveLoop()
+	go p.fullStateSyncPublishLoop()
+	go p.cleanupLoop()
+
+	return p, nil
+}
+
+func (p *dbPeer) heartbeatLoop() {
+	ticker := time.NewTicker(heartbeatInterval)
+	for {
+		select {
+		case <-ticker.C:
+			p.heartbeat()
+		case <-p.shutdownc:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (p *dbPeer) heartbeat() {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+	startTime := time.Now()
+	if err := p.store.HeartbeatAlertmanagerClusterPeer(ctx, p.name); err != nil {
+		p.nodePingFailures.Inc()
+		p.logger.Error("Error updating the heartbeat of the peer", "err", err, "peer", p.name)
+		return
+	}
+	p.nodePingDuration.WithLabelValues(databaseServerLabel).Observe(time.Since(startTime).Seconds())
+}
+
+func (p *dbPeer) membersSyncLoop() {
+	ticker := time.NewTicker(membersSyncInterval)
+	for {
+		select {
+		case <-ticker.C:
+			p.membersSync()
+		case <-p.shutdownc:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (p *dbPeer) membersSync() {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+	startTime := time.Now()
+	// Peers that have failed to send a heartbeat during the heartbeatTimeout are not members.
+	peers, err := p.store.GetAlertmanagerClusterPeers(ctx, time.Now().Add(-heartbeatTimeout))
+	if err != nil {
+		p.logger.Error("Error getting peers from the database", "err", err)
+		// To prevent a spike of duplicate messages, we return for the duration of
+		// membersValidFor the last known members and only empty the list if we do
+		// not eventually recover.
+		if p.membersFetchedAt.Before(time.Now().Add(-membersValidFor)) {
+			p.membersMtx.Lock()
+			p.members = []string{}
+			p.membersMtx.Unlock()
+			return
+		}
+		p.logger.Warn("Fetching members from the database failed, falling back to last known members", "last_known", p.members)
+		return
+	}
+
+	dur := time.Since(startTime)
+	p.logger.Debug("Membership sync done", "duration_ms", dur.Milliseconds())
+	p.membersMtx.Lock()
+	p.members = peers
+	p.membersMtx.Unlock()
+	p.membersFetchedAt = time.Now()
+}
+
+func (p *dbPeer) Position() int {
+	for i, peer := range p.Members() {
+		if peer == p.name {
+			p.logger.Debug("Cluster position found", "name", p.name, "position", i)
+			return i
+		}
+	}
+	p.logger.Warn("Failed to look up position, falling back to position 0")
+	return 0
+}
+
+// Returns the known size of the Cluster. This also includes dead nodes that
+// haven't been removed from the database yet.
+func (p *dbPeer) ClusterSize() int {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+	peers, err := p.store.GetAlertmanagerClusterPeers(ctx, time.Time{})
+	if err != nil {
+		p.logger.Error("Error getting peers from the database", "err", err)
+		return 0
+	}
+	return len(peers)
+}
+
+// If the cluster is healthy it should return 0, otherwise the number of
+// unhealthy nodes.
+func (p *dbPeer) GetHealthScore() int {
+	size := p.ClusterSize()
+	members := len(p.Members())
+	if size > members {
+		return size - members
+	}
+	return 0
+}
+
+// Members returns a list of active cluster Members.
+func (p *dbPeer) Members() []string {
+	p.membersMtx.Lock()
+	defer p.membersMtx.Unlock()
+	return p.members
+}
+
+func (p *dbPeer) WaitReady(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.readyc:
+		return nil
+	}
+}
+
+// Settle is the same as for the redis peer, except that instead of requesting the full state from other peers,
+// it merges all messages of other peers that are still stored in the database.
+func (p *dbPeer) Settle(ctx context.Context, interval time.Duration) {
+	const NumOkayRequired = 3
+	p.logger.Info("Waiting for gossip to settle...", "interval", interval)
+	start := time.Now()
+	nPeers := 0
+	nOkay := 0
+	totalPolls := 0
+	for {
+		select {
+		case <-ctx.Done():
+			elapsed := time.Since(start)
+			p.logger.Info("Gossip not settled but continuing anyway", "polls", totalPolls, "elapsed", elapsed)
+			close(p.readyc)
+			return
+		case <-time.After(interval):
+		}
+		elapsed := time.Since(start)
+		n := len(p.Members())
+		if nOkay >= NumOkayRequired {
+			p.logger.Info("Gossip settled; proceeding", "elapsed", elapsed)
+			break
+		}
+		if n == nPeers {
+			nOkay++
+			p.logger.Debug("Gossip looks settled", "elapsed", elapsed)
+		} else {
+			nOkay = 0
+			p.logger.Info("Gossip not settled", "polls", totalPolls, "before", nPeers, "now", n, "elapsed", elapsed)
+		}
+		nPeers = n
+		totalPolls++
+	}
+	p.replay(ctx)
+	close(p.readyc)
+}
+
+// replay merges all messages of other peers that are stored in the database. Since merging the state is idempotent,
+// it does not matter that some of the messages might have already been received.
+func (p *dbPeer) replay(ctx context.Context) {
+	var afterID int64
+	for {
+		messages, err := p.store.GetAlertmanagerClusterMessages(ctx, models.GetAlertmanagerClusterMessagesQuery{
+			AfterID:     afterID,
+			ExcludePeer: p.name,
+			Limit:       dbMessagesBatchSize,
+		})
+		if err != nil {
+			p.logger.Error("Error getting messages from the database", "err", err)
+			return
+		}
+		for _, msg := range messages {
+			p.merge(msg)
+			afterID = msg.ID
+		}
+		if len(messages) < dbMessagesBatchSize {
+			return
+		}
+	}
+}
+
+func (p *dbPeer) AddState(key string, state cluster.State, _ prometheus.Registerer) cluster.ClusterChannel {
+	p.statesMtx.Lock()
+	defer p.statesMtx.Unlock()
+	p.states[key] = state
+	return newDBChannel(p, key, update)
+}
+
+func (p *dbPeer) receiveLoop() {
+	ticker := time.NewTicker(p.pollInterval)
+	for {
+		select {
+		case <-ticker.C:
+			p.receive()
+		case <-p.shutdownc:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// receive merges the messages sent by other peers since the last poll. Messages are ordered by their ID, which is assigned
+// when the message is inserted. A message with a lower ID that is committed after a message with a higher ID was
+// received is missed. This is not critical, the state is eventually propagated to other peers by the full state sync.
+func (p *dbPeer) receive() {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+	for {
+		messages, err := p.store.GetAlertmanagerClusterMessages(ctx, models.GetAlertmanagerClusterMessagesQuery{
+			AfterID:     p.lastMessageID,
+			ExcludePeer: p.name,
+			Limit:       dbMessagesBatchSize,
+		})
+		if err != nil {
+			p.logger.Error("Error getting messages from the database", "err", err)
+			return
+		}
+		for _, msg := range messages {
+			p.merge(msg)
+			p.lastMessageID = msg.ID
+		}
+		if len(messages) < dbMessagesBatchSize {
+			return
+		}
+	}
+}
+
+func (p *dbPeer) merge(msg *models.AlertmanagerClusterMessage) {
+	switch msg.MessageType {
+	case models.AlertmanagerClusterMessageUpdate:
+		p.mergePartialState(msg.Payload)
+	case models.AlertmanagerClusterMessageFullState:
+		p.mergeFullState(msg.Payload)
+	default:
+		p.logger.Warn("Received message of unknown type", "type", msg.MessageType, "peer", msg.Peer)
+	}
+}
+
+func (p *dbPeer) mergePartialState(buf []byte) {
+	p.messagesReceived.WithLabelValues(update).Inc()
+	p.messagesReceivedSize.WithLabelValues(update).Add(float64(len(buf)))
+
+	var part clusterpb.Part
+	if err := proto.Unmarshal(buf, &part); err != nil {
+		p.logger.Warn("Error decoding the received broadcast message", "err", err)
+		return
+	}
+
+	p.statesMtx.RLock()
+	s, ok := p.states[part.Key]
+	p.statesMtx.RUnlock()
+
+	if !ok {
+		return
+	}
+	if err := s.Merge(part.Data); err != nil {
+		p.logger.Warn("Error merging the received broadcast message", "err", err, "key", part.Key)
+		return
+	}
+	p.logger.Debug("Partial state was successfully merged", "key", part.Key)
+}
+
+func (p *dbPeer) mergeFullState(buf []byte) {
+	p.messagesReceived.WithLabelValues(fullState).Inc()
+	p.messagesReceivedSize.WithLabelValues(fullState).Add(float64(len(buf)))
+
+	var fs clusterpb.FullState
+	if err := proto.Unmarshal(buf, &fs); err != nil {
+		p.logger.Warn("Error unmarshaling the received remote state", "err", err)
+		return
+	}
+
+	p.statesMtx.RLock()
+	defer p.statesMtx.RUnlock()
+	for _, part := range fs.Parts {
+		s, ok := p.states[part.Key]
+		if !ok {
+			p.logger.Warn("Received", "unknown state key", "len", len(buf), "key", part.Key)
+			continue
+		}
+		if err := s.Merge(part.Data); err != nil {
+			p.logger.Warn("Error merging the received remote state", "err", err, "key", part.Key)
+			return
+		}
+	}
+	p.logger.Debug("Full state was successfully merged")
+}
+
+func (p *dbPeer) fullStateSyncPublish() {
+	ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+	defer cancel()
+	err := p.store.SaveAlertmanagerClusterMessage(ctx, &models.AlertmanagerClusterMessage{
+		Peer:        p.name,
+		MessageType: models.AlertmanagerClusterMessageFullState,
+		Payload:     p.LocalState(),
+	})
+	if err != nil {
+		p.messagesPublishFailures.WithLabelValues(fullState, reasonDatabaseIssue).Inc()
+		p.logger.Error("Error saving the full state to the database", "err", err)
+	}
+}
+
+func (p *dbPeer) fullStateSyncPublishLoop() {
+	ticker := time.NewTicker(p.pushPullInterval)
+	for {
+		select {
+		case <-ticker.C:
+			p.fullStateSyncPublish()
+		case <-p.shutdownc:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (p *dbPeer) cleanupLoop() {
+	ticker := time.NewTicker(dbCleanupInterval)
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), dbOperationTimeout)
+			// Only one peer needs to clean up the database, the others skip it until the next interval.
+			if err := p.lock.LockAndExecute(ctx, dbCleanupActionName, dbCleanupInterval, p.cleanup); err != nil {
+				p.logger.Error("Error acquiring the lock to clean up the database", "err", err)
+			}
+			cancel()
+		case <-p.shutdownc:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// cleanup removes expired peers and messages from the database.
+func (p *dbPeer) cleanup(ctx context.Context) {
+	now := time.Now()
+	peers, err := p.store.DeleteStaleAlertmanagerClusterPeers(ctx, now.Add(-dbPeerExpiration))
+	if err != nil {
+		p.logger.Error("Error deleting stale peers from the database", "err", err)
+	}
+	messages, err := p.store.DeleteAlertmanagerClusterMessages(ctx, now.Add(-p.messageRetention()))
+	if err != nil {
+		p.logger.Error("Error deleting expired messages from the database", "err", err)
+	}
+	p.logger.Debug("Cleaned up the database", "peers", peers, "messages", messages)
+}
+
+func (p *dbPeer) messageRetention() time.Duration {
+	retention := 3 * p.pushPullInterval
+	if retention < dbMinMessageRetention {
+		return dbMinMessageRetention
+	}
+	return retention
+}
+
+func (p *dbPeer) LocalState() []byte {
+	p.statesMtx.RLock()
+	defer p.statesMtx.RUnlock()
+	all := &clusterpb.FullState{
+		Parts: make([]clusterpb.Part, 0, len(p.states)),
+	}
+
+	for key, s := range p.states {
+		b, err := s.MarshalBinary()
+		if err != nil {
+			p.logger.Warn("Error encoding the local state", "err", err, "key", key)
+		}
+		all.Parts = append(all.Parts, clusterpb.Part{Key: key, Data: b})
+	}
+	b, err := proto.Marshal(all)
+	if err != nil {
+		p.logger.Warn("Error encoding the local state to proto", "err", err)
+	}
+	p.messagesSent.WithLabelValues(fullState).Inc()
+	p.messagesSentSize.WithLabelValues(fullState).Add(float64(len(b)))
+	return b
+}
+
+func (p *dbPeer) Shutdown() {
+	p.logger.Info("Stopping database peer...")
+	close(p.shutdownc)
+	p.fullStateSyncPublish()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := p.store.DeleteAlertmanagerClusterPeer(ctx, p.name); err != nil {
+		p.logger.Error("Error deleting the peer from the database on shutdown", "err", err, "peer", p.name)
+	}
+}
@@ -0,0 +1,254 @@
+package notifier
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestDBPeer(t *testing.T) {
+	st := newFakeClusterStore()
+	lock := &fakeServerLock{}
+	newPeer := func(name string) *dbPeer {
+		p, err := newDBPeer(dbConfig{name: name, pollInterval: 10 * time.Millisecond}, st, lock, log.NewNopLogger(), prometheus.NewRegistry(), time.Hour)
+		require.NoError(t, err)
+		return p
+	}
+
+	p1 := newPeer("peer-1")
+	s1 := &fakeClusterState{data: []byte("state-1")}
+	c1 := p1.AddState("silences", s1, nil)
+
+	// A full state and an update sent before the second peer starts are merged when it settles.
+	p1.fullStateSyncPublish()
+	c1.Broadcast([]byte("update-1"))
+	require.Eventually(t, func() bool { return len(st.messagesOf("peer-1")) == 2 }, time.Second, 10*time.Millisecond)
+
+	p2 := newPeer("peer-2")
+	s2 := &fakeClusterState{data: []byte("state-2")}
+	c2 := p2.AddState("silences", s2, nil)
+
+	require.Equal(t, []string{"peer-1", "peer-2"}, p2.Members())
+	require.Equal(t, 0, p1.Position())
+	require.Equal(t, 1, p2.Position())
+	require.Equal(t, 2, p2.ClusterSize())
+	require.Equal(t, 0, p2.GetHealthScore())
+
+	require.Empty(t, s2.mergedData())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p2.Settle(ctx, time.Millisecond)
+	require.NoError(t, p2.WaitReady(ctx))
+	require.Equal(t, []string{"state-1", "update-1"}, s2.mergedData())
+
+	// Updates are received by polling, a peer never receives its own messages.
+	c2.Broadcast([]byte("update-2"))
+	require.Eventually(t, func() bool {
+		return len(s1.mergedData()) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"update-2"}, s1.mergedData())
+	require.Equal(t, []string{"state-1", "update-1"}, s2.mergedData())
+
+	// The peer publishes its full state and leaves the cluster on shutdown.
+	p2.Shutdown()
+	require.Eventually(t, func() bool {
+		return len(s1.mergedData()) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"update-2", "state-2"}, s1.mergedData())
+	peers, err := st.GetAlertmanagerClusterPeers(context.Background(), time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"peer-1"}, peers)
+
+	p1.Shutdown()
+}
+
+func TestDBPeerCleanup(t *testing.T) {
+	st := newFakeClusterStore()
+	p := &dbPeer{
+		name:             "peer-1",
+		store:            st,
+		logger:           log.NewNopLogger(),
+		pushPullInterval: time.Minute,
+	}
+	require.Equal(t, dbMinMessageRetention, p.messageRetention())
+	p.pushPullInterval = time.Hour
+	require.Equal(t, 3*time.Hour, p.messageRetention())
+
+	now := time.Now()
+	st.peers["stale"] = now.Add(-2 * dbPeerExpiration)
+	st.peers["alive"] = now
+	st.messages = []*models.AlertmanagerClusterMessage{
+		{ID: 1, Peer: "stale", MessageType: models.AlertmanagerClusterMessageFullState, CreatedAt: now.Add(-4 * time.Hour)},
+		{ID: 2, Peer: "alive", MessageType: models.AlertmanagerClusterMessageFullState, CreatedAt: now},
+	}
+
+	p.cleanup(context.Background())
+
+	peers, err := st.GetAlertmanagerClusterPeers(context.Background(), time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"alive"}, peers)
+	require.Len(t, st.messages, 1)
+	require.Equal(t, int64(2), st.messages[0].ID)
+}
+
+type fakeClusterState struct {
+	mtx    sync.Mutex
+	data   []byte
+	merged []string
+}
+
+func (s *fakeClusterState) MarshalBinary() ([]byte, error) {
+	return s.data, nil
+}
+
+func (s *fakeClusterState) Merge(b []byte) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.merged = append(s.merged, string(b))
+	return nil
+}
+
+func (s *fakeClusterState) mergedData() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]string{}, s.merged...)
+}
+
+type fakeServerLock struct{}
+
+func (l *fakeServerLock) LockAndExecute(ctx context.Context, _ string, _ time.Duration, fn func(ctx context.Context)) error {
+	fn(ctx)
+	return nil
+}
+
+// fakeClusterStore is an in-memory store.AlertmanagerClusterStore.
+type fakeClusterStore struct {
+	mtx      sync.Mutex
+	peers    map[string]time.Time
+	messages []*models.AlertmanagerClusterMessage
+	lastID   int64
+}
+
+func newFakeClusterStore() *fakeClusterStore {
+	return &fakeClusterStore{peers: map[string]time.Time{}}
+}
+
+func (f *fakeClusterStore) messagesOf(peer string) []*models.AlertmanagerClusterMessage {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var result []*models.AlertmanagerClusterMessage
+	for _, msg := range f.messages {
+		if msg.Peer == peer {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+func (f *fakeClusterStore) HeartbeatAlertmanagerClusterPeer(_ context.Context, name string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.peers[name] = time.Now()
+	return nil
+}
+
+func (f *fakeClusterStore) GetAlertmanagerClusterPeers(_ context.Context, since time.Time) ([]string, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := make([]string, 0, len(f.peers))
+	for name, heartbeat := range f.peers {
+		if since.IsZero() || heartbeat.After(since) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (f *fakeClusterStore) DeleteAlertmanagerClusterPeer(_ context.Context, name string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.peers, name)
+	return nil
+}
+
+func (f *fakeClusterStore) DeleteStaleAlertmanagerClusterPeers(_ context.Context, before time.Time) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var n int64
+	for name, heartbeat := range f.peers {
+		if heartbeat.Before(before) {
+			delete(f.peers, name)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeClusterStore) SaveAlertmanagerClusterMessage(_ context.Context, msg *models.AlertmanagerClusterMessage) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if msg.MessageType == models.AlertmanagerClusterMessageFullState {
+		messages := f.messages[:0]
+		for _, m := range f.messages {
+			if m.Peer != msg.Peer || m.MessageType != msg.MessageType {
+				messages = append(messages, m)
+			}
+		}
+		f.messages = messages
+	}
+	f.lastID++
+	msg.ID = f.lastID
+	msg.CreatedAt = time.Now()
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+func (f *fakeClusterStore) GetAlertmanagerClusterMessages(_ context.Context, query models.GetAlertmanagerClusterMessagesQuery) ([]*models.AlertmanagerClusterMessage, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var result []*models.AlertmanagerClusterMessage
+	for _, msg := range f.messages {
+		if msg.ID <= query.AfterID || msg.Peer == query.ExcludePeer {
+			continue
+		}
+		if query.MessageType != "" && msg.MessageType != query.MessageType {
+			continue
+		}
+		result = append(result, msg)
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeClusterStore) GetLastAlertmanagerClusterMessageID(_ context.Context) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.lastID, nil
+}
+
+func (f *fakeClusterStore) DeleteAlertmanagerClusterMessages(_ context.Context, before time.Time) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var n int64
+	messages := f.messages[:0]
+	for _, msg := range f.messages {
+		if msg.CreatedAt.Before(before) {
+			n++
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	f.messages = messages
+	return n, nil
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -73,6 +74,9 @@ type MultiOrgAlertmanager struct {
 	// clusterPeer represents the clustering peers of Alertmanagers between Grafana instances.
 	peer         alertingNotify.ClusterPeer
 	settleCancel context.CancelFunc
+	// clusterStore and clusterLock are used by the database-backed clustering peer.
+	clusterStore store.AlertmanagerClusterStore
+	clusterLock  serverLock
 
 	configStore AlertingStore
 	orgStore    store.OrgStore
@@ -95,6 +99,15 @@ func WithAlertmanagerOverride(f orgAlertmanagerFactory) Option {
 	}
 }
 
+// WithClusterStore sets the store and the lock that are used to share the state between Grafana instances
+// through the database when it is enabled in the settings.
+func WithClusterStore(st store.AlertmanagerClusterStore, lock *serverlock.ServerLockService) Option {
+	return func(moa *MultiOrgAlertmanager) {
+		moa.clusterStore = st
+		moa.clusterLock = lock
+	}
+}
+
 func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore AlertingStore, orgStore store.OrgStore,
 	kvStore kvstore.KVStore, provStore provisioningStore, decryptFn alertingNotify.GetDecryptedValueFn,
 	m *metrics.MultiOrgAlertmanager, ns notifications.Service, l log.Logger, s secrets.Service, opts ...Option,
@@ -115,10 +128,6 @@ func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore AlertingStore, orgSto
 		peer:          &NilPeer{},
 	}
 
-	if err := moa.setupClustering(cfg); err != nil {
-		return nil, err
-	}
-
 	// Set up the default per tenant Alertmanager factory.
 	moa.factory = func(ctx context.Context, orgID int64) (Alertmanager, error) {
 		m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID))
@@ -129,6 +138,11 @@ func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore AlertingStore, orgSto
 		opt(moa)
 	}
 
+	// Clustering is set up after the options are applied as they can provide the storage for the database-backed peer.
+	if err := moa.setupClustering(cfg); err != nil {
+		return nil, err
+	}
+
 	return moa, nil
 }
 
@@ -158,6 +172,24 @@ func (moa *MultiOrgAlertmanager) setupClustering(cfg *setting.Cfg) error {
 		moa.peer = redisPeer
 		return nil
 	}
+	// Database setup.
+	if cfg.UnifiedAlerting.HADatabaseEnabled {
+		if moa.clusterStore == nil || moa.clusterLock == nil {
+			return fmt.Errorf("unable to initialize database clustering: no store configured")
+		}
+		dbPeer, err := newDBPeer(dbConfig{
+			name:         cfg.UnifiedAlerting.HADatabasePeerName,
+			pollInterval: cfg.UnifiedAlerting.HADatabasePollInterval,
+		}, moa.clusterStore, moa.clusterLock, clusterLogger, moa.metrics.Registerer, cfg.UnifiedAlerting.HAPushPullInterval)
+		if err != nil {
+			return fmt.Errorf("unable to initialize database clustering: %w", err)
+		}
+		var ctx context.Context
+		ctx, moa.settleCancel = context.WithTimeout(context.Background(), 30*time.Second)
+		go dbPeer.Settle(ctx, settleTimeout)
+		moa.peer = dbPeer
+		return nil
+	}
 	// Memberlist setup.
 	if len(cfg.UnifiedAlerting.HAPeers) > 0 {
 		peer, err := cluster.Create(
@@ -396,6 +428,11 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 		moa.settleCancel()
 		r.Shutdown()
 	}
+	d, ok := moa.peer.(*dbPeer)
+	if ok {
+		moa.settleCancel()
+		d.Shutdown()
+	}
 }
 
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
@@ -0,0 +1,356 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// recurringSilencesInterval is how often recurring silences are materialized.
+	recurringSilencesInterval = time.Minute
+	// recurringSilencesLookahead is how far ahead the occurrences of recurring silences are created as silences.
+	recurringSilencesLookahead = 24 * time.Hour
+	// maxRecurringSilenceOccurrences is the maximum number of silences created for a recurring silence in one run.
+	maxRecurringSilenceOccurrences = 100
+	// maxMuteTimingRange is the maximum duration of a silence created from a time range of a mute timing.
+	maxMuteTimingRange = 7 * 24 * time.Hour
+
+	// RecurringSilenceCreatedByPrefix is the prefix of the creator of the silences created from recurring silences.
+	// It is followed by the UID of the recurring silence.
+	RecurringSilenceCreatedByPrefix = "recurring-silence:"
+)
+
+// RecurringSilenceStore is the storage that the RecurringSilenceMaterializer reads the recurring silences from.
+type RecurringSilenceStore interface {
+	GetOrgs(ctx context.Context) ([]int64, error)
+	GetSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error)
+	ListRecurringSilences(ctx context.Context, query models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error)
+	UpdateRecurringSilenceMaterializedUntil(ctx context.Context, id int64, previous, until int64) (bool, error)
+	GetLatestAlertmanagerConfiguration(ctx context.Context, orgID int64) (*models.AlertConfiguration, error)
+}
+
+type alertmanagerProvider interface {
+	AlertmanagerFor(orgID int64) (Alertmanager, error)
+}
+
+// RecurringSilenceMaterializer periodically creates the occurrences of recurring silences as silences in the
+// Alertmanager of their organization, ahead of time. It also expires the silences that were created from recurring
+// silences that have since been changed or deleted.
+//
+// In high availability setups every instance runs the materializer. The instances claim the time range to materialize
+// in the database before they create silences, so each occurrence is created once.
+type RecurringSilenceMaterializer struct {
+	store         RecurringSilenceStore
+	alertmanagers alertmanagerProvider
+	clock         clock.Clock
+	logger        log.Logger
+}
+
+func NewRecurringSilenceMaterializer(store RecurringSilenceStore, alertmanagers alertmanagerProvider, clk clock.Clock, logger log.Logger) *RecurringSilenceMaterializer {
+	return &RecurringSilenceMaterializer{
+		store:         store,
+		alertmanagers: alertmanagers,
+		clock:         clk,
+		logger:        logger,
+	}
+}
+
+func (m *RecurringSilenceMaterializer) Run(ctx context.Context) error {
+	ticker := m.clock.Ticker(recurringSilencesInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.materialize(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// silenceOccurrence is the time range of a silence to create.
+type silenceOccurrence struct {
+	start time.Time
+	end   time.Time
+}
+
+func (m *RecurringSilenceMaterializer) materialize(ctx context.Context) {
+	orgs, err := m.store.GetOrgs(ctx)
+	if err != nil {
+		m.logger.Error("Failed to get organizations to materialize recurring silences", "error", err)
+		return
+	}
+	for _, orgID := range orgs {
+		if err := m.materializeOrg(ctx, orgID); err != nil {
+			m.logger.Error("Failed to materialize recurring silences", "org", orgID, "error", err)
+		}
+	}
+}
+
+func (m *RecurringSilenceMaterializer) materializeOrg(ctx context.Context, orgID int64) error {
+	am, err := m.alertmanagers.AlertmanagerFor(orgID)
+	if err != nil {
+		if errors.Is(err, ErrNoAlertmanagerForOrg) || errors.Is(err, ErrAlertmanagerNotReady) {
+			m.logger.Debug("Skipping materialization of recurring silences", "org", orgID, "reason", err)
+			return nil
+		}
+		return err
+	}
+	silences, err := m.store.ListRecurringSilences(ctx, models.ListRecurringSilencesQuery{OrgID: orgID})
+	if err != nil {
+		return fmt.Errorf("failed to list recurring silences: %w", err)
+	}
+	byUID := make(map[string]*models.RecurringSilence, len(silences))
+	for _, s := range silences {
+		byUID[s.UID] = s
+	}
+	if err := m.expireOutdatedSilences(ctx, am, byUID); err != nil {
+		return err
+	}
+	if len(silences) == 0 {
+		return nil
+	}
+
+	templates, err := m.store.GetSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get silence templates: %w", err)
+	}
+	templatesByUID := make(map[string]*models.SilenceTemplate, len(templates))
+	for _, t := range templates {
+		templatesByUID[t.UID] = t
+	}
+
+	var muteTimings map[string][]timeinterval.TimeInterval
+	for _, s := range silences {
+		if s.MuteTimeInterval != "" {
+			if muteTimings, err = m.getMuteTimings(ctx, orgID); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	now := m.clock.Now()
+	until := now.Add(recurringSilencesLookahead).Unix()
+	for _, s := range silences {
+		if s.MaterializedUntil >= until {
+			continue
+		}
+		logger := m.logger.New("org", orgID, "recurring_silence", s.UID)
+		matchers, comment, duration := s.Matchers, s.Comment, s.Duration
+		if s.TemplateUID != "" {
+			tmpl, ok := templatesByUID[s.TemplateUID]
+			if !ok {
+				logger.Warn("Skipping recurring silence, its silence template does not exist", "template", s.TemplateUID)
+				continue
+			}
+			if len(matchers) == 0 {
+				matchers = tmpl.Matchers
+			}
+			if comment == "" {
+				comment = tmpl.Comment
+			}
+			if duration == 0 {
+				duration = tmpl.Duration
+			}
+		}
+
+		var occurrences []silenceOccurrence
+		if s.Schedule != "" {
+			occurrences, err = cronOccurrences(s, duration, now, time.Unix(until, 0).In(now.Location()))
+			if err != nil {
+				logger.Warn("Skipping recurring silence with an invalid schedule", "error", err)
+				continue
+			}
+		} else {
+			intervals, ok := muteTimings[s.MuteTimeInterval]
+			if !ok {
+				logger.Warn("Skipping recurring silence, its mute timing does not exist", "mute_timing", s.MuteTimeInterval)
+				continue
+			}
+			occurrences = muteTimingOccurrences(intervals, s.MaterializedUntil, now, time.Unix(until, 0).In(now.Location()))
+		}
+
+		// Claim the time range, so that other instances do not create the same silences.
+		ok, err := m.store.UpdateRecurringSilenceMaterializedUntil(ctx, s.ID, s.MaterializedUntil, until)
+		if err != nil {
+			logger.Error("Failed to update the time until which the recurring silence is materialized", "error", err)
+			continue
+		}
+		if !ok {
+			logger.Debug("Recurring silence was materialized or changed concurrently")
+			continue
+		}
+
+		for _, o := range occurrences {
+			ps, err := postableSilence(s.UID, matchers, comment, o)
+			if err != nil {
+				logger.Error("Failed to create silence from recurring silence", "error", err)
+				break
+			}
+			id, err := am.CreateSilence(ctx, ps)
+			if err != nil {
+				logger.Error("Failed to create silence from recurring silence", "starts_at", o.start, "ends_at", o.end, "error", err)
+				continue
+			}
+			logger.Debug("Created silence from recurring silence", "silence", id, "starts_at", o.start, "ends_at", o.end)
+		}
+	}
+	return nil
+}
+
+// expireOutdatedSilences expires the silences that were created from recurring silences that were deleted
+// or changed after the silences were created.
+func (m *RecurringSilenceMaterializer) expireOutdatedSilences(ctx context.Context, am Alertmanager, silences map[string]*models.RecurringSilence) error {
+	existing, err := am.ListSilences(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list silences: %w", err)
+	}
+	for _, sil := range existing {
+		if sil.ID == nil || sil.CreatedBy == nil || !strings.HasPrefix(*sil.CreatedBy, RecurringSilenceCreatedByPrefix) {
+			continue
+		}
+		if sil.Status != nil && sil.Status.State != nil && *sil.Status.State == amv2.SilenceStatusStateExpired {
+			continue
+		}
+		uid := strings.TrimPrefix(*sil.CreatedBy, RecurringSilenceCreatedByPrefix)
+		if s, ok := silences[uid]; ok && (sil.UpdatedAt == nil || !time.Time(*sil.UpdatedAt).Before(s.Updated)) {
+			continue
+		}
+		// Other instances might have expired the silence already.
+		if err := am.DeleteSilence(ctx, *sil.ID); err != nil {
+			m.logger.Debug("Failed to expire silence of recurring silence", "silence", *sil.ID, "recurring_silence", uid, "error", err)
+		}
+	}
+	return nil
+}
+
+func (m *RecurringSilenceMaterializer) getMuteTimings(ctx context.Context, orgID int64) (map[string][]timeinterval.TimeInterval, error) {
+	amConfig, err := m.store.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the Alertmanager configuration: %w", err)
+	}
+	cfg, err := Load([]byte(amConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Alertmanager configuration: %w", err)
+	}
+	result := make(map[string][]timeinterval.TimeInterval, len(cfg.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		result[mt.Name] = mt.TimeIntervals
+	}
+	return result, nil
+}
+
+// cronOccurrences returns the occurrences of the schedule that start before until and end after now. If the recurring
+// silence was materialized before, only the occurrences that start after that time are returned.
+func cronOccurrences(s *models.RecurringSilence, duration time.Duration, now, until time.Time) ([]silenceOccurrence, error) {
+	schedule, err := s.CronSchedule()
+	if err != nil {
+		return nil, err
+	}
+	loc, err := s.TimeLocation()
+	if err != nil {
+		return nil, err
+	}
+	// The next activation is always after the time, so the occurrences at exactly the time from which to start
+	// are included.
+	from := now.Add(-duration)
+	if s.MaterializedUntil > 0 {
+		from = time.Unix(s.MaterializedUntil, 0).In(now.Location()).Add(-time.Second)
+	}
+	var result []silenceOccurrence
+	for t := from.In(loc); len(result) < maxRecurringSilenceOccurrences; {
+		t = schedule.Next(t)
+		if t.IsZero() || !t.Before(until) {
+			break
+		}
+		end := t.Add(duration)
+		if end.After(now) {
+			result = append(result, silenceOccurrence{start: t, end: end})
+		}
+	}
+	return result, nil
+}
+
+// muteTimingOccurrences returns the continuous time ranges of the mute timing that start before until. A time range
+// that starts before until lasts until its end, up to maxMuteTimingRange. If the recurring silence is materialized
+// until a time after now, the time ranges start at that time, and the time range in progress at that time is skipped
+// because it was created in full before.
+func muteTimingOccurrences(intervals []timeinterval.TimeInterval, materializedUntil int64, now, until time.Time) []silenceOccurrence {
+	contains := func(t time.Time) bool {
+		for _, ti := range intervals {
+			if ti.ContainsTime(t) {
+				return true
+			}
+		}
+		return false
+	}
+	t := now.Truncate(time.Minute)
+	// If the recurring silence was not materialized for a while, it is materialized again from now on.
+	if materializedUntil > t.Unix() {
+		t = time.Unix(materializedUntil, 0).In(now.Location()).Truncate(time.Minute)
+		if contains(t.Add(-time.Minute)) {
+			for t.Before(until) && contains(t) {
+				t = t.Add(time.Minute)
+			}
+		}
+	}
+	var result []silenceOccurrence
+	for ; t.Before(until) && len(result) < maxRecurringSilenceOccurrences; t = t.Add(time.Minute) {
+		if !contains(t) {
+			continue
+		}
+		start := t
+		for contains(t) && t.Sub(start) < maxMuteTimingRange {
+			t = t.Add(time.Minute)
+		}
+		result = append(result, silenceOccurrence{start: start, end: t})
+	}
+	return result
+}
+
+func postableSilence(uid string, matchers models.SilenceMatchers, comment string, o silenceOccurrence) (*apimodels.PostableSilence, error) {
+	if comment == "" {
+		comment = "Created from recurring silence " + uid
+	}
+	createdBy := RecurringSilenceCreatedByPrefix + uid
+	startsAt, endsAt := strfmt.DateTime(o.start), strfmt.DateTime(o.end)
+	result := &apimodels.PostableSilence{
+		Silence: amv2.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+			Matchers:  make(amv2.Matchers, 0, len(matchers)),
+		},
+	}
+	for _, matcher := range matchers {
+		lm, err := matcher.Matcher()
+		if err != nil {
+			return nil, err
+		}
+		isEqual := lm.Type == labels.MatchEqual || lm.Type == labels.MatchRegexp
+		isRegex := lm.Type == labels.MatchRegexp || lm.Type == labels.MatchNotRegexp
+		name, value := lm.Name, lm.Value
+		result.Silence.Matchers = append(result.Silence.Matchers, &amv2.Matcher{
+			Name:    &name,
+			Value:   &value,
+			IsEqual: &isEqual,
+			IsRegex: &isRegex,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,235 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestCronOccurrences(t *testing.T) {
+	now := time.Date(2023, 11, 6, 12, 30, 0, 0, time.UTC)
+	until := now.Add(recurringSilencesLookahead)
+
+	t.Run("should include the occurrence in progress the first time", func(t *testing.T) {
+		s := &models.RecurringSilence{Schedule: "0 */8 * * *"}
+		occurrences, err := cronOccurrences(s, time.Hour, now.Add(-10*time.Minute), until)
+		require.NoError(t, err)
+		require.Equal(t, []silenceOccurrence{
+			{start: time.Date(2023, 11, 6, 16, 0, 0, 0, time.UTC), end: time.Date(2023, 11, 6, 17, 0, 0, 0, time.UTC)},
+			{start: time.Date(2023, 11, 7, 0, 0, 0, 0, time.UTC), end: time.Date(2023, 11, 7, 1, 0, 0, 0, time.UTC)},
+			{start: time.Date(2023, 11, 7, 8, 0, 0, 0, time.UTC), end: time.Date(2023, 11, 7, 9, 0, 0, 0, time.UTC)},
+		}, occurrences)
+
+		occurrences, err = cronOccurrences(s, time.Hour, time.Date(2023, 11, 6, 8, 30, 0, 0, time.UTC), until)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2023, 11, 6, 8, 0, 0, 0, time.UTC), occurrences[0].start)
+	})
+
+	t.Run("should continue from the time it was materialized until", func(t *testing.T) {
+		s := &models.RecurringSilence{Schedule: "0 */8 * * *", MaterializedUntil: time.Date(2023, 11, 7, 8, 0, 0, 0, time.UTC).Unix()}
+		occurrences, err := cronOccurrences(s, time.Hour, now, until.Add(8*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, []silenceOccurrence{
+			{start: time.Date(2023, 11, 7, 8, 0, 0, 0, time.UTC), end: time.Date(2023, 11, 7, 9, 0, 0, 0, time.UTC)},
+			{start: time.Date(2023, 11, 7, 16, 0, 0, 0, time.UTC), end: time.Date(2023, 11, 7, 17, 0, 0, 0, time.UTC)},
+		}, occurrences)
+	})
+
+	t.Run("should use the location", func(t *testing.T) {
+		s := &models.RecurringSilence{Schedule: "0 2 * * *", Location: "America/New_York"}
+		occurrences, err := cronOccurrences(s, time.Hour, now, until)
+		require.NoError(t, err)
+		require.Len(t, occurrences, 1)
+		require.True(t, time.Date(2023, 11, 7, 7, 0, 0, 0, time.UTC).Equal(occurrences[0].start))
+	})
+
+	t.Run("should limit the number of occurrences", func(t *testing.T) {
+		s := &models.RecurringSilence{Schedule: "* * * * *"}
+		occurrences, err := cronOccurrences(s, time.Minute, now, until)
+		require.NoError(t, err)
+		require.Len(t, occurrences, maxRecurringSilenceOccurrences)
+	})
+}
+
+func TestMuteTimingOccurrences(t *testing.T) {
+	// Every day from 22:00 to 02:00.
+	intervals := []timeinterval.TimeInterval{
+		{Times: []timeinterval.TimeRange{{StartMinute: 22 * 60, EndMinute: 24 * 60}}},
+		{Times: []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 2 * 60}}},
+	}
+	day := func(d, h int) time.Time {
+		return time.Date(2023, 11, d, h, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("should merge continuous time ranges and extend them past until", func(t *testing.T) {
+		now := day(6, 12)
+		occurrences := muteTimingOccurrences(intervals, 0, now, now.Add(recurringSilencesLookahead))
+		require.Equal(t, []silenceOccurrence{
+			{start: day(6, 22), end: day(7, 2)},
+		}, occurrences)
+	})
+
+	t.Run("should start the time range in progress now the first time", func(t *testing.T) {
+		now := day(7, 1).Add(30 * time.Minute)
+		occurrences := muteTimingOccurrences(intervals, 0, now, day(7, 12))
+		require.Equal(t, []silenceOccurrence{
+			{start: now, end: day(7, 2)},
+		}, occurrences)
+	})
+
+	t.Run("should skip the time range in progress when continuing", func(t *testing.T) {
+		now := day(6, 12)
+		occurrences := muteTimingOccurrences(intervals, day(7, 0).Unix(), now, day(8, 0))
+		require.Equal(t, []silenceOccurrence{
+			{start: day(7, 22), end: day(8, 2)},
+		}, occurrences)
+	})
+}
+
+func TestRecurringSilenceMaterializer(t *testing.T) {
+	clk := clock.NewMock()
+	clk.Set(time.Date(2023, 11, 6, 12, 0, 0, 0, time.UTC))
+	st := &fakeRecurringSilenceStore{
+		templates: []*models.SilenceTemplate{
+			{OrgID: 1, UID: "tmpl", Matchers: models.SilenceMatchers{{Name: "team", Type: "=~", Value: "ops|sre"}}, Duration: time.Hour, Comment: "maintenance"},
+		},
+		silences: []*models.RecurringSilence{
+			{ID: 1, OrgID: 1, UID: "nightly", TemplateUID: "tmpl", Schedule: "0 2 * * *"},
+			{ID: 2, OrgID: 1, UID: "missing-template", TemplateUID: "unknown", Schedule: "0 2 * * *"},
+		},
+	}
+	am := &fakeSilenceAlertmanager{clock: clk}
+	m := NewRecurringSilenceMaterializer(st, fakeAlertmanagerProvider{1: am}, clk, log.NewNopLogger())
+
+	m.materialize(context.Background())
+
+	require.Len(t, am.silences, 1)
+	sil := am.silences[0]
+	require.Equal(t, RecurringSilenceCreatedByPrefix+"nightly", *sil.CreatedBy)
+	require.Equal(t, "maintenance", *sil.Comment)
+	require.Equal(t, time.Date(2023, 11, 7, 2, 0, 0, 0, time.UTC), time.Time(*sil.StartsAt).UTC())
+	require.Equal(t, time.Date(2023, 11, 7, 3, 0, 0, 0, time.UTC), time.Time(*sil.EndsAt).UTC())
+	require.Len(t, sil.Matchers, 1)
+	require.Equal(t, "team", *sil.Matchers[0].Name)
+	require.True(t, *sil.Matchers[0].IsRegex)
+	require.True(t, *sil.Matchers[0].IsEqual)
+	require.Equal(t, clk.Now().Add(recurringSilencesLookahead).Unix(), st.silences[0].MaterializedUntil)
+	require.Zero(t, st.silences[1].MaterializedUntil)
+
+	t.Run("should not create the same silences again", func(t *testing.T) {
+		clk.Add(time.Minute)
+		m.materialize(context.Background())
+		require.Len(t, am.silences, 1)
+	})
+
+	t.Run("should expire the silences of changed and deleted recurring silences", func(t *testing.T) {
+		clk.Add(time.Minute)
+		st.silences[0].Updated = clk.Now()
+		st.silences[0].MaterializedUntil = 0
+		m.materialize(context.Background())
+		require.Equal(t, []string{"silence-1"}, am.expired)
+		require.Len(t, am.silences, 2)
+
+		st.silences = st.silences[1:]
+		m.materialize(context.Background())
+		require.Equal(t, []string{"silence-1", "silence-2"}, am.expired)
+	})
+}
+
+type fakeAlertmanagerProvider map[int64]Alertmanager
+
+func (f fakeAlertmanagerProvider) AlertmanagerFor(orgID int64) (Alertmanager, error) {
+	am, ok := f[orgID]
+	if !ok {
+		return nil, ErrNoAlertmanagerForOrg
+	}
+	return am, nil
+}
+
+// fakeSilenceAlertmanager implements the silences of an Alertmanager.
+type fakeSilenceAlertmanager struct {
+	Alertmanager
+	clock    clock.Clock
+	silences []*apimodels.PostableSilence
+	created  []time.Time
+	expired  []string
+}
+
+func (f *fakeSilenceAlertmanager) CreateSilence(_ context.Context, ps *apimodels.PostableSilence) (string, error) {
+	f.silences = append(f.silences, ps)
+	f.created = append(f.created, f.clock.Now())
+	return fmt.Sprintf("silence-%d", len(f.silences)), nil
+}
+
+func (f *fakeSilenceAlertmanager) DeleteSilence(_ context.Context, id string) error {
+	f.expired = append(f.expired, id)
+	return nil
+}
+
+func (f *fakeSilenceAlertmanager) ListSilences(_ context.Context, _ []string) (apimodels.GettableSilences, error) {
+	result := make(apimodels.GettableSilences, 0, len(f.silences))
+	for i, ps := range f.silences {
+		id := fmt.Sprintf("silence-%d", i+1)
+		state := amv2.SilenceStatusStateActive
+		for _, expired := range f.expired {
+			if expired == id {
+				state = amv2.SilenceStatusStateExpired
+			}
+		}
+		updatedAt := strfmt.DateTime(f.created[i])
+		result = append(result, &amv2.GettableSilence{
+			ID:        &id,
+			Status:    &amv2.SilenceStatus{State: &state},
+			UpdatedAt: &updatedAt,
+			Silence:   ps.Silence,
+		})
+	}
+	return result, nil
+}
+
+type fakeRecurringSilenceStore struct {
+	templates []*models.SilenceTemplate
+	silences  []*models.RecurringSilence
+}
+
+func (f *fakeRecurringSilenceStore) GetOrgs(_ context.Context) ([]int64, error) {
+	return []int64{1, 2}, nil
+}
+
+func (f *fakeRecurringSilenceStore) GetSilenceTemplates(_ context.Context, _ int64) ([]*models.SilenceTemplate, error) {
+	return f.templates, nil
+}
+
+func (f *fakeRecurringSilenceStore) ListRecurringSilences(_ context.Context, _ models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error) {
+	result := make([]*models.RecurringSilence, 0, len(f.silences))
+	for _, s := range f.silences {
+		c := *s
+		result = append(result, &c)
+	}
+	return result, nil
+}
+
+func (f *fakeRecurringSilenceStore) UpdateRecurringSilenceMaterializedUntil(_ context.Context, id int64, previous, until int64) (bool, error) {
+	for _, s := range f.silences {
+		if s.ID == id && s.MaterializedUntil == previous {
+			s.MaterializedUntil = until
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRecurringSilenceStore) GetLatestAlertmanagerConfiguration(_ context.Context, _ int64) (*models.AlertConfiguration, error) {
+	return nil, fmt.Errorf("not implemented")
+}
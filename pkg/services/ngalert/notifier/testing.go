@@ -19,6 +19,9 @@ type fakeConfigStore struct {
 
 	// historicConfigs stores configs by orgID.
 	historicConfigs map[int64][]*models.HistoricAlertConfiguration
+
+	// notificationSettings stores notification settings of alert rules by orgID.
+	notificationSettings map[int64]map[models.AlertRuleKey]models.NotificationSettings
 }
 
 // Saves the image or returns an error.
@@ -42,6 +45,17 @@ func (f *fakeConfigStore) GetImages(ctx context.Context, tokens []string) ([]mod
 	return nil, nil, alertingImages.ErrImageNotFound
 }
 
+func (f *fakeConfigStore) ListNotificationSettings(_ context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey]models.NotificationSettings, error) {
+	result := make(map[models.AlertRuleKey]models.NotificationSettings)
+	for key, settings := range f.notificationSettings[q.OrgID] {
+		if q.ReceiverName != "" && settings.Receiver != q.ReceiverName {
+			continue
+		}
+		result[key] = settings
+	}
+	return result, nil
+}
+
 func NewFakeConfigStore(t *testing.T, configs map[int64]*models.AlertConfiguration) *fakeConfigStore {
 	t.Helper()
 
@@ -0,0 +1,315 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SilenceStore represents the ability to persist and query silence templates and recurring silences.
+type SilenceStore interface {
+	GetSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error)
+	GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error)
+	SaveSilenceTemplate(ctx context.Context, t *models.SilenceTemplate) error
+	DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error
+	ListRecurringSilences(ctx context.Context, query models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error)
+	GetRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error)
+	SaveRecurringSilence(ctx context.Context, s *models.RecurringSilence) error
+	DeleteRecurringSilence(ctx context.Context, orgID int64, uid string) error
+}
+
+type SilenceService struct {
+	store  SilenceStore
+	config AMConfigStore
+	prov   ProvisioningStore
+	xact   TransactionManager
+	log    log.Logger
+}
+
+func NewSilenceService(store SilenceStore, config AMConfigStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *SilenceService {
+	return &SilenceService{
+		store:  store,
+		config: config,
+		prov:   prov,
+		xact:   xact,
+		log:    log,
+	}
+}
+
+// GetSilenceTemplates returns all silence templates of the org and their provenance, keyed by UID.
+func (svc *SilenceService) GetSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, map[string]models.Provenance, error) {
+	templates, err := svc.store.GetSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	provenances := make(map[string]models.Provenance)
+	if len(templates) > 0 {
+		provenances, err = svc.prov.GetProvenances(ctx, orgID, templates[0].ResourceType())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return templates, provenances, nil
+}
+
+// GetSilenceTemplate returns the silence template with the UID and its provenance.
+func (svc *SilenceService) GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (models.SilenceTemplate, models.Provenance, error) {
+	tmpl, err := svc.store.GetSilenceTemplate(ctx, orgID, uid)
+	if err != nil {
+		return models.SilenceTemplate{}, models.ProvenanceNone, err
+	}
+	provenance, err := svc.prov.GetProvenance(ctx, tmpl, orgID)
+	if err != nil {
+		return models.SilenceTemplate{}, models.ProvenanceNone, err
+	}
+	return *tmpl, provenance, nil
+}
+
+// CreateSilenceTemplate creates the silence template. A UID is generated if the template does not have one.
+func (svc *SilenceService) CreateSilenceTemplate(ctx context.Context, tmpl models.SilenceTemplate, provenance models.Provenance) (models.SilenceTemplate, error) {
+	if err := tmpl.Validate(); err != nil {
+		return models.SilenceTemplate{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	if tmpl.UID != "" {
+		if err := util.ValidateUID(tmpl.UID); err != nil {
+			return models.SilenceTemplate{}, fmt.Errorf("%w: cannot create silence template with UID '%s': %s", ErrValidation, tmpl.UID, err.Error())
+		}
+	}
+	tmpl.ID = 0
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if tmpl.UID != "" {
+			_, err := svc.store.GetSilenceTemplate(ctx, tmpl.OrgID, tmpl.UID)
+			if err == nil {
+				return fmt.Errorf("%w: a silence template with UID '%s' already exists", ErrValidation, tmpl.UID)
+			}
+			if !errors.Is(err, models.ErrSilenceTemplateNotFound) {
+				return err
+			}
+		}
+		if err := svc.store.SaveSilenceTemplate(ctx, &tmpl); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &tmpl, tmpl.OrgID, provenance)
+	})
+	if err != nil {
+		return models.SilenceTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// UpdateSilenceTemplate updates the silence template with the UID of tmpl. The recurring silences that use the
+// template replace the silences that were created from the previous version of the template.
+func (svc *SilenceService) UpdateSilenceTemplate(ctx context.Context, tmpl models.SilenceTemplate, provenance models.Provenance) (models.SilenceTemplate, error) {
+	if err := tmpl.Validate(); err != nil {
+		return models.SilenceTemplate{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	stored, storedProvenance, err := svc.GetSilenceTemplate(ctx, tmpl.OrgID, tmpl.UID)
+	if err != nil {
+		return models.SilenceTemplate{}, err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return models.SilenceTemplate{}, fmt.Errorf("%w: cannot change provenance from '%s' to '%s'", ErrValidation, storedProvenance, provenance)
+	}
+	tmpl.ID = stored.ID
+	tmpl.Updated = stored.Updated
+	changed := !reflect.DeepEqual(tmpl, stored)
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if changed {
+			if err := svc.store.SaveSilenceTemplate(ctx, &tmpl); err != nil {
+				return err
+			}
+			silences, err := svc.store.ListRecurringSilences(ctx, models.ListRecurringSilencesQuery{OrgID: tmpl.OrgID, TemplateUID: tmpl.UID})
+			if err != nil {
+				return err
+			}
+			for _, s := range silences {
+				s.MaterializedUntil = 0
+				if err := svc.store.SaveRecurringSilence(ctx, s); err != nil {
+					return err
+				}
+			}
+		}
+		return svc.prov.SetProvenance(ctx, &tmpl, tmpl.OrgID, provenance)
+	})
+	if err != nil {
+		return models.SilenceTemplate{}, err
+	}
+	return tmpl, nil
+}
+
+// DeleteSilenceTemplate deletes the silence template with the UID. If the template does not exist, no error is returned.
+// Templates that are used by recurring silences cannot be deleted.
+func (svc *SilenceService) DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string, provenance models.Provenance) error {
+	target := &models.SilenceTemplate{OrgID: orgID, UID: uid}
+	storedProvenance, err := svc.prov.GetProvenance(ctx, target, orgID)
+	if err != nil {
+		return err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return fmt.Errorf("%w: cannot delete with provided provenance '%s', needs '%s'", ErrValidation, provenance, storedProvenance)
+	}
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		silences, err := svc.store.ListRecurringSilences(ctx, models.ListRecurringSilencesQuery{OrgID: orgID, TemplateUID: uid})
+		if err != nil {
+			return err
+		}
+		if len(silences) > 0 {
+			return fmt.Errorf("%w: silence template '%s' is used by %d recurring silences", ErrValidation, uid, len(silences))
+		}
+		if err := svc.store.DeleteSilenceTemplate(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.prov.DeleteProvenance(ctx, target, orgID)
+	})
+}
+
+// GetRecurringSilences returns all recurring silences of the org and their provenance, keyed by UID.
+func (svc *SilenceService) GetRecurringSilences(ctx context.Context, orgID int64) ([]*models.RecurringSilence, map[string]models.Provenance, error) {
+	silences, err := svc.store.ListRecurringSilences(ctx, models.ListRecurringSilencesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, nil, err
+	}
+	provenances := make(map[string]models.Provenance)
+	if len(silences) > 0 {
+		provenances, err = svc.prov.GetProvenances(ctx, orgID, silences[0].ResourceType())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return silences, provenances, nil
+}
+
+// GetRecurringSilence returns the recurring silence with the UID and its provenance.
+func (svc *SilenceService) GetRecurringSilence(ctx context.Context, orgID int64, uid string) (models.RecurringSilence, models.Provenance, error) {
+	s, err := svc.store.GetRecurringSilence(ctx, orgID, uid)
+	if err != nil {
+		return models.RecurringSilence{}, models.ProvenanceNone, err
+	}
+	provenance, err := svc.prov.GetProvenance(ctx, s, orgID)
+	if err != nil {
+		return models.RecurringSilence{}, models.ProvenanceNone, err
+	}
+	return *s, provenance, nil
+}
+
+// CreateRecurringSilence creates the recurring silence. A UID is generated if the silence does not have one.
+func (svc *SilenceService) CreateRecurringSilence(ctx context.Context, s models.RecurringSilence, provenance models.Provenance) (models.RecurringSilence, error) {
+	if err := svc.validateRecurringSilence(ctx, s); err != nil {
+		return models.RecurringSilence{}, err
+	}
+	if s.UID != "" {
+		if err := util.ValidateUID(s.UID); err != nil {
+			return models.RecurringSilence{}, fmt.Errorf("%w: cannot create recurring silence with UID '%s': %s", ErrValidation, s.UID, err.Error())
+		}
+	}
+	s.ID = 0
+	s.MaterializedUntil = 0
+	err := svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if s.UID != "" {
+			_, err := svc.store.GetRecurringSilence(ctx, s.OrgID, s.UID)
+			if err == nil {
+				return fmt.Errorf("%w: a recurring silence with UID '%s' already exists", ErrValidation, s.UID)
+			}
+			if !errors.Is(err, models.ErrRecurringSilenceNotFound) {
+				return err
+			}
+		}
+		if err := svc.store.SaveRecurringSilence(ctx, &s); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &s, s.OrgID, provenance)
+	})
+	if err != nil {
+		return models.RecurringSilence{}, err
+	}
+	return s, nil
+}
+
+// UpdateRecurringSilence updates the recurring silence with the UID of s. If the recurring silence is changed,
+// the silences that were created from its previous version are replaced.
+func (svc *SilenceService) UpdateRecurringSilence(ctx context.Context, s models.RecurringSilence, provenance models.Provenance) (models.RecurringSilence, error) {
+	if err := svc.validateRecurringSilence(ctx, s); err != nil {
+		return models.RecurringSilence{}, err
+	}
+	stored, storedProvenance, err := svc.GetRecurringSilence(ctx, s.OrgID, s.UID)
+	if err != nil {
+		return models.RecurringSilence{}, err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return models.RecurringSilence{}, fmt.Errorf("%w: cannot change provenance from '%s' to '%s'", ErrValidation, storedProvenance, provenance)
+	}
+	s.ID = stored.ID
+	s.Updated = stored.Updated
+	s.MaterializedUntil = stored.MaterializedUntil
+	if len(s.Matchers) == 0 && len(stored.Matchers) == 0 {
+		s.Matchers = stored.Matchers
+	}
+	changed := !reflect.DeepEqual(s, stored)
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if changed {
+			// The silences are created again from the current time.
+			s.MaterializedUntil = 0
+			if err := svc.store.SaveRecurringSilence(ctx, &s); err != nil {
+				return err
+			}
+		}
+		return svc.prov.SetProvenance(ctx, &s, s.OrgID, provenance)
+	})
+	if err != nil {
+		return models.RecurringSilence{}, err
+	}
+	return s, nil
+}
+
+// DeleteRecurringSilence deletes the recurring silence with the UID. If the silence does not exist, no error is returned.
+func (svc *SilenceService) DeleteRecurringSilence(ctx context.Context, orgID int64, uid string, provenance models.Provenance) error {
+	target := &models.RecurringSilence{OrgID: orgID, UID: uid}
+	storedProvenance, err := svc.prov.GetProvenance(ctx, target, orgID)
+	if err != nil {
+		return err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return fmt.Errorf("%w: cannot delete with provided provenance '%s', needs '%s'", ErrValidation, provenance, storedProvenance)
+	}
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.DeleteRecurringSilence(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.prov.DeleteProvenance(ctx, target, orgID)
+	})
+}
+
+// validateRecurringSilence checks that the recurring silence is valid and that the template and the mute timing
+// it refers to exist.
+func (svc *SilenceService) validateRecurringSilence(ctx context.Context, s models.RecurringSilence) error {
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	if s.TemplateUID != "" {
+		_, err := svc.store.GetSilenceTemplate(ctx, s.OrgID, s.TemplateUID)
+		if errors.Is(err, models.ErrSilenceTemplateNotFound) {
+			return fmt.Errorf("%w: silence template '%s' does not exist", ErrValidation, s.TemplateUID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if s.MuteTimeInterval != "" {
+		revision, err := getLastConfiguration(ctx, s.OrgID, svc.config)
+		if err != nil {
+			return err
+		}
+		for _, mt := range revision.cfg.AlertmanagerConfig.MuteTimeIntervals {
+			if mt.Name == s.MuteTimeInterval {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: mute timing '%s' does not exist", ErrValidation, s.MuteTimeInterval)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSilenceService(t *testing.T) {
+	ctx := context.Background()
+	sut := createSilenceServiceSut(t)
+	matchers := models.SilenceMatchers{{Name: "team", Type: "=", Value: "ops"}}
+
+	tmpl, err := sut.CreateSilenceTemplate(ctx, models.SilenceTemplate{OrgID: 1, UID: "maintenance", Name: "Maintenance", Matchers: matchers, Duration: time.Hour}, models.ProvenanceAPI)
+	require.NoError(t, err)
+
+	t.Run("silence templates", func(t *testing.T) {
+		t.Run("should be validated", func(t *testing.T) {
+			_, err := sut.CreateSilenceTemplate(ctx, models.SilenceTemplate{OrgID: 1, Name: "no matchers", Duration: time.Hour}, models.ProvenanceNone)
+			require.ErrorIs(t, err, ErrValidation)
+		})
+
+		t.Run("should not create a template with an existing UID", func(t *testing.T) {
+			_, err := sut.CreateSilenceTemplate(ctx, models.SilenceTemplate{OrgID: 1, UID: tmpl.UID, Name: "other", Matchers: matchers, Duration: time.Hour}, models.ProvenanceNone)
+			require.ErrorIs(t, err, ErrValidation)
+		})
+
+		t.Run("should return templates with provenance", func(t *testing.T) {
+			templates, provenances, err := sut.GetSilenceTemplates(ctx, 1)
+			require.NoError(t, err)
+			require.Len(t, templates, 1)
+			require.Equal(t, models.ProvenanceAPI, provenances[tmpl.UID])
+		})
+
+		t.Run("should not change provenance", func(t *testing.T) {
+			_, err := sut.UpdateSilenceTemplate(ctx, tmpl, models.ProvenanceFile)
+			require.ErrorIs(t, err, ErrValidation)
+			err = sut.DeleteSilenceTemplate(ctx, 1, tmpl.UID, models.ProvenanceFile)
+			require.ErrorIs(t, err, ErrValidation)
+		})
+	})
+
+	t.Run("recurring silences", func(t *testing.T) {
+		t.Run("should require an existing template", func(t *testing.T) {
+			_, err := sut.CreateRecurringSilence(ctx, models.RecurringSilence{OrgID: 1, Name: "nightly", TemplateUID: "unknown", Schedule: "0 2 * * *"}, models.ProvenanceNone)
+			require.ErrorIs(t, err, ErrValidation)
+		})
+
+		t.Run("should require an existing mute timing", func(t *testing.T) {
+			_, err := sut.CreateRecurringSilence(ctx, models.RecurringSilence{OrgID: 1, Name: "weekly", Matchers: matchers, MuteTimeInterval: "unknown"}, models.ProvenanceNone)
+			require.ErrorIs(t, err, ErrValidation)
+
+			_, err = sut.CreateRecurringSilence(ctx, models.RecurringSilence{OrgID: 1, Name: "weekly", Matchers: matchers, MuteTimeInterval: "asdf"}, models.ProvenanceNone)
+			require.NoError(t, err)
+		})
+
+		s, err := sut.CreateRecurringSilence(ctx, models.RecurringSilence{OrgID: 1, Name: "nightly", TemplateUID: tmpl.UID, Schedule: "0 2 * * *"}, models.ProvenanceAPI)
+		require.NoError(t, err)
+		require.NotEmpty(t, s.UID)
+
+		t.Run("should keep the materialized time if nothing changed", func(t *testing.T) {
+			ok, err := sut.store.(store.DBstore).UpdateRecurringSilenceMaterializedUntil(ctx, s.ID, 0, 100)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			_, err = sut.UpdateRecurringSilence(ctx, s, models.ProvenanceAPI)
+			require.NoError(t, err)
+			stored, _, err := sut.GetRecurringSilence(ctx, 1, s.UID)
+			require.NoError(t, err)
+			require.Equal(t, int64(100), stored.MaterializedUntil)
+		})
+
+		t.Run("should reset the materialized time if the template changed", func(t *testing.T) {
+			tmpl.Duration = 2 * time.Hour
+			_, err := sut.UpdateSilenceTemplate(ctx, tmpl, models.ProvenanceAPI)
+			require.NoError(t, err)
+			stored, _, err := sut.GetRecurringSilence(ctx, 1, s.UID)
+			require.NoError(t, err)
+			require.Zero(t, stored.MaterializedUntil)
+		})
+
+		t.Run("should not delete a template in use", func(t *testing.T) {
+			err := sut.DeleteSilenceTemplate(ctx, 1, tmpl.UID, models.ProvenanceAPI)
+			require.ErrorIs(t, err, ErrValidation)
+
+			require.NoError(t, sut.DeleteRecurringSilence(ctx, 1, s.UID, models.ProvenanceAPI))
+			require.NoError(t, sut.DeleteSilenceTemplate(ctx, 1, tmpl.UID, models.ProvenanceAPI))
+			_, _, err = sut.GetSilenceTemplate(ctx, 1, tmpl.UID)
+			require.ErrorIs(t, err, models.ErrSilenceTemplateNotFound)
+		})
+	})
+}
+
+func createSilenceServiceSut(t *testing.T) *SilenceService {
+	t.Helper()
+	sqlStore := db.InitTestDB(t)
+	st := store.DBstore{
+		SQLStore: sqlStore,
+		Cfg: setting.UnifiedAlertingSettings{
+			BaseInterval: time.Second * 10,
+		},
+		Logger: log.NewNopLogger(),
+	}
+	return &SilenceService{
+		store:  st,
+		config: newFakeAMConfigStore(configWithMuteTimings),
+		prov:   st,
+		xact:   sqlStore,
+		log:    log.NewNopLogger(),
+	}
+}
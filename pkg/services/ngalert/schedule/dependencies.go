@@ -0,0 +1,166 @@
+package schedule
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+// inhibition is the effect of the current state of the upstream rules on a rule that depends on them.
+type inhibition struct {
+	// ruleUID is the UID of the upstream rule that inhibits all instances of
+	// the rule. It is empty if the rule is not inhibited as a whole.
+	ruleUID string
+	// instances inhibit the instances of the rule that have the same values of
+	// some labels as the instances of upstream rules.
+	instances []instanceInhibitor
+}
+
+type instanceInhibitor struct {
+	equal    []string
+	upstream []data.Labels
+}
+
+// getInhibition returns the inhibition of the rule by the rules it depends on.
+// An upstream rule inhibits the rule while any of its instances is not Normal.
+func (sch *schedule) getInhibition(rule *ngmodels.AlertRule) inhibition {
+	var result inhibition
+	for _, dep := range rule.Dependencies {
+		var upstream []data.Labels
+		for _, s := range sch.stateManager.GetStatesForRuleUID(rule.OrgID, dep.RuleUID) {
+			if s.State != eval.Normal {
+				upstream = append(upstream, s.Labels)
+			}
+		}
+		if len(upstream) == 0 {
+			continue
+		}
+		if len(dep.Equal) == 0 {
+			return inhibition{ruleUID: dep.RuleUID}
+		}
+		result.instances = append(result.instances, instanceInhibitor{equal: dep.Equal, upstream: upstream})
+	}
+	return result
+}
+
+// inhibits returns true if an instance with the labels is inhibited. Labels
+// are equal if they have the same value or if both are missing.
+func (i inhibition) inhibits(lbls data.Labels) bool {
+	if i.ruleUID != "" {
+		return true
+	}
+	for _, inhibitor := range i.instances {
+	upstream:
+		for _, up := range inhibitor.upstream {
+			for _, name := range inhibitor.equal {
+				if up[name] != lbls[name] {
+					continue upstream
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// apply makes the results of the instances that are inhibited Normal, so that
+// their alerts are resolved. The labels of the rule are considered as labels
+// of the instances.
+func (i inhibition) apply(rule *ngmodels.AlertRule, results eval.Results) (eval.Results, int) {
+	if len(i.instances) == 0 {
+		return results, 0
+	}
+	inhibited := 0
+	for idx, r := range results {
+		if r.State == eval.Normal {
+			continue
+		}
+		lbls := make(data.Labels, len(r.Instance)+len(rule.Labels))
+		for k, v := range rule.Labels {
+			lbls[k] = v
+		}
+		for k, v := range r.Instance {
+			lbls[k] = v
+		}
+		if !i.inhibits(lbls) {
+			continue
+		}
+		results[idx].State = eval.Normal
+		results[idx].Error = nil
+		inhibited++
+	}
+	return results, inhibited
+}
+
+// filterSamples returns the samples of the instances that are not inhibited.
+func (i inhibition) filterSamples(samples []writer.Sample) []writer.Sample {
+	if len(i.instances) == 0 {
+		return samples
+	}
+	result := samples[:0]
+	for _, s := range samples {
+		if !i.inhibits(s.Labels) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// sequenceDependentRules chains the evaluations of the rules of groups in
+// which rules depend on other rules, so that the rules of such a group are
+// evaluated one after another in the order of their dependencies and index.
+// It returns the items to dispatch: the items of the other groups and the
+// first item of each chain.
+func (sch *schedule) sequenceDependentRules(items []readyToRunItem) []readyToRunItem {
+	groups := make(map[ngmodels.AlertRuleGroupKey]ngmodels.RulesGroup)
+	for _, item := range items {
+		key := item.rule.GetGroupKey()
+		groups[key] = append(groups[key], item.rule)
+	}
+
+	result := make([]readyToRunItem, 0, len(items))
+	dispatched := make(map[ngmodels.AlertRuleGroupKey]struct{}, len(groups))
+	for _, item := range items {
+		key := item.rule.GetGroupKey()
+		group := groups[key]
+		if !group.HasDependencies() {
+			result = append(result, item)
+			continue
+		}
+		if _, ok := dispatched[key]; ok {
+			continue
+		}
+		dispatched[key] = struct{}{}
+
+		sorted, err := group.SortByDependencies()
+		if err != nil {
+			// this is not expected as the dependencies are validated when the rules are saved.
+			sch.log.Warn("Failed to sort the rules of the group by their dependencies, the rules are evaluated in the order of their index", "group", key.String(), "error", err)
+			sorted = make(ngmodels.RulesGroup, len(group))
+			copy(sorted, group)
+			sorted.SortByGroupIndex()
+		}
+		byRule := make(map[*ngmodels.AlertRule]readyToRunItem, len(sorted))
+		for _, it := range items {
+			if it.rule.GetGroupKey() == key {
+				byRule[it.rule] = it
+			}
+		}
+
+		var next *readyToRunItem
+		for i := len(sorted) - 1; i >= 0; i-- {
+			current := byRule[sorted[i]]
+			if next != nil {
+				n := *next
+				current.afterEval = func() {
+					go sch.runEvaluation(n)
+				}
+			}
+			next = &current
+		}
+		result = append(result, *next)
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+func TestGetInhibition(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	upstream := models.AlertRuleGen(models.WithOrgID(1), models.WithLabels(nil))()
+	process := func(results ...eval.Result) {
+		_ = sch.stateManager.ProcessEvalResults(context.Background(), sch.clock.Now(), upstream, results, nil)
+	}
+	newResult := func(state eval.State, instance string) eval.Result {
+		return eval.Result{State: state, Instance: data.Labels{"instance": instance}, EvaluatedAt: sch.clock.Now()}
+	}
+
+	t.Run("not inhibited if upstream instances are Normal", func(t *testing.T) {
+		process(newResult(eval.Normal, "a"), newResult(eval.Normal, "b"))
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithDependencies(upstream.UID))()
+
+		inh := sch.getInhibition(rule)
+		require.Empty(t, inh.ruleUID)
+		require.Empty(t, inh.instances)
+	})
+
+	t.Run("inhibits the whole rule if Equal is not set", func(t *testing.T) {
+		process(newResult(eval.Alerting, "a"), newResult(eval.Normal, "b"))
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithDependencies(upstream.UID))()
+
+		inh := sch.getInhibition(rule)
+		require.Equal(t, upstream.UID, inh.ruleUID)
+		require.True(t, inh.inhibits(data.Labels{"instance": "b"}))
+	})
+
+	t.Run("inhibits instances with equal labels", func(t *testing.T) {
+		process(newResult(eval.Alerting, "a"), newResult(eval.Normal, "b"))
+		rule := models.AlertRuleGen(models.WithOrgID(1))()
+		rule.Dependencies = []models.RuleDependency{{RuleUID: upstream.UID, Equal: []string{"instance"}}}
+
+		inh := sch.getInhibition(rule)
+		require.Empty(t, inh.ruleUID)
+		require.True(t, inh.inhibits(data.Labels{"instance": "a", "job": "x"}))
+		require.False(t, inh.inhibits(data.Labels{"instance": "b"}))
+		require.False(t, inh.inhibits(data.Labels{"job": "x"}))
+	})
+
+	t.Run("ignores unknown rules", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithDependencies("unknown"))()
+
+		inh := sch.getInhibition(rule)
+		require.Empty(t, inh.ruleUID)
+		require.Empty(t, inh.instances)
+	})
+}
+
+func TestInhibitionApply(t *testing.T) {
+	inh := inhibition{instances: []instanceInhibitor{{
+		equal:    []string{"instance", "team"},
+		upstream: []data.Labels{{"instance": "a", "team": "x"}},
+	}}}
+	rule := models.AlertRuleGen(models.WithLabels(data.Labels{"team": "x"}))()
+
+	results := eval.Results{
+		{State: eval.Alerting, Instance: data.Labels{"instance": "a"}},
+		{State: eval.Alerting, Instance: data.Labels{"instance": "b"}},
+		{State: eval.Error, Instance: data.Labels{"instance": "a", "team": "y"}},
+	}
+	results, inhibited := inh.apply(rule, results)
+
+	require.Equal(t, 1, inhibited)
+	require.Equal(t, eval.Normal, results[0].State)
+	require.Equal(t, eval.Alerting, results[1].State)
+	require.Equal(t, eval.Error, results[2].State)
+}
+
+func TestInhibitionFilterSamples(t *testing.T) {
+	inh := inhibition{instances: []instanceInhibitor{{
+		equal:    []string{"instance"},
+		upstream: []data.Labels{{"instance": "a"}},
+	}}}
+
+	samples := inh.filterSamples([]writer.Sample{
+		{Labels: data.Labels{"instance": "a"}, Value: 1},
+		{Labels: data.Labels{"instance": "b"}, Value: 2},
+	})
+
+	require.Equal(t, []writer.Sample{{Labels: data.Labels{"instance": "b"}, Value: 2}}, samples)
+}
+
+func TestSequenceDependentRules(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	groupKey := models.GenerateGroupKey(1)
+	gen := models.AlertRuleGen(models.WithGroupKey(groupKey))
+	first, second, third := gen(), gen(), gen()
+	first.UID, second.UID, third.UID = "first", "second", "third"
+	first.RuleGroupIndex, second.RuleGroupIndex, third.RuleGroupIndex = 1, 2, 3
+	// first is evaluated after third because it depends on it
+	first.Dependencies = []models.RuleDependency{{RuleUID: "third"}}
+	second.Dependencies = nil
+	third.Dependencies = nil
+	independent := models.AlertRuleGen(models.WithGroupKey(models.GenerateGroupKey(1)))()
+	independent.Dependencies = nil
+
+	tick := time.Now()
+	item := func(rule *models.AlertRule) readyToRunItem {
+		return readyToRunItem{ruleInfo: newAlertRuleInfo(ctx), evaluation: evaluation{scheduledAt: tick, rule: rule}}
+	}
+	items := []readyToRunItem{item(first), item(independent), item(second), item(third)}
+
+	result := sch.sequenceDependentRules(items)
+
+	require.Len(t, result, 2)
+	require.Equal(t, "third", result[0].rule.UID)
+	require.Equal(t, independent, result[1].rule)
+	require.Nil(t, result[1].afterEval)
+
+	// each rule of the chain dispatches the next one after it is evaluated.
+	expected := []*readyToRunItem{&items[0], &items[2]}
+	current := result[0]
+	for _, next := range expected {
+		require.NotNil(t, current.afterEval)
+		current.afterEval()
+		select {
+		case e := <-next.ruleInfo.evalCh:
+			require.Equal(t, next.rule, e.rule)
+			require.Equal(t, tick, e.scheduledAt)
+			current = readyToRunItem{ruleInfo: next.ruleInfo, evaluation: *e}
+		case <-time.After(time.Second):
+			require.Fail(t, "the next rule of the chain was not dispatched", next.rule.UID)
+		}
+	}
+	require.Nil(t, current.afterEval)
+}
+
+func TestRunEvaluationDropped(t *testing.T) {
+	sch := setupScheduler(t, nil, nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// the buffered channel holds the evaluation that is not picked up yet
+	info := newAlertRuleInfo(ctx)
+	info.evalCh = make(chan *evaluation, 1)
+	rule := models.AlertRuleGen()()
+	chained := false
+	info.evalCh <- &evaluation{scheduledAt: time.Now(), rule: rule, afterEval: func() {
+		chained = true
+	}}
+	next := readyToRunItem{ruleInfo: info, evaluation: evaluation{scheduledAt: time.Now().Add(time.Second), rule: rule}}
+
+	sch.runEvaluation(next)
+
+	require.Equal(t, next.scheduledAt, (<-info.evalCh).scheduledAt)
+	// the rules that depend on the rule of the dropped evaluation are still evaluated.
+	require.True(t, chained)
+}
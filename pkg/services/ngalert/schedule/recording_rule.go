@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+// errInvalidRecordingResults is returned when the results of a recording rule
+// cannot be recorded. The evaluation is not retried as it would fail the same way.
+var errInvalidRecordingResults = errors.New("invalid results of recording rule")
+
+// recordRule evaluates a recording rule and writes its results as samples of
+// the metric of the rule at the scheduled time. Recording rules do not have
+// a state. It returns an error only if the evaluation should be retried.
+func (sch *schedule) recordRule(ctx context.Context, logger log.Logger, f fingerprint, attempt int64, e *evaluation, span trace.Span, retry bool) error {
+	logger = logger.New("version", e.rule.Version, "fingerprint", f, "attempt", attempt, "now", e.scheduledAt).FromContext(ctx)
+	if sch.recordingWriter == nil {
+		logger.Debug("Skip evaluation of the recording rule because recording rules are disabled")
+		return nil
+	}
+
+	orgID := fmt.Sprint(e.rule.OrgID)
+	start := sch.clock.Now()
+	evalCtx := eval.NewContext(ctx, SchedulerUserFor(e.rule.OrgID))
+	ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
+	var resp *backend.QueryDataResponse
+	if err == nil {
+		resp, err = ruleEval.EvaluateRaw(ctx, e.scheduledAt)
+	}
+	dur := sch.clock.Now().Sub(start)
+	sch.metrics.RecordingEvalTotal.WithLabelValues(orgID).Inc()
+	sch.metrics.RecordingEvalDuration.WithLabelValues(orgID).Observe(dur.Seconds())
+
+	if ctx.Err() != nil { // check if the context is not cancelled. The evaluation can be a long-running task.
+		span.SetStatus(codes.Error, "rule evaluation cancelled")
+		logger.Debug("Skip writing the results because the context has been cancelled")
+		return nil
+	}
+
+	var samples []writer.Sample
+	if err == nil {
+		samples, err = recordingSamples(e.rule, resp)
+	}
+	if err == nil && len(e.rule.Dependencies) > 0 {
+		samples = sch.getInhibition(e.rule).filterSamples(samples)
+	}
+	if err == nil {
+		logger.Debug("Recording rule evaluated", "samples", len(samples), "duration", dur)
+		span.AddEvent("rule evaluated", trace.WithAttributes(
+			attribute.Int64("samples", int64(len(samples))),
+		))
+		start = sch.clock.Now()
+		err = sch.recordingWriter.Write(ctx, e.rule.OrgID, e.rule.Record.Metric, e.scheduledAt, samples)
+		sch.metrics.RecordingWriteDuration.WithLabelValues(orgID).Observe(sch.clock.Now().Sub(start).Seconds())
+	}
+	if err == nil {
+		return nil
+	}
+
+	sch.metrics.RecordingEvalFailures.WithLabelValues(orgID).Inc()
+	span.SetStatus(codes.Error, "recording rule evaluation failed")
+	span.RecordError(err)
+	logger.Error("Failed to record rule", "error", err, "duration", dur)
+	if retry && !errors.Is(err, errInvalidRecordingResults) {
+		return fmt.Errorf("failed to record rule: %w", err)
+	}
+	return nil
+}
+
+// recordingSamples returns the samples of the query or expression recorded by
+// the rule. The labels of the rule are added to the labels of the samples,
+// and take precedence over them.
+func recordingSamples(rule *ngmodels.AlertRule, resp *backend.QueryDataResponse) ([]writer.Sample, error) {
+	res, ok := resp.Responses[rule.Record.From]
+	if !ok {
+		return nil, nil
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	samples, err := writer.SamplesFromFrames(res.Frames)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidRecordingResults, err)
+	}
+	ruleLabels := rule.GetLabels()
+	if len(ruleLabels) == 0 {
+		return samples, nil
+	}
+	for i, s := range samples {
+		labels := make(data.Labels, len(s.Labels)+len(ruleLabels))
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		for k, v := range ruleLabels {
+			labels[k] = v
+		}
+		samples[i].Labels = labels
+	}
+	return samples, nil
+}
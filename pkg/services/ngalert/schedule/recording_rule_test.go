@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+func TestRecordRule(t *testing.T) {
+	span := trace.SpanFromContext(context.Background())
+	logger := log.NewNopLogger()
+
+	t.Run("writes results at the scheduled time", func(t *testing.T) {
+		sch := setupScheduler(t, nil, nil, nil, nil, nil)
+		w := &writer.FakeWriter{}
+		sch.recordingWriter = w
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("test_metric"), models.WithLabels(map[string]string{"team": "a"}))()
+		scheduledAt := time.Unix(1700000000, 0)
+
+		err := sch.recordRule(context.Background(), logger, 0, 1, &evaluation{scheduledAt: scheduledAt, rule: rule}, span, true)
+
+		require.NoError(t, err)
+		writes := w.GetWrites()
+		require.Len(t, writes, 1)
+		require.Equal(t, rule.OrgID, writes[0].OrgID)
+		require.Equal(t, "test_metric", writes[0].Metric)
+		require.Equal(t, scheduledAt, writes[0].Time)
+		require.Equal(t, []writer.Sample{{Labels: data.Labels{"team": "a"}, Value: 1}}, writes[0].Samples)
+	})
+
+	t.Run("does nothing when recording rules are disabled", func(t *testing.T) {
+		sch := setupScheduler(t, nil, nil, nil, nil, nil)
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("test_metric"))()
+
+		err := sch.recordRule(context.Background(), logger, 0, 1, &evaluation{scheduledAt: time.Now(), rule: rule}, span, true)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("retries failed writes", func(t *testing.T) {
+		sch := setupScheduler(t, nil, nil, nil, nil, nil)
+		w := &writer.FakeWriter{Err: errors.New("unavailable")}
+		sch.recordingWriter = w
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithRecord("test_metric"))()
+
+		err := sch.recordRule(context.Background(), logger, 0, 1, &evaluation{scheduledAt: time.Now(), rule: rule}, span, true)
+		require.ErrorContains(t, err, "unavailable")
+
+		err = sch.recordRule(context.Background(), logger, 0, 2, &evaluation{scheduledAt: time.Now(), rule: rule}, span, false)
+		require.NoError(t, err)
+		require.Len(t, w.GetWrites(), 2)
+	})
+}
+
+func TestRecordingSamples(t *testing.T) {
+	rule := models.AlertRuleGen(models.WithRecord("test_metric"), models.WithLabels(map[string]string{"team": "a"}))()
+	from := rule.Record.From
+
+	t.Run("merges labels of the rule", func(t *testing.T) {
+		resp := &backend.QueryDataResponse{Responses: backend.Responses{
+			from: {Frames: data.Frames{
+				data.NewFrame("", data.NewField("Value", data.Labels{"instance": "x", "team": "b"}, []float64{2})),
+			}},
+		}}
+		samples, err := recordingSamples(rule, resp)
+		require.NoError(t, err)
+		require.Equal(t, []writer.Sample{{Labels: data.Labels{"instance": "x", "team": "a"}, Value: 2}}, samples)
+	})
+
+	t.Run("returns query errors", func(t *testing.T) {
+		resp := &backend.QueryDataResponse{Responses: backend.Responses{
+			from: {Error: errors.New("query failed")},
+		}}
+		_, err := recordingSamples(rule, resp)
+		require.ErrorContains(t, err, "query failed")
+		require.NotErrorIs(t, err, errInvalidRecordingResults)
+	})
+
+	t.Run("rejects time series", func(t *testing.T) {
+		resp := &backend.QueryDataResponse{Responses: backend.Responses{
+			from: {Frames: data.Frames{
+				data.NewFrame("",
+					data.NewField("Time", nil, []time.Time{time.Now()}),
+					data.NewField("Value", nil, []float64{1}),
+				),
+			}},
+		}}
+		_, err := recordingSamples(rule, resp)
+		require.ErrorIs(t, err, errInvalidRecordingResults)
+	})
+}
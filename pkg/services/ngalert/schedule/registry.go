@@ -130,6 +130,9 @@ type evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// afterEval is called when the evaluation is finished or skipped. It is used
+	// to evaluate the rules that depend on the rule after it.
+	afterEval func()
 }
 
 type alertRulesRegistry struct {
@@ -329,6 +332,13 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeLabels(rule.Labels)
 	writeString(rule.Condition)
 	writeQuery()
+	if rule.Record != nil {
+		writeString(rule.Record.Metric)
+		writeString(rule.Record.From)
+	}
+	if rule.NotificationSettings != nil {
+		writeString(rule.NotificationSettings.Fingerprint().String())
+	}
 
 	if rule.IsPaused {
 		writeInt(1)
@@ -342,6 +352,7 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(rule.OrgID)
 	writeInt(rule.IntervalSeconds)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
 	writeLabels(rule.Annotations)
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
@@ -353,5 +364,11 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(int64(rule.RuleGroupIndex))
 	writeString(string(rule.NoDataState))
 	writeString(string(rule.ExecErrState))
+	for _, dep := range rule.Dependencies {
+		writeString(dep.RuleUID)
+		for _, l := range dep.Equal {
+			writeString(l)
+		}
+	}
 	return fingerprint(sum.Sum64())
 }
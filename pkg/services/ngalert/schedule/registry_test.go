@@ -408,6 +408,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NoDataState:     "test-nodata",
 			ExecErrState:    "test-err",
 			For:             12,
+			KeepFiringFor:   13,
 			Annotations: map[string]string{
 				"key-annotation": "value-annotation",
 			},
@@ -415,6 +416,16 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 				"key-label": "value-label",
 			},
 			IsPaused: false,
+			Record: &models.Record{
+				Metric: "test_metric",
+				From:   "A",
+			},
+			Dependencies: []models.RuleDependency{
+				{RuleUID: "upstream-1", Equal: []string{"instance"}},
+			},
+			NotificationSettings: &models.NotificationSettings{
+				Receiver: "receiver-1",
+			},
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -443,6 +454,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NoDataState:     "test-nodata2",
 			ExecErrState:    "test-err2",
 			For:             1141,
+			KeepFiringFor:   1142,
 			Annotations: map[string]string{
 				"key-annotation2": "value-annotation",
 			},
@@ -450,6 +462,17 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 				"key-label": "value-label23",
 			},
 			IsPaused: true,
+			Record: &models.Record{
+				Metric: "test_metric_2",
+				From:   "B",
+			},
+			Dependencies: []models.RuleDependency{
+				{RuleUID: "upstream-2", Equal: []string{"job"}},
+			},
+			NotificationSettings: &models.NotificationSettings{
+				Receiver: "receiver-2",
+				GroupBy:  []string{models.GroupByAll},
+			},
 		}
 
 		excludedFields := map[string]struct{}{
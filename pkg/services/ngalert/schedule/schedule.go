@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util/ticker"
@@ -87,6 +88,10 @@ type schedule struct {
 	alertsSender    AlertsSender
 	minRuleInterval time.Duration
 
+	// recordingWriter writes the results of recording rules. It is nil when
+	// recording rules are disabled, and they are then not evaluated.
+	recordingWriter writer.Writer
+
 	// schedulableAlertRules contains the alert rules that are considered for
 	// evaluation in the current tick. The evaluation of an alert rule in the
 	// current tick depends on its evaluation interval and when it was
@@ -108,6 +113,7 @@ type SchedulerCfg struct {
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
+	RecordingWriter      writer.Writer
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 }
@@ -129,6 +135,7 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
 		tracer:                cfg.Tracer,
 	}
 
@@ -321,21 +328,13 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
 	}
 
-	for i := range readyToRun {
-		item := readyToRun[i]
+	// rules that depend on other rules are evaluated after them, so only the first rule of such groups is dispatched.
+	toDispatch := sch.sequenceDependentRules(readyToRun)
+	for i := range toDispatch {
+		item := toDispatch[i]
 
 		time.AfterFunc(time.Duration(int64(i)*step), func() {
-			key := item.rule.GetKey()
-			success, dropped := item.ruleInfo.eval(&item.evaluation)
-			if !success {
-				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
-				return
-			}
-			if dropped != nil {
-				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
-				orgID := fmt.Sprint(key.OrgID)
-				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
-			}
+			sch.runEvaluation(item)
 		})
 	}
 
@@ -348,6 +347,28 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	return readyToRun, registeredDefinitions, updatedRules
 }
 
+// runEvaluation sends the evaluation to the evaluation routine of the rule.
+func (sch *schedule) runEvaluation(item readyToRunItem) {
+	key := item.rule.GetKey()
+	success, dropped := item.ruleInfo.eval(&item.evaluation)
+	if dropped != nil && dropped.afterEval != nil {
+		// the dependent rules of the dropped evaluation would not be evaluated otherwise
+		dropped.afterEval()
+	}
+	if !success {
+		sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", item.scheduledAt)...)
+		if item.afterEval != nil {
+			item.afterEval()
+		}
+		return
+	}
+	if dropped != nil {
+		sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", item.scheduledAt)...)
+		orgID := fmt.Sprint(key.OrgID)
+		sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
+	}
+}
+
 //nolint:gocyclo
 func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertRuleKey, evalCh <-chan *evaluation, updateCh <-chan ruleVersionAndPauseStatus) error {
 	grafanaCtx = ngmodels.WithRuleKey(grafanaCtx, key)
@@ -445,6 +466,13 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				attribute.Int64("results", int64(len(results))),
 			))
 		}
+		if len(e.rule.Dependencies) > 0 {
+			var inhibitedCount int
+			results, inhibitedCount = sch.getInhibition(e.rule).apply(e.rule, results)
+			if inhibitedCount > 0 {
+				logger.Debug("Some instances of the rule are inhibited by the rule it depends on", "inhibited", inhibitedCount)
+			}
+		}
 		start = sch.clock.Now()
 		processedStates := sch.stateManager.ProcessEvalResults(
 			ctx,
@@ -470,6 +498,8 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 	}
 
 	evalRunning := false
+	// inhibited is true if all instances of the rule are inhibited by the rule it depends on.
+	inhibited := false
 	var currentFingerprint fingerprint
 	defer sch.stopApplied(key)
 	for {
@@ -492,6 +522,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				return nil
 			}
 			if evalRunning {
+				if ctx.afterEval != nil {
+					ctx.afterEval()
+				}
 				continue
 			}
 
@@ -499,6 +532,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				evalRunning = true
 				defer func() {
 					evalRunning = false
+					if ctx.afterEval != nil {
+						ctx.afterEval()
+					}
 					sch.evalApplied(key, ctx.scheduledAt)
 				}()
 
@@ -524,6 +560,19 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 						return
 					}
 
+					if inh := sch.getInhibition(ctx.rule); inh.ruleUID != "" {
+						sch.metrics.EvalInhibited.WithLabelValues(fmt.Sprint(ctx.rule.OrgID)).Inc()
+						if !inhibited {
+							logger.Debug("Clearing the state of the rule because it is inhibited by the rule it depends on", "upstreamRuleUID", inh.ruleUID)
+							states := sch.stateManager.ResetStateByRuleUID(grafanaCtx, ctx.rule, ngmodels.StateReasonInhibited)
+							notify(states)
+							inhibited = true
+						}
+						logger.Debug("Skip rule evaluation because it is inhibited by the rule it depends on", "upstreamRuleUID", inh.ruleUID)
+						return
+					}
+					inhibited = false
+
 					fpStr := currentFingerprint.String()
 					utcTick := ctx.scheduledAt.UTC().Format(time.RFC3339Nano)
 					tracingCtx, span := sch.tracer.Start(grafanaCtx, "alert rule execution", trace.WithAttributes(
@@ -543,7 +592,12 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 					}
 
 					retry := attempt < sch.maxAttempts
-					err := evaluate(tracingCtx, f, attempt, ctx, span, retry)
+					var err error
+					if ctx.rule.Type() == ngmodels.RuleTypeRecording {
+						err = sch.recordRule(tracingCtx, logger, f, attempt, ctx, span, retry)
+					} else {
+						err = evaluate(tracingCtx, f, attempt, ctx, span, retry)
+					}
 					// This is extremely confusing - when we exhaust all retry attempts, or we have no retryable errors
 					// we return nil - so technically, this is meaningless to know whether the evaluation has errors or not.
 					span.End()
@@ -0,0 +1,184 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var errAcknowledgementsNotSupported = errors.New("acknowledgement of alert instances is not supported")
+
+// UpdateAcknowledgement acknowledges, assigns or comments on the alert instance of the rule with the labels of the
+// command. The labels do not need to include the internal labels of the instance. It returns the new acknowledgement,
+// or ngModels.ErrAlertInstanceNotFound if the instance does not exist.
+func (st *Manager) UpdateAcknowledgement(ctx context.Context, cmd ngModels.UpdateAlertInstanceAcknowledgementCommand) (*ngModels.AlertInstanceAcknowledgement, error) {
+	if st.ackStore == nil {
+		return nil, errAcknowledgementsNotSupported
+	}
+	s := st.findStateByLabels(cmd.OrgID, cmd.RuleUID, cmd.Labels)
+	if s == nil {
+		return nil, ngModels.ErrAlertInstanceNotFound
+	}
+	key, err := s.GetAlertInstanceKey()
+	if err != nil {
+		return nil, err
+	}
+
+	st.ackMtx.Lock()
+	defer st.ackMtx.Unlock()
+	now := st.clock.Now().UTC()
+	ack := s.GetAcknowledgement().Copy()
+	if ack == nil {
+		ack = &ngModels.AlertInstanceAcknowledgement{}
+	}
+	ack.AlertInstanceKey = key
+	if cmd.Acknowledged != nil {
+		switch {
+		case !*cmd.Acknowledged:
+			ack.AcknowledgedBy = ""
+			ack.AcknowledgedAt = time.Time{}
+		case s.State == eval.Normal:
+			return nil, ngModels.ErrAlertInstanceNormal
+		case !ack.IsAcknowledged():
+			ack.AcknowledgedBy = cmd.User
+			ack.AcknowledgedAt = now
+		}
+	}
+	if cmd.Assignee != nil {
+		ack.Assignee = *cmd.Assignee
+	}
+	if cmd.Comment != "" {
+		ack.Comments = append(ack.Comments, ngModels.AlertInstanceComment{
+			Author:  cmd.User,
+			Text:    cmd.Comment,
+			Created: now,
+		})
+	}
+	ack.Updated = now
+
+	if err := st.saveAcknowledgement(ctx, ack); err != nil {
+		return nil, err
+	}
+	if ack.IsEmpty() {
+		s.SetAcknowledgement(nil)
+	} else {
+		s.SetAcknowledgement(ack)
+	}
+	return ack.Copy(), nil
+}
+
+// findStateByLabels returns the state of the rule with the labels, ignoring the internal labels.
+func (st *Manager) findStateByLabels(orgID int64, ruleUID string, lbs map[string]string) *State {
+	wanted := make(map[string]string, len(lbs))
+	for k, v := range lbs {
+		wanted[k] = v
+	}
+	ngModels.WithoutInternalLabels()(wanted)
+	for _, s := range st.cache.getStatesForRuleUID(orgID, ruleUID, false) {
+		if labelsEqual(s.GetLabels(ngModels.WithoutInternalLabels()), wanted) {
+			return s
+		}
+	}
+	return nil
+}
+
+func labelsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// clearAcknowledgement removes the acknowledgement of the state but keeps its assignee and comments.
+func (st *Manager) clearAcknowledgement(ctx context.Context, logger log.Logger, s *State) {
+	st.ackMtx.Lock()
+	defer st.ackMtx.Unlock()
+	ack := s.GetAcknowledgement()
+	if !ack.IsAcknowledged() {
+		// the acknowledgement was removed by the API in the meantime.
+		return
+	}
+	ack = ack.Copy()
+	ack.AcknowledgedBy = ""
+	ack.AcknowledgedAt = time.Time{}
+	ack.Updated = st.clock.Now().UTC()
+	if st.ackStore != nil {
+		if err := st.saveAcknowledgement(ctx, ack); err != nil {
+			logger.Error("Failed to clear the acknowledgement of the alert state", "error", err)
+		}
+	}
+	if ack.IsEmpty() {
+		ack = nil
+	}
+	s.SetAcknowledgement(ack)
+}
+
+// loadAcknowledgements replaces the acknowledgements of the states of the rule with the ones in the store, which
+// can be changed by the other Grafana instances in high availability mode.
+func (st *Manager) loadAcknowledgements(ctx context.Context, logger log.Logger, alertRule *ngModels.AlertRule) {
+	if !st.syncAcknowledgements || st.ackStore == nil {
+		return
+	}
+	states := st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID, false)
+	if len(states) == 0 {
+		return
+	}
+
+	st.ackMtx.Lock()
+	defer st.ackMtx.Unlock()
+	acks, err := st.ackStore.ListAlertInstanceAcknowledgements(ctx, &ngModels.ListAlertInstanceAcknowledgementsQuery{
+		RuleOrgID: alertRule.OrgID,
+		RuleUID:   alertRule.UID,
+	})
+	if err != nil {
+		logger.Error("Failed to fetch the acknowledgements of the alert states", "error", err)
+		return
+	}
+	byKey := make(map[ngModels.AlertInstanceKey]*ngModels.AlertInstanceAcknowledgement, len(acks))
+	for _, ack := range acks {
+		byKey[ack.AlertInstanceKey] = ack
+	}
+	for _, s := range states {
+		key, err := s.GetAlertInstanceKey()
+		if err != nil {
+			continue
+		}
+		if ack := byKey[key]; ack != nil || s.GetAcknowledgement() != nil {
+			s.SetAcknowledgement(ack)
+		}
+	}
+}
+
+// saveAcknowledgement saves the acknowledgement, or deletes it if it is empty.
+func (st *Manager) saveAcknowledgement(ctx context.Context, ack *ngModels.AlertInstanceAcknowledgement) error {
+	if ack.IsEmpty() {
+		return st.ackStore.DeleteAlertInstanceAcknowledgement(ctx, ack.AlertInstanceKey)
+	}
+	return st.ackStore.SaveAlertInstanceAcknowledgement(ctx, *ack)
+}
+
+// fetchAcknowledgements returns the acknowledgements of the alert instances of the organization.
+func (st *Manager) fetchAcknowledgements(ctx context.Context, orgID int64) map[ngModels.AlertInstanceKey]*ngModels.AlertInstanceAcknowledgement {
+	if st.ackStore == nil {
+		return nil
+	}
+	acks, err := st.ackStore.ListAlertInstanceAcknowledgements(ctx, &ngModels.ListAlertInstanceAcknowledgementsQuery{RuleOrgID: orgID})
+	if err != nil {
+		st.log.Error("Unable to fetch acknowledgements of alert instances", "error", err)
+		return nil
+	}
+	result := make(map[ngModels.AlertInstanceKey]*ngModels.AlertInstanceAcknowledgement, len(acks))
+	for _, ack := range acks {
+		result[ack.AlertInstanceKey] = ack
+	}
+	return result
+}
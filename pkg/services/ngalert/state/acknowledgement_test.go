@@ -0,0 +1,203 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUpdateAcknowledgement(t *testing.T) {
+	interval := 10 * time.Second
+	tN := func(n int) time.Time {
+		return time.Unix(0, 0).Add(time.Duration(n) * interval)
+	}
+	setup := func(t *testing.T, opts ...func(*ManagerCfg)) (*Manager, *FakeAcknowledgementStore, *ngmodels.AlertRule, map[string]string) {
+		ackStore := &FakeAcknowledgementStore{}
+		mockClock := clock.NewMock()
+		mockClock.Set(tN(1))
+		cfg := ManagerCfg{
+			Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			Tracer:                  tracing.InitializeTracerForTest(),
+			Log:                     log.New("ngalert.state.manager"),
+			InstanceStore:           &FakeInstanceStore{},
+			AcknowledgementStore:    ackStore,
+			Images:                  &NotAvailableImageService{},
+			Clock:                   mockClock,
+			Historian:               &FakeHistorian{},
+			MaxStateSaveConcurrency: 1,
+		}
+		for _, opt := range opts {
+			opt(&cfg)
+		}
+		st := NewManager(cfg)
+		rule := ngmodels.AlertRuleGen(
+			ngmodels.WithInterval(interval),
+			ngmodels.WithFor(0),
+			ngmodels.WithLabels(map[string]string{"team": "a"}),
+		)()
+		st.ProcessEvalResults(context.Background(), tN(1), rule, eval.Results{{State: eval.Alerting, EvaluatedAt: tN(1)}}, nil)
+		states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		return st, ackStore, rule, states[0].GetLabels(ngmodels.WithoutInternalLabels())
+	}
+
+	t.Run("acknowledges, assigns and comments on a firing instance", func(t *testing.T) {
+		st, ackStore, rule, lbs := setup(t)
+
+		ack, err := st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+			OrgID:        rule.OrgID,
+			RuleUID:      rule.UID,
+			Labels:       lbs,
+			User:         "alice",
+			Acknowledged: util.Pointer(true),
+			Assignee:     util.Pointer("bob"),
+			Comment:      "looking into it",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "alice", ack.AcknowledgedBy)
+		require.Equal(t, tN(1).UTC(), ack.AcknowledgedAt)
+		require.Equal(t, "bob", ack.Assignee)
+		require.Equal(t, []ngmodels.AlertInstanceComment{{Author: "alice", Text: "looking into it", Created: tN(1).UTC()}}, ack.Comments)
+
+		s := st.GetStatesForRuleUID(rule.OrgID, rule.UID)[0]
+		require.Equal(t, ack, s.GetAcknowledgement())
+		require.Len(t, ackStore.Acks, 1)
+
+		// acknowledging again does not change who acknowledged the instance.
+		ack, err = st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+			OrgID:        rule.OrgID,
+			RuleUID:      rule.UID,
+			Labels:       lbs,
+			User:         "carol",
+			Acknowledged: util.Pointer(true),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "alice", ack.AcknowledgedBy)
+		require.Equal(t, "bob", ack.Assignee)
+		require.Len(t, ack.Comments, 1)
+	})
+
+	t.Run("deletes the acknowledgement when it becomes empty", func(t *testing.T) {
+		st, ackStore, rule, lbs := setup(t)
+
+		_, err := st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+			OrgID: rule.OrgID, RuleUID: rule.UID, Labels: lbs, User: "alice", Acknowledged: util.Pointer(true),
+		})
+		require.NoError(t, err)
+		require.Len(t, ackStore.Acks, 1)
+
+		_, err = st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+			OrgID: rule.OrgID, RuleUID: rule.UID, Labels: lbs, User: "alice", Acknowledged: util.Pointer(false),
+		})
+		require.NoError(t, err)
+		require.Empty(t, ackStore.Acks)
+		require.Nil(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID)[0].GetAcknowledgement())
+	})
+
+	t.Run("returns ErrAlertInstanceNotFound for unknown instances", func(t *testing.T) {
+		st, _, rule, _ := setup(t)
+
+		_, err := st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+			OrgID: rule.OrgID, RuleUID: rule.UID, Labels: map[string]string{"unknown": "label"}, User: "alice", Acknowledged: util.Pointer(true),
+		})
+		require.ErrorIs(t, err, ngmodels.ErrAlertInstanceNotFound)
+	})
+
+	t.Run("clears the acknowledgement when the instance fires again", func(t *testing.T) {
+		st, ackStore, rule, lbs := setup(t)
+
+		_, err := st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+			OrgID: rule.OrgID, RuleUID: rule.UID, Labels: lbs, User: "alice", Acknowledged: util.Pointer(true), Assignee: util.Pointer("bob"),
+		})
+		require.NoError(t, err)
+
+		transitions := st.ProcessEvalResults(context.Background(), tN(2), rule, eval.Results{{State: eval.Normal, EvaluatedAt: tN(2)}}, nil)
+		require.Equal(t, eval.Normal, transitions[0].State.State)
+		require.Equal(t, "alice", transitions[0].State.GetAcknowledgement().AcknowledgedBy, "the resolved alert keeps the acknowledgement")
+
+		_, err = st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+			OrgID: rule.OrgID, RuleUID: rule.UID, Labels: lbs, User: "alice", Acknowledged: util.Pointer(true),
+		})
+		require.ErrorIs(t, err, ngmodels.ErrAlertInstanceNormal)
+
+		transitions = st.ProcessEvalResults(context.Background(), tN(3), rule, eval.Results{{State: eval.Alerting, EvaluatedAt: tN(3)}}, nil)
+		ack := transitions[0].State.GetAcknowledgement()
+		require.False(t, ack.IsAcknowledged())
+		require.Equal(t, "bob", ack.Assignee)
+		for _, stored := range ackStore.Acks {
+			require.Empty(t, stored.AcknowledgedBy)
+			require.Equal(t, "bob", stored.Assignee)
+		}
+	})
+
+	t.Run("loads the acknowledgements changed by other instances", func(t *testing.T) {
+		st, ackStore, rule, _ := setup(t, func(cfg *ManagerCfg) { cfg.SyncAcknowledgements = true })
+		key, err := st.GetStatesForRuleUID(rule.OrgID, rule.UID)[0].GetAlertInstanceKey()
+		require.NoError(t, err)
+
+		require.NoError(t, ackStore.SaveAlertInstanceAcknowledgement(context.Background(), ngmodels.AlertInstanceAcknowledgement{
+			AlertInstanceKey: key, AcknowledgedBy: "alice", AcknowledgedAt: tN(1),
+		}))
+		transitions := st.ProcessEvalResults(context.Background(), tN(2), rule, eval.Results{{State: eval.Alerting, EvaluatedAt: tN(2)}}, nil)
+		require.Equal(t, "alice", transitions[0].State.GetAcknowledgement().AcknowledgedBy)
+
+		require.NoError(t, ackStore.DeleteAlertInstanceAcknowledgement(context.Background(), key))
+		transitions = st.ProcessEvalResults(context.Background(), tN(3), rule, eval.Results{{State: eval.Alerting, EvaluatedAt: tN(3)}}, nil)
+		require.Nil(t, transitions[0].State.GetAcknowledgement())
+	})
+
+	t.Run("can be updated while the rule is evaluated", func(t *testing.T) {
+		st, _, rule, lbs := setup(t, func(cfg *ManagerCfg) { cfg.SyncAcknowledgements = true })
+
+		var wg sync.WaitGroup
+		errs := make([]error, 10)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range errs {
+				_, errs[i] = st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{
+					OrgID: rule.OrgID, RuleUID: rule.UID, Labels: lbs, User: "alice", Acknowledged: util.Pointer(i%2 == 0),
+				})
+			}
+		}()
+		for i := 0; i < 10; i++ {
+			state := eval.Alerting
+			if i%2 == 0 {
+				state = eval.Normal
+			}
+			for _, transition := range st.ProcessEvalResults(context.Background(), tN(2+i), rule, eval.Results{{State: state, EvaluatedAt: tN(2 + i)}}, nil) {
+				StateToPostableAlert(transition.State, nil)
+			}
+		}
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				// the instance cannot be acknowledged while it is Normal.
+				require.ErrorIs(t, err, ngmodels.ErrAlertInstanceNormal)
+			}
+		}
+	})
+
+	t.Run("is not supported without a store", func(t *testing.T) {
+		st := NewManager(ManagerCfg{
+			Metrics: metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			Tracer:  tracing.InitializeTracerForTest(),
+			Log:     log.New("ngalert.state.manager"),
+			Clock:   clock.NewMock(),
+		})
+		_, err := st.UpdateAcknowledgement(context.Background(), ngmodels.UpdateAlertInstanceAcknowledgementCommand{OrgID: 1, RuleUID: "test"})
+		require.ErrorIs(t, err, errAcknowledgementsNotSupported)
+	})
+}
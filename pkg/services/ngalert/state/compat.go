@@ -56,6 +56,23 @@ func StateToPostableAlert(alertState *State, appURL *url.URL) *models.PostableAl
 		nA[alertingModels.StateReasonAnnotation] = alertState.StateReason
 	}
 
+	if alertState.Flapping {
+		nA[ngModels.FlappingAnnotation] = "true"
+	}
+
+	if ack := alertState.GetAcknowledgement(); ack != nil {
+		if ack.IsAcknowledged() {
+			nA[ngModels.AcknowledgedByAnnotation] = ack.AcknowledgedBy
+			nA[ngModels.AcknowledgedAtAnnotation] = ack.AcknowledgedAt.UTC().Format(time.RFC3339)
+		}
+		if ack.Assignee != "" {
+			nA[ngModels.AssigneeAnnotation] = ack.Assignee
+		}
+		if len(ack.Comments) > 0 {
+			nA[ngModels.CommentAnnotation] = ack.Comments[len(ack.Comments)-1].Text
+		}
+	}
+
 	if alertState.OrgID != 0 {
 		nA[alertingModels.OrgIDAnnotation] = strconv.FormatInt(alertState.OrgID, 10)
 	}
@@ -157,6 +157,40 @@ func Test_StateToPostableAlert(t *testing.T) {
 				require.Equal(t, alertState.StateReason, result.Annotations[ngModels.StateReasonAnnotation])
 			})
 
+			t.Run("should add flapping annotation if flapping", func(t *testing.T) {
+				alertState := randomState(tc.state)
+				alertState.Flapping = true
+				result := StateToPostableAlert(alertState, appURL)
+				require.Equal(t, "true", result.Annotations[ngModels.FlappingAnnotation])
+			})
+
+			t.Run("should add acknowledgement annotations if acknowledged", func(t *testing.T) {
+				alertState := randomState(tc.state)
+				alertState.SetAcknowledgement(&ngModels.AlertInstanceAcknowledgement{
+					AcknowledgedBy: "alice",
+					AcknowledgedAt: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+					Assignee:       "bob",
+					Comments: []ngModels.AlertInstanceComment{
+						{Author: "alice", Text: "first"},
+						{Author: "bob", Text: "latest"},
+					},
+				})
+				result := StateToPostableAlert(alertState, appURL)
+				require.Equal(t, "alice", result.Annotations[ngModels.AcknowledgedByAnnotation])
+				require.Equal(t, "2023-01-02T03:04:05Z", result.Annotations[ngModels.AcknowledgedAtAnnotation])
+				require.Equal(t, "bob", result.Annotations[ngModels.AssigneeAnnotation])
+				require.Equal(t, "latest", result.Annotations[ngModels.CommentAnnotation])
+			})
+
+			t.Run("should not add acknowledgement annotations if not acknowledged", func(t *testing.T) {
+				alertState := randomState(tc.state)
+				alertState.SetAcknowledgement(&ngModels.AlertInstanceAcknowledgement{Assignee: "bob"})
+				result := StateToPostableAlert(alertState, appURL)
+				require.NotContains(t, result.Annotations, ngModels.AcknowledgedByAnnotation)
+				require.NotContains(t, result.Annotations, ngModels.AcknowledgedAtAnnotation)
+				require.Equal(t, "bob", result.Annotations[ngModels.AssigneeAnnotation])
+			})
+
 			switch tc.state {
 			case eval.NoData:
 				t.Run("should keep existing labels and change name", func(t *testing.T) {
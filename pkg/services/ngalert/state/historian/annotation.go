@@ -170,6 +170,121 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 	return frame, nil
 }
 
+// QueryTransitions retrieves the state transitions of alert instances of the given rules from annotations.
+// Annotations can only be queried rule by rule, and the labels of the instances are parsed from the text of the annotations.
+// The limit applies to the annotations of each rule, and the most recent annotations are returned first.
+func (h *AnnotationBackend) QueryTransitions(ctx context.Context, query ngmodels.HistoryTransitionsQuery) (ngmodels.HistoryTransitions, error) {
+	logger := h.log.FromContext(ctx)
+	if len(query.RuleUIDs) == 0 {
+		return ngmodels.HistoryTransitions{}, fmt.Errorf("at least one ruleUID is required to query annotations")
+	}
+	query, err := withDefaultRange(query)
+	if err != nil {
+		return ngmodels.HistoryTransitions{}, err
+	}
+
+	limit := transitionsLimit(query.Limit)
+	transitions := make([]ngmodels.HistoryTransition, 0)
+	truncated := false
+	// since is the time from which the transitions of all rules are complete when the annotations of some rules
+	// reached the limit.
+	var since time.Time
+	for _, uid := range query.RuleUIDs {
+		rule, err := h.rules.GetAlertRuleByUID(ctx, &ngmodels.GetAlertRuleByUIDQuery{UID: uid, OrgID: query.OrgID})
+		if err != nil {
+			return ngmodels.HistoryTransitions{}, fmt.Errorf("failed to look up the requested rule %s: %w", uid, err)
+		}
+		items, err := h.store.Find(ctx, &annotations.ItemQuery{
+			AlertID:      rule.ID,
+			OrgID:        query.OrgID,
+			From:         query.From.UnixMilli(),
+			To:           query.To.UnixMilli(),
+			Limit:        int64(limit),
+			SignedInUser: query.SignedInUser,
+		})
+		if err != nil {
+			return ngmodels.HistoryTransitions{}, fmt.Errorf("failed to query annotations for state history: %w", err)
+		}
+		if len(items) >= limit {
+			truncated = true
+			oldest := time.UnixMilli(items[0].Time)
+			for _, item := range items {
+				if t := time.UnixMilli(item.Time); t.Before(oldest) {
+					oldest = t
+				}
+			}
+			if oldest.After(since) {
+				since = oldest
+			}
+		}
+		for _, item := range items {
+			lbls, err := parseAnnotationLabels(item.Text, rule.Title)
+			if err != nil {
+				logger.Warn("Failed to parse labels from the text of the annotation, skipping", "id", item.ID, "error", err)
+				continue
+			}
+			if !matchesLabels(lbls, query.Labels) {
+				continue
+			}
+			previous, previousReason := parseStateAndReason(item.PrevState)
+			current, currentReason := parseStateAndReason(item.NewState)
+			var ackBy, assignee string
+			if item.Data != nil {
+				ackBy = item.Data.Get("acknowledgedBy").MustString()
+				assignee = item.Data.Get("assignee").MustString()
+			}
+			transitions = append(transitions, ngmodels.HistoryTransition{
+				Time:           time.UnixMilli(item.Time),
+				RuleUID:        uid,
+				Fingerprint:    labelFingerprint(lbls),
+				Labels:         lbls,
+				Previous:       previous,
+				PreviousReason: previousReason,
+				Current:        current,
+				CurrentReason:  currentReason,
+				AcknowledgedBy: ackBy,
+				Assignee:       assignee,
+			})
+		}
+	}
+
+	if truncated {
+		// keep the most recent transitions of all rules, like the other backends.
+		complete := transitions[:0]
+		for _, t := range transitions {
+			if !t.Time.Before(since) {
+				complete = append(complete, t)
+			}
+		}
+		transitions = complete
+	}
+	return latestTransitions(transitions, limit, truncated), nil
+}
+
+// parseAnnotationLabels extracts the labels of the alert instance from the text built by buildAnnotationTextAndData.
+func parseAnnotationLabels(text, title string) (data.Labels, error) {
+	start := len(title) + len(" {")
+	if !strings.HasPrefix(text, title+" {") {
+		// The rule could have been renamed after the annotation was created.
+		start = strings.Index(text, " {") + len(" {")
+		if start < len(" {") {
+			return nil, fmt.Errorf("the text does not contain labels")
+		}
+	}
+	end := strings.LastIndex(text, "} - ")
+	if end < start {
+		return nil, fmt.Errorf("the text does not contain labels")
+	}
+	lbls, err := data.LabelsFromString(text[start:end])
+	if err != nil {
+		return nil, err
+	}
+	if lbls == nil {
+		lbls = data.Labels{}
+	}
+	return lbls, nil
+}
+
 func buildAnnotations(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []annotations.Item {
 	items := make([]annotations.Item, 0, len(states))
 	for _, state := range states {
@@ -225,6 +340,15 @@ func buildAnnotationTextAndData(rule history_model.RuleMeta, currentState *state
 		value = strings.Join(values, ", ")
 	}
 
+	if ack := currentState.GetAcknowledgement(); ack != nil {
+		if ack.AcknowledgedBy != "" {
+			jsonData.Set("acknowledgedBy", ack.AcknowledgedBy)
+		}
+		if ack.Assignee != "" {
+			jsonData.Set("assignee", ack.Assignee)
+		}
+	}
+
 	labels := removePrivateLabels(currentState.Labels)
 	return fmt.Sprintf("%s {%s} - %s", rule.Title, labels.String(), value), jsonData
 }
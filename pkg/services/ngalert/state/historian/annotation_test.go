@@ -100,6 +100,112 @@ grafana_alerting_state_history_writes_total{backend="annotations",org="1"} 2
 	})
 }
 
+func TestAnnotationQueryTransitions(t *testing.T) {
+	rules := fakes.NewRuleStore(t)
+	rule := models.AlertRuleGen(withOrgID(1), withUID("my-rule"))()
+	rule.Title = "MyAlert"
+	rules.Rules[1] = []*models.AlertRule{rule}
+	store := &fakeAnnotationStore{items: []*annotations.ItemDTO{
+		{ID: 2, Time: 20000, PrevState: "Alerting", NewState: "Normal (MissingSeries)", Text: "MyAlert {a=b, c=d} - No data"},
+		{ID: 1, Time: 10000, PrevState: "Normal", NewState: "Alerting", Text: "MyAlert {a=b, c=d} - A=1.000000"},
+		{ID: 3, Time: 15000, PrevState: "Normal", NewState: "Alerting", Text: "MyAlert {a=x} - A=1.000000"},
+		{ID: 4, Time: 15000, PrevState: "Normal", NewState: "Alerting", Text: "not parseable"},
+	}}
+	anns := NewAnnotationBackend(store, rules, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+	t.Run("requires rule UIDs", func(t *testing.T) {
+		_, err := anns.QueryTransitions(context.Background(), models.HistoryTransitionsQuery{OrgID: 1})
+		require.Error(t, err)
+	})
+
+	t.Run("parses labels and filters them", func(t *testing.T) {
+		res, err := anns.QueryTransitions(context.Background(), models.HistoryTransitionsQuery{
+			OrgID:    1,
+			RuleUIDs: []string{"my-rule"},
+			Labels:   map[string]string{"a": "b"},
+			From:     time.Unix(1, 0),
+			To:       time.Unix(100, 0),
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), store.lastQuery.From)
+		require.Equal(t, int64(100000), store.lastQuery.To)
+		require.Equal(t, rule.ID, store.lastQuery.AlertID)
+		require.False(t, res.Truncated)
+		require.Len(t, res.Transitions, 2)
+		lbls := map[string]string{"a": "b", "c": "d"}
+		require.Equal(t, models.HistoryTransition{
+			Time:        time.Unix(10, 0),
+			RuleUID:     "my-rule",
+			Fingerprint: labelFingerprint(lbls),
+			Labels:      lbls,
+			Previous:    "Normal",
+			Current:     "Alerting",
+		}, res.Transitions[0])
+		require.Equal(t, time.Unix(20, 0), res.Transitions[1].Time)
+		require.Equal(t, "Normal", res.Transitions[1].Current)
+		require.Equal(t, models.StateReasonMissingSeries, res.Transitions[1].CurrentReason)
+	})
+
+	t.Run("keeps the most recent transitions when the limit is reached", func(t *testing.T) {
+		res, err := anns.QueryTransitions(context.Background(), models.HistoryTransitionsQuery{
+			OrgID:    1,
+			RuleUIDs: []string{"my-rule"},
+			From:     time.Unix(1, 0),
+			To:       time.Unix(100, 0),
+			Limit:    2,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, int64(2), store.lastQuery.Limit)
+		require.True(t, res.Truncated)
+		require.Len(t, res.Transitions, 2)
+		require.Equal(t, time.Unix(15, 0), res.Transitions[0].Time)
+		require.Equal(t, time.Unix(20, 0), res.Transitions[1].Time)
+	})
+}
+
+func TestParseAnnotationLabels(t *testing.T) {
+	cases := []struct {
+		name  string
+		text  string
+		title string
+		exp   data.Labels
+		err   bool
+	}{
+		{name: "labels", text: "MyAlert {a=b, c=d} - A=1.000000", title: "MyAlert", exp: data.Labels{"a": "b", "c": "d"}},
+		{name: "no labels", text: "MyAlert {} - No data", title: "MyAlert", exp: data.Labels{}},
+		{name: "title with braces", text: "My {Alert} {a=b} - Error", title: "My {Alert}", exp: data.Labels{"a": "b"}},
+		{name: "renamed rule", text: "OldTitle {a=b} - Error", title: "MyAlert", exp: data.Labels{"a": "b"}},
+		{name: "no labels in text", text: "something else", title: "MyAlert", err: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lbls, err := parseAnnotationLabels(tc.text, tc.title)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.exp, lbls)
+		})
+	}
+}
+
+type fakeAnnotationStore struct {
+	items     []*annotations.ItemDTO
+	lastQuery *annotations.ItemQuery
+}
+
+func (f *fakeAnnotationStore) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	f.lastQuery = query
+	return f.items, nil
+}
+
+func (f *fakeAnnotationStore) Save(_ context.Context, _ *PanelKey, _ []annotations.Item, _ int64, _ log.Logger) error {
+	return nil
+}
+
 func createTestAnnotationBackendSut(t *testing.T) *AnnotationBackend {
 	return createTestAnnotationBackendSutWithMetrics(t, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
 }
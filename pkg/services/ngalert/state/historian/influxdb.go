@@ -0,0 +1,506 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	lp "github.com/influxdata/line-protocol"
+	"github.com/weaveworks/common/http/client"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+const (
+	// Only the org, the rule and the state are written as tags, so that the
+	// number of series does not grow with the instance labels. The instance
+	// labels are stored as JSON in the labels field.
+	influxTagState          = "state"
+	influxValueFieldPrefix  = "values_"
+	influxFieldSchema       = "schemaVersion"
+	influxFieldPrevious     = "previous"
+	influxFieldCurrent      = "current"
+	influxFieldError        = "error"
+	influxFieldCondition    = "condition"
+	influxFieldDashboardUID = "dashboardUID"
+	influxFieldPanelID      = "panelID"
+	influxFieldFingerprint  = "fingerprint"
+	influxFieldLabels       = "labels"
+	influxFieldAckBy        = "acknowledgedBy"
+	influxFieldAssignee     = "assignee"
+)
+
+var influxEntryFields = map[string]struct{}{
+	influxFieldSchema:       {},
+	influxFieldPrevious:     {},
+	influxFieldCurrent:      {},
+	influxFieldError:        {},
+	influxFieldCondition:    {},
+	influxFieldDashboardUID: {},
+	influxFieldPanelID:      {},
+	influxFieldFingerprint:  {},
+	influxFieldLabels:       {},
+	influxFieldAckBy:        {},
+	influxFieldAssignee:     {},
+}
+
+type remoteInfluxClient interface {
+	Ping(context.Context) error
+	Write(context.Context, []byte) error
+	Query(ctx context.Context, influxQL string) (InfluxQueryRes, error)
+}
+
+// InfluxBackend is a state.Historian that records state history to an external InfluxDB instance.
+type InfluxBackend struct {
+	client         remoteInfluxClient
+	measurement    string
+	externalLabels map[string]string
+	metrics        *metrics.Historian
+	log            log.Logger
+}
+
+func NewInfluxBackend(cfg InfluxConfig, req client.Requester, metrics *metrics.Historian) *InfluxBackend {
+	logger := log.New("ngalert.state.historian", "backend", "influxdb")
+	return &InfluxBackend{
+		client:         NewInfluxClient(cfg, req, metrics, logger),
+		measurement:    cfg.Measurement,
+		externalLabels: cfg.ExternalLabels,
+		metrics:        metrics,
+		log:            logger,
+	}
+}
+
+func (h *InfluxBackend) TestConnection(ctx context.Context) error {
+	return h.client.Ping(ctx)
+}
+
+// Record writes a number of state transitions for a given rule to an external InfluxDB instance.
+func (h *InfluxBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	points := statesToPoints(h.measurement, rule, states, h.externalLabels)
+
+	errCh := make(chan error, 1)
+	if len(points) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	lines, err := encodePoints(points)
+	if err != nil {
+		logger.Error("Failed to encode alert state history batch", "error", err)
+		errCh <- fmt.Errorf("failed to encode alert state history batch: %w", err)
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "influxdb").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(points)))
+
+		if err := h.client.Write(ctx, lines); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "influxdb").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(points)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from an external InfluxDB instance and formats the results into a dataframe.
+// The frame has the same shape as the one produced by the Loki backend.
+func (h *InfluxBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	now := time.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.From.After(query.To) {
+		return nil, fmt.Errorf("start time cannot be after end time")
+	}
+
+	influxQL := buildInfluxQuery(h.measurement, query)
+	res, err := h.client.Query(ctx, influxQL)
+	if err != nil {
+		return nil, err
+	}
+	return influxResultToFrame(res)
+}
+
+// QueryTransitions retrieves the state transitions of alert instances from an external InfluxDB instance.
+func (h *InfluxBackend) QueryTransitions(ctx context.Context, query models.HistoryTransitionsQuery) (models.HistoryTransitions, error) {
+	query, err := withDefaultRange(query)
+	if err != nil {
+		return models.HistoryTransitions{}, err
+	}
+
+	res, err := h.client.Query(ctx, buildInfluxTransitionsQuery(h.measurement, query))
+	if err != nil {
+		return models.HistoryTransitions{}, err
+	}
+
+	transitions := make([]models.HistoryTransition, 0)
+	for _, result := range res.Results {
+		for _, series := range result.Series {
+			for _, row := range series.Values {
+				ts, entry, _, err := influxRowToEntry(series, row)
+				if err != nil {
+					return models.HistoryTransitions{}, err
+				}
+				transitions = append(transitions, entryToTransition(ts, entry))
+			}
+		}
+	}
+	limit := transitionsLimit(query.Limit)
+	return latestTransitions(transitions, limit, len(transitions) >= limit), nil
+}
+
+func statesToPoints(measurement string, rule history_model.RuleMeta, states []state.StateTransition, externalLabels map[string]string) []*write.Point {
+	// External labels and the rule group and folder are written as fields,
+	// the entry fields take precedence over them.
+	ruleFields := make(map[string]any, len(externalLabels)+2)
+	for k, v := range externalLabels {
+		ruleFields[k] = v
+	}
+	ruleFields[GroupLabel] = rule.Group
+	ruleFields[FolderUIDLabel] = rule.NamespaceUID
+
+	points := make([]*write.Point, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		tags := map[string]string{
+			OrgIDLabel:     fmt.Sprint(rule.OrgID),
+			RuleUIDLabel:   rule.UID,
+			influxTagState: state.State.State.String(),
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		lbls, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			continue
+		}
+
+		fields := make(map[string]any, len(ruleFields)+len(influxEntryFields))
+		for k, v := range ruleFields {
+			fields[k] = v
+		}
+		fields[influxFieldSchema] = 1
+		fields[influxFieldPrevious] = state.PreviousFormatted()
+		fields[influxFieldCurrent] = state.Formatted()
+		fields[influxFieldCondition] = rule.Condition
+		fields[influxFieldDashboardUID] = rule.DashboardUID
+		fields[influxFieldPanelID] = rule.PanelID
+		fields[influxFieldFingerprint] = labelFingerprint(sanitizedLabels)
+		fields[influxFieldLabels] = string(lbls)
+		if state.State.State == eval.Error {
+			fields[influxFieldError] = state.Error.Error()
+		}
+		if ack := state.GetAcknowledgement(); ack != nil {
+			if ack.AcknowledgedBy != "" {
+				fields[influxFieldAckBy] = ack.AcknowledgedBy
+			}
+			if ack.Assignee != "" {
+				fields[influxFieldAssignee] = ack.Assignee
+			}
+		}
+		if state.State.State != eval.Error && state.State.State != eval.NoData {
+			for k, v := range state.State.Values {
+				// line protocol has no representation for NaN and infinities
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					continue
+				}
+				fields[influxValueFieldPrefix+k] = v
+			}
+		}
+
+		points = append(points, write.NewPoint(measurement, tags, fields, state.State.LastEvaluationTime))
+	}
+	return points
+}
+
+func encodePoints(points []*write.Point) ([]byte, error) {
+	var buf bytes.Buffer
+	e := lp.NewEncoder(&buf)
+	e.FailOnFieldErr(true)
+	e.SetPrecision(time.Nanosecond)
+	for _, p := range points {
+		if _, err := e.Encode(p); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func buildInfluxQuery(measurement string, query models.HistoryQuery) string {
+	conds := []string{
+		fmt.Sprintf("%s = %s", influxIdent(OrgIDLabel), influxString(fmt.Sprint(query.OrgID))),
+		fmt.Sprintf("time >= %d", query.From.UnixNano()),
+		fmt.Sprintf("time <= %d", query.To.UnixNano()),
+	}
+	if query.RuleUID != "" {
+		conds = append(conds, fmt.Sprintf("%s = %s", influxIdent(RuleUIDLabel), influxString(query.RuleUID)))
+	}
+	if query.DashboardUID != "" {
+		conds = append(conds, fmt.Sprintf("%s = %s", influxIdent(influxFieldDashboardUID), influxString(query.DashboardUID)))
+	}
+	if query.PanelID != 0 {
+		conds = append(conds, fmt.Sprintf("%s = %d", influxIdent(influxFieldPanelID), query.PanelID))
+	}
+
+	conds = append(conds, influxLabelConditions(query.Labels)...)
+
+	return fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY time ASC LIMIT %d", influxIdent(measurement), strings.Join(conds, " AND "), influxLimit(query.Limit))
+}
+
+// buildInfluxTransitionsQuery builds a query that selects the state history points of the given rules.
+func buildInfluxTransitionsQuery(measurement string, query models.HistoryTransitionsQuery) string {
+	conds := []string{
+		fmt.Sprintf("%s = %s", influxIdent(OrgIDLabel), influxString(fmt.Sprint(query.OrgID))),
+		fmt.Sprintf("time >= %d", query.From.UnixNano()),
+		fmt.Sprintf("time <= %d", query.To.UnixNano()),
+	}
+	if len(query.RuleUIDs) > 0 {
+		uids := make([]string, len(query.RuleUIDs))
+		copy(uids, query.RuleUIDs)
+		// Ensure that all queries we build are deterministic.
+		sort.Strings(uids)
+		ruleConds := make([]string, 0, len(uids))
+		for _, uid := range uids {
+			ruleConds = append(ruleConds, fmt.Sprintf("%s = %s", influxIdent(RuleUIDLabel), influxString(uid)))
+		}
+		conds = append(conds, "("+strings.Join(ruleConds, " OR ")+")")
+	}
+	conds = append(conds, influxLabelConditions(query.Labels)...)
+
+	// The most recent transitions are kept when there are more than the limit, like with the other backends.
+	return fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY time DESC LIMIT %d", influxIdent(measurement), strings.Join(conds, " AND "), transitionsLimit(query.Limit))
+}
+
+// influxLabelConditions matches the instance labels against the JSON encoded
+// labels field. The keys of the encoded object are sorted and quotes within
+// keys and values are escaped, so a key can only follow '{' or ','.
+func influxLabelConditions(labels map[string]string) []string {
+	labelKeys := make([]string, 0, len(labels))
+	for k := range labels {
+		labelKeys = append(labelKeys, k)
+	}
+	// Ensure that all queries we build are deterministic.
+	sort.Strings(labelKeys)
+	conds := make([]string, 0, len(labelKeys))
+	for _, k := range labelKeys {
+		key, _ := json.Marshal(k)
+		value, _ := json.Marshal(labels[k])
+		pattern := "[{,]" + regexp.QuoteMeta(string(key)+":"+string(value)) + "[,}]"
+		conds = append(conds, fmt.Sprintf("%s =~ /%s/", influxIdent(influxFieldLabels), strings.ReplaceAll(pattern, "/", `\/`)))
+	}
+	return conds
+}
+
+func influxLimit(limit int) int {
+	if limit < 1 {
+		return defaultPageSize
+	}
+	if limit > maximumPageSize {
+		return maximumPageSize
+	}
+	return limit
+}
+
+func influxIdent(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func influxString(s string) string {
+	return `'` + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + `'`
+}
+
+// influxResultToFrame converts the rows returned by InfluxDB into the state
+// history frame format: `time`, `line` with the transition as JSON and
+// `labels` with the labels of the stream the transition belongs to.
+func influxResultToFrame(res InfluxQueryRes) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0)
+	lines := make([]json.RawMessage, 0)
+	labels := make([]json.RawMessage, 0)
+
+	for _, result := range res.Results {
+		for _, series := range result.Series {
+			for _, row := range series.Values {
+				ts, entry, streamLbls, err := influxRowToEntry(series, row)
+				if err != nil {
+					return nil, err
+				}
+				line, err := json.Marshal(entry)
+				if err != nil {
+					return nil, fmt.Errorf("failed to serialize entry: %w", err)
+				}
+				lblsJson, err := json.Marshal(streamLbls)
+				if err != nil {
+					return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+				}
+				times = append(times, ts)
+				lines = append(lines, line)
+				labels = append(labels, lblsJson)
+			}
+		}
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+
+	return frame, nil
+}
+
+func influxRowToEntry(series InfluxSeries, row []any) (time.Time, lokiEntry, map[string]string, error) {
+	var ts time.Time
+	entry := lokiEntry{InstanceLabels: map[string]string{}}
+	values := simplejson.New()
+	streamLbls := map[string]string{
+		StateHistoryLabelKey: StateHistoryLabelValue,
+	}
+	for k, v := range series.Tags {
+		streamLbls[k] = v
+	}
+
+	for i, col := range series.Columns {
+		if i >= len(row) || row[i] == nil {
+			continue
+		}
+		v := row[i]
+
+		switch {
+		case col == "time":
+			n, err := influxInt(v)
+			if err != nil {
+				return ts, entry, nil, fmt.Errorf("invalid timestamp in InfluxDB response: %w", err)
+			}
+			ts = time.Unix(0, n)
+		case col == RuleUIDLabel:
+			entry.RuleUID = fmt.Sprint(v)
+		case col == influxTagState:
+			// the state is read back from the current field
+		case strings.HasPrefix(col, influxValueFieldPrefix):
+			f, err := influxFloat(v)
+			if err != nil {
+				return ts, entry, nil, fmt.Errorf("invalid value in InfluxDB response: %w", err)
+			}
+			values.Set(strings.TrimPrefix(col, influxValueFieldPrefix), f)
+		case isInfluxEntryField(col):
+			if err := setInfluxEntryField(&entry, col, v); err != nil {
+				return ts, entry, nil, err
+			}
+		default:
+			streamLbls[col] = fmt.Sprint(v)
+		}
+	}
+	entry.Values = values
+	return ts, entry, streamLbls, nil
+}
+
+func isInfluxEntryField(col string) bool {
+	_, ok := influxEntryFields[col]
+	return ok
+}
+
+func setInfluxEntryField(entry *lokiEntry, col string, v any) error {
+	switch col {
+	case influxFieldSchema:
+		n, err := influxInt(v)
+		if err != nil {
+			return fmt.Errorf("invalid schema version in InfluxDB response: %w", err)
+		}
+		entry.SchemaVersion = int(n)
+	case influxFieldPanelID:
+		n, err := influxInt(v)
+		if err != nil {
+			return fmt.Errorf("invalid panel ID in InfluxDB response: %w", err)
+		}
+		entry.PanelID = n
+	case influxFieldLabels:
+		if err := json.Unmarshal([]byte(fmt.Sprint(v)), &entry.InstanceLabels); err != nil {
+			return fmt.Errorf("invalid labels in InfluxDB response: %w", err)
+		}
+	case influxFieldPrevious:
+		entry.Previous = fmt.Sprint(v)
+	case influxFieldCurrent:
+		entry.Current = fmt.Sprint(v)
+	case influxFieldError:
+		entry.Error = fmt.Sprint(v)
+	case influxFieldCondition:
+		entry.Condition = fmt.Sprint(v)
+	case influxFieldDashboardUID:
+		entry.DashboardUID = fmt.Sprint(v)
+	case influxFieldFingerprint:
+		entry.Fingerprint = fmt.Sprint(v)
+	case influxFieldAckBy:
+		entry.AcknowledgedBy = fmt.Sprint(v)
+	case influxFieldAssignee:
+		entry.Assignee = fmt.Sprint(v)
+	}
+	return nil
+}
+
+func influxInt(v any) (int64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Int64()
+	case float64:
+		return int64(n), nil
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	default:
+		return 0, fmt.Errorf("unexpected type %T", v)
+	}
+}
+
+func influxFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Float64()
+	case float64:
+		return n, nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("unexpected type %T", v)
+	}
+}
@@ -0,0 +1,290 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/weaveworks/common/http/client"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// InfluxVersion identifies the InfluxDB write API to use.
+type InfluxVersion string
+
+const (
+	InfluxVersion1 InfluxVersion = "1"
+	InfluxVersion2 InfluxVersion = "2"
+	InfluxVersion3 InfluxVersion = "3"
+)
+
+const defaultInfluxMeasurement = "grafana_alert_state_history"
+
+type InfluxConfig struct {
+	URL               *url.URL
+	Version           InfluxVersion
+	Database          string
+	Organization      string
+	Bucket            string
+	Token             string
+	BasicAuthUser     string
+	BasicAuthPassword string
+	Measurement       string
+	ExternalLabels    map[string]string
+}
+
+func NewInfluxConfig(cfg setting.UnifiedAlertingStateHistorySettings) (InfluxConfig, error) {
+	if cfg.InfluxURL == "" {
+		return InfluxConfig{}, fmt.Errorf("InfluxDB URL must be provided")
+	}
+	u, err := url.Parse(cfg.InfluxURL)
+	if err != nil {
+		return InfluxConfig{}, fmt.Errorf("failed to parse InfluxDB URL: %w", err)
+	}
+
+	version := InfluxVersion(cfg.InfluxVersion)
+	if version == "" {
+		version = InfluxVersion2
+	}
+
+	switch version {
+	case InfluxVersion1, InfluxVersion3:
+		if cfg.InfluxDatabase == "" {
+			return InfluxConfig{}, fmt.Errorf("database must be provided for InfluxDB %s", version)
+		}
+	case InfluxVersion2:
+		if cfg.InfluxOrganization == "" || cfg.InfluxBucket == "" {
+			return InfluxConfig{}, fmt.Errorf("organization and bucket must be provided for InfluxDB 2")
+		}
+	default:
+		return InfluxConfig{}, fmt.Errorf("unsupported InfluxDB version: %s", version)
+	}
+
+	measurement := cfg.InfluxMeasurement
+	if measurement == "" {
+		measurement = defaultInfluxMeasurement
+	}
+
+	return InfluxConfig{
+		URL:               u,
+		Version:           version,
+		Database:          cfg.InfluxDatabase,
+		Organization:      cfg.InfluxOrganization,
+		Bucket:            cfg.InfluxBucket,
+		Token:             cfg.InfluxToken,
+		BasicAuthUser:     cfg.InfluxUsername,
+		BasicAuthPassword: cfg.InfluxPassword,
+		Measurement:       measurement,
+		ExternalLabels:    cfg.ExternalLabels,
+	}, nil
+}
+
+// queryDatabase returns the database state history is read from with InfluxQL.
+// InfluxDB 2 resolves it through the DBRP mapping of the bucket.
+func (cfg InfluxConfig) queryDatabase() string {
+	if cfg.Database != "" {
+		return cfg.Database
+	}
+	return cfg.Bucket
+}
+
+type HttpInfluxClient struct {
+	client  client.Requester
+	cfg     InfluxConfig
+	metrics *metrics.Historian
+	log     log.Logger
+}
+
+func NewInfluxClient(cfg InfluxConfig, req client.Requester, metrics *metrics.Historian, logger log.Logger) *HttpInfluxClient {
+	tc := client.NewTimedClient(req, metrics.WriteDuration)
+	return &HttpInfluxClient{
+		client:  tc,
+		cfg:     cfg,
+		metrics: metrics,
+		log:     logger.New("protocol", "http"),
+	}
+}
+
+func (c *HttpInfluxClient) Ping(ctx context.Context) error {
+	uri := c.cfg.URL.JoinPath("/ping")
+	req, err := http.NewRequest(http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	c.setAuthHeaders(req)
+
+	req = req.WithContext(ctx)
+	res, err := c.client.Do(req)
+	if res != nil {
+		defer func() {
+			if err := res.Body.Close(); err != nil {
+				c.log.Warn("Failed to close response body", "err", err)
+			}
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("ping request to InfluxDB endpoint returned a non-200 status code: %d", res.StatusCode)
+	}
+	c.log.Debug("Ping request to InfluxDB endpoint succeeded", "status", res.StatusCode)
+	return nil
+}
+
+// Write sends a batch of points encoded as line protocol with nanosecond precision.
+func (c *HttpInfluxClient) Write(ctx context.Context, lines []byte) error {
+	uri := c.writeURL()
+	req, err := http.NewRequest(http.MethodPost, uri.String(), bytes.NewBuffer(lines))
+	if err != nil {
+		return fmt.Errorf("failed to create InfluxDB request: %w", err)
+	}
+	c.setAuthHeaders(req)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	c.metrics.BytesWritten.Add(float64(len(lines)))
+	req = req.WithContext(ctx)
+	resp, err := c.client.Do(req)
+	if resp != nil {
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				c.log.Warn("Failed to close response body", "err", err)
+			}
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		byt, _ := io.ReadAll(resp.Body)
+		if len(byt) > 0 {
+			c.log.Error("Error response from InfluxDB", "response", string(byt), "status", resp.StatusCode)
+		} else {
+			c.log.Error("Error response from InfluxDB with an empty body", "status", resp.StatusCode)
+		}
+		return fmt.Errorf("received a non-200 response from InfluxDB, status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *HttpInfluxClient) writeURL() *url.URL {
+	values := url.Values{}
+	values.Set("precision", "ns")
+
+	var uri *url.URL
+	switch c.cfg.Version {
+	case InfluxVersion1:
+		uri = c.cfg.URL.JoinPath("/write")
+		values.Set("db", c.cfg.Database)
+		values.Set("precision", "n")
+	case InfluxVersion3:
+		// InfluxDB 3 accepts the v2 write API with the database as the bucket.
+		uri = c.cfg.URL.JoinPath("/api/v2/write")
+		values.Set("bucket", c.cfg.Database)
+		if c.cfg.Organization != "" {
+			values.Set("org", c.cfg.Organization)
+		}
+	default:
+		uri = c.cfg.URL.JoinPath("/api/v2/write")
+		values.Set("org", c.cfg.Organization)
+		values.Set("bucket", c.cfg.Bucket)
+	}
+	uri.RawQuery = values.Encode()
+	return uri
+}
+
+// Query runs an InfluxQL statement through the /query endpoint, which is
+// available in all supported InfluxDB versions.
+func (c *HttpInfluxClient) Query(ctx context.Context, influxQL string) (InfluxQueryRes, error) {
+	queryURL := c.cfg.URL.JoinPath("/query")
+
+	values := url.Values{}
+	values.Set("db", c.cfg.queryDatabase())
+	values.Set("q", influxQL)
+	values.Set("epoch", "ns")
+	queryURL.RawQuery = values.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, queryURL.String(), nil)
+	if err != nil {
+		return InfluxQueryRes{}, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req = req.WithContext(ctx)
+	c.setAuthHeaders(req)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return InfluxQueryRes{}, fmt.Errorf("error executing request: %w", err)
+	}
+
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return InfluxQueryRes{}, fmt.Errorf("error reading request response: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		if len(data) > 0 {
+			c.log.Error("Error response from InfluxDB", "response", string(data), "status", res.StatusCode)
+		} else {
+			c.log.Error("Error response from InfluxDB with an empty body", "status", res.StatusCode)
+		}
+		return InfluxQueryRes{}, fmt.Errorf("received a non-200 response from InfluxDB, status: %d", res.StatusCode)
+	}
+
+	result := InfluxQueryRes{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return InfluxQueryRes{}, fmt.Errorf("error parsing request response: %w", err)
+	}
+	if result.Error != "" {
+		return InfluxQueryRes{}, fmt.Errorf("InfluxDB query failed: %s", result.Error)
+	}
+	for _, r := range result.Results {
+		if r.Error != "" {
+			return InfluxQueryRes{}, fmt.Errorf("InfluxDB query failed: %s", r.Error)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *HttpInfluxClient) setAuthHeaders(req *http.Request) {
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", c.cfg.Token))
+		return
+	}
+	if c.cfg.BasicAuthUser != "" || c.cfg.BasicAuthPassword != "" {
+		req.SetBasicAuth(c.cfg.BasicAuthUser, c.cfg.BasicAuthPassword)
+	}
+}
+
+type InfluxQueryRes struct {
+	Results []InfluxResult `json:"results"`
+	Error   string         `json:"error,omitempty"`
+}
+
+type InfluxResult struct {
+	Series []InfluxSeries `json:"series"`
+	Error  string         `json:"error,omitempty"`
+}
+
+type InfluxSeries struct {
+	Name    string            `json:"name"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Columns []string          `json:"columns"`
+	Values  [][]any           `json:"values"`
+}
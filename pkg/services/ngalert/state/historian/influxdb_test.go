@@ -0,0 +1,261 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/http/client"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestInfluxBackend(t *testing.T) {
+	t.Run("statesToPoints", func(t *testing.T) {
+		t.Run("skips non-transitory states", func(t *testing.T) {
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{State: eval.Normal})
+
+			res := statesToPoints("history", rule, states, nil)
+
+			require.Empty(t, res)
+		})
+
+		t.Run("writes only the org, rule and state as tags", func(t *testing.T) {
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{
+				State:  eval.Alerting,
+				Labels: data.Labels{"a": "b", "__private__": "c"},
+				Values: map[string]float64{"A": 1.5, "B": math.NaN()},
+			})
+
+			res := statesToPoints("history", rule, states, map[string]string{"env": "prod"})
+
+			require.Len(t, res, 1)
+			tags := map[string]string{}
+			for _, tag := range res[0].TagList() {
+				tags[tag.Key] = tag.Value
+			}
+			require.Equal(t, map[string]string{
+				"orgID":   "1",
+				"ruleUID": rule.UID,
+				"state":   "Alerting",
+			}, tags)
+
+			fields := map[string]any{}
+			for _, field := range res[0].FieldList() {
+				fields[field.Key] = field.Value
+			}
+			require.Equal(t, "prod", fields["env"])
+			require.Equal(t, rule.Group, fields["group"])
+			require.Equal(t, rule.NamespaceUID, fields["folderUID"])
+			require.Equal(t, `{"a":"b"}`, fields["labels"])
+			require.Equal(t, "Alerting", fields["current"])
+			require.Equal(t, "Normal", fields["previous"])
+			require.Equal(t, 1.5, fields["values_A"])
+			require.NotContains(t, fields, "values_B")
+		})
+
+		t.Run("maps evaluation errors", func(t *testing.T) {
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{State: eval.Error, Error: fmt.Errorf("oh no")})
+
+			res := statesToPoints("history", rule, states, nil)
+
+			require.Len(t, res, 1)
+			lines, err := encodePoints(res)
+			require.NoError(t, err)
+			require.Contains(t, string(lines), `error="oh no"`)
+		})
+	})
+
+	t.Run("buildInfluxQuery", func(t *testing.T) {
+		from := time.Unix(1, 0)
+		to := time.Unix(2, 0)
+		q := buildInfluxQuery("history", models.HistoryQuery{
+			OrgID:   1,
+			RuleUID: "rule-'uid",
+			PanelID: 2,
+			Labels:  map[string]string{"b": "2", "a": "1"},
+			From:    from,
+			To:      to,
+		})
+		require.Equal(t, `SELECT * FROM "history" WHERE "orgID" = '1' AND time >= 1000000000 AND time <= 2000000000 AND "ruleUID" = 'rule-\'uid' AND "panelID" = 2 AND "labels" =~ /[{,]"a":"1"[,}]/ AND "labels" =~ /[{,]"b":"2"[,}]/ ORDER BY time ASC LIMIT 1000`, q)
+	})
+
+	t.Run("buildInfluxTransitionsQuery", func(t *testing.T) {
+		from := time.Unix(1, 0)
+		to := time.Unix(2, 0)
+		q := buildInfluxTransitionsQuery("history", models.HistoryTransitionsQuery{
+			OrgID:    1,
+			RuleUIDs: []string{"rule-2", "rule-1"},
+			Labels:   map[string]string{"a.b": "x/y"},
+			From:     from,
+			To:       to,
+			Limit:    10,
+		})
+		require.Equal(t, `SELECT * FROM "history" WHERE "orgID" = '1' AND time >= 1000000000 AND time <= 2000000000 AND ("ruleUID" = 'rule-1' OR "ruleUID" = 'rule-2') AND "labels" =~ /[{,]"a\.b":"x\/y"[,}]/ ORDER BY time DESC LIMIT 10`, q)
+	})
+
+	t.Run("writes line protocol to the v2 API", func(t *testing.T) {
+		req := NewFakeRequester()
+		b := createTestInfluxBackend(t, req, InfluxVersion2)
+		rule := createTestRule()
+		states := singleFromNormal(&state.State{
+			State:              eval.Alerting,
+			Labels:             data.Labels{"a": "b"},
+			LastEvaluationTime: time.Unix(10, 0),
+		})
+
+		err := <-b.Record(context.Background(), rule, states)
+
+		require.NoError(t, err)
+		require.Equal(t, "/api/v2/write", req.lastRequest.URL.Path)
+		require.Equal(t, "myorg", req.lastRequest.URL.Query().Get("org"))
+		require.Equal(t, "mybucket", req.lastRequest.URL.Query().Get("bucket"))
+		require.Equal(t, "Token secret", req.lastRequest.Header.Get("Authorization"))
+		body, err := io.ReadAll(req.lastRequest.Body)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(body), "history,orgID=1,ruleUID=rule-uid,state=Alerting "))
+		require.True(t, strings.HasSuffix(string(body), " 10000000000\n"))
+	})
+
+	t.Run("reads state history back as a frame", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(&http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(bytes.NewBufferString(`{"results":[{"statement_id":0,"series":[{"name":"history",
+				"columns":["time","condition","current","folderUID","group","labels","orgID","panelID","previous","ruleUID","schemaVersion","state","values_A"],
+				"values":[[10000000000,"A","Alerting","my-folder","my-group","{\"a\":\"b\"}","1",123,"Normal","rule-uid",1,"Alerting",1.5]]}]}]}`)),
+			Header: make(http.Header),
+		})
+		b := createTestInfluxBackend(t, req, InfluxVersion1)
+
+		frame, err := b.Query(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "rule-uid"})
+
+		require.NoError(t, err)
+		require.Equal(t, "/query", req.lastRequest.URL.Path)
+		require.Equal(t, "alerting", req.lastRequest.URL.Query().Get("db"))
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, time.Unix(10, 0), frame.Fields[0].At(0))
+
+		var entry lokiEntry
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+		require.Equal(t, "rule-uid", entry.RuleUID)
+		require.Equal(t, "Alerting", entry.Current)
+		require.Equal(t, "Normal", entry.Previous)
+		require.Equal(t, int64(123), entry.PanelID)
+		require.Equal(t, map[string]string{"a": "b"}, entry.InstanceLabels)
+		require.Equal(t, 1.5, entry.Values.Get("A").MustFloat64())
+
+		var lbls map[string]string
+		require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &lbls))
+		require.Equal(t, map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			"folderUID":          "my-folder",
+			"group":              "my-group",
+			"orgID":              "1",
+		}, lbls)
+	})
+
+	t.Run("reads state history back as transitions", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(&http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(bytes.NewBufferString(`{"results":[{"statement_id":0,"series":[{"name":"history",
+				"columns":["time","current","fingerprint","folderUID","labels","orgID","previous","ruleUID"],
+				"values":[[20000000000,"Normal (MissingSeries)","fp","my-folder","{\"a\":\"b\"}","1","Alerting","rule-uid"],
+					[10000000000,"Alerting","fp","my-folder","{\"a\":\"b\"}","1","Normal","rule-uid"]]}]}]}`)),
+			Header: make(http.Header),
+		})
+		b := createTestInfluxBackend(t, req, InfluxVersion1)
+
+		res, err := b.QueryTransitions(context.Background(), models.HistoryTransitionsQuery{OrgID: 1, RuleUIDs: []string{"rule-uid"}})
+
+		require.NoError(t, err)
+		require.False(t, res.Truncated)
+		require.Len(t, res.Transitions, 2)
+		require.Equal(t, time.Unix(10, 0), res.Transitions[0].Time)
+		require.Equal(t, "Normal", res.Transitions[0].Previous)
+		require.Equal(t, "Alerting", res.Transitions[0].Current)
+		require.Equal(t, "Normal", res.Transitions[1].Current)
+		require.Equal(t, models.StateReasonMissingSeries, res.Transitions[1].CurrentReason)
+		require.Equal(t, "rule-uid", res.Transitions[1].RuleUID)
+		require.Equal(t, "fp", res.Transitions[1].Fingerprint)
+		require.Equal(t, map[string]string{"a": "b"}, res.Transitions[1].Labels)
+	})
+
+	t.Run("reports that the limit is reached", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(&http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(bytes.NewBufferString(`{"results":[{"statement_id":0,"series":[{"name":"history",
+				"columns":["time","current","fingerprint","labels","orgID","previous","ruleUID"],
+				"values":[[20000000000,"Normal","fp","{}","1","Alerting","rule-uid"]]}]}]}`)),
+			Header: make(http.Header),
+		})
+		b := createTestInfluxBackend(t, req, InfluxVersion1)
+
+		res, err := b.QueryTransitions(context.Background(), models.HistoryTransitionsQuery{OrgID: 1, Limit: 1})
+
+		require.NoError(t, err)
+		require.True(t, res.Truncated)
+		require.Len(t, res.Transitions, 1)
+		require.Equal(t, time.Unix(20, 0), res.Transitions[0].Time)
+	})
+}
+
+func TestNewInfluxConfig(t *testing.T) {
+	t.Run("requires database for v1", func(t *testing.T) {
+		_, err := NewInfluxConfig(setting.UnifiedAlertingStateHistorySettings{InfluxURL: "http://localhost:8086", InfluxVersion: "1"})
+		require.Error(t, err)
+	})
+
+	t.Run("requires organization and bucket for v2", func(t *testing.T) {
+		_, err := NewInfluxConfig(setting.UnifiedAlertingStateHistorySettings{InfluxURL: "http://localhost:8086", InfluxVersion: "2", InfluxOrganization: "org"})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects unknown versions", func(t *testing.T) {
+		_, err := NewInfluxConfig(setting.UnifiedAlertingStateHistorySettings{InfluxURL: "http://localhost:8086", InfluxVersion: "4", InfluxDatabase: "db"})
+		require.Error(t, err)
+	})
+
+	t.Run("defaults measurement", func(t *testing.T) {
+		cfg, err := NewInfluxConfig(setting.UnifiedAlertingStateHistorySettings{InfluxURL: "http://localhost:8086", InfluxVersion: "3", InfluxDatabase: "db"})
+		require.NoError(t, err)
+		require.Equal(t, defaultInfluxMeasurement, cfg.Measurement)
+	})
+}
+
+func createTestInfluxBackend(t *testing.T, req client.Requester, version InfluxVersion) *InfluxBackend {
+	t.Helper()
+	u, err := url.Parse("http://some.url")
+	require.NoError(t, err)
+	cfg := InfluxConfig{
+		URL:          u,
+		Version:      version,
+		Database:     "alerting",
+		Organization: "myorg",
+		Bucket:       "mybucket",
+		Token:        "secret",
+		Measurement:  "history",
+	}
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	return NewInfluxBackend(cfg, req, met)
+}
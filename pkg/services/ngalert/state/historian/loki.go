@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -133,6 +135,37 @@ func (h *RemoteLokiBackend) Query(ctx context.Context, query models.HistoryQuery
 	return merge(res, query.RuleUID)
 }
 
+// QueryTransitions retrieves the state transitions of alert instances from an external Loki instance.
+func (h *RemoteLokiBackend) QueryTransitions(ctx context.Context, query models.HistoryTransitionsQuery) (models.HistoryTransitions, error) {
+	query, err := withDefaultRange(query)
+	if err != nil {
+		return models.HistoryTransitions{}, err
+	}
+	logQL, err := buildTransitionsLogQuery(query)
+	if err != nil {
+		return models.HistoryTransitions{}, err
+	}
+
+	// Loki returns the most recent entries first, up to the limit.
+	limit := transitionsLimit(query.Limit)
+	res, err := h.client.RangeQuery(ctx, logQL, query.From.UnixNano(), query.To.UnixNano(), int64(limit))
+	if err != nil {
+		return models.HistoryTransitions{}, err
+	}
+
+	transitions := make([]models.HistoryTransition, 0)
+	for _, stream := range res.Data.Result {
+		for _, sample := range stream.Values {
+			var entry lokiEntry
+			if err := json.Unmarshal([]byte(sample.V), &entry); err != nil {
+				return models.HistoryTransitions{}, fmt.Errorf("failed to unmarshal entry: %w", err)
+			}
+			transitions = append(transitions, entryToTransition(sample.T, entry))
+		}
+	}
+	return latestTransitions(transitions, limit, len(transitions) >= limit), nil
+}
+
 func buildSelectors(query models.HistoryQuery) ([]Selector, error) {
 	// OrgID and the state history label are static and will be included in all queries.
 	selectors := make([]Selector, 2)
@@ -261,6 +294,10 @@ func statesToStream(rule history_model.RuleMeta, states []state.StateTransition,
 		if state.State.State == eval.Error {
 			entry.Error = state.Error.Error()
 		}
+		if ack := state.GetAcknowledgement(); ack != nil {
+			entry.AcknowledgedBy = ack.AcknowledgedBy
+			entry.Assignee = ack.Assignee
+		}
 
 		jsn, err := json.Marshal(entry)
 		if err != nil {
@@ -304,6 +341,10 @@ type lokiEntry struct {
 	// InstanceLabels is exactly the set of labels associated with the alert instance in Alertmanager.
 	// These should not be conflated with labels associated with log streams.
 	InstanceLabels map[string]string `json:"labels"`
+	// AcknowledgedBy and Assignee are the user who acknowledged the alert instance and the user who owns it
+	// at the time of the transition.
+	AcknowledgedBy string `json:"acknowledgedBy,omitempty"`
+	Assignee       string `json:"assignee,omitempty"`
 }
 
 func valuesAsDataBlob(state *state.State) *simplejson.Json {
@@ -384,19 +425,46 @@ func buildLogQuery(query models.HistoryQuery) (string, error) {
 		logQL = fmt.Sprintf("%s | panelID=%d", logQL, query.PanelID)
 	}
 
+	logQL += buildLabelFilters(query.Labels)
+
+	return logQL, nil
+}
+
+// buildTransitionsLogQuery builds a query that selects the state history entries of the given rules.
+func buildTransitionsLogQuery(query models.HistoryTransitionsQuery) (string, error) {
+	selectors, err := buildSelectors(models.HistoryQuery{OrgID: query.OrgID})
+	if err != nil {
+		return "", fmt.Errorf("failed to build the provided selectors: %w", err)
+	}
+
+	logQL := fmt.Sprintf("%s | json", selectorString(selectors))
+
+	if len(query.RuleUIDs) > 0 {
+		uids := make([]string, 0, len(query.RuleUIDs))
+		for _, uid := range query.RuleUIDs {
+			uids = append(uids, regexp.QuoteMeta(uid))
+		}
+		// Ensure that all queries we build are deterministic.
+		sort.Strings(uids)
+		logQL = fmt.Sprintf("%s | ruleUID=~%q", logQL, strings.Join(uids, "|"))
+	}
+	logQL += buildLabelFilters(query.Labels)
+
+	return logQL, nil
+}
+
+func buildLabelFilters(labels map[string]string) string {
 	labelFilters := ""
-	labelKeys := make([]string, 0, len(query.Labels))
-	for k := range query.Labels {
+	labelKeys := make([]string, 0, len(labels))
+	for k := range labels {
 		labelKeys = append(labelKeys, k)
 	}
 	// Ensure that all queries we build are deterministic.
 	sort.Strings(labelKeys)
 	for _, k := range labelKeys {
-		labelFilters += fmt.Sprintf(" | labels_%s=%q", k, query.Labels[k])
+		labelFilters += fmt.Sprintf(" | labels_%s=%q", k, labels[k])
 	}
-	logQL += labelFilters
-
-	return logQL, nil
+	return labelFilters
 }
 
 func queryHasLogFilters(query models.HistoryQuery) bool {
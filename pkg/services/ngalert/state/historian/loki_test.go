@@ -186,6 +186,23 @@ func TestRemoteLokiBackend(t *testing.T) {
 			exp := labelFingerprint(states[0].Labels)
 			require.Equal(t, exp, entry.Fingerprint)
 		})
+
+		t.Run("includes acknowledgement of instance in log line", func(t *testing.T) {
+			rule := createTestRule()
+			l := log.NewNopLogger()
+			s := &state.State{
+				State:  eval.Alerting,
+				Labels: data.Labels{"a": "b"},
+			}
+			s.SetAcknowledgement(&models.AlertInstanceAcknowledgement{AcknowledgedBy: "alice", Assignee: "bob"})
+			states := singleFromNormal(s)
+
+			res := statesToStream(rule, states, nil, l)
+
+			entry := requireSingleEntry(t, res)
+			require.Equal(t, "alice", entry.AcknowledgedBy)
+			require.Equal(t, "bob", entry.Assignee)
+		})
 	})
 
 	t.Run("selector string", func(t *testing.T) {
@@ -285,6 +302,94 @@ func TestRemoteLokiBackend(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("buildTransitionsLogQuery", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			query models.HistoryTransitionsQuery
+			exp   string
+		}{
+			{
+				name:  "parses log lines without filters",
+				query: models.HistoryTransitionsQuery{OrgID: 123},
+				exp:   `{orgID="123",from="state-history"} | json`,
+			},
+			{
+				name: "filters rule UIDs with a regular expression",
+				query: models.HistoryTransitionsQuery{
+					OrgID:    123,
+					RuleUIDs: []string{"rule-2", "rule.1"},
+				},
+				exp: `{orgID="123",from="state-history"} | json | ruleUID=~"rule-2|rule\\.1"`,
+			},
+			{
+				name: "filters instance labels",
+				query: models.HistoryTransitionsQuery{
+					OrgID:    123,
+					RuleUIDs: []string{"rule-uid"},
+					Labels:   map[string]string{"customlabel": "customvalue"},
+				},
+				exp: `{orgID="123",from="state-history"} | json | ruleUID=~"rule-uid" | labels_customlabel="customvalue"`,
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				res, err := buildTransitionsLogQuery(tc.query)
+				require.NoError(t, err)
+				require.Equal(t, tc.exp, res)
+			})
+		}
+	})
+}
+
+func TestQueryTransitions(t *testing.T) {
+	t.Run("reads transitions of all streams sorted by time", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(&http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(bytes.NewBufferString(`{"data":{"result":[
+				{"stream":{"folderUID":"f1"},"values":[["20","{\"previous\":\"Pending\",\"current\":\"Alerting\",\"ruleUID\":\"rule-1\",\"fingerprint\":\"fp1\",\"labels\":{\"a\":\"b\"}}"]]},
+				{"stream":{"folderUID":"f2"},"values":[["10","{\"previous\":\"Normal\",\"current\":\"Pending (KeepFiring)\",\"ruleUID\":\"rule-2\",\"fingerprint\":\"fp2\",\"labels\":{}}"]]}
+			]}}`)),
+			Header: make(http.Header),
+		})
+		loki := createTestLokiBackend(req, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+		res, err := loki.QueryTransitions(context.Background(), models.HistoryTransitionsQuery{OrgID: 1, RuleUIDs: []string{"rule-1", "rule-2"}})
+
+		require.NoError(t, err)
+		require.Equal(t, `{orgID="1",from="state-history"} | json | ruleUID=~"rule-1|rule-2"`, req.lastRequest.URL.Query().Get("query"))
+		require.False(t, res.Truncated)
+		require.Len(t, res.Transitions, 2)
+		require.Equal(t, "rule-2", res.Transitions[0].RuleUID)
+		require.Equal(t, "Pending", res.Transitions[0].Current)
+		require.Equal(t, models.StateReasonKeepFiring, res.Transitions[0].CurrentReason)
+		require.Equal(t, "rule-1", res.Transitions[1].RuleUID)
+		require.Equal(t, "fp1", res.Transitions[1].Fingerprint)
+		require.Equal(t, map[string]string{"a": "b"}, res.Transitions[1].Labels)
+	})
+
+	t.Run("keeps the most recent transitions when the limit is reached", func(t *testing.T) {
+		req := NewFakeRequester().WithResponse(&http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(bytes.NewBufferString(`{"data":{"result":[
+				{"stream":{"folderUID":"f1"},"values":[["20","{\"previous\":\"Pending\",\"current\":\"Alerting\",\"ruleUID\":\"rule-1\",\"fingerprint\":\"fp1\"}"],["10","{\"previous\":\"Normal\",\"current\":\"Pending\",\"ruleUID\":\"rule-1\",\"fingerprint\":\"fp1\"}"]]}
+			]}}`)),
+			Header: make(http.Header),
+		})
+		loki := createTestLokiBackend(req, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+		res, err := loki.QueryTransitions(context.Background(), models.HistoryTransitionsQuery{OrgID: 1, Limit: 2})
+
+		require.NoError(t, err)
+		require.Equal(t, "2", req.lastRequest.URL.Query().Get("limit"))
+		require.True(t, res.Truncated)
+		require.Len(t, res.Transitions, 2)
+		require.Equal(t, "Pending", res.Transitions[0].Current)
+		require.Equal(t, "Alerting", res.Transitions[1].Current)
+	})
 }
 
 func TestMerge(t *testing.T) {
@@ -76,3 +76,7 @@ func (f *fakeBackend) Record(ctx context.Context, rule history_model.RuleMeta, s
 func (f *fakeBackend) Query(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
 	return f.resp, f.err
 }
+
+func (f *fakeBackend) QueryTransitions(ctx context.Context, query ngmodels.HistoryTransitionsQuery) (ngmodels.HistoryTransitions, error) {
+	return ngmodels.HistoryTransitions{}, f.err
+}
@@ -13,4 +13,5 @@ import (
 // TODO: This type should be moved to the side of the consumer, when the consumer is created in the future. We add it here temporarily to more clearly define this package's interface.
 type Querier interface {
 	Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
+	QueryTransitions(ctx context.Context, query models.HistoryTransitionsQuery) (models.HistoryTransitions, error)
 }
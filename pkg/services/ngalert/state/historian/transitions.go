@@ -0,0 +1,95 @@
+package historian
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// withDefaultRange sets the time range of the query to the default range if it is not specified and checks that it is valid.
+func withDefaultRange(query models.HistoryTransitionsQuery) (models.HistoryTransitionsQuery, error) {
+	now := time.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultQueryRange)
+	}
+	if query.From.After(query.To) {
+		return query, fmt.Errorf("start time cannot be after end time")
+	}
+	return query, nil
+}
+
+// transitionsLimit returns the maximum number of transitions read for a query with the given limit.
+func transitionsLimit(limit int) int {
+	if limit < 1 {
+		return defaultPageSize
+	}
+	if limit > maximumPageSize {
+		return maximumPageSize
+	}
+	return limit
+}
+
+// latestTransitions sorts the transitions by time and keeps the most recent ones up to limit. All backends keep the
+// most recent transitions when the state history holds more transitions than the limit.
+func latestTransitions(transitions []models.HistoryTransition, limit int, truncated bool) models.HistoryTransitions {
+	sortTransitions(transitions)
+	if len(transitions) > limit {
+		transitions = transitions[len(transitions)-limit:]
+		truncated = true
+	}
+	return models.HistoryTransitions{Transitions: transitions, Truncated: truncated}
+}
+
+// parseStateAndReason is the inverse of state.FormatStateAndReason.
+func parseStateAndReason(s string) (string, string) {
+	st, reason, found := strings.Cut(s, " (")
+	if !found {
+		return s, ""
+	}
+	return st, strings.TrimSuffix(reason, ")")
+}
+
+// entryToTransition converts a state history entry stored in Loki or InfluxDB to a transition.
+func entryToTransition(ts time.Time, entry lokiEntry) models.HistoryTransition {
+	previous, previousReason := parseStateAndReason(entry.Previous)
+	current, currentReason := parseStateAndReason(entry.Current)
+	fingerprint := entry.Fingerprint
+	if fingerprint == "" {
+		fingerprint = labelFingerprint(entry.InstanceLabels)
+	}
+	return models.HistoryTransition{
+		Time:           ts,
+		RuleUID:        entry.RuleUID,
+		Fingerprint:    fingerprint,
+		Labels:         entry.InstanceLabels,
+		Previous:       previous,
+		PreviousReason: previousReason,
+		Current:        current,
+		CurrentReason:  currentReason,
+		AcknowledgedBy: entry.AcknowledgedBy,
+		Assignee:       entry.Assignee,
+	}
+}
+
+// matchesLabels returns true if the labels contain all the label matchers.
+func matchesLabels(labels, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// sortTransitions sorts transitions by time. Transitions that happened at the same time keep their relative order.
+func sortTransitions(transitions []models.HistoryTransition) {
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].Time.Before(transitions[j].Time)
+	})
+}
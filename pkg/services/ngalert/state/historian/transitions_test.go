@@ -0,0 +1,69 @@
+package historian
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestParseStateAndReason(t *testing.T) {
+	cases := []struct {
+		state  eval.State
+		reason string
+	}{
+		{state: eval.Normal},
+		{state: eval.Alerting},
+		{state: eval.Normal, reason: models.StateReasonMissingSeries},
+		{state: eval.Alerting, reason: models.StateReasonKeepFiring},
+		{state: eval.Error, reason: models.StateReasonError},
+	}
+	for _, tc := range cases {
+		formatted := state.FormatStateAndReason(tc.state, tc.reason)
+		t.Run(formatted, func(t *testing.T) {
+			st, reason := parseStateAndReason(formatted)
+			require.Equal(t, tc.state.String(), st)
+			require.Equal(t, tc.reason, reason)
+		})
+	}
+}
+
+func TestEntryToTransition(t *testing.T) {
+	ts := time.Unix(10, 0)
+	entry := lokiEntry{
+		Previous:       "Pending",
+		Current:        "Normal (MissingSeries)",
+		RuleUID:        "rule-uid",
+		InstanceLabels: map[string]string{"a": "b"},
+	}
+
+	transition := entryToTransition(ts, entry)
+
+	require.Equal(t, models.HistoryTransition{
+		Time:          ts,
+		RuleUID:       "rule-uid",
+		Fingerprint:   labelFingerprint(map[string]string{"a": "b"}),
+		Labels:        map[string]string{"a": "b"},
+		Previous:      "Pending",
+		Current:       "Normal",
+		CurrentReason: models.StateReasonMissingSeries,
+	}, transition)
+}
+
+func TestWithDefaultRange(t *testing.T) {
+	t.Run("sets the default range", func(t *testing.T) {
+		q, err := withDefaultRange(models.HistoryTransitionsQuery{})
+		require.NoError(t, err)
+		require.False(t, q.To.IsZero())
+		require.Equal(t, defaultQueryRange, q.To.Sub(q.From))
+	})
+
+	t.Run("fails if the range is inverted", func(t *testing.T) {
+		_, err := withDefaultRange(models.HistoryTransitionsQuery{From: time.Unix(2, 0), To: time.Unix(1, 0)})
+		require.Error(t, err)
+	})
+}
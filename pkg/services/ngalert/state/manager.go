@@ -3,6 +3,7 @@ package state
 import (
 	"context"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -39,22 +40,38 @@ type Manager struct {
 	ResendDelay time.Duration
 
 	instanceStore InstanceStore
+	ackStore      AcknowledgementStore
 	images        ImageCapturer
 	historian     Historian
 	externalURL   *url.URL
 
+	// syncAcknowledgements loads the acknowledgements of the states of a rule from the ackStore before each evaluation.
+	syncAcknowledgements bool
+	// ackMtx serializes the changes of the acknowledgements of the states and their writes to the ackStore.
+	ackMtx sync.Mutex
+
 	doNotSaveNormalState           bool
 	maxStateSaveConcurrency        int
 	applyNoDataAndErrorToAllStates bool
+
+	flapDetectionWindow      time.Duration
+	flapDetectionTransitions int
 }
 
 type ManagerCfg struct {
 	Metrics       *metrics.State
 	ExternalURL   *url.URL
 	InstanceStore InstanceStore
-	Images        ImageCapturer
-	Clock         clock.Clock
-	Historian     Historian
+	// AcknowledgementStore persists the acknowledgements of alert instances. Alert instances cannot be acknowledged
+	// if it is nil.
+	AcknowledgementStore AcknowledgementStore
+	// SyncAcknowledgements loads the acknowledgements of the states of a rule from the AcknowledgementStore before
+	// each evaluation of the rule, so that the changes made by other Grafana instances are applied. It is required
+	// in high availability mode.
+	SyncAcknowledgements bool
+	Images               ImageCapturer
+	Clock                clock.Clock
+	Historian            Historian
 	// DoNotSaveNormalState controls whether eval.Normal state is persisted to the database and returned by get methods
 	DoNotSaveNormalState bool
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -64,6 +81,12 @@ type ManagerCfg struct {
 	// to all states when corresponding execution in the rule definition is set to either `Alerting` or `OK`
 	ApplyNoDataAndErrorToAllStates bool
 
+	// FlapDetectionWindow and FlapDetectionTransitions control the detection of flapping states: a state
+	// is flapping if it has at least FlapDetectionTransitions transitions between firing and not firing within
+	// FlapDetectionWindow. The detection is disabled if FlapDetectionWindow is 0.
+	FlapDetectionWindow      time.Duration
+	FlapDetectionTransitions int
+
 	Tracer tracing.Tracer
 	Log    log.Logger
 }
@@ -81,6 +104,8 @@ func NewManager(cfg ManagerCfg) *Manager {
 		log:                            cfg.Log,
 		metrics:                        cfg.Metrics,
 		instanceStore:                  cfg.InstanceStore,
+		ackStore:                       cfg.AcknowledgementStore,
+		syncAcknowledgements:           cfg.SyncAcknowledgements,
 		images:                         cfg.Images,
 		historian:                      cfg.Historian,
 		clock:                          cfg.Clock,
@@ -88,6 +113,8 @@ func NewManager(cfg ManagerCfg) *Manager {
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
 		maxStateSaveConcurrency:        cfg.MaxStateSaveConcurrency,
 		applyNoDataAndErrorToAllStates: cfg.ApplyNoDataAndErrorToAllStates,
+		flapDetectionWindow:            cfg.FlapDetectionWindow,
+		flapDetectionTransitions:       cfg.FlapDetectionTransitions,
 		tracer:                         cfg.Tracer,
 	}
 
@@ -140,6 +167,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 			st.log.Error("Unable to fetch previous state", "error", err)
 		}
 
+		acks := st.fetchAcknowledgements(ctx, orgId)
+
 		for _, entry := range alertInstances {
 			ruleForEntry, ok := ruleByUID[entry.RuleUID]
 			if !ok {
@@ -158,7 +187,7 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 			if err != nil {
 				st.log.Error("Error getting cacheId for entry", "error", err)
 			}
-			rulesStates.states[cacheID] = &State{
+			s := &State{
 				AlertRuleUID:         entry.RuleUID,
 				OrgID:                entry.RuleOrgID,
 				CacheID:              cacheID,
@@ -170,7 +199,12 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				EndsAt:               entry.CurrentStateEnd,
 				LastEvaluationTime:   entry.LastEvalTime,
 				Annotations:          ruleForEntry.Annotations,
+				FlapTransitions:      entry.FlapTransitions,
+			}
+			if ack, ok := acks[entry.AlertInstanceKey]; ok {
+				s.SetAcknowledgement(ack)
 			}
+			rulesStates.states[cacheID] = s
 			statesCount++
 		}
 	}
@@ -263,6 +297,7 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 
 	logger := st.log.FromContext(tracingCtx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
+	st.loadAcknowledgements(tracingCtx, logger, alertRule)
 	states := st.setNextStateForRule(tracingCtx, alertRule, results, extraLabels, logger)
 	span.AddEvent("results processed", trace.WithAttributes(
 		attribute.Int64("state_transitions", int64(len(states))),
@@ -324,6 +359,9 @@ func (st *Manager) setNextStateForAll(ctx context.Context, alertRule *ngModels.A
 // Set the current state based on evaluation results
 func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, currentState *State, result eval.Result, logger log.Logger) StateTransition {
 	start := st.clock.Now()
+	if st.flapDetectionWindow > 0 {
+		currentState.updateFlapping(result, st.flapDetectionWindow, st.flapDetectionTransitions)
+	}
 	currentState.LastEvaluationTime = result.EvaluatedAt
 	currentState.EvaluationDuration = result.EvaluationDuration
 	currentState.Results = append(currentState.Results, Evaluation{
@@ -387,10 +425,24 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = result.State.String()
 	}
 
+	if currentState.State == eval.Alerting && result.State == eval.Normal {
+		currentState.StateReason = ngModels.StateReasonKeepFiring
+		if currentState.Flapping {
+			currentState.StateReason = ngModels.StateReasonFlapping
+		}
+	} else {
+		currentState.KeepFiringSince = time.Time{}
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
 
+	// An acknowledgement applies to a single alert, so it is cleared when the state leaves Normal again.
+	if oldState == eval.Normal && currentState.State != eval.Normal && currentState.GetAcknowledgement().IsAcknowledged() {
+		st.clearAcknowledgement(ctx, logger, currentState)
+	}
+
 	if shouldTakeImage(currentState.State, oldState, currentState.Image, currentState.Resolved) {
 		image, err := takeImage(ctx, st.images, alertRule)
 		if err != nil {
@@ -458,6 +510,7 @@ func (st *Manager) saveAlertStates(ctx context.Context, logger log.Logger, state
 			LastEvalTime:      s.LastEvaluationTime,
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
+			FlapTransitions:   s.FlapTransitions,
 		}
 
 		err = st.instanceStore.SaveAlertInstance(ctx, instance)
@@ -359,7 +359,7 @@ func TestProcessEvalResults_StateTransitions(t *testing.T) {
 				}
 				delete(expectedTransitionsMap, transition.CacheID)
 				if !assert.ObjectsAreEqual(expected, transition) {
-					assert.Failf(t, fmt.Sprintf("expected and actual transitions at time [t%d] are not equal", tn), "CacheID: %s\nDiff: %s", transition.CacheID, cmp.Diff(expected, transition, cmpopts.EquateErrors()))
+					assert.Failf(t, fmt.Sprintf("expected and actual transitions at time [t%d] are not equal", tn), "CacheID: %s\nDiff: %s", transition.CacheID, cmp.Diff(expected, transition, cmpopts.EquateErrors(), cmpopts.IgnoreUnexported(State{})))
 				}
 			}
 			if len(expectedTransitionsMap) > 0 {
@@ -3337,3 +3337,106 @@ func setCacheID(s *State) *State {
 	s.CacheID = id
 	return s
 }
+
+func TestProcessEvalResults_KeepFiring(t *testing.T) {
+	interval := 10 * time.Second
+	tN := func(n int) time.Time {
+		return time.Unix(0, 0).Add(time.Duration(n) * interval)
+	}
+	newManager := func(flapWindow time.Duration, flapTransitions int) *Manager {
+		return NewManager(ManagerCfg{
+			Metrics:                  metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			Tracer:                   tracing.InitializeTracerForTest(),
+			Log:                      log.New("ngalert.state.manager"),
+			InstanceStore:            &FakeInstanceStore{},
+			Images:                   &NotAvailableImageService{},
+			Clock:                    clock.NewMock(),
+			Historian:                &FakeHistorian{},
+			MaxStateSaveConcurrency:  1,
+			FlapDetectionWindow:      flapWindow,
+			FlapDetectionTransitions: flapTransitions,
+		})
+	}
+	// process evaluates the rule with a single result of the given state at each step and returns the resulting states.
+	process := func(st *Manager, rule *ngmodels.AlertRule, states ...eval.State) []StateTransition {
+		result := make([]StateTransition, 0, len(states))
+		for i, s := range states {
+			transitions := st.ProcessEvalResults(context.Background(), tN(i+1), rule, eval.Results{{State: s, EvaluatedAt: tN(i + 1)}}, nil)
+			require.Len(t, transitions, 1)
+			transition := transitions[0]
+			copied := *transition.State
+			transition.State = &copied
+			result = append(result, transition)
+		}
+		return result
+	}
+	newRule := func(keepFiringFor time.Duration) *ngmodels.AlertRule {
+		return ngmodels.AlertRuleGen(
+			ngmodels.WithInterval(interval),
+			ngmodels.WithFor(0),
+			ngmodels.WithKeepFiringFor(keepFiringFor),
+			ngmodels.WithLabels(nil),
+		)()
+	}
+
+	t.Run("resolves immediately without keep_firing_for", func(t *testing.T) {
+		transitions := process(newManager(0, 0), newRule(0), eval.Alerting, eval.Normal)
+		require.Equal(t, eval.Normal, transitions[1].State.State)
+		require.True(t, transitions[1].State.Resolved)
+	})
+
+	t.Run("keeps firing for keep_firing_for after the condition stops being met", func(t *testing.T) {
+		transitions := process(newManager(0, 0), newRule(2*interval), eval.Alerting, eval.Normal, eval.Normal, eval.Normal)
+
+		for _, tr := range transitions[1:3] {
+			require.Equal(t, eval.Alerting, tr.State.State)
+			require.Equal(t, ngmodels.StateReasonKeepFiring, tr.State.StateReason)
+			require.Equal(t, tN(2), tr.State.KeepFiringSince)
+			require.False(t, tr.State.Resolved)
+			require.Equal(t, tN(1), tr.State.StartsAt)
+		}
+		require.Equal(t, eval.Normal, transitions[3].State.State)
+		require.True(t, transitions[3].State.Resolved)
+		require.True(t, transitions[3].State.KeepFiringSince.IsZero())
+	})
+
+	t.Run("restarts keep_firing_for when the condition is met again", func(t *testing.T) {
+		transitions := process(newManager(0, 0), newRule(2*interval), eval.Alerting, eval.Normal, eval.Alerting, eval.Normal, eval.Normal)
+
+		require.Equal(t, eval.Alerting, transitions[2].State.State)
+		require.Empty(t, transitions[2].State.StateReason)
+		require.True(t, transitions[2].State.KeepFiringSince.IsZero())
+		require.Equal(t, tN(1), transitions[2].State.StartsAt)
+		require.Equal(t, eval.Alerting, transitions[4].State.State)
+		require.Equal(t, tN(4), transitions[4].State.KeepFiringSince)
+	})
+
+	t.Run("flapping states keep firing until they stop flapping", func(t *testing.T) {
+		st := newManager(5*interval, 3)
+		transitions := process(st, newRule(0), eval.Alerting, eval.Normal, eval.Alerting, eval.Normal, eval.Normal, eval.Normal, eval.Normal)
+
+		// the second transition is resolved because the state is not flapping yet.
+		require.Equal(t, eval.Normal, transitions[1].State.State)
+		require.False(t, transitions[1].State.Flapping)
+		// the third transition makes the state flapping.
+		require.Equal(t, eval.Alerting, transitions[3].State.State)
+		require.True(t, transitions[3].State.Flapping)
+		require.Equal(t, ngmodels.StateReasonFlapping, transitions[3].State.StateReason)
+		require.Equal(t, []time.Time{tN(2), tN(3), tN(4)}, transitions[3].State.FlapTransitions)
+		// the state is resolved once the first transition is out of the window.
+		require.Equal(t, eval.Alerting, transitions[5].State.State)
+		require.Equal(t, eval.Normal, transitions[6].State.State)
+		require.True(t, transitions[6].State.Resolved)
+		require.False(t, transitions[6].State.Flapping)
+		require.Equal(t, []time.Time{tN(3), tN(4)}, transitions[6].State.FlapTransitions)
+	})
+
+	t.Run("flapping is not detected when disabled", func(t *testing.T) {
+		transitions := process(newManager(0, 0), newRule(0), eval.Alerting, eval.Normal, eval.Alerting, eval.Normal)
+		for _, tr := range transitions {
+			require.False(t, tr.State.Flapping)
+			require.Empty(t, tr.State.FlapTransitions)
+		}
+		require.Equal(t, eval.Normal, transitions[3].State.State)
+	})
+}
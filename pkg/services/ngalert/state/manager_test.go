@@ -211,7 +211,7 @@ func TestWarmStateCache(t *testing.T) {
 			setCacheID(entry)
 			cacheEntry := st.Get(entry.OrgID, entry.AlertRuleUID, entry.CacheID)
 
-			if diff := cmp.Diff(entry, cacheEntry, cmpopts.IgnoreFields(state.State{}, "Results")); diff != "" {
+			if diff := cmp.Diff(entry, cacheEntry, cmpopts.IgnoreFields(state.State{}, "Results"), cmpopts.IgnoreUnexported(state.State{})); diff != "" {
 				t.Errorf("Result mismatch (-want +got):\n%s", diff)
 				t.FailNow()
 			}
@@ -1274,7 +1274,7 @@ func TestProcessEvalResults(t *testing.T) {
 				}
 				delete(expectedStates, actual.CacheID)
 				if !assert.ObjectsAreEqual(expected, actual) {
-					assert.Failf(t, "expected and actual states are not equal", "Diff: %s", cmp.Diff(expected, actual, cmpopts.EquateErrors()))
+					assert.Failf(t, "expected and actual states are not equal", "Diff: %s", cmp.Diff(expected, actual, cmpopts.EquateErrors(), cmpopts.IgnoreUnexported(state.State{})))
 				}
 			}
 
@@ -16,6 +16,13 @@ type InstanceStore interface {
 	DeleteAlertInstancesByRule(ctx context.Context, key models.AlertRuleKey) error
 }
 
+// AcknowledgementStore represents the ability to fetch and write the acknowledgements of alert instances.
+type AcknowledgementStore interface {
+	ListAlertInstanceAcknowledgements(ctx context.Context, query *models.ListAlertInstanceAcknowledgementsQuery) ([]*models.AlertInstanceAcknowledgement, error)
+	SaveAlertInstanceAcknowledgement(ctx context.Context, ack models.AlertInstanceAcknowledgement) error
+	DeleteAlertInstanceAcknowledgement(ctx context.Context, key models.AlertInstanceKey) error
+}
+
 // RuleReader represents the ability to fetch alert rules.
 type RuleReader interface {
 	ListAlertRules(ctx context.Context, query *models.ListAlertRulesQuery) (models.RulesGroup, error)
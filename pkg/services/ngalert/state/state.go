@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	alertingModels "github.com/grafana/alerting/models"
@@ -67,6 +69,65 @@ type State struct {
 	LastEvaluationString string
 	LastEvaluationTime   time.Time
 	EvaluationDuration   time.Duration
+
+	// KeepFiringSince is the time of the first evaluation that did not meet the condition of the rule
+	// while the state keeps firing. It is zero if the state does not keep firing.
+	KeepFiringSince time.Time
+
+	// FlapTransitions are the times of the recent transitions of the evaluation results between firing
+	// and not firing. Only the transitions within the flap detection window are kept.
+	FlapTransitions []time.Time
+
+	// Flapping is set to true if the state has too many FlapTransitions. A flapping state keeps firing
+	// until it stops flapping.
+	Flapping bool
+
+	// acknowledgement records who acknowledged and who owns the state, and the comments about it. It is nil if
+	// nobody responded to the state. It is replaced rather than modified when it changes, because it is changed
+	// by the API while the state is evaluated.
+	acknowledgement atomic.Value // *models.AlertInstanceAcknowledgement
+}
+
+// GetAcknowledgement returns the acknowledgement of the state, or nil if nobody responded to it. It must not be modified.
+func (a *State) GetAcknowledgement() *models.AlertInstanceAcknowledgement {
+	ack, _ := a.acknowledgement.Load().(*models.AlertInstanceAcknowledgement)
+	return ack
+}
+
+// SetAcknowledgement replaces the acknowledgement of the state.
+func (a *State) SetAcknowledgement(ack *models.AlertInstanceAcknowledgement) {
+	a.acknowledgement.Store(ack)
+}
+
+// Copy returns a copy of the state that can be modified without changing the state.
+func (a *State) Copy() *State {
+	cp := &State{
+		OrgID:                a.OrgID,
+		AlertRuleUID:         a.AlertRuleUID,
+		CacheID:              a.CacheID,
+		State:                a.State,
+		StateReason:          a.StateReason,
+		Results:              append([]Evaluation(nil), a.Results...),
+		Error:                a.Error,
+		Resolved:             a.Resolved,
+		Image:                a.Image,
+		Annotations:          maps.Clone(a.Annotations),
+		Labels:               a.Labels.Copy(),
+		Values:               maps.Clone(a.Values),
+		StartsAt:             a.StartsAt,
+		EndsAt:               a.EndsAt,
+		LastSentAt:           a.LastSentAt,
+		LastEvaluationString: a.LastEvaluationString,
+		LastEvaluationTime:   a.LastEvaluationTime,
+		EvaluationDuration:   a.EvaluationDuration,
+		KeepFiringSince:      a.KeepFiringSince,
+		FlapTransitions:      append([]time.Time(nil), a.FlapTransitions...),
+		Flapping:             a.Flapping,
+	}
+	if ack := a.GetAcknowledgement(); ack != nil {
+		cp.SetAcknowledgement(ack)
+	}
+	return cp
 }
 
 func (a *State) GetRuleKey() models.AlertRuleKey {
@@ -187,9 +248,18 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]*float
 	return result
 }
 
-func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger log.Logger) {
+func resultNormal(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
 	if state.State == eval.Normal {
 		logger.Debug("Keeping state", "state", state.State)
+	} else if state.State == eval.Alerting && state.keepFiring(rule, result.EvaluatedAt) {
+		logger.Debug("Keeping state because the alert keeps firing",
+			"state",
+			state.State,
+			"keep_firing_since",
+			state.KeepFiringSince,
+			"flapping",
+			state.Flapping)
+		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
 	} else {
 		nextEndsAt := result.EvaluatedAt
 		logger.Debug("Changing state",
@@ -404,6 +474,38 @@ func (a *State) TrimResults(alertRule *models.AlertRule) {
 	a.Results = newResults
 }
 
+// keepFiring returns true if the firing state should keep firing although the condition of the rule is
+// not met anymore, either because of the KeepFiringFor of the rule or because the state is flapping.
+func (a *State) keepFiring(rule *models.AlertRule, evaluatedAt time.Time) bool {
+	if a.KeepFiringSince.IsZero() {
+		a.KeepFiringSince = evaluatedAt
+	}
+	return a.Flapping || evaluatedAt.Sub(a.KeepFiringSince) < rule.KeepFiringFor
+}
+
+// updateFlapping records a transition if the result and the previous result differ in whether they
+// are firing, forgets the transitions that happened before the window, and marks the state as flapping
+// if it has at least threshold transitions. It must be called before the result is added to Results.
+func (a *State) updateFlapping(result eval.Result, window time.Duration, threshold int) {
+	firing := result.State == eval.Alerting
+	if n := len(a.Results); n > 0 && (a.Results[n-1].EvaluationState == eval.Alerting) != firing {
+		a.FlapTransitions = append(a.FlapTransitions, result.EvaluatedAt)
+	}
+
+	start := result.EvaluatedAt.Add(-window)
+	idx := 0
+	for idx < len(a.FlapTransitions) && !a.FlapTransitions[idx].After(start) {
+		idx++
+	}
+	if idx == len(a.FlapTransitions) {
+		a.FlapTransitions = nil
+	} else if idx > 0 {
+		a.FlapTransitions = append([]time.Time(nil), a.FlapTransitions[idx:]...)
+	}
+
+	a.Flapping = len(a.FlapTransitions) >= threshold
+}
+
 func nextEndsTime(interval int64, evaluatedAt time.Time) time.Time {
 	ends := ResendDelay
 	intv := time.Second * time.Duration(interval)
@@ -490,5 +592,11 @@ func GetRuleExtraLabels(rule *models.AlertRule, folderTitle string, includeFolde
 	if includeFolder {
 		extraLabels[models.FolderTitleLabel] = folderTitle
 	}
+
+	if rule.NotificationSettings != nil {
+		for k, v := range rule.NotificationSettings.ToLabels() {
+			extraLabels[k] = v
+		}
+	}
 	return extraLabels
 }
@@ -666,3 +666,22 @@ func TestTakeImage(t *testing.T) {
 		assert.Equal(t, ngmodels.Image{Path: "foo.png"}, *image)
 	})
 }
+
+func TestGetRuleExtraLabels(t *testing.T) {
+	t.Run("should not add routing labels to rules without notification settings", func(t *testing.T) {
+		rule := ngmodels.AlertRuleGen()()
+		rule.NotificationSettings = nil
+		lbls := GetRuleExtraLabels(rule, "folder", true)
+		require.Equal(t, "folder", lbls[ngmodels.FolderTitleLabel])
+		require.NotContains(t, lbls, ngmodels.AutogeneratedRouteLabel)
+	})
+
+	t.Run("should add routing labels of notification settings", func(t *testing.T) {
+		settings := ngmodels.NotificationSettings{Receiver: "team-a"}
+		rule := ngmodels.AlertRuleGen(ngmodels.WithNotificationSettings(settings))()
+		lbls := GetRuleExtraLabels(rule, "folder", true)
+		for k, v := range settings.ToLabels() {
+			require.Equal(t, v, lbls[k])
+		}
+	})
+}
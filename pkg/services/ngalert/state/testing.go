@@ -83,3 +83,41 @@ type NoopImageService struct{}
 func (s *NoopImageService) NewImage(_ context.Context, _ *models.AlertRule) (*models.Image, error) {
 	return &models.Image{}, nil
 }
+
+var _ AcknowledgementStore = &FakeAcknowledgementStore{}
+
+// FakeAcknowledgementStore keeps the acknowledgements of alert instances in memory.
+type FakeAcknowledgementStore struct {
+	mtx  sync.Mutex
+	Acks map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement
+}
+
+func (f *FakeAcknowledgementStore) ListAlertInstanceAcknowledgements(_ context.Context, q *models.ListAlertInstanceAcknowledgementsQuery) ([]*models.AlertInstanceAcknowledgement, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var result []*models.AlertInstanceAcknowledgement
+	for key, ack := range f.Acks {
+		if key.RuleOrgID != q.RuleOrgID || (q.RuleUID != "" && key.RuleUID != q.RuleUID) {
+			continue
+		}
+		result = append(result, ack.Copy())
+	}
+	return result, nil
+}
+
+func (f *FakeAcknowledgementStore) SaveAlertInstanceAcknowledgement(_ context.Context, ack models.AlertInstanceAcknowledgement) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.Acks == nil {
+		f.Acks = make(map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement)
+	}
+	f.Acks[ack.AlertInstanceKey] = *ack.Copy()
+	return nil
+}
+
+func (f *FakeAcknowledgementStore) DeleteAlertInstanceAcknowledgement(_ context.Context, key models.AlertInstanceKey) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.Acks, key)
+	return nil
+}
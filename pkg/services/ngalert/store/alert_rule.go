@@ -141,22 +141,26 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 			}
 			newRules = append(newRules, r)
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleUID:          r.UID,
-				RuleOrgID:        r.OrgID,
-				RuleNamespaceUID: r.NamespaceUID,
-				RuleGroup:        r.RuleGroup,
-				ParentVersion:    0,
-				Version:          r.Version,
-				Created:          r.Updated,
-				Condition:        r.Condition,
-				Title:            r.Title,
-				Data:             r.Data,
-				IntervalSeconds:  r.IntervalSeconds,
-				NoDataState:      r.NoDataState,
-				ExecErrState:     r.ExecErrState,
-				For:              r.For,
-				Annotations:      r.Annotations,
-				Labels:           r.Labels,
+				RuleUID:              r.UID,
+				RuleOrgID:            r.OrgID,
+				RuleNamespaceUID:     r.NamespaceUID,
+				RuleGroup:            r.RuleGroup,
+				ParentVersion:        0,
+				Version:              r.Version,
+				Created:              r.Updated,
+				Condition:            r.Condition,
+				Title:                r.Title,
+				Data:                 r.Data,
+				IntervalSeconds:      r.IntervalSeconds,
+				NoDataState:          r.NoDataState,
+				ExecErrState:         r.ExecErrState,
+				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
+				Annotations:          r.Annotations,
+				Labels:               r.Labels,
+				Record:               r.Record,
+				NotificationSettings: r.NotificationSettings,
+				Dependencies:         r.Dependencies,
 			})
 		}
 		if len(newRules) > 0 {
@@ -216,23 +220,27 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 			}
 			parentVersion = r.Existing.Version
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				RuleGroupIndex:   r.New.RuleGroupIndex,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version + 1,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:            r.New.OrgID,
+				RuleUID:              r.New.UID,
+				RuleNamespaceUID:     r.New.NamespaceUID,
+				RuleGroup:            r.New.RuleGroup,
+				RuleGroupIndex:       r.New.RuleGroupIndex,
+				ParentVersion:        parentVersion,
+				Version:              r.New.Version + 1,
+				Created:              r.New.Updated,
+				Condition:            r.New.Condition,
+				Title:                r.New.Title,
+				Data:                 r.New.Data,
+				IntervalSeconds:      r.New.IntervalSeconds,
+				NoDataState:          r.New.NoDataState,
+				ExecErrState:         r.New.ExecErrState,
+				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
+				Annotations:          r.New.Annotations,
+				Labels:               r.New.Labels,
+				Record:               r.New.Record,
+				NotificationSettings: r.New.NotificationSettings,
+				Dependencies:         r.New.Dependencies,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -387,6 +395,33 @@ func (st DBstore) ListAlertRules(ctx context.Context, query *ngmodels.ListAlertR
 	return result, err
 }
 
+// ListNotificationSettings fetches the notification settings of the alert rules of the organization that have them.
+// If ReceiverName is set, only the settings that use this receiver are returned.
+func (st DBstore) ListNotificationSettings(ctx context.Context, q ngmodels.ListNotificationSettingsQuery) (map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings, error) {
+	var rules []ngmodels.AlertRule
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_rule").
+			Cols("org_id", "uid", "notification_settings").
+			Where("org_id = ?", q.OrgID).
+			Where("notification_settings IS NOT NULL").
+			Find(&rules)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings, len(rules))
+	for _, rule := range rules {
+		if rule.NotificationSettings == nil {
+			continue
+		}
+		if q.ReceiverName != "" && rule.NotificationSettings.Receiver != q.ReceiverName {
+			continue
+		}
+		result[rule.GetKey()] = *rule.NotificationSettings
+	}
+	return result, nil
+}
+
 // Count returns either the number of the alert rules under a specific org (if orgID is not zero)
 // or the number of all the alert rules
 func (st DBstore) Count(ctx context.Context, orgID int64) (int64, error) {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -572,6 +573,157 @@ func TestIntegrationInsertAlertRules(t *testing.T) {
 	}
 }
 
+func TestIntegrationRecordingRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	recording := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval), models.WithRecord("job:requests:rate5m"))()
+	alerting := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval))()
+	ids, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*recording, *alerting})
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+
+	t.Run("should store the record of recording rules", func(t *testing.T) {
+		q := &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: recording.UID}
+		rule, err := store.GetAlertRuleByUID(context.Background(), q)
+		require.NoError(t, err)
+		require.Equal(t, recording.Record, rule.Record)
+		require.Equal(t, models.RuleTypeRecording, rule.Type())
+	})
+
+	t.Run("should not set the record of alerting rules", func(t *testing.T) {
+		q := &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: alerting.UID}
+		rule, err := store.GetAlertRuleByUID(context.Background(), q)
+		require.NoError(t, err)
+		require.Nil(t, rule.Record)
+	})
+
+	t.Run("should store the record in the rule versions", func(t *testing.T) {
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			var versions []models.AlertRuleVersion
+			if err := sess.Table(models.AlertRuleVersion{}).Where("rule_uid = ?", recording.UID).Find(&versions); err != nil {
+				return err
+			}
+			require.Len(t, versions, 1)
+			require.Equal(t, recording.Record, versions[0].Record)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestIntegrationRuleDependencies(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	upstream := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval))()
+	dependent := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval))()
+	dependent.Dependencies = []models.RuleDependency{{RuleUID: upstream.UID, Equal: []string{"instance"}}}
+	_, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*upstream, *dependent})
+	require.NoError(t, err)
+
+	t.Run("should store the dependencies of rules", func(t *testing.T) {
+		rule, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: dependent.UID})
+		require.NoError(t, err)
+		require.Equal(t, dependent.Dependencies, rule.Dependencies)
+	})
+
+	t.Run("should store the dependencies in the rule versions", func(t *testing.T) {
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			var versions []models.AlertRuleVersion
+			if err := sess.Table(models.AlertRuleVersion{}).Where("rule_uid = ?", dependent.UID).Find(&versions); err != nil {
+				return err
+			}
+			require.Len(t, versions, 1)
+			require.Equal(t, dependent.Dependencies, versions[0].Dependencies)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestIntegrationListNotificationSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	groupWait := model.Duration(30 * time.Second)
+	teamA := models.NotificationSettings{Receiver: "team-a", GroupWait: &groupWait, MuteTimeIntervals: []string{"weekends"}}
+	teamB := models.NotificationSettings{Receiver: "team-b", GroupBy: []string{models.GroupByAll}}
+
+	gen := func(mutators ...models.AlertRuleMutator) *models.AlertRule {
+		return models.AlertRuleGen(append([]models.AlertRuleMutator{models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval)}, mutators...)...)()
+	}
+	ruleA := gen(models.WithNotificationSettings(teamA))
+	ruleB := gen(models.WithNotificationSettings(teamB))
+	ruleWithout := gen()
+	otherOrg := gen(models.WithNotificationSettings(teamA), models.WithOrgID(2))
+	_, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*ruleA, *ruleB, *ruleWithout, *otherOrg})
+	require.NoError(t, err)
+
+	t.Run("should list settings of all rules of the organization", func(t *testing.T) {
+		result, err := store.ListNotificationSettings(context.Background(), models.ListNotificationSettingsQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, map[models.AlertRuleKey]models.NotificationSettings{
+			ruleA.GetKey(): teamA,
+			ruleB.GetKey(): teamB,
+		}, result)
+	})
+
+	t.Run("should filter by receiver", func(t *testing.T) {
+		result, err := store.ListNotificationSettings(context.Background(), models.ListNotificationSettingsQuery{OrgID: 1, ReceiverName: "team-b"})
+		require.NoError(t, err)
+		require.Equal(t, map[models.AlertRuleKey]models.NotificationSettings{
+			ruleB.GetKey(): teamB,
+		}, result)
+	})
+
+	t.Run("should store the settings in the rule versions", func(t *testing.T) {
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			var versions []models.AlertRuleVersion
+			if err := sess.Table(models.AlertRuleVersion{}).Where("rule_uid = ?", ruleA.UID).Find(&versions); err != nil {
+				return err
+			}
+			require.Len(t, versions, 1)
+			require.Equal(t, &teamA, versions[0].NotificationSettings)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
 func createRule(t *testing.T, store *DBstore, generate func() *models.AlertRule) *models.AlertRule {
 	t.Helper()
 	if generate == nil {
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AlertmanagerClusterStore is the storage of the database-backed Alertmanager cluster.
+type AlertmanagerClusterStore interface {
+	// HeartbeatAlertmanagerClusterPeer registers the peer with the name, or updates the time of its last heartbeat
+	// if it is already registered.
+	HeartbeatAlertmanagerClusterPeer(ctx context.Context, name string) error
+
+	// GetAlertmanagerClusterPeers returns the names of all peers, sorted by name, that sent a heartbeat after the time.
+	// If the time is zero, it returns all registered peers.
+	GetAlertmanagerClusterPeers(ctx context.Context, since time.Time) ([]string, error)
+
+	// DeleteAlertmanagerClusterPeer deregisters the peer with the name. Messages sent by the peer are kept.
+	DeleteAlertmanagerClusterPeer(ctx context.Context, name string) error
+
+	// DeleteStaleAlertmanagerClusterPeers deregisters all peers that did not send a heartbeat since the time.
+	// It returns the number of deregistered peers or an error.
+	DeleteStaleAlertmanagerClusterPeers(ctx context.Context, before time.Time) (int64, error)
+
+	// SaveAlertmanagerClusterMessage saves the message and sets its ID and creation time. When the message contains the
+	// full state of the peer, the full state previously saved by the same peer is deleted.
+	SaveAlertmanagerClusterMessage(ctx context.Context, msg *models.AlertmanagerClusterMessage) error
+
+	// GetAlertmanagerClusterMessages returns the messages that match the query, ordered by ID.
+	GetAlertmanagerClusterMessages(ctx context.Context, query models.GetAlertmanagerClusterMessagesQuery) ([]*models.AlertmanagerClusterMessage, error)
+
+	// GetLastAlertmanagerClusterMessageID returns the ID of the last message, or zero if there are no messages.
+	GetLastAlertmanagerClusterMessageID(ctx context.Context) (int64, error)
+
+	// DeleteAlertmanagerClusterMessages deletes all messages created before the time.
+	// It returns the number of deleted messages or an error.
+	DeleteAlertmanagerClusterMessages(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (st DBstore) HeartbeatAlertmanagerClusterPeer(ctx context.Context, name string) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		peer := models.AlertmanagerClusterPeer{Name: name, Heartbeat: TimeNow().UTC()}
+		// Some databases return 0 rows affected if no changes were made, so check if the peer exists first.
+		ok, err := sess.Where("name = ?", name).Exist(&models.AlertmanagerClusterPeer{})
+		if err != nil {
+			return fmt.Errorf("failed to check if peer exists: %w", err)
+		}
+		if ok {
+			if _, err := sess.Where("name = ?", name).Cols("heartbeat").Update(&peer); err != nil {
+				return fmt.Errorf("failed to update heartbeat of peer: %w", err)
+			}
+			return nil
+		}
+		if _, err := sess.Insert(&peer); err != nil {
+			return fmt.Errorf("failed to insert peer: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetAlertmanagerClusterPeers(ctx context.Context, since time.Time) ([]string, error) {
+	var peers []models.AlertmanagerClusterPeer
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Asc("name")
+		if !since.IsZero() {
+			q = q.Where("heartbeat > ?", since.UTC())
+		}
+		return q.Find(&peers)
+	}); err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(peers))
+	for _, p := range peers {
+		result = append(result, p.Name)
+	}
+	return result, nil
+}
+
+func (st DBstore) DeleteAlertmanagerClusterPeer(ctx context.Context, name string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("name = ?", name).Delete(&models.AlertmanagerClusterPeer{}); err != nil {
+			return fmt.Errorf("failed to delete peer: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) DeleteStaleAlertmanagerClusterPeers(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("heartbeat < ?", before.UTC()).Delete(&models.AlertmanagerClusterPeer{})
+		if err != nil {
+			return fmt.Errorf("failed to delete stale peers: %w", err)
+		}
+		n = rows
+		return nil
+	}); err != nil {
+		return -1, err
+	}
+	return n, nil
+}
+
+func (st DBstore) SaveAlertmanagerClusterMessage(ctx context.Context, msg *models.AlertmanagerClusterMessage) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if msg.MessageType == models.AlertmanagerClusterMessageFullState {
+			if _, err := sess.Where("peer = ? AND message_type = ?", msg.Peer, msg.MessageType).Delete(&models.AlertmanagerClusterMessage{}); err != nil {
+				return fmt.Errorf("failed to delete previous full state: %w", err)
+			}
+		}
+		msg.ID = 0
+		msg.CreatedAt = TimeNow().UTC()
+		if _, err := sess.Insert(msg); err != nil {
+			return fmt.Errorf("failed to insert message: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetAlertmanagerClusterMessages(ctx context.Context, query models.GetAlertmanagerClusterMessagesQuery) ([]*models.AlertmanagerClusterMessage, error) {
+	var messages []*models.AlertmanagerClusterMessage
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("id > ?", query.AfterID)
+		if query.ExcludePeer != "" {
+			q = q.And("peer <> ?", query.ExcludePeer)
+		}
+		if query.MessageType != "" {
+			q = q.And("message_type = ?", query.MessageType)
+		}
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		return q.Asc("id").Find(&messages)
+	}); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (st DBstore) GetLastAlertmanagerClusterMessageID(ctx context.Context) (int64, error) {
+	var id int64
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Table(&models.AlertmanagerClusterMessage{}).Select("COALESCE(MAX(id), 0)").Get(&id)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (st DBstore) DeleteAlertmanagerClusterMessages(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("created_at < ?", before.UTC()).Delete(&models.AlertmanagerClusterMessage{})
+		if err != nil {
+			return fmt.Errorf("failed to delete messages: %w", err)
+		}
+		n = rows
+		return nil
+	}); err != nil {
+		return -1, err
+	}
+	return n, nil
+}
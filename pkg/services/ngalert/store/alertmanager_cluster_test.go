@@ -0,0 +1,137 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationAlertmanagerClusterPeers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Now().Truncate(time.Second)
+	t.Cleanup(func() {
+		store.TimeNow = time.Now
+	})
+	store.TimeNow = func() time.Time {
+		return now
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	require.NoError(t, dbstore.HeartbeatAlertmanagerClusterPeer(ctx, "peer-b"))
+	require.NoError(t, dbstore.HeartbeatAlertmanagerClusterPeer(ctx, "peer-a"))
+
+	peers, err := dbstore.GetAlertmanagerClusterPeers(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"peer-a", "peer-b"}, peers)
+
+	// peer-a sends another heartbeat later, peer-b does not.
+	now = now.Add(time.Minute)
+	require.NoError(t, dbstore.HeartbeatAlertmanagerClusterPeer(ctx, "peer-a"))
+
+	peers, err = dbstore.GetAlertmanagerClusterPeers(ctx, now.Add(-30*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []string{"peer-a"}, peers)
+
+	n, err := dbstore.DeleteStaleAlertmanagerClusterPeers(ctx, now.Add(-30*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	require.NoError(t, dbstore.DeleteAlertmanagerClusterPeer(ctx, "peer-a"))
+	peers, err = dbstore.GetAlertmanagerClusterPeers(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, peers)
+}
+
+func TestIntegrationAlertmanagerClusterMessages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Now().Truncate(time.Second)
+	t.Cleanup(func() {
+		store.TimeNow = time.Now
+	})
+	store.TimeNow = func() time.Time {
+		return now
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	id, err := dbstore.GetLastAlertmanagerClusterMessageID(ctx)
+	require.NoError(t, err)
+	require.Zero(t, id)
+
+	save := func(peer, msgType, payload string) *models.AlertmanagerClusterMessage {
+		msg := &models.AlertmanagerClusterMessage{Peer: peer, MessageType: msgType, Payload: []byte(payload)}
+		require.NoError(t, dbstore.SaveAlertmanagerClusterMessage(ctx, msg))
+		require.NotZero(t, msg.ID)
+		return msg
+	}
+	payloads := func(messages []*models.AlertmanagerClusterMessage) []string {
+		result := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			result = append(result, string(msg.Payload))
+		}
+		return result
+	}
+
+	save("peer-a", models.AlertmanagerClusterMessageFullState, "a-full-1")
+	first := save("peer-a", models.AlertmanagerClusterMessageUpdate, "a-update-1")
+	save("peer-b", models.AlertmanagerClusterMessageUpdate, "b-update-1")
+	now = now.Add(time.Minute)
+	save("peer-a", models.AlertmanagerClusterMessageFullState, "a-full-2")
+	last := save("peer-b", models.AlertmanagerClusterMessageFullState, "b-full-1")
+
+	t.Run("should replace the previous full state of the peer", func(t *testing.T) {
+		messages, err := dbstore.GetAlertmanagerClusterMessages(ctx, models.GetAlertmanagerClusterMessagesQuery{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a-update-1", "b-update-1", "a-full-2", "b-full-1"}, payloads(messages))
+	})
+
+	t.Run("should filter messages", func(t *testing.T) {
+		messages, err := dbstore.GetAlertmanagerClusterMessages(ctx, models.GetAlertmanagerClusterMessagesQuery{
+			AfterID:     first.ID,
+			ExcludePeer: "peer-b",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a-full-2"}, payloads(messages))
+
+		messages, err = dbstore.GetAlertmanagerClusterMessages(ctx, models.GetAlertmanagerClusterMessagesQuery{
+			MessageType: models.AlertmanagerClusterMessageFullState,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a-full-2", "b-full-1"}, payloads(messages))
+
+		messages, err = dbstore.GetAlertmanagerClusterMessages(ctx, models.GetAlertmanagerClusterMessagesQuery{Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a-update-1"}, payloads(messages))
+	})
+
+	t.Run("should return the ID of the last message", func(t *testing.T) {
+		id, err := dbstore.GetLastAlertmanagerClusterMessageID(ctx)
+		require.NoError(t, err)
+		require.Equal(t, last.ID, id)
+	})
+
+	t.Run("should delete old messages", func(t *testing.T) {
+		n, err := dbstore.DeleteAlertmanagerClusterMessages(ctx, now.Add(-time.Second))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), n)
+
+		messages, err := dbstore.GetAlertmanagerClusterMessages(ctx, models.GetAlertmanagerClusterMessagesQuery{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a-full-2", "b-full-1"}, payloads(messages))
+	})
+}
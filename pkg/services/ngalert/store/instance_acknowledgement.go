@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ListAlertInstanceAcknowledgements returns the acknowledgements of the alert instances of the organization,
+// optionally filtered by rule.
+func (st DBstore) ListAlertInstanceAcknowledgements(ctx context.Context, query *models.ListAlertInstanceAcknowledgementsQuery) (result []*models.AlertInstanceAcknowledgement, err error) {
+	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		acks := make([]*models.AlertInstanceAcknowledgement, 0)
+
+		s := strings.Builder{}
+		params := make([]any, 0)
+		s.WriteString("SELECT * FROM alert_instance_acknowledgement WHERE rule_org_id = ?")
+		params = append(params, query.RuleOrgID)
+		if query.RuleUID != "" {
+			s.WriteString(" AND rule_uid = ?")
+			params = append(params, query.RuleUID)
+		}
+		if err := sess.SQL(s.String(), params...).Find(&acks); err != nil {
+			return err
+		}
+		result = acks
+		return nil
+	})
+	return result, err
+}
+
+// SaveAlertInstanceAcknowledgement inserts or replaces the acknowledgement of an alert instance.
+func (st DBstore) SaveAlertInstanceAcknowledgement(ctx context.Context, ack models.AlertInstanceAcknowledgement) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var comments any
+		if len(ack.Comments) > 0 {
+			b, err := json.Marshal(ack.Comments)
+			if err != nil {
+				return err
+			}
+			comments = string(b)
+		}
+		params := []any{ack.RuleOrgID, ack.RuleUID, ack.LabelsHash, ack.AcknowledgedBy, ack.AcknowledgedAt.Unix(), ack.Assignee, comments, ack.Updated.Unix()}
+
+		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
+			"alert_instance_acknowledgement",
+			[]string{"rule_org_id", "rule_uid", "labels_hash"},
+			[]string{"rule_org_id", "rule_uid", "labels_hash", "acknowledged_by", "acknowledged_at", "assignee", "comments", "updated"})
+		_, err := sess.SQL(upsertSQL, params...).Query()
+		return err
+	})
+}
+
+// DeleteAlertInstanceAcknowledgement deletes the acknowledgement of an alert instance. It does nothing if the
+// acknowledgement does not exist.
+func (st DBstore) DeleteAlertInstanceAcknowledgement(ctx context.Context, key models.AlertInstanceKey) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM alert_instance_acknowledgement WHERE rule_org_id = ? AND rule_uid = ? AND labels_hash = ?", key.RuleOrgID, key.RuleUID, key.LabelsHash)
+		return err
+	})
+}
@@ -0,0 +1,102 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationAlertInstanceAcknowledgements(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+	alertRule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	newInstance := func(t *testing.T, value string) models.AlertInstance {
+		t.Helper()
+		labels := models.InstanceLabels{"test": value}
+		_, hash, _ := labels.StringAndHash()
+		instance := models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  alertRule.OrgID,
+				RuleUID:    alertRule.UID,
+				LabelsHash: hash,
+			},
+			CurrentState: models.InstanceStateFiring,
+			Labels:       labels,
+		}
+		require.NoError(t, dbstore.SaveAlertInstance(ctx, instance))
+		return instance
+	}
+	list := func(t *testing.T) []*models.AlertInstanceAcknowledgement {
+		t.Helper()
+		acks, err := dbstore.ListAlertInstanceAcknowledgements(ctx, &models.ListAlertInstanceAcknowledgementsQuery{RuleOrgID: alertRule.OrgID, RuleUID: alertRule.UID})
+		require.NoError(t, err)
+		return acks
+	}
+	now := time.Unix(1700000000, 0).UTC()
+
+	t.Run("can save, update and delete an acknowledgement", func(t *testing.T) {
+		instance := newInstance(t, "save")
+		ack := models.AlertInstanceAcknowledgement{
+			AlertInstanceKey: instance.AlertInstanceKey,
+			AcknowledgedBy:   "alice",
+			AcknowledgedAt:   now,
+			Assignee:         "bob",
+			Comments:         []models.AlertInstanceComment{{Author: "alice", Text: "on it", Created: now}},
+			Updated:          now,
+		}
+		require.NoError(t, dbstore.SaveAlertInstanceAcknowledgement(ctx, ack))
+
+		acks := list(t)
+		require.Len(t, acks, 1)
+		require.Equal(t, ack.AlertInstanceKey, acks[0].AlertInstanceKey)
+		require.Equal(t, "alice", acks[0].AcknowledgedBy)
+		require.Equal(t, now, acks[0].AcknowledgedAt.UTC())
+		require.Equal(t, "bob", acks[0].Assignee)
+		require.Len(t, acks[0].Comments, 1)
+		require.Equal(t, "on it", acks[0].Comments[0].Text)
+		require.Equal(t, now, acks[0].Comments[0].Created.UTC())
+
+		ack.AcknowledgedBy = ""
+		ack.AcknowledgedAt = time.Time{}
+		require.NoError(t, dbstore.SaveAlertInstanceAcknowledgement(ctx, ack))
+		acks = list(t)
+		require.Len(t, acks, 1)
+		require.False(t, acks[0].IsAcknowledged())
+		require.True(t, acks[0].AcknowledgedAt.IsZero())
+		require.Equal(t, "bob", acks[0].Assignee)
+
+		require.NoError(t, dbstore.DeleteAlertInstanceAcknowledgement(ctx, ack.AlertInstanceKey))
+		require.Empty(t, list(t))
+	})
+
+	t.Run("deleting alert instances deletes their acknowledgements", func(t *testing.T) {
+		instance1 := newInstance(t, "delete1")
+		instance2 := newInstance(t, "delete2")
+		for _, instance := range []models.AlertInstance{instance1, instance2} {
+			require.NoError(t, dbstore.SaveAlertInstanceAcknowledgement(ctx, models.AlertInstanceAcknowledgement{
+				AlertInstanceKey: instance.AlertInstanceKey,
+				Assignee:         "bob",
+				Updated:          now,
+			}))
+		}
+
+		require.NoError(t, dbstore.DeleteAlertInstances(ctx, instance1.AlertInstanceKey))
+		acks := list(t)
+		require.Len(t, acks, 1)
+		require.Equal(t, instance2.AlertInstanceKey, acks[0].AlertInstanceKey)
+
+		require.NoError(t, dbstore.DeleteAlertInstancesByRule(ctx, alertRule.GetKey()))
+		require.Empty(t, list(t))
+	})
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -54,12 +55,20 @@ func (st DBstore) SaveAlertInstance(ctx context.Context, alertInstance models.Al
 		if err != nil {
 			return err
 		}
-		params := append(make([]any, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+		var flapTransitions any
+		if len(alertInstance.FlapTransitions) > 0 {
+			b, err := json.Marshal(alertInstance.FlapTransitions)
+			if err != nil {
+				return err
+			}
+			flapTransitions = string(b)
+		}
+		params := append(make([]any, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(), flapTransitions)
 
 		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
 			"alert_instance",
 			[]string{"rule_org_id", "rule_uid", "labels_hash"},
-			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time"})
+			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "flap_transitions"})
 		_, err = sess.SQL(upsertSQL, params...).Query()
 		if err != nil {
 			return err
@@ -135,17 +144,21 @@ func (st DBstore) DeleteAlertInstances(ctx context.Context, keys ...models.Alert
 		placeholders = strings.TrimRight(placeholders, ", ")
 		placeholders = placeholders + ")"
 
-		queryString := fmt.Sprintf(
-			"DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ? AND labels_hash IN %s;",
-			placeholders,
-		)
-
-		execArgs := make([]any, 0, 3+len(rd.labelHashes))
-		execArgs = append(execArgs, queryString, rd.ruleOrgID, rd.ruleUID)
-		execArgs = append(execArgs, rd.labelHashes...)
-		_, err := s.Exec(execArgs...)
-		if err != nil {
-			return err
+		// The acknowledgements of the instances are deleted along with them.
+		for _, table := range []string{"alert_instance", "alert_instance_acknowledgement"} {
+			queryString := fmt.Sprintf(
+				"DELETE FROM %s WHERE rule_org_id = ? AND rule_uid = ? AND labels_hash IN %s;",
+				table,
+				placeholders,
+			)
+
+			execArgs := make([]any, 0, 3+len(rd.labelHashes))
+			execArgs = append(execArgs, queryString, rd.ruleOrgID, rd.ruleUID)
+			execArgs = append(execArgs, rd.labelHashes...)
+			_, err := s.Exec(execArgs...)
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -194,6 +207,10 @@ func (st DBstore) DeleteAlertInstances(ctx context.Context, keys ...models.Alert
 func (st DBstore) DeleteAlertInstancesByRule(ctx context.Context, key models.AlertRuleKey) error {
 	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		_, err := sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", key.OrgID, key.UID)
+		if err != nil {
+			return err
+		}
+		_, err = sess.Exec("DELETE FROM alert_instance_acknowledgement WHERE rule_org_id = ? AND rule_uid = ?", key.OrgID, key.UID)
 		return err
 	})
 }
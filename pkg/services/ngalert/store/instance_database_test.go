@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -293,4 +294,29 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, instance2.Labels, alerts[0].Labels)
 		require.Equal(t, instance2.CurrentState, alerts[0].CurrentState)
 	})
+
+	t.Run("can save and read flap transitions of alert instance", func(t *testing.T) {
+		alertRule5 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		labels := models.InstanceLabels{"test": "flapping"}
+		_, hash, _ := labels.StringAndHash()
+		instance := models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  alertRule5.OrgID,
+				RuleUID:    alertRule5.UID,
+				LabelsHash: hash,
+			},
+			CurrentState:    models.InstanceStateFiring,
+			Labels:          labels,
+			FlapTransitions: []time.Time{time.Unix(1700000000, 0).UTC(), time.Unix(1700000060, 0).UTC()},
+		}
+		err := dbstore.SaveAlertInstance(ctx, instance)
+		require.NoError(t, err)
+
+		alerts, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: instance.RuleOrgID, RuleUID: instance.RuleUID})
+		require.NoError(t, err)
+		require.Len(t, alerts, 1)
+		require.Len(t, alerts[0].FlapTransitions, 2)
+		require.True(t, instance.FlapTransitions[0].Equal(alerts[0].FlapTransitions[0]))
+		require.True(t, instance.FlapTransitions[1].Equal(alerts[0].FlapTransitions[1]))
+	})
 }
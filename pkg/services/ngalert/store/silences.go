@@ -0,0 +1,180 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SilenceStore is the storage of silence templates and recurring silences.
+type SilenceStore interface {
+	// GetSilenceTemplates returns all silence templates of the organization, sorted by name.
+	GetSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error)
+
+	// GetSilenceTemplate returns the silence template with the UID, or models.ErrSilenceTemplateNotFound.
+	GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error)
+
+	// SaveSilenceTemplate inserts the silence template if its ID is zero, and updates it otherwise.
+	// A UID is generated if the inserted template does not have one.
+	SaveSilenceTemplate(ctx context.Context, t *models.SilenceTemplate) error
+
+	// DeleteSilenceTemplate deletes the silence template with the UID. It does nothing if the template does not exist.
+	DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error
+
+	// ListRecurringSilences returns the recurring silences that match the query, sorted by organization and name.
+	ListRecurringSilences(ctx context.Context, query models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error)
+
+	// GetRecurringSilence returns the recurring silence with the UID, or models.ErrRecurringSilenceNotFound.
+	GetRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error)
+
+	// SaveRecurringSilence inserts the recurring silence if its ID is zero, and updates it otherwise.
+	// A UID is generated if the inserted silence does not have one.
+	SaveRecurringSilence(ctx context.Context, s *models.RecurringSilence) error
+
+	// DeleteRecurringSilence deletes the recurring silence with the UID. It does nothing if the silence does not exist.
+	DeleteRecurringSilence(ctx context.Context, orgID int64, uid string) error
+
+	// UpdateRecurringSilenceMaterializedUntil sets MaterializedUntil of the recurring silence with the ID to until,
+	// provided that it is still previous. It returns false if the recurring silence was changed concurrently.
+	UpdateRecurringSilenceMaterializedUntil(ctx context.Context, id int64, previous, until int64) (bool, error)
+}
+
+func (st DBstore) GetSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error) {
+	var result []*models.SilenceTemplate
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name", "id").Find(&result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (st DBstore) GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error) {
+	var result models.SilenceTemplate
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		ok, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&result)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrSilenceTemplateNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (st DBstore) SaveSilenceTemplate(ctx context.Context, t *models.SilenceTemplate) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		t.Updated = TimeNow().UTC()
+		if t.ID != 0 {
+			if _, err := sess.ID(t.ID).AllCols().Update(t); err != nil {
+				return fmt.Errorf("failed to update silence template: %w", err)
+			}
+			return nil
+		}
+		if t.UID == "" {
+			t.UID = util.GenerateShortUID()
+		}
+		if _, err := sess.Insert(t); err != nil {
+			return fmt.Errorf("failed to insert silence template: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM alert_silence_template WHERE org_id = ? AND uid = ?", orgID, uid)
+		return err
+	})
+}
+
+func (st DBstore) ListRecurringSilences(ctx context.Context, query models.ListRecurringSilencesQuery) ([]*models.RecurringSilence, error) {
+	var result []*models.RecurringSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Asc("org_id", "name", "id")
+		if query.OrgID > 0 {
+			q = q.Where("org_id = ?", query.OrgID)
+		}
+		if query.TemplateUID != "" {
+			q = q.Where("template_uid = ?", query.TemplateUID)
+		}
+		return q.Find(&result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (st DBstore) GetRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error) {
+	var result models.RecurringSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		ok, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&result)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrRecurringSilenceNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (st DBstore) SaveRecurringSilence(ctx context.Context, s *models.RecurringSilence) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		s.Updated = TimeNow().UTC()
+		if s.ID != 0 {
+			if _, err := sess.ID(s.ID).AllCols().Update(s); err != nil {
+				return fmt.Errorf("failed to update recurring silence: %w", err)
+			}
+			return nil
+		}
+		if s.UID == "" {
+			s.UID = util.GenerateShortUID()
+		}
+		if _, err := sess.Insert(s); err != nil {
+			return fmt.Errorf("failed to insert recurring silence: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) DeleteRecurringSilence(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM alert_recurring_silence WHERE org_id = ? AND uid = ?", orgID, uid)
+		return err
+	})
+}
+
+func (st DBstore) UpdateRecurringSilenceMaterializedUntil(ctx context.Context, id int64, previous, until int64) (bool, error) {
+	var updated bool
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE alert_recurring_silence SET materialized_until = ? WHERE id = ? AND materialized_until = ?", until, id, previous)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		updated = n > 0
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return updated, nil
+}
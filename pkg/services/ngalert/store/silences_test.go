@@ -0,0 +1,106 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationSilenceTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	matchers := models.SilenceMatchers{{Name: "team", Type: "=", Value: "ops"}}
+	tmpl := &models.SilenceTemplate{OrgID: 1, Name: "maintenance", Matchers: matchers, Duration: time.Hour}
+	require.NoError(t, dbstore.SaveSilenceTemplate(ctx, tmpl))
+	require.NotZero(t, tmpl.ID)
+	require.NotEmpty(t, tmpl.UID)
+	require.NoError(t, dbstore.SaveSilenceTemplate(ctx, &models.SilenceTemplate{OrgID: 2, UID: "other", Name: "other", Matchers: matchers, Duration: time.Hour}))
+
+	stored, err := dbstore.GetSilenceTemplate(ctx, 1, tmpl.UID)
+	require.NoError(t, err)
+	require.Equal(t, matchers, stored.Matchers)
+	require.Equal(t, time.Hour, stored.Duration)
+
+	_, err = dbstore.GetSilenceTemplate(ctx, 1, "other")
+	require.ErrorIs(t, err, models.ErrSilenceTemplateNotFound)
+
+	stored.Comment = "updated"
+	require.NoError(t, dbstore.SaveSilenceTemplate(ctx, stored))
+	templates, err := dbstore.GetSilenceTemplates(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	require.Equal(t, "updated", templates[0].Comment)
+
+	require.NoError(t, dbstore.DeleteSilenceTemplate(ctx, 1, tmpl.UID))
+	templates, err = dbstore.GetSilenceTemplates(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, templates)
+}
+
+func TestIntegrationRecurringSilences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	s1 := &models.RecurringSilence{OrgID: 1, Name: "b", TemplateUID: "tmpl", Schedule: "0 2 * * *"}
+	s2 := &models.RecurringSilence{OrgID: 1, Name: "a", Matchers: models.SilenceMatchers{{Name: "a", Type: "=", Value: "b"}}, MuteTimeInterval: "weekends"}
+	s3 := &models.RecurringSilence{OrgID: 2, Name: "c", TemplateUID: "tmpl", Schedule: "0 3 * * *"}
+	for _, s := range []*models.RecurringSilence{s1, s2, s3} {
+		require.NoError(t, dbstore.SaveRecurringSilence(ctx, s))
+		require.NotEmpty(t, s.UID)
+	}
+
+	names := func(silences []*models.RecurringSilence) []string {
+		result := make([]string, 0, len(silences))
+		for _, s := range silences {
+			result = append(result, s.Name)
+		}
+		return result
+	}
+
+	t.Run("should list recurring silences", func(t *testing.T) {
+		silences, err := dbstore.ListRecurringSilences(ctx, models.ListRecurringSilencesQuery{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, names(silences))
+
+		silences, err = dbstore.ListRecurringSilences(ctx, models.ListRecurringSilencesQuery{OrgID: 1, TemplateUID: "tmpl"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"b"}, names(silences))
+	})
+
+	t.Run("should update materialized until only if unchanged", func(t *testing.T) {
+		ok, err := dbstore.UpdateRecurringSilenceMaterializedUntil(ctx, s1.ID, 0, 100)
+		require.NoError(t, err)
+		require.True(t, ok)
+		ok, err = dbstore.UpdateRecurringSilenceMaterializedUntil(ctx, s1.ID, 0, 200)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		s1.Schedule = "0 4 * * *"
+		s1.MaterializedUntil = 0
+		require.NoError(t, dbstore.SaveRecurringSilence(ctx, s1))
+		stored, err := dbstore.GetRecurringSilence(ctx, 1, s1.UID)
+		require.NoError(t, err)
+		require.Equal(t, "0 4 * * *", stored.Schedule)
+		require.Zero(t, stored.MaterializedUntil)
+	})
+
+	t.Run("should delete recurring silence", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteRecurringSilence(ctx, 1, s1.UID))
+		_, err := dbstore.GetRecurringSilence(ctx, 1, s1.UID)
+		require.ErrorIs(t, err, models.ErrRecurringSilenceNotFound)
+	})
+}
@@ -347,3 +347,19 @@ func (f *RuleStore) IncreaseVersionForAllRulesInNamespace(_ context.Context, org
 func (f *RuleStore) CountInFolder(ctx context.Context, orgID int64, folderUID string, u identity.Requester) (int64, error) {
 	return 0, nil
 }
+
+func (f *RuleStore) ListNotificationSettings(_ context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey]models.NotificationSettings, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := make(map[models.AlertRuleKey]models.NotificationSettings)
+	for _, rule := range f.Rules[q.OrgID] {
+		if rule.NotificationSettings == nil {
+			continue
+		}
+		if q.ReceiverName != "" && rule.NotificationSettings.Receiver != q.ReceiverName {
+			continue
+		}
+		result[rule.GetKey()] = *rule.NotificationSettings
+	}
+	return result, nil
+}
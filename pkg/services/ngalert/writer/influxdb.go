@@ -0,0 +1,143 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	lp "github.com/influxdata/line-protocol"
+	"github.com/weaveworks/common/http/client"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// influxValueField is the field holding the value of the samples. The
+// measurement is the metric name and the labels are tags.
+const influxValueField = "value"
+
+// InfluxWriter writes samples as InfluxDB line protocol.
+type InfluxWriter struct {
+	client            client.Requester
+	writeURL          *url.URL
+	token             string
+	basicAuthUser     string
+	basicAuthPassword string
+	externalLabels    map[string]string
+	metrics           *metrics.RemoteWriter
+	log               log.Logger
+}
+
+func NewInfluxWriter(u *url.URL, cfg setting.RecordingRuleSettings, req client.Requester, m *metrics.RemoteWriter, logger log.Logger) (*InfluxWriter, error) {
+	writeURL, err := influxWriteURL(u, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &InfluxWriter{
+		client:            client.NewTimedClient(req, m.WriteDuration),
+		writeURL:          writeURL,
+		token:             cfg.InfluxToken,
+		basicAuthUser:     cfg.BasicAuthUsername,
+		basicAuthPassword: cfg.BasicAuthPassword,
+		externalLabels:    cfg.ExternalLabels,
+		metrics:           m,
+		log:               logger,
+	}, nil
+}
+
+// influxWriteURL returns the URL of the write API of the configured version of InfluxDB.
+func influxWriteURL(u *url.URL, cfg setting.RecordingRuleSettings) (*url.URL, error) {
+	values := url.Values{}
+	values.Set("precision", "ns")
+
+	var uri *url.URL
+	switch cfg.InfluxVersion {
+	case "1":
+		if cfg.InfluxDatabase == "" {
+			return nil, fmt.Errorf("database must be provided for InfluxDB 1")
+		}
+		uri = u.JoinPath("/write")
+		values.Set("db", cfg.InfluxDatabase)
+		values.Set("precision", "n")
+	case "3":
+		if cfg.InfluxDatabase == "" {
+			return nil, fmt.Errorf("database must be provided for InfluxDB 3")
+		}
+		// InfluxDB 3 accepts the v2 write API with the database as the bucket.
+		uri = u.JoinPath("/api/v2/write")
+		values.Set("bucket", cfg.InfluxDatabase)
+		if cfg.InfluxOrganization != "" {
+			values.Set("org", cfg.InfluxOrganization)
+		}
+	case "", "2":
+		if cfg.InfluxOrganization == "" || cfg.InfluxBucket == "" {
+			return nil, fmt.Errorf("organization and bucket must be provided for InfluxDB 2")
+		}
+		uri = u.JoinPath("/api/v2/write")
+		values.Set("org", cfg.InfluxOrganization)
+		values.Set("bucket", cfg.InfluxBucket)
+	default:
+		return nil, fmt.Errorf("unsupported InfluxDB version: %s", cfg.InfluxVersion)
+	}
+	uri.RawQuery = values.Encode()
+	return uri, nil
+}
+
+func (w *InfluxWriter) Write(ctx context.Context, orgID int64, metric string, t time.Time, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	lines, err := encodeLines(metric, t, samples, w.externalLabels)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	org := fmt.Sprint(orgID)
+	w.metrics.WritesTotal.WithLabelValues(org, TargetInfluxDB).Inc()
+
+	req, err := http.NewRequest(http.MethodPost, w.writeURL.String(), bytes.NewReader(lines))
+	if err != nil {
+		w.metrics.WritesFailed.WithLabelValues(org, TargetInfluxDB).Inc()
+		return fmt.Errorf("failed to create InfluxDB request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", w.token))
+	} else if w.basicAuthUser != "" || w.basicAuthPassword != "" {
+		req.SetBasicAuth(w.basicAuthUser, w.basicAuthPassword)
+	}
+
+	if err := send(ctx, w.client, req, w.log); err != nil {
+		w.metrics.WritesFailed.WithLabelValues(org, TargetInfluxDB).Inc()
+		return fmt.Errorf("failed to write to InfluxDB: %w", err)
+	}
+	w.metrics.SamplesWritten.WithLabelValues(org, TargetInfluxDB).Add(float64(len(samples)))
+	return nil
+}
+
+// encodeLines encodes the samples as line protocol with nanosecond precision.
+// NaN and infinite values are left out as InfluxDB does not support them.
+func encodeLines(metric string, t time.Time, samples []Sample, externalLabels map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	e := lp.NewEncoder(&buf)
+	e.FailOnFieldErr(true)
+	e.SetPrecision(time.Nanosecond)
+	for _, s := range samples {
+		if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+			continue
+		}
+		p := write.NewPoint(metric, withExternalLabels(s.Labels, externalLabels), map[string]any{influxValueField: s.Value}, t)
+		if _, err := e.Encode(p); err != nil {
+			return nil, fmt.Errorf("failed to encode sample as line protocol: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,125 @@
+package writer
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestInfluxWriteURL(t *testing.T) {
+	u, err := url.Parse("http://localhost:8086")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		cfg      setting.RecordingRuleSettings
+		expected string
+		err      string
+	}{
+		{
+			name:     "v1 writes to database",
+			cfg:      setting.RecordingRuleSettings{InfluxVersion: "1", InfluxDatabase: "db"},
+			expected: "http://localhost:8086/write?db=db&precision=n",
+		},
+		{
+			name: "v1 requires database",
+			cfg:  setting.RecordingRuleSettings{InfluxVersion: "1"},
+			err:  "database must be provided",
+		},
+		{
+			name:     "v2 writes to bucket",
+			cfg:      setting.RecordingRuleSettings{InfluxVersion: "2", InfluxOrganization: "org", InfluxBucket: "bucket"},
+			expected: "http://localhost:8086/api/v2/write?bucket=bucket&org=org&precision=ns",
+		},
+		{
+			name:     "defaults to v2",
+			cfg:      setting.RecordingRuleSettings{InfluxOrganization: "org", InfluxBucket: "bucket"},
+			expected: "http://localhost:8086/api/v2/write?bucket=bucket&org=org&precision=ns",
+		},
+		{
+			name: "v2 requires organization and bucket",
+			cfg:  setting.RecordingRuleSettings{InfluxVersion: "2", InfluxBucket: "bucket"},
+			err:  "organization and bucket must be provided",
+		},
+		{
+			name:     "v3 writes to database as bucket",
+			cfg:      setting.RecordingRuleSettings{InfluxVersion: "3", InfluxDatabase: "db"},
+			expected: "http://localhost:8086/api/v2/write?bucket=db&precision=ns",
+		},
+		{
+			name: "rejects unknown versions",
+			cfg:  setting.RecordingRuleSettings{InfluxVersion: "4"},
+			err:  "unsupported InfluxDB version",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := influxWriteURL(u, tc.cfg)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res.String())
+		})
+	}
+}
+
+func TestEncodeLines(t *testing.T) {
+	lines, err := encodeLines("job:requests:rate5m", testTime, []Sample{
+		{Labels: data.Labels{"job": "api"}, Value: 1.5},
+		{Labels: data.Labels{"job": "nan"}, Value: math.NaN()},
+		{Labels: data.Labels{"job": "inf"}, Value: math.Inf(1)},
+	}, map[string]string{"cluster": "eu"})
+	require.NoError(t, err)
+	require.Equal(t, "job:requests:rate5m,cluster=eu,job=api value=1.5 1700000000000000000\n", string(lines))
+}
+
+func TestInfluxWriter(t *testing.T) {
+	u, err := url.Parse("http://localhost:8086")
+	require.NoError(t, err)
+
+	t.Run("does not send requests without valid samples", func(t *testing.T) {
+		req := newFakeRequester()
+		cfg := setting.RecordingRuleSettings{InfluxVersion: "1", InfluxDatabase: "db"}
+		w, err := NewInfluxWriter(u, cfg, req, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, w.Write(context.Background(), 1, "metric", testTime, []Sample{{Value: math.NaN()}}))
+		require.Nil(t, req.lastRequest)
+	})
+
+	t.Run("authenticates with token", func(t *testing.T) {
+		req := newFakeRequester()
+		cfg := setting.RecordingRuleSettings{InfluxOrganization: "org", InfluxBucket: "bucket", InfluxToken: "secret", BasicAuthUsername: "user"}
+		w, err := NewInfluxWriter(u, cfg, req, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, w.Write(context.Background(), 1, "metric", testTime, []Sample{{Value: 1}}))
+		require.Equal(t, "/api/v2/write", req.lastRequest.URL.Path)
+		require.Equal(t, "Token secret", req.lastRequest.Header.Get("Authorization"))
+		require.Equal(t, "metric value=1 1700000000000000000\n", string(req.lastBody))
+	})
+
+	t.Run("authenticates with basic auth", func(t *testing.T) {
+		req := newFakeRequester()
+		cfg := setting.RecordingRuleSettings{InfluxVersion: "1", InfluxDatabase: "db", BasicAuthUsername: "user", BasicAuthPassword: "pass"}
+		w, err := NewInfluxWriter(u, cfg, req, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, w.Write(context.Background(), 1, "metric", testTime, []Sample{{Value: 1}}))
+		user, pass, ok := req.lastRequest.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "pass", pass)
+	})
+}
@@ -0,0 +1,114 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/weaveworks/common/http/client"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// PrometheusWriter writes samples to an endpoint accepting the Prometheus remote write protocol.
+type PrometheusWriter struct {
+	client            client.Requester
+	url               *url.URL
+	basicAuthUser     string
+	basicAuthPassword string
+	externalLabels    map[string]string
+	metrics           *metrics.RemoteWriter
+	log               log.Logger
+}
+
+func NewPrometheusWriter(u *url.URL, cfg setting.RecordingRuleSettings, req client.Requester, m *metrics.RemoteWriter, logger log.Logger) *PrometheusWriter {
+	return &PrometheusWriter{
+		client:            client.NewTimedClient(req, m.WriteDuration),
+		url:               u,
+		basicAuthUser:     cfg.BasicAuthUsername,
+		basicAuthPassword: cfg.BasicAuthPassword,
+		externalLabels:    cfg.ExternalLabels,
+		metrics:           m,
+		log:               logger,
+	}
+}
+
+func (w *PrometheusWriter) Write(ctx context.Context, orgID int64, metric string, t time.Time, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	org := fmt.Sprint(orgID)
+	w.metrics.WritesTotal.WithLabelValues(org, TargetPrometheus).Inc()
+
+	body, err := encodeWriteRequest(metric, t, samples, w.externalLabels)
+	if err != nil {
+		w.metrics.WritesFailed.WithLabelValues(org, TargetPrometheus).Inc()
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.url.String(), bytes.NewReader(body))
+	if err != nil {
+		w.metrics.WritesFailed.WithLabelValues(org, TargetPrometheus).Inc()
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.basicAuthUser != "" || w.basicAuthPassword != "" {
+		req.SetBasicAuth(w.basicAuthUser, w.basicAuthPassword)
+	}
+
+	if err := send(ctx, w.client, req, w.log); err != nil {
+		w.metrics.WritesFailed.WithLabelValues(org, TargetPrometheus).Inc()
+		return fmt.Errorf("failed to write to the remote write endpoint: %w", err)
+	}
+	w.metrics.SamplesWritten.WithLabelValues(org, TargetPrometheus).Add(float64(len(samples)))
+	return nil
+}
+
+// encodeWriteRequest returns the snappy compressed remote write request of
+// the samples. Invalid characters in label names are replaced with
+// underscores, as in the names of series written by Grafana Live.
+func encodeWriteRequest(metric string, t time.Time, samples []Sample, externalLabels map[string]string) ([]byte, error) {
+	series := make([]prompb.TimeSeries, 0, len(samples))
+	for _, s := range samples {
+		lbls := withExternalLabels(s.Labels, externalLabels)
+		labels := make([]prompb.Label, 0, len(lbls)+1)
+		labels = append(labels, prompb.Label{Name: "__name__", Value: metric})
+		for name, value := range lbls {
+			if name == "__name__" {
+				continue
+			}
+			labels = append(labels, prompb.Label{Name: sanitizeLabelName(name), Value: value})
+		}
+		// Remote write requires the labels to be sorted by name.
+		sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+		series = append(series, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Value: s.Value, Timestamp: t.UnixMilli()}},
+		})
+	}
+	data, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal remote write request: %w", err)
+	}
+	return snappy.Encode(nil, data), nil
+}
+
+func sanitizeLabelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9' && i > 0)) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,97 @@
+package writer
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var testTime = time.Unix(1700000000, 0)
+
+func TestEncodeWriteRequest(t *testing.T) {
+	body, err := encodeWriteRequest("job:requests:rate5m", testTime, []Sample{
+		{Labels: data.Labels{"job": "api", "dotted.label": "x", "__name__": "ignored"}, Value: 1.5},
+		{Labels: nil, Value: math.NaN()},
+	}, map[string]string{"cluster": "eu", "job": "external"})
+	require.NoError(t, err)
+
+	wr := decodeWriteRequest(t, body)
+	require.Len(t, wr.Timeseries, 2)
+	require.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "job:requests:rate5m"},
+		{Name: "cluster", Value: "eu"},
+		{Name: "dotted_label", Value: "x"},
+		{Name: "job", Value: "api"},
+	}, wr.Timeseries[0].Labels)
+	require.Equal(t, []prompb.Sample{{Value: 1.5, Timestamp: testTime.UnixMilli()}}, wr.Timeseries[0].Samples)
+	require.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "job:requests:rate5m"},
+		{Name: "cluster", Value: "eu"},
+		{Name: "job", Value: "external"},
+	}, wr.Timeseries[1].Labels)
+	require.True(t, math.IsNaN(wr.Timeseries[1].Samples[0].Value))
+}
+
+func TestSanitizeLabelName(t *testing.T) {
+	require.Equal(t, "valid_name", sanitizeLabelName("valid_name"))
+	require.Equal(t, "a_b_c", sanitizeLabelName("a.b-c"))
+	require.Equal(t, "_abc", sanitizeLabelName("1abc"))
+	require.Equal(t, "a1", sanitizeLabelName("a1"))
+}
+
+func TestPrometheusWriter(t *testing.T) {
+	u, err := url.Parse("http://localhost:9090/api/v1/write")
+	require.NoError(t, err)
+	cfg := setting.RecordingRuleSettings{BasicAuthUsername: "user", BasicAuthPassword: "pass"}
+
+	t.Run("does not send empty requests", func(t *testing.T) {
+		req := newFakeRequester()
+		w := NewPrometheusWriter(u, cfg, req, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+
+		require.NoError(t, w.Write(context.Background(), 1, "metric", testTime, nil))
+		require.Nil(t, req.lastRequest)
+	})
+
+	t.Run("sends remote write request", func(t *testing.T) {
+		req := newFakeRequester()
+		w := NewPrometheusWriter(u, cfg, req, metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+
+		err := w.Write(context.Background(), 1, "metric", testTime, []Sample{{Labels: data.Labels{"a": "b"}, Value: 2}})
+		require.NoError(t, err)
+
+		require.Equal(t, u.String(), req.lastRequest.URL.String())
+		require.Equal(t, "application/x-protobuf", req.lastRequest.Header.Get("Content-Type"))
+		require.Equal(t, "snappy", req.lastRequest.Header.Get("Content-Encoding"))
+		require.Equal(t, "0.1.0", req.lastRequest.Header.Get("X-Prometheus-Remote-Write-Version"))
+		user, pass, ok := req.lastRequest.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "pass", pass)
+
+		wr := decodeWriteRequest(t, req.lastBody)
+		require.Len(t, wr.Timeseries, 1)
+		require.Equal(t, 2.0, wr.Timeseries[0].Samples[0].Value)
+	})
+}
+
+func decodeWriteRequest(t *testing.T, body []byte) prompb.WriteRequest {
+	t.Helper()
+	decoded, err := snappy.Decode(nil, body)
+	require.NoError(t, err)
+	var wr prompb.WriteRequest
+	require.NoError(t, proto.Unmarshal(decoded, &wr))
+	return wr
+}
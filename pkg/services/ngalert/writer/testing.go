@@ -0,0 +1,75 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type fakeRequester struct {
+	lastRequest *http.Request
+	lastBody    []byte
+	resp        *http.Response
+}
+
+func newFakeRequester() *fakeRequester {
+	return &fakeRequester{
+		resp: &http.Response{
+			Status:        "204 No Content",
+			StatusCode:    http.StatusNoContent,
+			Body:          io.NopCloser(bytes.NewBufferString("")),
+			ContentLength: int64(0),
+			Header:        make(http.Header, 0),
+		},
+	}
+}
+
+func (f *fakeRequester) WithResponse(resp *http.Response) *fakeRequester {
+	f.resp = resp
+	return f
+}
+
+func (f *fakeRequester) Do(req *http.Request) (*http.Response, error) {
+	f.lastRequest = req
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		f.lastBody = b
+	}
+	f.resp.Request = req // Not concurrency-safe!
+	return f.resp, nil
+}
+
+// FakeWrite is a call to FakeWriter.Write.
+type FakeWrite struct {
+	OrgID   int64
+	Metric  string
+	Time    time.Time
+	Samples []Sample
+}
+
+// FakeWriter records the writes and returns Err.
+type FakeWriter struct {
+	mtx    sync.Mutex
+	Writes []FakeWrite
+	Err    error
+}
+
+func (w *FakeWriter) Write(_ context.Context, orgID int64, metric string, t time.Time, samples []Sample) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.Writes = append(w.Writes, FakeWrite{OrgID: orgID, Metric: metric, Time: t, Samples: samples})
+	return w.Err
+}
+
+// GetWrites returns a copy of the recorded writes.
+func (w *FakeWriter) GetWrites() []FakeWrite {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]FakeWrite(nil), w.Writes...)
+}
@@ -0,0 +1,178 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/weaveworks/common/http/client"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// TargetPrometheus writes samples with the Prometheus remote write protocol.
+	TargetPrometheus = "prometheus"
+	// TargetInfluxDB writes samples as InfluxDB line protocol.
+	TargetInfluxDB = "influxdb"
+)
+
+// Sample is the value of a series identified by its labels.
+type Sample struct {
+	Labels data.Labels
+	Value  float64
+}
+
+// Writer writes the results of recording rules to a time series database.
+type Writer interface {
+	// Write writes the samples of the series metric at time t.
+	Write(ctx context.Context, orgID int64, metric string, t time.Time, samples []Sample) error
+}
+
+// New returns the Writer configured by the settings.
+func New(cfg setting.RecordingRuleSettings, req client.Requester, m *metrics.RemoteWriter) (Writer, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("the URL of the recording rules target must be provided")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the URL of the recording rules target: %w", err)
+	}
+	logger := log.New("ngalert.writer", "target", cfg.Target)
+	switch cfg.Target {
+	case TargetPrometheus:
+		return NewPrometheusWriter(u, cfg, req, m, logger), nil
+	case TargetInfluxDB:
+		return NewInfluxWriter(u, cfg, req, m, logger)
+	default:
+		return nil, fmt.Errorf("unsupported recording rules target %q, expected one of [%s, %s]", cfg.Target, TargetPrometheus, TargetInfluxDB)
+	}
+}
+
+// NewRequester returns the HTTP client used to send requests to the target.
+func NewRequester(timeout time.Duration) client.Requester {
+	return &http.Client{Timeout: timeout}
+}
+
+// SamplesFromFrames converts the numbers returned by a query or expression
+// to samples. Frames with a single numeric field and at most one row are
+// numbers, a frame without rows has no value and is skipped. Any other frame,
+// such as a time series, is an error.
+func SamplesFromFrames(frames data.Frames) ([]Sample, error) {
+	samples := make([]Sample, 0, len(frames))
+	seen := make(map[data.Fingerprint]struct{}, len(frames))
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		if len(frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)) > 0 {
+			return nil, fmt.Errorf("the results of %s look like time series data, only reduced data can be recorded", frame.RefID)
+		}
+		if len(frame.Fields) != 1 {
+			return nil, fmt.Errorf("unexpected field length in the results of %s: %d instead of 1", frame.RefID, len(frame.Fields))
+		}
+		field := frame.Fields[0]
+		if field.Len() == 0 {
+			continue
+		}
+		if field.Len() > 1 {
+			return nil, fmt.Errorf("unexpected row length in the results of %s: %d instead of 0 or 1", frame.RefID, field.Len())
+		}
+		v, ok := field.ConcreteAt(0)
+		if !ok {
+			continue
+		}
+		value, err := toFloat(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in the results of %s: %w", frame.RefID, err)
+		}
+		fp := field.Labels.Fingerprint()
+		if _, ok := seen[fp]; ok {
+			return nil, fmt.Errorf("the results of %s cannot uniquely be identified by their labels: has duplicate results with labels {%s}", frame.RefID, field.Labels)
+		}
+		seen[fp] = struct{}{}
+		samples = append(samples, Sample{Labels: field.Labels, Value: value})
+	}
+	return samples, nil
+}
+
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case int16:
+		return float64(n), nil
+	case int8:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case uint32:
+		return float64(n), nil
+	case uint16:
+		return float64(n), nil
+	case uint8:
+		return float64(n), nil
+	case bool:
+		if n {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+// withExternalLabels returns the labels of the sample with the external
+// labels it does not already have.
+func withExternalLabels(labels data.Labels, external map[string]string) data.Labels {
+	if len(external) == 0 {
+		return labels
+	}
+	result := make(data.Labels, len(labels)+len(external))
+	for k, v := range external {
+		result[k] = v
+	}
+	for k, v := range labels {
+		result[k] = v
+	}
+	return result
+}
+
+// send posts the body to the target and returns an error if the request
+// failed or was not successful.
+func send(ctx context.Context, c client.Requester, req *http.Request, logger log.Logger) error {
+	resp, err := c.Do(req.WithContext(ctx))
+	if resp != nil {
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				logger.Warn("Failed to close response body", "error", err)
+			}
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		byt, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if len(byt) > 0 {
+			logger.Error("Error response from the recording rules target", "response", string(byt), "status", resp.StatusCode)
+		} else {
+			logger.Error("Error response from the recording rules target with an empty body", "status", resp.StatusCode)
+		}
+		return fmt.Errorf("received a non-200 response, status: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNew(t *testing.T) {
+	m := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+
+	t.Run("requires URL", func(t *testing.T) {
+		_, err := New(setting.RecordingRuleSettings{Target: TargetPrometheus}, newFakeRequester(), m)
+		require.Error(t, err)
+	})
+
+	t.Run("rejects unknown targets", func(t *testing.T) {
+		_, err := New(setting.RecordingRuleSettings{Target: "graphite", URL: "http://localhost"}, newFakeRequester(), m)
+		require.ErrorContains(t, err, "unsupported recording rules target")
+	})
+
+	t.Run("creates Prometheus writer", func(t *testing.T) {
+		w, err := New(setting.RecordingRuleSettings{Target: TargetPrometheus, URL: "http://localhost/api/v1/write"}, newFakeRequester(), m)
+		require.NoError(t, err)
+		require.IsType(t, &PrometheusWriter{}, w)
+	})
+
+	t.Run("creates InfluxDB writer", func(t *testing.T) {
+		w, err := New(setting.RecordingRuleSettings{Target: TargetInfluxDB, URL: "http://localhost", InfluxVersion: "1", InfluxDatabase: "db"}, newFakeRequester(), m)
+		require.NoError(t, err)
+		require.IsType(t, &InfluxWriter{}, w)
+	})
+
+	t.Run("validates InfluxDB settings", func(t *testing.T) {
+		_, err := New(setting.RecordingRuleSettings{Target: TargetInfluxDB, URL: "http://localhost", InfluxVersion: "2", InfluxOrganization: "org"}, newFakeRequester(), m)
+		require.Error(t, err)
+	})
+}
+
+func TestSamplesFromFrames(t *testing.T) {
+	number := func(labels data.Labels, values ...float64) *data.Frame {
+		return data.NewFrame("", data.NewField("Value", labels, values))
+	}
+
+	t.Run("converts numbers to samples", func(t *testing.T) {
+		fp := func(v float64) *float64 { return &v }
+		samples, err := SamplesFromFrames(data.Frames{
+			number(data.Labels{"a": "1"}, 1.5),
+			data.NewFrame("", data.NewField("Value", data.Labels{"a": "2"}, []*float64{fp(2)})),
+			data.NewFrame("", data.NewField("Value", data.Labels{"a": "3"}, []int64{3})),
+			data.NewFrame("", data.NewField("Value", data.Labels{"a": "4"}, []bool{true})),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []Sample{
+			{Labels: data.Labels{"a": "1"}, Value: 1.5},
+			{Labels: data.Labels{"a": "2"}, Value: 2},
+			{Labels: data.Labels{"a": "3"}, Value: 3},
+			{Labels: data.Labels{"a": "4"}, Value: 1},
+		}, samples)
+	})
+
+	t.Run("skips frames without values", func(t *testing.T) {
+		samples, err := SamplesFromFrames(data.Frames{
+			data.NewFrame(""),
+			data.NewFrame("", data.NewField("Value", nil, []float64{})),
+			data.NewFrame("", data.NewField("Value", nil, []*float64{nil})),
+		})
+		require.NoError(t, err)
+		require.Empty(t, samples)
+	})
+
+	t.Run("rejects more than one field", func(t *testing.T) {
+		frame := data.NewFrame("",
+			data.NewField("A", nil, []float64{1}),
+			data.NewField("B", nil, []float64{1}),
+		)
+		_, err := SamplesFromFrames(data.Frames{frame})
+		require.ErrorContains(t, err, "unexpected field length")
+	})
+
+	t.Run("rejects frames with a time field", func(t *testing.T) {
+		_, err := SamplesFromFrames(data.Frames{timeSeries()})
+		require.ErrorContains(t, err, "look like time series data")
+	})
+
+	t.Run("rejects more than one row", func(t *testing.T) {
+		_, err := SamplesFromFrames(data.Frames{number(nil, 1, 2)})
+		require.ErrorContains(t, err, "unexpected row length")
+	})
+
+	t.Run("rejects duplicate labels", func(t *testing.T) {
+		_, err := SamplesFromFrames(data.Frames{
+			number(data.Labels{"a": "1"}, 1),
+			number(data.Labels{"a": "1"}, 2),
+		})
+		require.ErrorContains(t, err, "duplicate results")
+	})
+
+	t.Run("rejects unsupported values", func(t *testing.T) {
+		_, err := SamplesFromFrames(data.Frames{data.NewFrame("", data.NewField("Value", nil, []string{"a"}))})
+		require.ErrorContains(t, err, "unsupported value type")
+	})
+}
+
+func TestWithExternalLabels(t *testing.T) {
+	labels := data.Labels{"a": "1", "env": "dev"}
+	require.Equal(t, labels, withExternalLabels(labels, nil))
+	require.Equal(t, data.Labels{"a": "1", "env": "dev", "region": "eu"}, withExternalLabels(labels, map[string]string{"env": "prod", "region": "eu"}))
+}
+
+func TestSend(t *testing.T) {
+	m := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+	cfg := setting.RecordingRuleSettings{Target: TargetPrometheus, URL: "http://localhost/api/v1/write"}
+
+	t.Run("fails on non-2xx responses", func(t *testing.T) {
+		req := newFakeRequester().WithResponse(&http.Response{
+			Status:     "400 Bad Request",
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(bytes.NewBufferString("out of order sample")),
+			Header:     make(http.Header),
+		})
+		w, err := New(cfg, req, m)
+		require.NoError(t, err)
+
+		err = w.Write(context.Background(), 1, "metric", testTime, []Sample{{Value: 1}})
+		require.ErrorContains(t, err, "status: 400")
+	})
+}
+
+func timeSeries() *data.Frame {
+	return data.NewFrame("",
+		data.NewField("Time", nil, []time.Time{testTime}),
+		data.NewField("Value", nil, []float64{1}),
+	)
+}
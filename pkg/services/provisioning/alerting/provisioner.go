@@ -18,6 +18,7 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	SilenceService             provisioning.SilenceService
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -53,6 +54,11 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
+	silProvisioner := NewSilencesProvisioner(logger, cfg.SilenceService)
+	err = silProvisioner.Provision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("silences: %w", err)
+	}
 	npProvisioner := NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService)
 	err = npProvisioner.Provision(ctx, files)
 	if err != nil {
@@ -66,6 +72,10 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
 	}
+	err = silProvisioner.Unprovision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("silences: %w", err)
+	}
 	err = mtProvisioner.Unprovision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("mute times: %w", err)
@@ -57,22 +57,95 @@ func (ruleGroupV1 *AlertRuleGroupV1) MapToModel() (models.AlertRuleGroupWithFold
 		}
 		ruleGroup.Rules = append(ruleGroup.Rules, rule)
 	}
+	rules := make(models.RulesGroup, 0, len(ruleGroup.Rules))
+	for i := range ruleGroup.Rules {
+		rules = append(rules, &ruleGroup.Rules[i])
+	}
+	if _, err := rules.SortByDependencies(); err != nil {
+		return models.AlertRuleGroupWithFolderTitle{}, fmt.Errorf("rule group '%s' failed to parse: %w", ruleGroup.Title, err)
+	}
 	return ruleGroup, nil
 }
 
 type AlertRuleV1 struct {
-	UID          values.StringValue    `json:"uid" yaml:"uid"`
-	Title        values.StringValue    `json:"title" yaml:"title"`
-	Condition    values.StringValue    `json:"condition" yaml:"condition"`
-	Data         []QueryV1             `json:"data" yaml:"data"`
-	DashboardUID values.StringValue    `json:"dasboardUid" yaml:"dashboardUid"`
-	PanelID      values.Int64Value     `json:"panelId" yaml:"panelId"`
-	NoDataState  values.StringValue    `json:"noDataState" yaml:"noDataState"`
-	ExecErrState values.StringValue    `json:"execErrState" yaml:"execErrState"`
-	For          values.StringValue    `json:"for" yaml:"for"`
-	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
-	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
-	IsPaused     values.BoolValue      `json:"isPaused" yaml:"isPaused"`
+	UID                  values.StringValue      `json:"uid" yaml:"uid"`
+	Title                values.StringValue      `json:"title" yaml:"title"`
+	Condition            values.StringValue      `json:"condition" yaml:"condition"`
+	Data                 []QueryV1               `json:"data" yaml:"data"`
+	DashboardUID         values.StringValue      `json:"dasboardUid" yaml:"dashboardUid"`
+	PanelID              values.Int64Value       `json:"panelId" yaml:"panelId"`
+	NoDataState          values.StringValue      `json:"noDataState" yaml:"noDataState"`
+	ExecErrState         values.StringValue      `json:"execErrState" yaml:"execErrState"`
+	For                  values.StringValue      `json:"for" yaml:"for"`
+	KeepFiringFor        values.StringValue      `json:"keepFiringFor" yaml:"keepFiringFor"`
+	Annotations          values.StringMapValue   `json:"annotations" yaml:"annotations"`
+	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
+	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
+	Record               *RecordV1               `json:"record" yaml:"record"`
+	Dependencies         []RuleDependencyV1      `json:"dependencies" yaml:"dependencies"`
+	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
+}
+
+type NotificationSettingsV1 struct {
+	Receiver          values.StringValue   `json:"receiver" yaml:"receiver"`
+	GroupBy           []values.StringValue `json:"group_by" yaml:"group_by"`
+	GroupWait         values.StringValue   `json:"group_wait" yaml:"group_wait"`
+	GroupInterval     values.StringValue   `json:"group_interval" yaml:"group_interval"`
+	RepeatInterval    values.StringValue   `json:"repeat_interval" yaml:"repeat_interval"`
+	MuteTimeIntervals []values.StringValue `json:"mute_time_intervals" yaml:"mute_time_intervals"`
+}
+
+func (nsV1 *NotificationSettingsV1) mapToModel() (*models.NotificationSettings, error) {
+	if nsV1.Receiver.Value() == "" {
+		return nil, fmt.Errorf("receiver must not be empty")
+	}
+	parseDuration := func(field string, value values.StringValue) (*model.Duration, error) {
+		if value.Value() == "" {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(value.Value())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", field, err)
+		}
+		return &d, nil
+	}
+	settings := &models.NotificationSettings{
+		Receiver: nsV1.Receiver.Value(),
+	}
+	var err error
+	if settings.GroupWait, err = parseDuration("group_wait", nsV1.GroupWait); err != nil {
+		return nil, err
+	}
+	if settings.GroupInterval, err = parseDuration("group_interval", nsV1.GroupInterval); err != nil {
+		return nil, err
+	}
+	if settings.RepeatInterval, err = parseDuration("repeat_interval", nsV1.RepeatInterval); err != nil {
+		return nil, err
+	}
+	for _, label := range nsV1.GroupBy {
+		settings.GroupBy = append(settings.GroupBy, label.Value())
+	}
+	for _, mt := range nsV1.MuteTimeIntervals {
+		settings.MuteTimeIntervals = append(settings.MuteTimeIntervals, mt.Value())
+	}
+	return settings, nil
+}
+
+type RuleDependencyV1 struct {
+	RuleUID values.StringValue `json:"ruleUid" yaml:"ruleUid"`
+	Equal   []string           `json:"equal" yaml:"equal"`
+}
+
+type RecordV1 struct {
+	Metric values.StringValue `json:"metric" yaml:"metric"`
+	From   values.StringValue `json:"from" yaml:"from"`
+}
+
+func (record *RecordV1) mapToModel() *models.Record {
+	return &models.Record{
+		Metric: record.Metric.Value(),
+		From:   record.From.Value(),
+	}
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -86,11 +159,24 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no UID set", alertRule.Title)
 	}
 	alertRule.OrgID = orgID
-	duration, err := model.ParseDuration(rule.For.Value())
-	if err != nil {
-		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+	if rule.Record != nil {
+		alertRule.Record = rule.Record.mapToModel()
+	}
+	// Recording rules do not have a pending period, so it can be omitted.
+	if rule.For.Value() != "" || alertRule.Record == nil {
+		duration, err := model.ParseDuration(rule.For.Value())
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.For = time.Duration(duration)
+	}
+	if rule.KeepFiringFor.Value() != "" {
+		duration, err := model.ParseDuration(rule.KeepFiringFor.Value())
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.KeepFiringFor = time.Duration(duration)
 	}
-	alertRule.For = time.Duration(duration)
 	dashboardUID := rule.DashboardUID.Value()
 	alertRule.DashboardUID = &dashboardUID
 	panelID := rule.PanelID.Value()
@@ -114,6 +200,10 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	}
 	alertRule.NoDataState = noDataState
 	alertRule.Condition = rule.Condition.Value()
+	if alertRule.Condition == "" && alertRule.Record != nil {
+		// Recording rules do not have a condition. The recorded query or expression is evaluated instead.
+		alertRule.Condition = alertRule.Record.From
+	}
 	if alertRule.Condition == "" {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no condition set", alertRule.Title)
 	}
@@ -130,6 +220,19 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no data set", alertRule.Title)
 	}
 	alertRule.IsPaused = rule.IsPaused.Value()
+	for _, dep := range rule.Dependencies {
+		alertRule.Dependencies = append(alertRule.Dependencies, models.RuleDependency{
+			RuleUID: dep.RuleUID.Value(),
+			Equal:   dep.Equal,
+		})
+	}
+	if rule.NotificationSettings != nil {
+		settings, err := rule.NotificationSettings.mapToModel()
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse notification settings: %w", alertRule.Title, err)
+		}
+		alertRule.NotificationSettings = settings
+	}
 	return alertRule, nil
 }
 
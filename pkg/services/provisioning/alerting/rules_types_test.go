@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
@@ -17,6 +18,12 @@ func TestRuleGroup(t *testing.T) {
 		_, err := rg.MapToModel()
 		require.NoError(t, err)
 	})
+	t.Run("a rule group with dependencies forming a cycle should error", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		rg.Rules = []AlertRuleV1{ruleWithDependency(t, "a", "b"), ruleWithDependency(t, "b", "a")}
+		_, err := rg.MapToModel()
+		require.ErrorIs(t, err, models.ErrRuleDependencyCycle)
+	})
 	t.Run("a rule group with out a name should error", func(t *testing.T) {
 		rg := validRuleGroupV1(t)
 		var name values.StringValue
@@ -187,6 +194,89 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, ruleMapped.NoDataState, models.NoData)
 	})
+	t.Run("a recording rule should map record and not require a condition or for duration", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Condition = values.StringValue{}
+		rule.For = values.StringValue{}
+		var record RecordV1
+		err := yaml.Unmarshal([]byte("metric: job:requests:rate5m\nfrom: A"), &record)
+		require.NoError(t, err)
+		rule.Record = &record
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, &models.Record{Metric: "job:requests:rate5m", From: "A"}, ruleMapped.Record)
+		require.Equal(t, "A", ruleMapped.Condition)
+		require.Equal(t, time.Duration(0), ruleMapped.For)
+	})
+	t.Run("a rule with dependencies should map them", func(t *testing.T) {
+		rule := ruleWithDependency(t, "a", "b")
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, []models.RuleDependency{{RuleUID: "b", Equal: []string{"instance"}}}, ruleMapped.Dependencies)
+	})
+	t.Run("a rule with keepFiringFor should map it correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("10m"), &rule.KeepFiringFor)
+		require.NoError(t, err)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 10*time.Minute, ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with invalid keepFiringFor should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("abc"), &rule.KeepFiringFor)
+		require.NoError(t, err)
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with notification settings should map them", func(t *testing.T) {
+		rule := validRuleV1(t)
+		var settings NotificationSettingsV1
+		err := yaml.Unmarshal([]byte("receiver: team-a\ngroup_by: [alertname, grafana_folder, cluster]\ngroup_wait: 30s\nrepeat_interval: 4h\nmute_time_intervals: [weekends]"), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		groupWait := model.Duration(30 * time.Second)
+		repeatInterval := model.Duration(4 * time.Hour)
+		require.Equal(t, &models.NotificationSettings{
+			Receiver:          "team-a",
+			GroupBy:           []string{"alertname", "grafana_folder", "cluster"},
+			GroupWait:         &groupWait,
+			RepeatInterval:    &repeatInterval,
+			MuteTimeIntervals: []string{"weekends"},
+		}, ruleMapped.NotificationSettings)
+	})
+	t.Run("a rule with notification settings without receiver should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		var settings NotificationSettingsV1
+		err := yaml.Unmarshal([]byte("group_wait: 30s"), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with notification settings with invalid duration should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		var settings NotificationSettingsV1
+		err := yaml.Unmarshal([]byte("receiver: team-a\ngroup_interval: abc"), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+}
+
+func ruleWithDependency(t *testing.T, uid, dependsOn string) AlertRuleV1 {
+	t.Helper()
+	rule := validRuleV1(t)
+	err := yaml.Unmarshal([]byte(uid), &rule.UID)
+	require.NoError(t, err)
+	var dep RuleDependencyV1
+	err = yaml.Unmarshal([]byte("ruleUid: "+dependsOn+"\nequal: [instance]"), &dep)
+	require.NoError(t, err)
+	rule.Dependencies = []RuleDependencyV1{dep}
+	return rule
 }
 
 func validRuleGroupV1(t *testing.T) AlertRuleGroupV1 {
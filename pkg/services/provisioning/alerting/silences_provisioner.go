@@ -0,0 +1,82 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+type SilencesProvisioner interface {
+	Provision(ctx context.Context, files []*AlertingFile) error
+	Unprovision(ctx context.Context, files []*AlertingFile) error
+}
+
+type defaultSilencesProvisioner struct {
+	logger         log.Logger
+	silenceService provisioning.SilenceService
+}
+
+func NewSilencesProvisioner(logger log.Logger,
+	silenceService provisioning.SilenceService) SilencesProvisioner {
+	return &defaultSilencesProvisioner{
+		logger:         logger,
+		silenceService: silenceService,
+	}
+}
+
+// Provision creates or updates the silence templates, and then the recurring silences that might use them.
+func (c *defaultSilencesProvisioner) Provision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, tmpl := range file.SilenceTemplates {
+			_, _, err := c.silenceService.GetSilenceTemplate(ctx, tmpl.OrgID, tmpl.UID)
+			if err == nil {
+				_, err = c.silenceService.UpdateSilenceTemplate(ctx, tmpl, models.ProvenanceFile)
+			} else if errors.Is(err, models.ErrSilenceTemplateNotFound) {
+				_, err = c.silenceService.CreateSilenceTemplate(ctx, tmpl, models.ProvenanceFile)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, file := range files {
+		for _, s := range file.RecurringSilences {
+			_, _, err := c.silenceService.GetRecurringSilence(ctx, s.OrgID, s.UID)
+			if err == nil {
+				_, err = c.silenceService.UpdateRecurringSilence(ctx, s, models.ProvenanceFile)
+			} else if errors.Is(err, models.ErrRecurringSilenceNotFound) {
+				_, err = c.silenceService.CreateRecurringSilence(ctx, s, models.ProvenanceFile)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Unprovision deletes the recurring silences, and then the silence templates they might have used.
+func (c *defaultSilencesProvisioner) Unprovision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, s := range file.DeleteRecurringSilences {
+			err := c.silenceService.DeleteRecurringSilence(ctx, s.OrgID, s.UID, models.ProvenanceFile)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, file := range files {
+		for _, tmpl := range file.DeleteSilenceTemplates {
+			err := c.silenceService.DeleteSilenceTemplate(ctx, tmpl.OrgID, tmpl.UID, models.ProvenanceFile)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+type SilenceTemplateV1 struct {
+	OrgID    values.Int64Value          `json:"orgId" yaml:"orgId"`
+	UID      values.StringValue         `json:"uid" yaml:"uid"`
+	Name     values.StringValue         `json:"name" yaml:"name"`
+	Matchers definitions.ObjectMatchers `json:"matchers" yaml:"matchers"`
+	Duration values.StringValue         `json:"duration" yaml:"duration"`
+	Comment  values.StringValue         `json:"comment" yaml:"comment"`
+}
+
+func (v1 *SilenceTemplateV1) mapToModel() (models.SilenceTemplate, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return models.SilenceTemplate{}, errors.New("silence template missing uid")
+	}
+	duration, err := parseSilenceDuration(v1.Duration.Value())
+	if err != nil {
+		return models.SilenceTemplate{}, fmt.Errorf("silence template '%s': %w", uid, err)
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return models.SilenceTemplate{
+		OrgID:    orgID,
+		UID:      uid,
+		Name:     v1.Name.Value(),
+		Matchers: silenceMatchersFromObjectMatchers(v1.Matchers),
+		Duration: duration,
+		Comment:  v1.Comment.Value(),
+	}, nil
+}
+
+type DeleteSilenceTemplateV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+func (v1 *DeleteSilenceTemplateV1) mapToModel() (DeleteSilenceTemplate, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return DeleteSilenceTemplate{}, errors.New("delete silence template missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return DeleteSilenceTemplate{
+		OrgID: orgID,
+		UID:   uid,
+	}, nil
+}
+
+type DeleteSilenceTemplate struct {
+	OrgID int64
+	UID   string
+}
+
+type RecurringSilenceV1 struct {
+	OrgID            values.Int64Value          `json:"orgId" yaml:"orgId"`
+	UID              values.StringValue         `json:"uid" yaml:"uid"`
+	Name             values.StringValue         `json:"name" yaml:"name"`
+	TemplateUID      values.StringValue         `json:"templateUid" yaml:"templateUid"`
+	Matchers         definitions.ObjectMatchers `json:"matchers" yaml:"matchers"`
+	Comment          values.StringValue         `json:"comment" yaml:"comment"`
+	Schedule         values.StringValue         `json:"schedule" yaml:"schedule"`
+	Location         values.StringValue         `json:"location" yaml:"location"`
+	Duration         values.StringValue         `json:"duration" yaml:"duration"`
+	MuteTimeInterval values.StringValue         `json:"muteTimeInterval" yaml:"muteTimeInterval"`
+}
+
+func (v1 *RecurringSilenceV1) mapToModel() (models.RecurringSilence, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return models.RecurringSilence{}, errors.New("recurring silence missing uid")
+	}
+	var duration time.Duration
+	if d := v1.Duration.Value(); d != "" {
+		var err error
+		if duration, err = parseSilenceDuration(d); err != nil {
+			return models.RecurringSilence{}, fmt.Errorf("recurring silence '%s': %w", uid, err)
+		}
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return models.RecurringSilence{
+		OrgID:            orgID,
+		UID:              uid,
+		Name:             v1.Name.Value(),
+		TemplateUID:      strings.TrimSpace(v1.TemplateUID.Value()),
+		Matchers:         silenceMatchersFromObjectMatchers(v1.Matchers),
+		Comment:          v1.Comment.Value(),
+		Schedule:         strings.TrimSpace(v1.Schedule.Value()),
+		Location:         strings.TrimSpace(v1.Location.Value()),
+		Duration:         duration,
+		MuteTimeInterval: strings.TrimSpace(v1.MuteTimeInterval.Value()),
+	}, nil
+}
+
+type DeleteRecurringSilenceV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+func (v1 *DeleteRecurringSilenceV1) mapToModel() (DeleteRecurringSilence, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return DeleteRecurringSilence{}, errors.New("delete recurring silence missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return DeleteRecurringSilence{
+		OrgID: orgID,
+		UID:   uid,
+	}, nil
+}
+
+type DeleteRecurringSilence struct {
+	OrgID int64
+	UID   string
+}
+
+func parseSilenceDuration(s string) (time.Duration, error) {
+	d, err := model.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration '%s': %w", s, err)
+	}
+	return time.Duration(d), nil
+}
+
+func silenceMatchersFromObjectMatchers(m definitions.ObjectMatchers) models.SilenceMatchers {
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(models.SilenceMatchers, 0, len(m))
+	for _, matcher := range m {
+		result = append(result, models.SilenceMatcher{Name: matcher.Name, Type: matcher.Type.String(), Value: matcher.Value})
+	}
+	return result
+}
@@ -284,6 +284,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		st, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
 	mutetimingsService := provisioning.NewMuteTimingService(&st, st, &st, ps.log)
 	templateService := provisioning.NewTemplateService(&st, st, &st, ps.log)
+	silenceService := provisioning.NewSilenceService(st, &st, st, &st, ps.log)
 	cfg := prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
 		RuleService:                *ruleService,
@@ -293,6 +294,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		NotificiationPolicyService: *notificationPolicyService,
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
+		SilenceService:             *silenceService,
 	}
 	return ps.provisionAlerting(ctx, cfg)
 }
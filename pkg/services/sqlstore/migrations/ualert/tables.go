@@ -24,6 +24,12 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("add last_applied column to alert_configuration_history", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_configuration_history"}, &migrator.Column{
 		Name: "last_applied", Type: migrator.DB_Int, Nullable: false, Default: "0",
 	}))
+
+	addAlertmanagerClusterMigrations(mg)
+
+	addSilenceTemplateMigrations(mg)
+
+	addAlertInstanceAcknowledgementMigrations(mg)
 	// End of migration log, add new migrations above this line.
 }
 
@@ -189,6 +195,11 @@ func alertInstanceMigration(mg *migrator.Migrator) {
 		migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
 			Name: "current_reason", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true,
 		}))
+
+	mg.AddMigration("add flap_transitions column to alert_instance",
+		migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+			Name: "flap_transitions", Type: migrator.DB_Text, Nullable: true,
+		}))
 }
 
 func addAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64) {
@@ -296,6 +307,43 @@ func addAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 	mg.AddMigration("fix is_paused column for alert_rule table", migrator.NewRawSQLMigration("").
 		Postgres(`ALTER TABLE alert_rule ALTER COLUMN is_paused SET DEFAULT false;
 UPDATE alert_rule SET is_paused = false;`))
+
+	mg.AddMigration("add record column to alert_rule table", migrator.NewAddColumnMigration(
+		alertRule,
+		&migrator.Column{
+			Name:     "record",
+			Type:     migrator.DB_Text,
+			Nullable: true,
+		},
+	))
+
+	mg.AddMigration("add dependencies column to alert_rule table", migrator.NewAddColumnMigration(
+		alertRule,
+		&migrator.Column{
+			Name:     "dependencies",
+			Type:     migrator.DB_Text,
+			Nullable: true,
+		},
+	))
+
+	mg.AddMigration("add keep_firing_for column to alert_rule table", migrator.NewAddColumnMigration(
+		alertRule,
+		&migrator.Column{
+			Name:     "keep_firing_for",
+			Type:     migrator.DB_BigInt,
+			Nullable: false,
+			Default:  "0",
+		},
+	))
+
+	mg.AddMigration("add notification_settings column to alert_rule table", migrator.NewAddColumnMigration(
+		alertRule,
+		&migrator.Column{
+			Name:     "notification_settings",
+			Type:     migrator.DB_Text,
+			Nullable: true,
+		},
+	))
 }
 
 func addAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -365,6 +413,43 @@ func addAlertRuleVersionMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("fix is_paused column for alert_rule_version table", migrator.NewRawSQLMigration("").
 		Postgres(`ALTER TABLE alert_rule_version ALTER COLUMN is_paused SET DEFAULT false;
 UPDATE alert_rule_version SET is_paused = false;`))
+
+	mg.AddMigration("add record column to alert_rule_version table", migrator.NewAddColumnMigration(
+		alertRuleVersion,
+		&migrator.Column{
+			Name:     "record",
+			Type:     migrator.DB_Text,
+			Nullable: true,
+		},
+	))
+
+	mg.AddMigration("add dependencies column to alert_rule_version table", migrator.NewAddColumnMigration(
+		alertRuleVersion,
+		&migrator.Column{
+			Name:     "dependencies",
+			Type:     migrator.DB_Text,
+			Nullable: true,
+		},
+	))
+
+	mg.AddMigration("add keep_firing_for column to alert_rule_version table", migrator.NewAddColumnMigration(
+		alertRuleVersion,
+		&migrator.Column{
+			Name:     "keep_firing_for",
+			Type:     migrator.DB_BigInt,
+			Nullable: false,
+			Default:  "0",
+		},
+	))
+
+	mg.AddMigration("add notification_settings column to alert_rule_version table", migrator.NewAddColumnMigration(
+		alertRuleVersion,
+		&migrator.Column{
+			Name:     "notification_settings",
+			Type:     migrator.DB_Text,
+			Nullable: true,
+		},
+	))
 }
 
 func addAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -491,6 +576,107 @@ func addAlertImageMigrations(mg *migrator.Migrator) {
 		Mysql("ALTER TABLE alert_image MODIFY url VARCHAR(2048) NOT NULL;"))
 }
 
+// addAlertmanagerClusterMigrations creates the tables that the database-backed Alertmanager cluster peer uses
+// to track the members of the cluster and to exchange the state of silences and notification logs.
+func addAlertmanagerClusterMigrations(mg *migrator.Migrator) {
+	peerTable := migrator.Table{
+		Name: "alertmanager_cluster_peer",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "heartbeat", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"name"}, Type: migrator.UniqueIndex},
+		},
+	}
+	mg.AddMigration("create alertmanager_cluster_peer table", migrator.NewAddTableMigration(peerTable))
+	mg.AddMigration("add unique index on name to alertmanager_cluster_peer table", migrator.NewAddIndexMigration(peerTable, peerTable.Indices[0]))
+
+	messageTable := migrator.Table{
+		Name: "alertmanager_cluster_message",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "peer", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "message_type", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "payload", Type: migrator.DB_LongBlob, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"created_at"}},
+			{Cols: []string{"peer", "message_type"}},
+		},
+	}
+	mg.AddMigration("create alertmanager_cluster_message table", migrator.NewAddTableMigration(messageTable))
+	mg.AddMigration("add index on created_at to alertmanager_cluster_message table", migrator.NewAddIndexMigration(messageTable, messageTable.Indices[0]))
+	mg.AddMigration("add index on peer and message_type to alertmanager_cluster_message table", migrator.NewAddIndexMigration(messageTable, messageTable.Indices[1]))
+}
+
+// addSilenceTemplateMigrations creates the tables for silence templates and recurring silences.
+func addSilenceTemplateMigrations(mg *migrator.Migrator) {
+	templateTable := migrator.Table{
+		Name: "alert_silence_template",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+	mg.AddMigration("create alert_silence_template table", migrator.NewAddTableMigration(templateTable))
+	mg.AddMigration("add unique index on org_id and uid to alert_silence_template table", migrator.NewAddIndexMigration(templateTable, templateTable.Indices[0]))
+
+	recurringTable := migrator.Table{
+		Name: "alert_recurring_silence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "template_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: true},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: true},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "schedule", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "location", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "mute_time_interval", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "materialized_until", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+	mg.AddMigration("create alert_recurring_silence table", migrator.NewAddTableMigration(recurringTable))
+	mg.AddMigration("add unique index on org_id and uid to alert_recurring_silence table", migrator.NewAddIndexMigration(recurringTable, recurringTable.Indices[0]))
+}
+
+// addAlertInstanceAcknowledgementMigrations creates the table for the acknowledgements of alert instances.
+func addAlertInstanceAcknowledgementMigrations(mg *migrator.Migrator) {
+	ackTable := migrator.Table{
+		Name: "alert_instance_acknowledgement",
+		Columns: []*migrator.Column{
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "acknowledged_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "acknowledged_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "assignee", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "comments", Type: migrator.DB_Text, Nullable: true},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		PrimaryKeys: []string{"rule_org_id", "rule_uid", "labels_hash"},
+	}
+	mg.AddMigration("create alert_instance_acknowledgement table", migrator.NewAddTableMigration(ackTable))
+}
+
 func extractAlertmanagerConfigurationHistoryMigration(mg *migrator.Migrator) {
 	// Since it's not always consistent as to what state the org ID indexes are in, just drop them all and rebuild from scratch.
 	// This is not expensive since this table is guaranteed to have a small number of rows.
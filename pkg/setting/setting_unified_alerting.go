@@ -15,12 +15,13 @@ import (
 )
 
 const (
-	alertmanagerDefaultClusterAddr        = "0.0.0.0:9094"
-	alertmanagerDefaultPeerTimeout        = 15 * time.Second
-	alertmanagerDefaultGossipInterval     = cluster.DefaultGossipInterval
-	alertmanagerDefaultPushPullInterval   = cluster.DefaultPushPullInterval
-	alertmanagerDefaultConfigPollInterval = time.Minute
-	alertmanagerRedisDefaultMaxConns      = 5
+	alertmanagerDefaultClusterAddr          = "0.0.0.0:9094"
+	alertmanagerDefaultPeerTimeout          = 15 * time.Second
+	alertmanagerDefaultGossipInterval       = cluster.DefaultGossipInterval
+	alertmanagerDefaultPushPullInterval     = cluster.DefaultPushPullInterval
+	alertmanagerDefaultConfigPollInterval   = time.Minute
+	alertmanagerRedisDefaultMaxConns        = 5
+	alertmanagerDefaultDatabasePollInterval = time.Second
 	// To start, the alertmanager needs at least one route defined.
 	// TODO: we should move this to Grafana settings and define this as the default.
 	alertmanagerDefaultConfiguration = `{
@@ -80,6 +81,9 @@ type UnifiedAlertingSettings struct {
 	HARedisPassword                string
 	HARedisDB                      int
 	HARedisMaxConns                int
+	HADatabaseEnabled              bool
+	HADatabasePeerName             string
+	HADatabasePollInterval         time.Duration
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -95,6 +99,8 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	RecordingRules                RecordingRuleSettings
+	FlapDetection                 FlapDetectionSettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	Upgrade                       UnifiedAlertingUpgradeSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -132,9 +138,50 @@ type UnifiedAlertingStateHistorySettings struct {
 	// if one of them is set.
 	LokiBasicAuthPassword string
 	LokiBasicAuthUsername string
-	MultiPrimary          string
-	MultiSecondaries      []string
-	ExternalLabels        map[string]string
+	InfluxURL             string
+	InfluxVersion         string
+	InfluxDatabase        string
+	InfluxOrganization    string
+	InfluxBucket          string
+	InfluxToken           string
+	// InfluxUsername and InfluxPassword are used for basic auth
+	// if one of them is set.
+	InfluxUsername    string
+	InfluxPassword    string
+	InfluxMeasurement string
+	MultiPrimary      string
+	MultiSecondaries  []string
+	ExternalLabels    map[string]string
+}
+
+// RecordingRuleSettings configures where the series produced by recording rules are written.
+type RecordingRuleSettings struct {
+	Enabled bool
+	// Target is either "prometheus", to write with the Prometheus remote write
+	// protocol, or "influxdb", to write InfluxDB line protocol.
+	Target  string
+	URL     string
+	Timeout time.Duration
+	// BasicAuthUsername and BasicAuthPassword are used for basic auth
+	// if one of them is set.
+	BasicAuthUsername  string
+	BasicAuthPassword  string
+	InfluxVersion      string
+	InfluxDatabase     string
+	InfluxOrganization string
+	InfluxBucket       string
+	InfluxToken        string
+	ExternalLabels     map[string]string
+}
+
+// FlapDetectionSettings configures the detection of alert instances that
+// change between firing and not firing too often.
+type FlapDetectionSettings struct {
+	Enabled bool
+	// Window is the period of time over which the transitions of an instance are counted.
+	Window time.Duration
+	// Transitions is the number of transitions within the window above which an instance is flapping.
+	Transitions int
 }
 
 type UnifiedAlertingUpgradeSettings struct {
@@ -259,6 +306,15 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisPassword = ua.Key("ha_redis_password").MustString("")
 	uaCfg.HARedisDB = ua.Key("ha_redis_db").MustInt(0)
 	uaCfg.HARedisMaxConns = ua.Key("ha_redis_max_conns").MustInt(alertmanagerRedisDefaultMaxConns)
+	uaCfg.HADatabaseEnabled = ua.Key("ha_database_enabled").MustBool(false)
+	uaCfg.HADatabasePeerName = ua.Key("ha_database_peer_name").MustString("")
+	uaCfg.HADatabasePollInterval, err = gtime.ParseDuration(valueAsString(ua, "ha_database_poll_interval", (alertmanagerDefaultDatabasePollInterval).String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.HADatabasePollInterval <= 0 {
+		return fmt.Errorf("value of setting 'ha_database_poll_interval' should be greater than 0")
+	}
 	peers := ua.Key("ha_peers").MustString("")
 	uaCfg.HAPeers = make([]string, 0)
 	if peers != "" {
@@ -389,12 +445,53 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		LokiTenantID:          stateHistory.Key("loki_tenant_id").MustString(""),
 		LokiBasicAuthUsername: stateHistory.Key("loki_basic_auth_username").MustString(""),
 		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
+		InfluxURL:             stateHistory.Key("influxdb_url").MustString(""),
+		InfluxVersion:         stateHistory.Key("influxdb_version").MustString("2"),
+		InfluxDatabase:        stateHistory.Key("influxdb_database").MustString(""),
+		InfluxOrganization:    stateHistory.Key("influxdb_organization").MustString(""),
+		InfluxBucket:          stateHistory.Key("influxdb_bucket").MustString(""),
+		InfluxToken:           stateHistory.Key("influxdb_token").MustString(""),
+		InfluxUsername:        stateHistory.Key("influxdb_username").MustString(""),
+		InfluxPassword:        stateHistory.Key("influxdb_password").MustString(""),
+		InfluxMeasurement:     stateHistory.Key("influxdb_measurement").MustString("grafana_alert_state_history"),
 		MultiPrimary:          stateHistory.Key("primary").MustString(""),
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	recordingRulesLabels := iniFile.Section("unified_alerting.recording_rules.external_labels")
+	uaCfg.RecordingRules = RecordingRuleSettings{
+		Enabled:            recordingRules.Key("enabled").MustBool(false),
+		Target:             recordingRules.Key("target").MustString("prometheus"),
+		URL:                recordingRules.Key("url").MustString(""),
+		Timeout:            recordingRules.Key("timeout").MustDuration(30 * time.Second),
+		BasicAuthUsername:  recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword:  recordingRules.Key("basic_auth_password").MustString(""),
+		InfluxVersion:      recordingRules.Key("influxdb_version").MustString("2"),
+		InfluxDatabase:     recordingRules.Key("influxdb_database").MustString(""),
+		InfluxOrganization: recordingRules.Key("influxdb_organization").MustString(""),
+		InfluxBucket:       recordingRules.Key("influxdb_bucket").MustString(""),
+		InfluxToken:        recordingRules.Key("influxdb_token").MustString(""),
+		ExternalLabels:     recordingRulesLabels.KeysHash(),
+	}
+
+	flapDetection := iniFile.Section("unified_alerting.flap_detection")
+	uaCfg.FlapDetection = FlapDetectionSettings{
+		Enabled:     flapDetection.Key("enabled").MustBool(false),
+		Window:      flapDetection.Key("window").MustDuration(time.Hour),
+		Transitions: flapDetection.Key("transitions").MustInt(6),
+	}
+	if uaCfg.FlapDetection.Enabled {
+		if uaCfg.FlapDetection.Window <= 0 {
+			return fmt.Errorf("the value of setting 'window' in section 'unified_alerting.flap_detection' should be positive")
+		}
+		if uaCfg.FlapDetection.Transitions < 2 {
+			return fmt.Errorf("the value of setting 'transitions' in section 'unified_alerting.flap_detection' should be at least 2")
+		}
+	}
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
 	upgrade := iniFile.Section("unified_alerting.upgrade")
@@ -68,8 +68,16 @@ func (f *Framer) Frames() (data.Frames, error) {
 		}
 		// Enter corresponding label values from exemplar fields
 		for _, bf := range b.Fields {
-			if _, exists := exemplarLabels[bf.Name]; exists {
-				exemplarLabels[bf.Name] = bf.CopyAt(b.RowIdx).(string)
+			if _, exists := exemplarLabels[bf.Name]; !exists || b.RowIdx >= bf.Len() {
+				continue
+			}
+			switch v := bf.At(b.RowIdx).(type) {
+			case string:
+				exemplarLabels[bf.Name] = v
+			case *string:
+				if v != nil {
+					exemplarLabels[bf.Name] = *v
+				}
 			}
 		}
 
@@ -1,6 +1,7 @@
 package exemplar
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
@@ -14,6 +15,25 @@ type Sampler interface {
 	Reset()
 }
 
+// Names of the samplers which can be selected in the datasource settings.
+const (
+	SamplerNone = "none"
+	SamplerStep = "step"
+)
+
+// NewSampler returns the sampler with the given name. The no-op sampler,
+// which keeps every exemplar, is used when no name is given.
+func NewSampler(name string) (Sampler, error) {
+	switch name {
+	case "", SamplerNone:
+		return NewNoOpSampler(), nil
+	case SamplerStep:
+		return NewStepSampler(), nil
+	default:
+		return nil, fmt.Errorf("unknown exemplar sampler: %s", name)
+	}
+}
+
 var _ Sampler = (*NoOpSampler)(nil)
 
 type NoOpSampler struct {
@@ -0,0 +1,122 @@
+package exemplar
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+var _ Sampler = (*StepSampler)(nil)
+
+// StepSampler keeps one exemplar per step and series, the one with the
+// highest value, along with the exemplars of the step which stand out from
+// it by more than two standard deviations of the values of the series.
+type StepSampler struct {
+	step   time.Duration
+	series map[string]*stepSeries
+}
+
+type stepSeries struct {
+	buckets map[int64][]models.Exemplar
+	count   int
+	mean    float64
+	m2      float64
+}
+
+func NewStepSampler() Sampler {
+	return &StepSampler{
+		series: map[string]*stepSeries{},
+	}
+}
+
+func (e *StepSampler) SetStep(step time.Duration) {
+	e.step = step
+}
+
+func (e *StepSampler) Add(ex models.Exemplar) {
+	key := data.Labels(ex.SeriesLabels).String()
+	s, ok := e.series[key]
+	if !ok {
+		s = &stepSeries{buckets: map[int64][]models.Exemplar{}}
+		e.series[key] = s
+	}
+
+	bucket := ex.Timestamp.UnixNano()
+	if e.step > 0 {
+		bucket -= bucket % int64(e.step)
+	}
+	s.buckets[bucket] = append(s.buckets[bucket], ex)
+	s.updateAggregations(ex.Value)
+}
+
+// updateAggregations uses Welford's online algorithm for calculating the mean and variance
+// https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Welford's_online_algorithm
+func (s *stepSeries) updateAggregations(val float64) {
+	s.count++
+	delta := val - s.mean
+	s.mean += delta / float64(s.count)
+	delta2 := val - s.mean
+	s.m2 += delta * delta2
+}
+
+func (s *stepSeries) standardDeviation() float64 {
+	if s.count < 2 {
+		return 0
+	}
+	return math.Sqrt(s.m2 / float64(s.count-1))
+}
+
+func (e *StepSampler) Sample() []models.Exemplar {
+	// series and buckets are visited in order for exemplars with the same
+	// timestamp to keep their order across calls
+	keys := make([]string, 0, len(e.series))
+	for k := range e.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	exemplars := []models.Exemplar{}
+	for _, k := range keys {
+		s := e.series[k]
+		stdDev := s.standardDeviation()
+		buckets := make([]int64, 0, len(s.buckets))
+		for bucket := range s.buckets {
+			buckets = append(buckets, bucket)
+		}
+		sort.Slice(buckets, func(i, j int) bool {
+			return buckets[i] < buckets[j]
+		})
+		for _, bucket := range buckets {
+			b := s.buckets[bucket]
+			// sort by value in descending order
+			sort.SliceStable(b, func(i, j int) bool {
+				return b[i].Value > b[j].Value
+			})
+			prev := b[0]
+			exemplars = append(exemplars, prev)
+			if stdDev == 0 {
+				continue
+			}
+			for _, ex := range b[1:] {
+				// only sample values at least 2 standard deviations away from the previously taken value
+				if prev.Value-ex.Value > stdDev*2 {
+					exemplars = append(exemplars, ex)
+					prev = ex
+				}
+			}
+		}
+	}
+	sort.SliceStable(exemplars, func(i, j int) bool {
+		return exemplars[i].Timestamp.Before(exemplars[j].Timestamp)
+	})
+	return exemplars
+}
+
+func (e *StepSampler) Reset() {
+	e.step = 0
+	e.series = map[string]*stepSeries{}
+}
@@ -0,0 +1,71 @@
+package exemplar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/exemplar"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+func TestStepSampler(t *testing.T) {
+	t.Run("keeps one exemplar per step and series", func(t *testing.T) {
+		sampler := exemplar.NewStepSampler()
+		sampler.SetStep(10 * time.Second)
+		for i := 0; i < 30; i++ {
+			for _, host := range []string{"a", "b"} {
+				sampler.Add(models.Exemplar{
+					SeriesLabels: map[string]string{"host": host},
+					Timestamp:    time.Unix(int64(i), 0),
+					Value:        5,
+				})
+			}
+		}
+
+		sampled := sampler.Sample()
+		require.Len(t, sampled, 6)
+		for i, ex := range sampled {
+			require.Equal(t, time.Unix(int64(i/2*10), 0), ex.Timestamp)
+		}
+		require.Equal(t, "a", sampled[0].SeriesLabels["host"])
+		require.Equal(t, "b", sampled[1].SeriesLabels["host"])
+	})
+
+	t.Run("keeps outliers", func(t *testing.T) {
+		sampler := exemplar.NewStepSampler()
+		sampler.SetStep(time.Minute)
+		values := []float64{1, 2, 1, 2, 1, 2, 1, 100}
+		for i, v := range values {
+			sampler.Add(models.Exemplar{Timestamp: time.Unix(int64(i), 0), Value: v})
+		}
+		sampler.Add(models.Exemplar{Timestamp: time.Unix(120, 0), Value: 1})
+
+		sampled := sampler.Sample()
+		require.Len(t, sampled, 3)
+		require.Equal(t, 2.0, sampled[0].Value)
+		require.Equal(t, 100.0, sampled[1].Value)
+		require.Equal(t, 1.0, sampled[2].Value)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		sampler := exemplar.NewStepSampler()
+		sampler.Add(models.Exemplar{Timestamp: time.Unix(0, 0), Value: 1})
+		sampler.Reset()
+		require.Empty(t, sampler.Sample())
+	})
+}
+
+func TestNewSampler(t *testing.T) {
+	sampler, err := exemplar.NewSampler("")
+	require.NoError(t, err)
+	require.IsType(t, &exemplar.NoOpSampler{}, sampler)
+
+	sampler, err = exemplar.NewSampler(exemplar.SamplerStep)
+	require.NoError(t, err)
+	require.IsType(t, &exemplar.StepSampler{}, sampler)
+
+	_, err = exemplar.NewSampler("random")
+	require.Error(t, err)
+}
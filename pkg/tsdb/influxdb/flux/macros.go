@@ -30,8 +30,8 @@ func interpolateFluxSpecificVariables(query queryModel) string {
 	matches := fluxVariableFilterExp.FindAllStringSubmatch(flux, -1)
 	if matches != nil {
 		timeRange := query.TimeRange
-		from := timeRange.From.UTC().Format(time.RFC3339Nano)
-		to := timeRange.To.UTC().Format(time.RFC3339Nano)
+		from := fluxTime(timeRange.From)
+		to := fluxTime(timeRange.To)
 		for _, match := range matches {
 			switch match[2] {
 			case "timeRangeStart":
@@ -52,7 +52,45 @@ func interpolateFluxSpecificVariables(query queryModel) string {
 	return flux
 }
 
+// fluxTime formats t as a Flux time literal.
+func fluxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// fluxDuration formats d as a Flux duration literal, which only allows
+// integer magnitudes: 1.5s is written as 1s500ms.
+func fluxDuration(d time.Duration) string {
+	if d <= 0 {
+		return "0s"
+	}
+	units := []struct {
+		unit string
+		size time.Duration
+	}{
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+		{"ms", time.Millisecond},
+		{"us", time.Microsecond},
+		{"ns", time.Nanosecond},
+	}
+	var b strings.Builder
+	for _, u := range units {
+		if n := d / u.size; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10))
+			b.WriteString(u.unit)
+			d -= n * u.size
+		}
+	}
+	return b.String()
+}
+
+// interpolate returns the script of query with its variables replaced. The
+// scripts compiled from the query builder already hold the values.
 func interpolate(query queryModel) string {
+	if query.EditorMode == editorModeBuilder {
+		return query.RawQuery
+	}
 	flux := interpolateFluxSpecificVariables(query)
 	flux = interpolateInterval(flux, query.Interval)
 	return flux
@@ -0,0 +1,285 @@
+package flux
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const editorModeBuilder = "builder"
+
+var (
+	regexpRegexValue    = regexp.MustCompile(`^\/.*\/$`)
+	regexpFluxDuration  = regexp.MustCompile(`^(\d+(ns|us|µs|ms|s|mo|m|h|d|w|y))+$`)
+	fluxStringEscaper   = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `${`, `\${`)
+	fluxAggregates      map[string]aggregateDefinition
+	builderTagOperators = map[string]string{
+		"=":  "==",
+		"!=": "!=",
+		"<":  "<",
+		">":  ">",
+		"=~": "=~",
+		"!~": "!~",
+	}
+)
+
+type aggregateDefinition struct {
+	// Renderer returns the fn argument of aggregateWindow.
+	Renderer func(agg *builderAggregate) (string, error)
+}
+
+func init() {
+	fluxAggregates = map[string]aggregateDefinition{}
+	for _, fn := range []string{"mean", "median", "sum", "count", "min", "max", "first", "last", "spread", "stddev", "mode"} {
+		fluxAggregates[fn] = aggregateDefinition{Renderer: functionAggregateRenderer}
+	}
+	fluxAggregates["distinct"] = aggregateDefinition{Renderer: distinctAggregateRenderer}
+	fluxAggregates["percentile"] = aggregateDefinition{Renderer: percentileAggregateRenderer}
+}
+
+func functionAggregateRenderer(agg *builderAggregate) (string, error) {
+	return agg.Function, nil
+}
+
+// distinct is not a valid aggregateWindow function, counting the distinct
+// values is what InfluxQL's count(distinct()) does.
+func distinctAggregateRenderer(_ *builderAggregate) (string, error) {
+	return `(column, tables=<-) => tables |> distinct(column: column) |> count(column: "_value")`, nil
+}
+
+func percentileAggregateRenderer(agg *builderAggregate) (string, error) {
+	if len(agg.Params) != 1 {
+		return "", fmt.Errorf("percentile expects 1 parameter, received %d", len(agg.Params))
+	}
+	nth, err := strconv.ParseFloat(agg.Params[0], 64)
+	if err != nil || nth < 0 || nth > 100 {
+		return "", fmt.Errorf("invalid percentile: %s", agg.Params[0])
+	}
+	return fmt.Sprintf("(column, tables=<-) => tables |> quantile(q: %s, column: column)", floatLiteral(nth/100)), nil
+}
+
+// builderQuery is the structured query of the visual query editor, it is
+// compiled into Flux on the backend.
+type builderQuery struct {
+	// Bucket defaults to the bucket of the query options.
+	Bucket      string            `json:"bucket"`
+	Measurement string            `json:"measurement"`
+	Fields      []string          `json:"fields"`
+	Tags        []builderTag      `json:"tags"`
+	Aggregate   *builderAggregate `json:"aggregate"`
+	GroupBy     []string          `json:"groupBy"`
+	// Fill is one of null (the default), none, previous, linear or a number,
+	// as in InfluxQL.
+	Fill string `json:"fill"`
+}
+
+type builderTag struct {
+	Key       string `json:"key"`
+	Operator  string `json:"operator"`
+	Value     string `json:"value"`
+	Condition string `json:"condition"`
+}
+
+type builderAggregate struct {
+	Function string   `json:"function"`
+	Params   []string `json:"params"`
+	// Every is the window duration, the query interval when empty.
+	Every string `json:"every"`
+}
+
+// compile returns the Flux script of the query. The time range and the interval
+// are written as literals, the script is not interpolated like raw queries
+// since the quoted user values could match the variables.
+func (q *builderQuery) compile(options queryOptions, timeRange backend.TimeRange, interval time.Duration) (string, error) {
+	bucket := q.Bucket
+	if bucket == "" {
+		bucket = options.Bucket
+	}
+	if bucket == "" {
+		return "", fmt.Errorf("query builder: missing bucket")
+	}
+
+	var imports []string
+	var b strings.Builder
+	fmt.Fprintf(&b, "from(bucket: %s)\n", StringLiteral(bucket))
+	fmt.Fprintf(&b, "  |> range(start: %s, stop: %s)\n", fluxTime(timeRange.From), fluxTime(timeRange.To))
+
+	if q.Measurement != "" {
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => r._measurement == %s)\n", StringLiteral(q.Measurement))
+	}
+	if len(q.Fields) > 0 {
+		conditions := make([]string, 0, len(q.Fields))
+		for _, field := range q.Fields {
+			conditions = append(conditions, "r._field == "+StringLiteral(field))
+		}
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => %s)\n", strings.Join(conditions, " or "))
+	}
+	if len(q.Tags) > 0 {
+		predicate, err := q.renderTags()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "  |> filter(fn: (r) => %s)\n", predicate)
+	}
+
+	if q.Aggregate != nil || len(q.GroupBy) > 0 {
+		columns := []string{StringLiteral("_measurement"), StringLiteral("_field")}
+		for _, key := range q.GroupBy {
+			columns = append(columns, StringLiteral(key))
+		}
+		fmt.Fprintf(&b, "  |> group(columns: [%s])\n", strings.Join(columns, ", "))
+	}
+
+	if q.Aggregate != nil {
+		aggregate, fill, fillImports, err := q.renderAggregate(interval)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(aggregate)
+		b.WriteString(fill)
+		imports = append(imports, fillImports...)
+	}
+
+	var script strings.Builder
+	for _, imp := range imports {
+		fmt.Fprintf(&script, "import %s\n", StringLiteral(imp))
+	}
+	if len(imports) > 0 {
+		script.WriteString("\n")
+	}
+	script.WriteString(strings.TrimSuffix(b.String(), "\n"))
+	return script.String(), nil
+}
+
+func (q *builderQuery) renderTags() (string, error) {
+	var predicate strings.Builder
+	for i, tag := range q.Tags {
+		if i > 0 {
+			switch strings.ToUpper(tag.Condition) {
+			case "", "AND":
+				predicate.WriteString(" and ")
+			case "OR":
+				predicate.WriteString(" or ")
+			default:
+				return "", fmt.Errorf("query builder: invalid tag condition %q", tag.Condition)
+			}
+		}
+
+		operator := tag.Operator
+		// If the operator is missing we fall back to sensible defaults
+		if operator == "" {
+			if regexpRegexValue.MatchString(tag.Value) {
+				operator = "=~"
+			} else {
+				operator = "="
+			}
+		}
+		fluxOperator, ok := builderTagOperators[operator]
+		if !ok {
+			return "", fmt.Errorf("query builder: invalid tag operator %q", tag.Operator)
+		}
+
+		var value string
+		if operator == "=~" || operator == "!~" {
+			re := tag.Value
+			if regexpRegexValue.MatchString(re) {
+				re = re[1 : len(re)-1]
+			}
+			if _, err := regexp.Compile(re); err != nil {
+				return "", fmt.Errorf("query builder: invalid regex for tag %s: %w", tag.Key, err)
+			}
+			value = "/" + escapeFluxRegex(re) + "/"
+		} else {
+			value = StringLiteral(tag.Value)
+		}
+
+		fmt.Fprintf(&predicate, "r[%s] %s %s", StringLiteral(tag.Key), fluxOperator, value)
+	}
+	return predicate.String(), nil
+}
+
+// renderAggregate returns the aggregateWindow call, the calls filling the
+// empty windows and the packages they need.
+func (q *builderQuery) renderAggregate(interval time.Duration) (string, string, []string, error) {
+	def, ok := fluxAggregates[q.Aggregate.Function]
+	if !ok {
+		return "", "", nil, fmt.Errorf("query builder: unsupported aggregate function %q", q.Aggregate.Function)
+	}
+	fn, err := def.Renderer(q.Aggregate)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("query builder: %w", err)
+	}
+
+	every := q.Aggregate.Every
+	switch {
+	case every == "" || every == "auto" || every == "$__interval":
+		every = fluxDuration(interval)
+	case regexpFluxDuration.MatchString(every):
+	default:
+		return "", "", nil, fmt.Errorf("query builder: invalid aggregate window %q", every)
+	}
+
+	createEmpty := true
+	var fill string
+	var imports []string
+	switch q.Fill {
+	case "", "null":
+	case "none":
+		createEmpty = false
+	case "previous":
+		fill = "  |> fill(usePrevious: true)\n"
+	case "linear":
+		createEmpty = false
+		fill = fmt.Sprintf("  |> interpolate.linear(every: %s)\n", every)
+		imports = append(imports, "interpolate")
+	default:
+		value, err := strconv.ParseFloat(q.Fill, 64)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("query builder: invalid fill %q", q.Fill)
+		}
+		fill = fmt.Sprintf("  |> fill(value: %s)\n", floatLiteral(value))
+	}
+
+	aggregate := fmt.Sprintf("  |> aggregateWindow(every: %s, fn: %s, createEmpty: %t)\n", every, fn, createEmpty)
+	return aggregate, fill, imports, nil
+}
+
+// escapeFluxRegex escapes the slashes of re which are not escaped yet, so that
+// it can be written as a Flux regex literal.
+func escapeFluxRegex(re string) string {
+	var b strings.Builder
+	for i := 0; i < len(re); i++ {
+		switch re[i] {
+		case '\\':
+			b.WriteByte(re[i])
+			if i+1 < len(re) {
+				i++
+				b.WriteByte(re[i])
+			}
+		case '/':
+			b.WriteString(`\/`)
+		default:
+			b.WriteByte(re[i])
+		}
+	}
+	return b.String()
+}
+
+// StringLiteral quotes s as a Flux string literal.
+func StringLiteral(s string) string {
+	return `"` + fluxStringEscaper.Replace(s) + `"`
+}
+
+// floatLiteral formats v as a float literal, in Flux 1 is an integer and can
+// not be used for float columns.
+func floatLiteral(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package flux
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+func TestBuilderQueryCompile(t *testing.T) {
+	options := queryOptions{Bucket: "telegraf"}
+	timeRange := backend.TimeRange{
+		From: time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC),
+		To:   time.Date(2023, 5, 1, 11, 0, 0, 0, time.UTC),
+	}
+	interval := 30 * time.Second
+
+	t.Run("raw values", func(t *testing.T) {
+		q := &builderQuery{
+			Measurement: "cpu",
+			Fields:      []string{"usage_user", "usage_system"},
+			Tags: []builderTag{
+				{Key: "host", Value: "server-1"},
+				{Key: "cpu", Operator: "=~", Value: "/cpu[0-9]/", Condition: "AND"},
+				{Key: "region", Operator: "!=", Value: `eu"west`, Condition: "OR"},
+			},
+		}
+		script, err := q.compile(options, timeRange, interval)
+		require.NoError(t, err)
+		require.Equal(t, `from(bucket: "telegraf")
+  |> range(start: 2023-05-01T10:00:00Z, stop: 2023-05-01T11:00:00Z)
+  |> filter(fn: (r) => r._measurement == "cpu")
+  |> filter(fn: (r) => r._field == "usage_user" or r._field == "usage_system")
+  |> filter(fn: (r) => r["host"] == "server-1" and r["cpu"] =~ /cpu[0-9]/ or r["region"] != "eu\"west")`, script)
+	})
+
+	t.Run("aggregate with group by and fill", func(t *testing.T) {
+		q := &builderQuery{
+			Bucket:      "metrics",
+			Measurement: "cpu",
+			Fields:      []string{"usage_user"},
+			Aggregate:   &builderAggregate{Function: "mean"},
+			GroupBy:     []string{"host"},
+			Fill:        "previous",
+		}
+		script, err := q.compile(options, timeRange, interval)
+		require.NoError(t, err)
+		require.Equal(t, `from(bucket: "metrics")
+  |> range(start: 2023-05-01T10:00:00Z, stop: 2023-05-01T11:00:00Z)
+  |> filter(fn: (r) => r._measurement == "cpu")
+  |> filter(fn: (r) => r._field == "usage_user")
+  |> group(columns: ["_measurement", "_field", "host"])
+  |> aggregateWindow(every: 30s, fn: mean, createEmpty: true)
+  |> fill(usePrevious: true)`, script)
+	})
+
+	t.Run("percentile with linear fill", func(t *testing.T) {
+		q := &builderQuery{
+			Measurement: "http",
+			Aggregate:   &builderAggregate{Function: "percentile", Params: []string{"95"}, Every: "5m"},
+			Fill:        "linear",
+		}
+		script, err := q.compile(options, timeRange, interval)
+		require.NoError(t, err)
+		require.Equal(t, `import "interpolate"
+
+from(bucket: "telegraf")
+  |> range(start: 2023-05-01T10:00:00Z, stop: 2023-05-01T11:00:00Z)
+  |> filter(fn: (r) => r._measurement == "http")
+  |> group(columns: ["_measurement", "_field"])
+  |> aggregateWindow(every: 5m, fn: (column, tables=<-) => tables |> quantile(q: 0.95, column: column), createEmpty: false)
+  |> interpolate.linear(every: 5m)`, script)
+	})
+
+	t.Run("fill with value", func(t *testing.T) {
+		q := &builderQuery{Aggregate: &builderAggregate{Function: "sum"}, Fill: "0"}
+		script, err := q.compile(options, timeRange, interval)
+		require.NoError(t, err)
+		require.Contains(t, script, "  |> fill(value: 0.0)")
+	})
+
+	t.Run("fractional intervals are valid Flux durations", func(t *testing.T) {
+		for _, every := range []string{"auto", "$__interval"} {
+			q := &builderQuery{Measurement: "cpu", Aggregate: &builderAggregate{Function: "mean", Every: every}}
+			script, err := q.compile(options, timeRange, 90*time.Second+500*time.Millisecond)
+			require.NoError(t, err)
+			require.Contains(t, script, "aggregateWindow(every: 1m30s500ms,", every)
+		}
+	})
+
+	t.Run("user values are not interpolated", func(t *testing.T) {
+		q := &builderQuery{
+			Measurement: "v.bucket",
+			Fields:      []string{"$__interval"},
+			Tags:        []builderTag{{Key: "path", Operator: "=~", Value: `/^\/var\/log/tmp/`}},
+		}
+		script, err := q.compile(options, timeRange, interval)
+		require.NoError(t, err)
+		qm := queryModel{RawQuery: script, EditorMode: editorModeBuilder, Options: options, Interval: interval}
+		require.Equal(t, `from(bucket: "telegraf")
+  |> range(start: 2023-05-01T10:00:00Z, stop: 2023-05-01T11:00:00Z)
+  |> filter(fn: (r) => r._measurement == "v.bucket")
+  |> filter(fn: (r) => r._field == "$__interval")
+  |> filter(fn: (r) => r["path"] =~ /^\/var\/log\/tmp/)`, interpolate(qm))
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		invalid := []*builderQuery{
+			{Aggregate: &builderAggregate{Function: "holt_winters"}},
+			{Aggregate: &builderAggregate{Function: "percentile"}},
+			{Aggregate: &builderAggregate{Function: "mean", Every: "5 minutes"}},
+			{Aggregate: &builderAggregate{Function: "mean"}, Fill: "zero"},
+			{Tags: []builderTag{{Key: "host", Operator: "LIKE", Value: "a"}}},
+			{Tags: []builderTag{{Key: "host", Operator: "=~", Value: "/(/"}}},
+		}
+		for _, q := range invalid {
+			_, err := q.compile(options, timeRange, interval)
+			require.Error(t, err)
+		}
+
+		_, err := (&builderQuery{}).compile(queryOptions{}, timeRange, interval)
+		require.Error(t, err)
+	})
+}
+
+func TestGetQueryModelBuilder(t *testing.T) {
+	query := backend.DataQuery{
+		JSON: []byte(`{
+			"editorMode": "builder",
+			"builder": {"measurement": "cpu", "aggregate": {"function": "max", "every": "$__interval"}}
+		}`),
+		Interval: time.Minute,
+	}
+	qm, err := getQueryModel(query, backend.TimeRange{}, &models.DatasourceInfo{DefaultBucket: "telegraf"})
+	require.NoError(t, err)
+	require.Contains(t, qm.RawQuery, `from(bucket: "telegraf")`)
+	require.Contains(t, interpolate(*qm), "aggregateWindow(every: 1m, fn: max, createEmpty: true)")
+
+	query.JSON = []byte(`{"editorMode": "builder"}`)
+	_, err = getQueryModel(query, backend.TimeRange{}, &models.DatasourceInfo{DefaultBucket: "telegraf"})
+	require.Error(t, err)
+}
@@ -22,6 +22,10 @@ type queryModel struct {
 	RawQuery string       `json:"query"`
 	Options  queryOptions `json:"options"`
 
+	// The query is compiled from Builder in builder mode
+	EditorMode string        `json:"editorMode"`
+	Builder    *builderQuery `json:"builder"`
+
 	// Not from JSON
 	TimeRange     backend.TimeRange `json:"-"`
 	MaxDataPoints int64             `json:"-"`
@@ -43,7 +47,6 @@ func getQueryModel(query backend.DataQuery, timeRange backend.TimeRange,
 	if model.Options.Organization == "" {
 		model.Options.Organization = dsInfo.Organization
 	}
-
 	// Copy directly from the well typed query
 	model.TimeRange = timeRange
 	model.MaxDataPoints = query.MaxDataPoints
@@ -54,5 +57,16 @@ func getQueryModel(query backend.DataQuery, timeRange backend.TimeRange,
 	if model.Interval.Milliseconds() == 0 {
 		model.Interval = time.Millisecond // 1ms
 	}
+
+	if model.EditorMode == editorModeBuilder {
+		if model.Builder == nil {
+			return nil, fmt.Errorf("error reading query: missing query builder model")
+		}
+		rawQuery, err := model.Builder.compile(model.Options, model.TimeRange, model.Interval)
+		if err != nil {
+			return nil, err
+		}
+		model.RawQuery = rawQuery
+	}
 	return model, nil
 }
@@ -10,6 +10,8 @@ import (
 
 	"github.com/apache/arrow/go/v13/arrow"
 	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/decimal128"
+	"github.com/apache/arrow/go/v13/arrow/decimal256"
 	"github.com/apache/arrow/go/v13/arrow/scalar"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -22,6 +24,9 @@ import (
 // let users hit that for now until we decide how to proceed.
 const rowLimit = 1_000_000
 
+// timeOfDayLayout formats Arrow time of day values.
+const timeOfDayLayout = "15:04:05.999999999"
+
 type recordReader interface {
 	Next() bool
 	Schema() *arrow.Schema
@@ -123,7 +128,7 @@ func newFrame(schema *arrow.Schema) *data.Frame {
 
 func newField(f arrow.Field) *data.Field {
 	switch f.Type.ID() {
-	case arrow.STRING:
+	case arrow.STRING, arrow.LARGE_STRING:
 		return newDataField[string](f)
 	case arrow.FLOAT32:
 		return newDataField[float32](f)
@@ -151,7 +156,23 @@ func newField(f arrow.Field) *data.Field {
 		return newDataField[time.Time](f)
 	case arrow.DURATION:
 		return newDataField[int64](f)
+	case arrow.DECIMAL128, arrow.DECIMAL256:
+		return newDataField[float64](f)
+	case arrow.DATE32, arrow.DATE64:
+		return newDataField[time.Time](f)
+	case arrow.TIME32, arrow.TIME64:
+		// time of day, without date
+		return newDataField[string](f)
+	case arrow.DICTIONARY:
+		// dictionary encoded columns hold the values of the dictionary
+		return newField(arrow.Field{
+			Name:     f.Name,
+			Type:     f.Type.(*arrow.DictionaryType).ValueType,
+			Nullable: f.Nullable,
+			Metadata: f.Metadata,
+		})
 	default:
+		// intervals, lists, structs and maps are sent as JSON
 		return newDataField[json.RawMessage](f)
 	}
 }
@@ -223,6 +244,8 @@ func copyData(field *data.Field, col arrow.Array) error {
 		}
 	case arrow.STRING:
 		copyBasic[string](field, array.NewStringData(colData))
+	case arrow.LARGE_STRING:
+		copyBasic[string](field, array.NewLargeStringData(colData))
 	case arrow.UINT8:
 		copyBasic[uint8](field, array.NewUint8Data(colData))
 	case arrow.UINT16:
@@ -247,8 +270,37 @@ func copyData(field *data.Field, col arrow.Array) error {
 		copyBasic[bool](field, array.NewBooleanData(colData))
 	case arrow.DURATION:
 		copyBasic[int64](field, array.NewInt64Data(colData))
+	case arrow.DECIMAL128:
+		scale := col.DataType().(*arrow.Decimal128Type).Scale
+		copyConverted(field, array.NewDecimal128Data(colData), func(n decimal128.Num) float64 {
+			return n.ToFloat64(scale)
+		})
+	case arrow.DECIMAL256:
+		scale := col.DataType().(*arrow.Decimal256Type).Scale
+		copyConverted(field, array.NewDecimal256Data(colData), func(n decimal256.Num) float64 {
+			return n.ToFloat64(scale)
+		})
+	case arrow.DATE32:
+		copyConverted(field, array.NewDate32Data(colData), arrow.Date32.ToTime)
+	case arrow.DATE64:
+		copyConverted(field, array.NewDate64Data(colData), arrow.Date64.ToTime)
+	case arrow.TIME32:
+		unit := col.DataType().(*arrow.Time32Type).Unit
+		copyConverted(field, array.NewTime32Data(colData), func(t arrow.Time32) string {
+			return t.ToTime(unit).Format(timeOfDayLayout)
+		})
+	case arrow.TIME64:
+		unit := col.DataType().(*arrow.Time64Type).Unit
+		copyConverted(field, array.NewTime64Data(colData), func(t arrow.Time64) string {
+			return t.ToTime(unit).Format(timeOfDayLayout)
+		})
+	case arrow.DICTIONARY:
+		return copyDictionary(field, array.NewDictionaryData(colData))
+	case arrow.INTERVAL_MONTHS, arrow.INTERVAL_DAY_TIME, arrow.INTERVAL_MONTH_DAY_NANO,
+		arrow.LIST, arrow.LARGE_LIST, arrow.FIXED_SIZE_LIST, arrow.STRUCT, arrow.MAP:
+		return copyJSON(field, col)
 	default:
-		fmt.Printf("datatype %s is unhandled", col.DataType().ID())
+		glog.Warn("Unhandled Arrow data type", "type", col.DataType().ID())
 	}
 
 	return nil
@@ -275,3 +327,63 @@ func copyBasic[T any, Array arrowArray[T]](dst *data.Field, src Array) {
 		dst.Append(src.Value(i))
 	}
 }
+
+// copyConverted copies the contents of an Arrow array into a Data Frame
+// field, converting each value to the field type.
+func copyConverted[S, T any, Array arrowArray[S]](dst *data.Field, src Array, convert func(S) T) {
+	for i := 0; i < src.Len(); i++ {
+		if dst.Nullable() {
+			if src.IsNull(i) {
+				var s *T
+				dst.Append(s)
+				continue
+			}
+			s := convert(src.Value(i))
+			dst.Append(&s)
+			continue
+		}
+		dst.Append(convert(src.Value(i)))
+	}
+}
+
+// copyDictionary copies a dictionary encoded column, resolving every index
+// to its value in the dictionary.
+func copyDictionary(field *data.Field, v *array.Dictionary) error {
+	values := newField(arrow.Field{
+		Name:     field.Name,
+		Type:     v.Dictionary().DataType(),
+		Nullable: field.Nullable(),
+	})
+	if err := copyData(values, v.Dictionary()); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if v.IsNull(i) {
+			field.Extend(1)
+			continue
+		}
+		field.Append(values.CopyAt(v.GetValueIndex(i)))
+	}
+	return nil
+}
+
+// copyJSON copies nested and other complex Arrow values as JSON.
+func copyJSON(field *data.Field, col arrow.Array) error {
+	for i := 0; i < col.Len(); i++ {
+		if col.IsNull(i) {
+			field.Extend(1)
+			continue
+		}
+		b, err := json.Marshal(col.GetOneForMarshal(i))
+		if err != nil {
+			return err
+		}
+		if field.Nullable() {
+			raw := json.RawMessage(b)
+			field.Append(&raw)
+			continue
+		}
+		field.Append(json.RawMessage(b))
+	}
+	return nil
+}
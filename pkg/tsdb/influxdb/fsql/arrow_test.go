@@ -1,6 +1,7 @@
 package fsql
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -494,3 +495,127 @@ func TestCustomMetadata(t *testing.T) {
 		},
 	}, resp.Frames[0].Meta.Custom)
 }
+
+func TestCopyData_Decimal(t *testing.T) {
+	dt := &arrow.Decimal128Type{Precision: 10, Scale: 2}
+	field := newField(arrow.Field{Name: "field", Type: dt, Nullable: true})
+	arr, _, err := array.FromJSON(memory.DefaultAllocator, dt, strings.NewReader(`["1.23", null, "-4.50"]`))
+	assert.NoError(t, err)
+	err = copyData(field, arr)
+	assert.NoError(t, err)
+	assert.Equal(t, data.FieldTypeNullableFloat64, field.Type())
+	assert.InDelta(t, 1.23, *field.CopyAt(0).(*float64), 1e-9)
+	assert.Equal(t, (*float64)(nil), field.CopyAt(1))
+	assert.InDelta(t, -4.5, *field.CopyAt(2).(*float64), 1e-9)
+
+	dt256 := &arrow.Decimal256Type{Precision: 40, Scale: 3}
+	field = newField(arrow.Field{Name: "field", Type: dt256})
+	arr, _, err = array.FromJSON(memory.DefaultAllocator, dt256, strings.NewReader(`["12.345"]`))
+	assert.NoError(t, err)
+	err = copyData(field, arr)
+	assert.NoError(t, err)
+	assert.InDelta(t, 12.345, field.CopyAt(0).(float64), 1e-9)
+}
+
+func TestCopyData_Dictionary(t *testing.T) {
+	dt := &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}
+	builder := array.NewDictionaryBuilder(memory.DefaultAllocator, dt).(*array.BinaryDictionaryBuilder)
+	assert.NoError(t, builder.AppendString("us-east"))
+	assert.NoError(t, builder.AppendString("us-west"))
+	builder.AppendNull()
+	assert.NoError(t, builder.AppendString("us-east"))
+
+	field := newField(arrow.Field{Name: "region", Type: dt, Nullable: true})
+	err := copyData(field, builder.NewArray())
+	assert.NoError(t, err)
+	assert.Equal(t, data.FieldTypeNullableString, field.Type())
+	assert.Equal(t, "us-east", *field.CopyAt(0).(*string))
+	assert.Equal(t, "us-west", *field.CopyAt(1).(*string))
+	assert.Equal(t, (*string)(nil), field.CopyAt(2))
+	assert.Equal(t, "us-east", *field.CopyAt(3).(*string))
+}
+
+func TestCopyData_DateAndTime(t *testing.T) {
+	field := newField(arrow.Field{Name: "date", Type: arrow.FixedWidthTypes.Date32})
+	arr, _, err := array.FromJSON(memory.DefaultAllocator, arrow.FixedWidthTypes.Date32, strings.NewReader(`[0, 19358]`))
+	assert.NoError(t, err)
+	err = copyData(field, arr)
+	assert.NoError(t, err)
+	assert.Equal(t, data.FieldTypeTime, field.Type())
+	assert.Equal(t, time.Unix(0, 0).UTC(), field.CopyAt(0))
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), field.CopyAt(1))
+
+	field = newField(arrow.Field{Name: "date", Type: arrow.FixedWidthTypes.Date64})
+	arr, _, err = array.FromJSON(memory.DefaultAllocator, arrow.FixedWidthTypes.Date64, strings.NewReader(`[86400000]`))
+	assert.NoError(t, err)
+	err = copyData(field, arr)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), field.CopyAt(0))
+
+	field = newField(arrow.Field{Name: "time", Type: arrow.FixedWidthTypes.Time32ms})
+	arr, _, err = array.FromJSON(memory.DefaultAllocator, arrow.FixedWidthTypes.Time32ms, strings.NewReader(`[3723500]`))
+	assert.NoError(t, err)
+	err = copyData(field, arr)
+	assert.NoError(t, err)
+	assert.Equal(t, data.FieldTypeString, field.Type())
+	assert.Equal(t, "01:02:03.5", field.CopyAt(0))
+
+	field = newField(arrow.Field{Name: "time", Type: arrow.FixedWidthTypes.Time64ns})
+	arr, _, err = array.FromJSON(memory.DefaultAllocator, arrow.FixedWidthTypes.Time64ns, strings.NewReader(`[1]`))
+	assert.NoError(t, err)
+	err = copyData(field, arr)
+	assert.NoError(t, err)
+	assert.Equal(t, "00:00:00.000000001", field.CopyAt(0))
+}
+
+func TestCopyData_JSON(t *testing.T) {
+	cs := []struct {
+		name string
+		dt   arrow.DataType
+		in   string
+		out  []string
+	}{
+		{
+			name: "list",
+			dt:   arrow.ListOf(arrow.PrimitiveTypes.Int64),
+			in:   `[[1, 2], [], [3, null]]`,
+			out:  []string{`[1,2]`, `[]`, `[3,null]`},
+		},
+		{
+			name: "struct",
+			dt: arrow.StructOf(
+				arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int64},
+				arrow.Field{Name: "b", Type: arrow.BinaryTypes.String},
+			),
+			in:  `[{"a": 1, "b": "x"}]`,
+			out: []string{`{"a":1,"b":"x"}`},
+		},
+	}
+	for _, c := range cs {
+		t.Run(c.name, func(t *testing.T) {
+			field := newField(arrow.Field{Name: "field", Type: c.dt})
+			arr, _, err := array.FromJSON(memory.DefaultAllocator, c.dt, strings.NewReader(c.in))
+			assert.NoError(t, err)
+			err = copyData(field, arr)
+			assert.NoError(t, err)
+			assert.Equal(t, data.FieldTypeJSON, field.Type())
+			assert.Equal(t, len(c.out), field.Len())
+			for i, want := range c.out {
+				assert.JSONEq(t, want, string(field.CopyAt(i).(json.RawMessage)))
+			}
+		})
+	}
+
+	t.Run("interval", func(t *testing.T) {
+		builder := array.NewMonthDayNanoIntervalBuilder(memory.DefaultAllocator)
+		builder.Append(arrow.MonthDayNanoInterval{Months: 1, Days: 2, Nanoseconds: 3})
+		builder.AppendNull()
+
+		field := newField(arrow.Field{Name: "field", Type: arrow.FixedWidthTypes.MonthDayNanoInterval, Nullable: true})
+		err := copyData(field, builder.NewArray())
+		assert.NoError(t, err)
+		assert.Equal(t, data.FieldTypeNullableJSON, field.Type())
+		assert.JSONEq(t, `{"months":1,"days":2,"nanoseconds":3}`, string(*field.CopyAt(0).(*json.RawMessage)))
+		assert.Equal(t, (*json.RawMessage)(nil), field.CopyAt(1))
+	})
+}
@@ -19,6 +19,9 @@ import (
 type client struct {
 	*flightsql.Client
 	md metadata.MD
+	// addr is the host:port the client is connected to.
+	addr   string
+	secure bool
 }
 
 // FlightClient returns the underlying [flight.Client].
@@ -35,7 +38,7 @@ func newFlightSQLClient(addr string, metadata metadata.MD, secure bool) (*client
 	if err != nil {
 		return nil, err
 	}
-	return &client{Client: fsqlClient, md: metadata}, nil
+	return &client{Client: fsqlClient, md: metadata, addr: addr, secure: secure}, nil
 }
 
 func grpcDialOptions(secure bool) ([]grpc.DialOption, error) {
@@ -0,0 +1,211 @@
+package fsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/flight"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/metadata"
+)
+
+// maxConcurrentEndpoints bounds the number of endpoints of a single query
+// which are fetched at the same time.
+const maxConcurrentEndpoints = 4
+
+// locationReuseConnection is the URI of a location which is served by the
+// connection the FlightInfo was received on.
+const locationReuseConnection = "arrow-flight-reuse-connection://?"
+
+// fetchEndpoints reads the records of all endpoints of info. Endpoints are
+// fetched concurrently, the records are returned in endpoint order so the
+// ordering guaranteed by the server is kept. The headers of the first
+// endpoint are returned along with the records.
+//
+// Reading stops once more than rowLimit rows have been fetched, frameForRecords
+// reports the truncation.
+func (r *runner) fetchEndpoints(ctx context.Context, info *flight.FlightInfo) (*recordsReader, metadata.MD, error) {
+	results := make([]endpointResult, len(info.Endpoint))
+	var rows atomic.Int64
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentEndpoints)
+	for i, endpoint := range info.Endpoint {
+		i, endpoint := i, endpoint
+		g.Go(func() error {
+			res, err := r.fetchEndpoint(gctx, endpoint, &rows)
+			if err != nil {
+				return fmt.Errorf("endpoint %d: %w", i, err)
+			}
+			results[i] = res
+			return nil
+		})
+	}
+	err := g.Wait()
+
+	reader := &recordsReader{}
+	for _, res := range results {
+		reader.records = append(reader.records, res.records...)
+	}
+	if err != nil {
+		reader.Release()
+		return nil, nil, err
+	}
+
+	var headers metadata.MD
+	if len(results) > 0 {
+		reader.schema = results[0].schema
+		headers = results[0].headers
+	} else {
+		reader.schema, err = flight.DeserializeSchema(info.Schema, r.client.Alloc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("schema: %w", err)
+		}
+	}
+	return reader, headers, nil
+}
+
+type endpointResult struct {
+	schema  *arrow.Schema
+	records []arrow.Record
+	headers metadata.MD
+}
+
+// fetchEndpoint reads all records of an endpoint, from the first of its
+// locations when the server redirects to other nodes.
+func (r *runner) fetchEndpoint(ctx context.Context, endpoint *flight.FlightEndpoint, rows *atomic.Int64) (endpointResult, error) {
+	c, closeClient, err := r.clientForEndpoint(endpoint)
+	if err != nil {
+		return endpointResult{}, err
+	}
+	defer closeClient()
+
+	// the credentials are only sent to the host the client is configured for
+	ctx = metadata.NewOutgoingContext(ctx, c.md)
+	reader, err := c.DoGetWithHeaderExtraction(ctx, endpoint.Ticket)
+	if err != nil {
+		return endpointResult{}, err
+	}
+	defer reader.Release()
+
+	res := endpointResult{schema: reader.Schema()}
+	for rows.Load() <= rowLimit && reader.Next() {
+		record := reader.Record()
+		record.Retain()
+		res.records = append(res.records, record)
+		rows.Add(record.NumRows())
+	}
+	if err := reader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		for _, record := range res.records {
+			record.Release()
+		}
+		return endpointResult{}, err
+	}
+
+	res.headers, err = reader.Header()
+	if err != nil {
+		glog.FromContext(ctx).Error(fmt.Sprintf("Failed to extract headers: %s", err))
+	}
+	return res, nil
+}
+
+// clientForEndpoint returns the client to fetch endpoint with. Endpoints
+// without location are served by the server the query was sent to,
+// otherwise a connection to the first location is opened. The metadata of
+// the data source, which holds its credentials, is only kept for locations
+// on the configured host, and locations can't downgrade a TLS connection.
+func (r *runner) clientForEndpoint(endpoint *flight.FlightEndpoint) (*client, func(), error) {
+	noop := func() {}
+	if len(endpoint.Location) == 0 || endpoint.Location[0].Uri == locationReuseConnection {
+		return r.client, noop, nil
+	}
+
+	u, err := url.Parse(endpoint.Location[0].Uri)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bad location: %w", err)
+	}
+	var secure bool
+	switch u.Scheme {
+	case "grpc", "grpc+tcp":
+	case "grpc+tls":
+		secure = true
+	default:
+		return nil, nil, fmt.Errorf("unsupported location scheme: %s", u.Scheme)
+	}
+	if r.client.secure && !secure {
+		return nil, nil, fmt.Errorf("insecure location scheme %s for a secure connection", u.Scheme)
+	}
+
+	var md metadata.MD
+	if sameHost(u.Host, r.client.addr) {
+		md = r.client.md
+	}
+	c, err := newFlightSQLClient(u.Host, md, secure)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, func() {
+		if err := c.Close(); err != nil {
+			glog.Warn("Failed to close fsql client", "err", err)
+		}
+	}, nil
+}
+
+// sameHost reports whether the host:port addresses a and b are on the same host.
+func sameHost(a, b string) bool {
+	hostA, _, err := net.SplitHostPort(a)
+	if err != nil {
+		hostA = a
+	}
+	hostB, _, err := net.SplitHostPort(b)
+	if err != nil {
+		hostB = b
+	}
+	return strings.EqualFold(hostA, hostB)
+}
+
+// recordsReader is a [recordReader] over records which have already been
+// fetched.
+type recordsReader struct {
+	schema  *arrow.Schema
+	records []arrow.Record
+	current int
+	once    sync.Once
+}
+
+func (r *recordsReader) Next() bool {
+	if r.current >= len(r.records) {
+		return false
+	}
+	r.current++
+	return true
+}
+
+func (r *recordsReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+func (r *recordsReader) Record() arrow.Record {
+	return r.records[r.current-1]
+}
+
+func (r *recordsReader) Err() error {
+	return nil
+}
+
+// Release releases the records of the reader.
+func (r *recordsReader) Release() {
+	r.once.Do(func() {
+		for _, record := range r.records {
+			record.Release()
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package fsql
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/flight"
+	"github.com/apache/arrow/go/v13/arrow/flight/flightsql"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+var partitionSchema = arrow.NewSchema([]arrow.Field{{Name: "value", Type: arrow.PrimitiveTypes.Int64}}, nil)
+
+// partitionedServer answers every query with one endpoint per partition,
+// each partition returning two rows. Partitions listed in locations are
+// served from another address.
+type partitionedServer struct {
+	flightsql.BaseServer
+	partitions int
+	locations  map[int]string
+}
+
+func (s *partitionedServer) GetFlightInfoStatement(_ context.Context, _ flightsql.StatementQuery, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	info := &flight.FlightInfo{
+		FlightDescriptor: desc,
+		Schema:           flight.SerializeSchema(partitionSchema, memory.DefaultAllocator),
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}
+	for i := 0; i < s.partitions; i++ {
+		tkt, err := flightsql.CreateStatementQueryTicket([]byte(strconv.Itoa(i)))
+		if err != nil {
+			return nil, err
+		}
+		endpoint := &flight.FlightEndpoint{Ticket: &flight.Ticket{Ticket: tkt}}
+		if loc, ok := s.locations[i]; ok {
+			endpoint.Location = []*flight.Location{{Uri: loc}}
+		}
+		info.Endpoint = append(info.Endpoint, endpoint)
+	}
+	return info, nil
+}
+
+func (s *partitionedServer) DoGetStatement(_ context.Context, cmd flightsql.StatementQueryTicket) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	partition, err := strconv.Atoi(string(cmd.GetStatementHandle()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, partitionSchema)
+	defer b.Release()
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{int64(partition * 10), int64(partition*10 + 1)}, nil)
+
+	ch := make(chan flight.StreamChunk, 1)
+	ch <- flight.StreamChunk{Data: b.NewRecord()}
+	close(ch)
+	return partitionSchema, ch, nil
+}
+
+func startFlightSQLServer(t *testing.T, srv flightsql.Server, addr string) {
+	t.Helper()
+	server := flight.NewServerWithMiddleware(nil)
+	server.RegisterFlightService(flightsql.NewFlightServer(srv))
+	require.NoError(t, server.Init(addr))
+	go func() {
+		assert.NoError(t, server.Serve())
+	}()
+	t.Cleanup(server.Shutdown)
+}
+
+func TestIntegration_QueryDataMultipleEndpoints(t *testing.T) {
+	locations := map[int]string{
+		1: "grpc+tcp://localhost:12348",
+		2: locationReuseConnection,
+	}
+	startFlightSQLServer(t, &partitionedServer{partitions: 5, locations: locations}, "localhost:12347")
+	startFlightSQLServer(t, &partitionedServer{partitions: 5}, "localhost:12348")
+
+	resp, err := Query(
+		context.Background(),
+		&models.DatasourceInfo{
+			URL: "http://localhost:12347",
+		},
+		backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					RefID: "A",
+					JSON:  mustQueryJSON(t, "A", "select value from partitioned"),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	respA := resp.Responses["A"]
+	require.NoError(t, respA.Error)
+	require.Len(t, respA.Frames, 1)
+
+	field := respA.Frames[0].Fields[0]
+	require.Equal(t, 10, field.Len())
+	for i, want := range []int64{0, 1, 10, 11, 20, 21, 30, 31, 40, 41} {
+		assert.Equal(t, want, field.At(i))
+	}
+}
+
+func TestClientForEndpoint(t *testing.T) {
+	r := &runner{client: &client{}}
+
+	c, _, err := r.clientForEndpoint(&flight.FlightEndpoint{})
+	require.NoError(t, err)
+	require.Same(t, r.client, c)
+
+	c, _, err = r.clientForEndpoint(&flight.FlightEndpoint{Location: []*flight.Location{{Uri: locationReuseConnection}}})
+	require.NoError(t, err)
+	require.Same(t, r.client, c)
+
+	_, _, err = r.clientForEndpoint(&flight.FlightEndpoint{Location: []*flight.Location{{Uri: "http://localhost:1234"}}})
+	require.Error(t, err)
+}
+
+func TestClientForEndpointCredentials(t *testing.T) {
+	md := metadata.Pairs("Authorization", "Bearer token")
+	location := func(uri string) *flight.FlightEndpoint {
+		return &flight.FlightEndpoint{Location: []*flight.Location{{Uri: uri}}}
+	}
+
+	t.Run("should keep the credentials for the configured host", func(t *testing.T) {
+		r := &runner{client: &client{md: md, addr: "localhost:1234"}}
+		c, closeClient, err := r.clientForEndpoint(location("grpc+tcp://localhost:1235"))
+		require.NoError(t, err)
+		defer closeClient()
+		require.Equal(t, md, c.md)
+	})
+
+	t.Run("should drop the credentials for a foreign host", func(t *testing.T) {
+		r := &runner{client: &client{md: md, addr: "localhost:1234"}}
+		c, closeClient, err := r.clientForEndpoint(location("grpc+tcp://example.com:1234"))
+		require.NoError(t, err)
+		defer closeClient()
+		require.Empty(t, c.md)
+	})
+
+	t.Run("should reject a plaintext location for a secure connection", func(t *testing.T) {
+		r := &runner{client: &client{md: md, addr: "localhost:1234", secure: true}}
+		for _, uri := range []string{"grpc://localhost:1234", "grpc+tcp://localhost:1234"} {
+			_, _, err := r.clientForEndpoint(location(uri))
+			require.Error(t, err, uri)
+		}
+	})
+}
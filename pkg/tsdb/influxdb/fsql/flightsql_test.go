@@ -64,14 +64,27 @@ func TestIntegration_QueryData(t *testing.T) {
 					RefID: "B",
 					JSON:  mustQueryJSON(t, "B", "select 1"),
 				},
+				{
+					RefID: "C",
+					JSON: mustQueryJSONWithParameters(t, "C", "select * from intTable where keyName = $__param(key)", map[string]string{
+						"key": "one' or '1'='1",
+					}),
+				},
+				{
+					RefID: "D",
+					JSON: mustQueryJSONWithParameters(t, "D", "select * from intTable where keyName = $__param(key)", map[string]string{
+						"key": "one",
+					}),
+				},
 			},
 		},
 	)
 	require.NoError(t, err)
-	require.Len(t, resp.Responses, 2)
+	require.Len(t, resp.Responses, 4)
 
 	respA := resp.Responses["A"]
 	require.NoError(t, respA.Error)
+	require.Len(t, respA.Frames, 1)
 	frame := respA.Frames[0]
 
 	require.Equal(t, "id", frame.Fields[0].Name)
@@ -81,15 +94,32 @@ func TestIntegration_QueryData(t *testing.T) {
 	for _, f := range frame.Fields {
 		assert.Equal(t, 4, f.Len())
 	}
+
+	// parameters are bound, not interpolated into the query
+	respC := resp.Responses["C"]
+	require.NoError(t, respC.Error)
+	require.Empty(t, respC.Frames)
+
+	respD := resp.Responses["D"]
+	require.NoError(t, respD.Error)
+	require.Len(t, respD.Frames, 1)
+	require.Equal(t, 1, respD.Frames[0].Rows())
 }
 
 func mustQueryJSON(t *testing.T, refID, sql string) []byte {
 	t.Helper()
 
+	return mustQueryJSONWithParameters(t, refID, sql, nil)
+}
+
+func mustQueryJSONWithParameters(t *testing.T, refID, sql string, parameters map[string]string) []byte {
+	t.Helper()
+
 	b, err := json.Marshal(queryRequest{
-		RefID:    refID,
-		RawQuery: sql,
-		Format:   "table",
+		RefID:      refID,
+		RawQuery:   sql,
+		Format:     "table",
+		Parameters: parameters,
 	})
 	if err != nil {
 		panic(err)
@@ -6,9 +6,13 @@ import (
 	"net"
 	"net/url"
 	"strings"
+	"time"
 
+	"github.com/apache/arrow/go/v13/arrow/flight"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
@@ -18,6 +22,9 @@ var (
 	glog = log.New("tsdb.influx_flightsql")
 )
 
+// cancelTimeout bounds the time spent on cancelling an abandoned query.
+const cancelTimeout = 5 * time.Second
+
 type SQLOptions struct {
 	Addr     string              `json:"host"`
 	Metadata []map[string]string `json:"metadata"`
@@ -39,9 +46,7 @@ func Query(ctx context.Context, dsInfo *models.DatasourceInfo, req backend.Query
 		}
 	}(r.client)
 
-	if r.client.md.Len() != 0 {
-		ctx = metadata.NewOutgoingContext(ctx, r.client.md)
-	}
+	ctx = r.outgoingContext(ctx)
 
 	for _, q := range req.Queries {
 		qm, err := getQueryModel(q)
@@ -51,38 +56,107 @@ func Query(ctx context.Context, dsInfo *models.DatasourceInfo, req backend.Query
 		}
 
 		logger.Info(fmt.Sprintf("InfluxDB executing SQL: %s", qm.RawSQL))
-		info, err := r.client.Execute(ctx, qm.RawSQL)
-		if err != nil {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
-			return tRes, nil
-		}
-		if len(info.Endpoint) != 1 {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("unsupported endpoint count in response: %d", len(info.Endpoint)))
-			return tRes, nil
-		}
+		tRes.Responses[q.RefID] = r.query(ctx, qm)
+	}
 
-		reader, err := r.client.DoGetWithHeaderExtraction(ctx, info.Endpoint[0].Ticket)
-		if err != nil {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
-			return tRes, nil
-		}
-		defer reader.Release()
+	return tRes, nil
+}
 
-		headers, err := reader.Header()
-		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to extract headers: %s", err))
-		}
+// query runs a single query. The prepared statement and the cancellation of
+// the query are released before it returns.
+func (r *runner) query(ctx context.Context, qm *queryModel) backend.DataResponse {
+	info, closeStmt, err := r.execute(ctx, qm)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
+	}
+	defer closeStmt()
+
+	defer r.cancelOnDone(ctx, info)()
 
-		tRes.Responses[q.RefID] = newQueryDataResponse(reader, *qm.Query, headers)
+	reader, headers, err := r.fetchEndpoints(ctx, info)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
 	}
+	defer reader.Release()
 
-	return tRes, nil
+	return newQueryDataResponse(reader, *qm.Query, headers)
 }
 
 type runner struct {
 	client *client
 }
 
+// execute runs the query, as a prepared statement when it has bound
+// parameters. The returned function releases the prepared statement, it must
+// be called once the results have been read.
+func (r *runner) execute(ctx context.Context, qm *queryModel) (*flight.FlightInfo, func(), error) {
+	if qm.params == nil || qm.params.Len() == 0 {
+		info, err := r.client.Execute(ctx, qm.RawSQL)
+		return info, func() {}, err
+	}
+
+	stmt, err := r.client.Prepare(ctx, qm.RawSQL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("prepare: %w", err)
+	}
+	closeStmt := func() {
+		if err := stmt.Close(r.outgoingContext(context.Background())); err != nil {
+			glog.Warn("Failed to close prepared statement", "err", err)
+		}
+	}
+
+	params := qm.params.record(r.client.Alloc)
+	stmt.SetParameters(params)
+	params.Release()
+
+	info, err := stmt.Execute(ctx)
+	if err != nil {
+		closeStmt()
+		return nil, nil, err
+	}
+	return info, closeStmt, nil
+}
+
+// cancelOnDone cancels the query on the server when ctx is done before the
+// returned function is called. Abandoned queries keep running otherwise.
+func (r *runner) cancelOnDone(ctx context.Context, info *flight.FlightInfo) func() {
+	done := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(done)
+		r.cancel(info)
+	})
+	return func() {
+		if !stop() {
+			// don't close the client while the cancellation is in flight
+			<-done
+		}
+	}
+}
+
+// cancel asks the server to stop working on a query which results are no
+// longer needed. Servers which don't implement CancelFlightInfo yet are sent
+// the deprecated FlightSQL CancelQuery action instead.
+func (r *runner) cancel(info *flight.FlightInfo) {
+	ctx, cancel := context.WithTimeout(r.outgoingContext(context.Background()), cancelTimeout)
+	defer cancel()
+
+	_, err := r.client.CancelFlightInfo(ctx, &flight.CancelFlightInfoRequest{Info: info})
+	if status.Code(err) == codes.Unimplemented {
+		//nolint:staticcheck // fallback for servers not implementing CancelFlightInfo
+		_, err = r.client.CancelQuery(ctx, info)
+	}
+	if err != nil {
+		glog.Debug("Failed to cancel query", "err", err)
+	}
+}
+
+func (r *runner) outgoingContext(ctx context.Context) context.Context {
+	if r.client.md.Len() == 0 {
+		return ctx
+	}
+	return metadata.NewOutgoingContext(ctx, r.client.md)
+}
+
 // runnerFromDataSource creates a runner from the datasource model (the datasource instance's configuration).
 func runnerFromDataSource(dsInfo *models.DatasourceInfo) (*runner, error) {
 	if dsInfo.URL == "" {
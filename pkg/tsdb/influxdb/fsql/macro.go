@@ -2,23 +2,63 @@ package fsql
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 )
 
-var macros = sqlutil.Macros{
-	"dateBin":        macroDateBin(""),
-	"dateBinAlias":   macroDateBin("_binned"),
-	"interval":       macroInterval,
-	"timeGroup":      macroTimeGroup,
-	"timeGroupAlias": macroTimeGroupAlias,
-
-	// The behaviors of timeFrom and timeTo as defined in the SDK are different
-	// from all other Grafana SQL plugins. Instead we'll take the implementations,
-	// rename them and define timeFrom and timeTo ourselves.
-	"timeTo":   macroTo,
-	"timeFrom": macroFrom,
+var macros = newMacros(nil)
+
+// newMacros returns the macros to interpolate a query with. When params is
+// set, the time range and query parameters are bound as prepared statement
+// parameters instead of being written into the SQL text.
+func newMacros(params *queryParameters) sqlutil.Macros {
+	m := sqlutil.Macros{
+		"dateBin":        macroDateBin(""),
+		"dateBinAlias":   macroDateBin("_binned"),
+		"interval":       macroInterval,
+		"timeGroup":      macroTimeGroup,
+		"timeGroupAlias": macroTimeGroupAlias,
+
+		// The behaviors of timeFrom and timeTo as defined in the SDK are different
+		// from all other Grafana SQL plugins. Instead we'll take the implementations,
+		// rename them and define timeFrom and timeTo ourselves.
+		"timeTo":   macroTo,
+		"timeFrom": macroFrom,
+		"param":    macroParamUnsupported,
+	}
+	if params != nil {
+		m["timeTo"] = params.macroTo
+		m["timeFrom"] = params.macroFrom
+		m["param"] = params.macroParam
+	}
+	return m
+}
+
+var paramMacroRegexp = regexp.MustCompile(`\$__param\(([^)]*)\)`)
+
+// interpolate expands the macros of query, binding the time range and the
+// query parameters to params.
+//
+// $__param is expanded first: the SDK takes the parentheses of a later macro
+// as the arguments of a macro without any, such as $__timeTo.
+func interpolate(query *sqlutil.Query, params *queryParameters) (string, error) {
+	var err error
+	sql := paramMacroRegexp.ReplaceAllStringFunc(query.RawSQL, func(match string) string {
+		if err != nil {
+			return match
+		}
+		args := strings.Split(paramMacroRegexp.FindStringSubmatch(match)[1], ",")
+		var res string
+		res, err = params.macroParam(query, args)
+		return res
+	})
+	if err != nil {
+		return "", err
+	}
+	return sqlutil.Interpolate(query.WithSQL(sql), newMacros(params))
 }
 
 func macroTimeGroup(query *sqlutil.Query, args []string) (string, error) {
@@ -91,6 +131,10 @@ func macroTo(query *sqlutil.Query, _ []string) (string, error) {
 	return fmt.Sprintf("cast('%s' as timestamp)", query.TimeRange.To.Format(time.RFC3339)), nil
 }
 
+func macroParamUnsupported(_ *sqlutil.Query, _ []string) (string, error) {
+	return "", fmt.Errorf("query parameters are not supported")
+}
+
 func macroDateBin(suffix string) sqlutil.MacroFunc {
 	return func(query *sqlutil.Query, args []string) (string, error) {
 		if len(args) != 1 {
@@ -1,9 +1,11 @@
 package fsql
 
 import (
+	"regexp"
 	"testing"
 	"time"
 
+	"github.com/apache/arrow/go/v13/arrow/memory"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 	"github.com/stretchr/testify/require"
@@ -61,3 +63,35 @@ func TestMacros(t *testing.T) {
 		})
 	}
 }
+
+func TestMacrosWithParameters(t *testing.T) {
+	from, _ := time.Parse(time.RFC3339, "2023-01-01T00:00:00Z")
+
+	query := sqlutil.Query{
+		TimeRange: backend.TimeRange{
+			From: from,
+			To:   from.Add(10 * time.Minute),
+		},
+		Interval: 10 * time.Second,
+	}
+
+	params := newQueryParameters(map[string]string{"host": "a'b"})
+	sql, err := interpolate(query.WithSQL(`select * from x where time >= $__timeFrom and time < $__timeTo and host = $__param(host) and time > $__timeFrom`), params)
+	require.NoError(t, err)
+	m := regexp.MustCompile(`^select \* from x where time >= (\$\d) and time < \$\d and host = \$\d and time > (\$\d)$`).FindStringSubmatch(sql)
+	require.Len(t, m, 3, sql)
+	require.Equal(t, m[1], m[2], "same macro should reuse the placeholder")
+	require.Equal(t, 3, params.Len())
+	require.ElementsMatch(t, []any{from, from.Add(10 * time.Minute), "a'b"}, params.bound)
+
+	rec := params.record(memory.DefaultAllocator)
+	defer rec.Release()
+	require.Equal(t, int64(1), rec.NumRows())
+	require.Equal(t, int64(3), rec.NumCols())
+
+	_, err = interpolate(query.WithSQL(`select $__param(missing)`), params)
+	require.Error(t, err)
+
+	_, err = sqlutil.Interpolate(query.WithSQL(`select $__param(host)`), macros)
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package fsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// queryParameters collects the values bound to the placeholders of a
+// prepared statement. Placeholders are numbered ($1, $2, ...) so the order
+// in which the macros are expanded does not matter.
+type queryParameters struct {
+	// values are the named parameters sent with the query, for example the
+	// current values of dashboard variables.
+	values map[string]string
+
+	bound []any
+	index map[string]int
+}
+
+func newQueryParameters(values map[string]string) *queryParameters {
+	return &queryParameters{
+		values: values,
+		index:  map[string]int{},
+	}
+}
+
+// bind returns the placeholder for key, binding value when it is first seen.
+func (p *queryParameters) bind(key string, value any) string {
+	i, ok := p.index[key]
+	if !ok {
+		p.bound = append(p.bound, value)
+		i = len(p.bound) - 1
+		p.index[key] = i
+	}
+	return fmt.Sprintf("$%d", i+1)
+}
+
+// Len returns the number of bound parameters.
+func (p *queryParameters) Len() int {
+	return len(p.bound)
+}
+
+func (p *queryParameters) macroFrom(query *sqlutil.Query, _ []string) (string, error) {
+	return p.bind("__timeFrom", query.TimeRange.From.UTC()), nil
+}
+
+func (p *queryParameters) macroTo(query *sqlutil.Query, _ []string) (string, error) {
+	return p.bind("__timeTo", query.TimeRange.To.UTC()), nil
+}
+
+// macroParam binds a named parameter of the query: $__param(name).
+func (p *queryParameters) macroParam(_ *sqlutil.Query, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("%w: expected 1 argument, received %d", sqlutil.ErrorBadArgumentCount, len(args))
+	}
+	name := strings.TrimSpace(args[0])
+	value, ok := p.values[name]
+	if !ok {
+		return "", fmt.Errorf("unknown query parameter: %s", name)
+	}
+	return p.bind("param:"+name, value), nil
+}
+
+// record returns the bound values as a single row record, with one column
+// per placeholder.
+func (p *queryParameters) record(alloc memory.Allocator) arrow.Record {
+	fields := make([]arrow.Field, len(p.bound))
+	for i, v := range p.bound {
+		name := strconv.Itoa(i + 1)
+		switch v.(type) {
+		case time.Time:
+			fields[i] = arrow.Field{Name: name, Type: &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}}
+		default:
+			fields[i] = arrow.Field{Name: name, Type: arrow.BinaryTypes.String}
+		}
+	}
+
+	b := array.NewRecordBuilder(alloc, arrow.NewSchema(fields, nil))
+	defer b.Release()
+	for i, v := range p.bound {
+		switch v := v.(type) {
+		case time.Time:
+			b.Field(i).(*array.TimestampBuilder).Append(arrow.Timestamp(v.UnixNano()))
+		default:
+			b.Field(i).(*array.StringBuilder).Append(fmt.Sprint(v))
+		}
+	}
+	return b.NewRecord()
+}
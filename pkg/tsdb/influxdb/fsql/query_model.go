@@ -11,6 +11,9 @@ import (
 
 type queryModel struct {
 	*sqlutil.Query
+
+	// params holds the values bound to the placeholders of RawSQL.
+	params *queryParameters
 }
 
 // queryRequest is an inbound query request as part of a batch of queries sent
@@ -21,6 +24,8 @@ type queryRequest struct {
 	IntervalMilliseconds int    `json:"intervalMs"`
 	MaxDataPoints        int64  `json:"maxDataPoints"`
 	Format               string `json:"format"`
+	// Parameters are bound to the query with the $__param(name) macro.
+	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
 func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
@@ -48,12 +53,14 @@ func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
 		Format:        format,
 	}
 
-	// Process macros and execute the query.
-	sql, err := sqlutil.Interpolate(query, macros)
+	// Process macros and execute the query. Values coming from the request
+	// are bound as parameters rather than written into the SQL text.
+	params := newQueryParameters(q.Parameters)
+	sql, err := interpolate(query, params)
 	if err != nil {
 		return nil, fmt.Errorf("macro interpolation: %w", err)
 	}
 	query.RawSQL = sql
 
-	return &queryModel{query}, nil
+	return &queryModel{Query: query, params: params}, nil
 }
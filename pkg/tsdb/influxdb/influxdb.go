@@ -8,9 +8,11 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
 
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/exemplar"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/flux"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/fsql"
 
@@ -25,13 +27,27 @@ var logger log.Logger = log.New("tsdb.influxdb")
 type Service struct {
 	im       instancemgmt.InstanceManager
 	features featuremgmt.FeatureToggles
+
+	resourceHandler backend.CallResourceHandler
+	schemaCache     *schemaCache
 }
 
+var (
+	_ backend.QueryDataHandler    = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+	_ backend.CallResourceHandler = (*Service)(nil)
+	_ backend.StreamHandler       = (*Service)(nil)
+)
+
 func ProvideService(httpClient httpclient.Provider, features featuremgmt.FeatureToggles) *Service {
-	return &Service{
-		im:       datasource.NewInstanceManager(newInstanceSettings(httpClient)),
-		features: features,
+	s := &Service{
+		im:          datasource.NewInstanceManager(newInstanceSettings(httpClient)),
+		features:    features,
+		schemaCache: newSchemaCache(schemaCacheTTL),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+
+	return s
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -68,6 +84,12 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			version = influxVersionInfluxQL
 		}
 
+		exemplarSampler := jsonData.ExemplarSampler
+		if _, err := exemplar.NewSampler(exemplarSampler); err != nil {
+			logger.Warn("Unknown exemplar sampler, using the default one", "error", err, "datasource", settings.UID)
+			exemplarSampler = ""
+		}
+
 		database := jsonData.DbName
 		if database == "" {
 			database = settings.Database
@@ -87,6 +109,10 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			SecureGrpc:                  true,
 			Token:                       settings.DecryptedSecureJSONData["token"],
 			ExemplarTraceIdDestinations: jsonData.ExemplarTraceIdDestinations,
+			ExemplarSampler:             exemplarSampler,
+			QuerySplitDuration:          jsonData.QuerySplitDuration,
+			QuerySplitMaxPoints:         jsonData.QuerySplitMaxPoints,
+			QuerySplitConcurrency:       jsonData.QuerySplitConcurrency,
 		}
 		return model, nil
 	}
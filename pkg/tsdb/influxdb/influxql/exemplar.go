@@ -0,0 +1,55 @@
+package influxql
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/exemplar"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// exemplarResponse replaces the series of resp with a single exemplar frame
+// holding their points, reduced by the sampler of the datasource. The string
+// fields of the series besides time and value, like a trace ID, are kept as
+// label fields of the exemplars.
+func exemplarResponse(dsInfo *models.DatasourceInfo, logger log.Logger, query *models.Query, resp backend.DataResponse) backend.DataResponse {
+	sampler, err := exemplar.NewSampler(dsInfo.ExemplarSampler)
+	if err != nil {
+		logger.Warn("Falling back to the default exemplar sampler", "error", err)
+		sampler = exemplar.NewNoOpSampler()
+	}
+	sampler.SetStep(query.Interval)
+
+	labelTracker := exemplar.NewLabelTracker()
+	for _, frame := range resp.Frames {
+		if len(frame.Fields) > 2 {
+			labelTracker.AddFields(stringFields(frame.Fields[2:]))
+		}
+	}
+	for _, ex := range transformToExemplars(resp.Frames) {
+		labelTracker.Add(ex.SeriesLabels)
+		sampler.Add(ex)
+	}
+
+	framer := exemplar.NewFramer(sampler, labelTracker)
+	framer.SetRefID(query.RefID)
+	if len(resp.Frames) > 0 && resp.Frames[0].Meta != nil {
+		framer.SetMeta(resp.Frames[0].Meta)
+	} else {
+		framer.SetMeta(&data.FrameMeta{ExecutedQueryString: query.RawQuery})
+	}
+
+	frames, err := framer.Frames()
+	return backend.DataResponse{Frames: frames, Error: err}
+}
+
+func stringFields(fields []*data.Field) []*data.Field {
+	var res []*data.Field
+	for _, f := range fields {
+		if f.Type() == data.FieldTypeString || f.Type() == data.FieldTypeNullableString {
+			res = append(res, f)
+		}
+	}
+	return res
+}
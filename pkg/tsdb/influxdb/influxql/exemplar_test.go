@@ -0,0 +1,109 @@
+package influxql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/exemplar"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+func TestQueryExemplars(t *testing.T) {
+	// one point per second for 2 minutes
+	values := make([]string, 0, 120)
+	for i := 0; i < 120; i++ {
+		values = append(values, fmt.Sprintf("[%d,5]", 1672531200000+i*1000))
+	}
+	body := `{"results":[{"series":[{"name":"spans","tags":{"service":"api"},"columns":["time","duration"],"values":[` + strings.Join(values, ",") + `]}]}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	req := &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{
+				RefID:    "A",
+				Interval: 10 * time.Second,
+				TimeRange: backend.TimeRange{
+					From: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+					To:   time.Date(2023, 1, 1, 0, 2, 0, 0, time.UTC),
+				},
+				JSON: []byte(`{"rawQuery": true, "exemplar": true, "query": "SELECT \"duration\" FROM \"spans\" WHERE $timeFilter GROUP BY \"service\""}`),
+			},
+		},
+	}
+
+	query := func(t *testing.T, sampler string) *backend.DataResponse {
+		t.Helper()
+		dsInfo := &models.DatasourceInfo{
+			HTTPClient:      srv.Client(),
+			URL:             srv.URL,
+			HTTPMode:        "GET",
+			ExemplarSampler: sampler,
+		}
+		resp, err := Query(context.Background(), tracing.InitializeTracerForTest(), dsInfo, req, featuremgmt.WithFeatures())
+		require.NoError(t, err)
+		respA := resp.Responses["A"]
+		require.NoError(t, respA.Error)
+		require.Len(t, respA.Frames, 1)
+		require.Equal(t, "exemplar", respA.Frames[0].Name)
+		require.Equal(t, "A", respA.Frames[0].RefID)
+		return &respA
+	}
+
+	t.Run("keeps every point without sampler", func(t *testing.T) {
+		frame := query(t, exemplar.SamplerNone).Frames[0]
+		require.Equal(t, 120, frame.Rows())
+		field, _ := frame.FieldByName("service")
+		require.NotNil(t, field)
+		require.Equal(t, "api", field.At(0))
+	})
+
+	t.Run("keeps one exemplar per step with the step sampler", func(t *testing.T) {
+		frame := query(t, exemplar.SamplerStep).Frames[0]
+		require.Equal(t, 12, frame.Rows())
+	})
+
+	t.Run("keeps the string fields of the series", func(t *testing.T) {
+		tableBody := `{"results":[{"series":[{"name":"spans","columns":["time","duration","trace_id"],"values":[[1672531200000,5,"abc"],[1672531201000,6,"def"]]}]}]}`
+		tableSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			_, _ = rw.Write([]byte(tableBody))
+		}))
+		defer tableSrv.Close()
+
+		dsInfo := &models.DatasourceInfo{
+			HTTPClient: tableSrv.Client(),
+			URL:        tableSrv.URL,
+			HTTPMode:   "GET",
+		}
+		tableReq := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					RefID:     "A",
+					Interval:  10 * time.Second,
+					TimeRange: req.Queries[0].TimeRange,
+					JSON:      []byte(`{"rawQuery": true, "exemplar": true, "resultFormat": "table", "query": "SELECT \"duration\", \"trace_id\" FROM \"spans\" WHERE $timeFilter"}`),
+				},
+			},
+		}
+		resp, err := Query(context.Background(), tracing.InitializeTracerForTest(), dsInfo, tableReq, featuremgmt.WithFeatures())
+		require.NoError(t, err)
+		respA := resp.Responses["A"]
+		require.NoError(t, respA.Error)
+		require.Len(t, respA.Frames, 1)
+		field, _ := respA.Frames[0].FieldByName("trace_id")
+		require.NotNil(t, field)
+		require.Equal(t, []string{"abc", "def"}, []string{field.At(0).(string), field.At(1).(string)})
+	})
+}
@@ -45,24 +45,50 @@ func Query(ctx context.Context, tracer trace.Tracer, dsInfo *models.DatasourceIn
 		}
 
 		query.RefID = reqQuery.RefID
+
+		shard, err := shardDuration(dsInfo, query, rawQuery)
+		if err != nil {
+			response.Responses[query.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+		var shardQueries []*models.Query
+		if shard > 0 && reqQuery.TimeRange.To.Sub(reqQuery.TimeRange.From) > shard && isSplittable(query, rawQuery) {
+			shardQueries, err = buildShardQueries(req, query, splitTimeRange(reqQuery.TimeRange, shard))
+			if err != nil {
+				return &backend.QueryDataResponse{}, err
+			}
+		}
+
 		query.RawQuery = rawQuery
 
 		if setting.Env == setting.Dev {
 			logger.Info("Influxdb query", "raw query", rawQuery)
 		}
 
-		request, err := createRequest(ctx, logger, dsInfo, rawQuery, query.Policy)
-		if err != nil {
-			return &backend.QueryDataResponse{}, err
-		}
+		isStreamingParserEnabled := features.IsEnabled(ctx, featuremgmt.FlagInfluxqlStreamingParser)
 
-		resp, err := execute(ctx, tracer, dsInfo, logger, query, request, features.IsEnabled(ctx, featuremgmt.FlagInfluxqlStreamingParser))
+		var resp backend.DataResponse
+		if len(shardQueries) > 1 {
+			logger.Debug("Splitting InfluxQL query", "refId", query.RefID, "shards", len(shardQueries))
+			resp, err = executeSplit(ctx, tracer, dsInfo, logger, query, shardQueries, isStreamingParserEnabled)
+		} else {
+			var request *http.Request
+			request, err = createRequest(ctx, logger, dsInfo, rawQuery, query.Policy)
+			if err != nil {
+				return &backend.QueryDataResponse{}, err
+			}
+
+			resp, err = execute(ctx, tracer, dsInfo, logger, query, request, isStreamingParserEnabled)
+		}
 
 		if err != nil {
 			response.Responses[query.RefID] = backend.DataResponse{Error: err}
-		} else {
-			response.Responses[query.RefID] = resp
+			continue
+		}
+		if query.Exemplar && resp.Error == nil {
+			resp = exemplarResponse(dsInfo, logger, query, resp)
 		}
+		response.Responses[query.RefID] = resp
 	}
 
 	return response, nil
@@ -0,0 +1,139 @@
+package querydata
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql/util"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// MergeResponses merges the responses of the queries of consecutive time
+// ranges, in time order, into one response. Frames of the same series are
+// concatenated into a single frame.
+func MergeResponses(responses []*backend.DataResponse, query *models.Query) *backend.DataResponse {
+	var keys []string
+	series := map[string][]*data.Frame{}
+	for _, resp := range responses {
+		for _, frame := range resp.Frames {
+			key := frameKey(frame)
+			if _, ok := series[key]; !ok {
+				keys = append(keys, key)
+			}
+			series[key] = append(series[key], frame)
+		}
+	}
+
+	rsp := &backend.DataResponse{}
+	for _, key := range keys {
+		rsp.Frames = append(rsp.Frames, mergeFrames(series[key])...)
+	}
+	// the queries of the shards are reported once, on the first frame
+	for _, frame := range rsp.Frames {
+		if frame.Meta != nil {
+			frame.Meta.ExecutedQueryString = ""
+		}
+	}
+
+	// The ExecutedQueryString can be viewed in QueryInspector in UI
+	if len(rsp.Frames) > 0 {
+		first := rsp.Frames[0]
+		if first.Meta == nil {
+			first.Meta = &data.FrameMeta{}
+		}
+		first.Meta.ExecutedQueryString = query.RawQuery
+		first.Meta.PreferredVisualization = util.GetVisType(query.ResultFormat)
+	}
+
+	return rsp
+}
+
+// mergeMeta merges the metadata of the frames of a series. The notices of all
+// the frames are kept, the other properties are taken from the first frame
+// which has them.
+func mergeMeta(frames []*data.Frame) *data.FrameMeta {
+	var merged *data.FrameMeta
+	seen := map[data.Notice]bool{}
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			continue
+		}
+		if merged == nil {
+			meta := *frame.Meta
+			meta.Notices = nil
+			merged = &meta
+		}
+		for _, notice := range frame.Meta.Notices {
+			if !seen[notice] {
+				seen[notice] = true
+				merged.Notices = append(merged.Notices, notice)
+			}
+		}
+	}
+	return merged
+}
+
+// frameKey identifies the series of a frame.
+func frameKey(frame *data.Frame) string {
+	var b strings.Builder
+	b.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		b.WriteString("\x00")
+		b.WriteString(field.Name)
+		b.WriteString("\x00")
+		b.WriteString(field.Labels.String())
+	}
+	return b.String()
+}
+
+// mergeFrames concatenates the rows of the frames of a series. Fields which
+// only hold null values have no known type and are merged into fields of any
+// type. The frames are returned unmerged when their field types conflict.
+//
+// The fields are copied since the frames of a response may share their time
+// field.
+func mergeFrames(frames []*data.Frame) []*data.Frame {
+	first := frames[0]
+	if len(frames) == 1 {
+		return frames
+	}
+
+	types := make([]data.FieldType, len(first.Fields))
+	for i := range first.Fields {
+		types[i] = data.FieldTypeNullableJSON
+		for _, frame := range frames {
+			t := frame.Fields[i].Type()
+			if t == data.FieldTypeNullableJSON {
+				continue
+			}
+			if types[i] != data.FieldTypeNullableJSON && types[i] != t {
+				return frames
+			}
+			types[i] = t
+		}
+	}
+
+	merged := data.NewFrame(first.Name)
+	merged.RefID = first.RefID
+	merged.Meta = mergeMeta(frames)
+	for i, f := range first.Fields {
+		field := data.NewFieldFromFieldType(types[i], 0)
+		field.Name = f.Name
+		field.Labels = f.Labels
+		field.Config = f.Config
+		for _, frame := range frames {
+			src := frame.Fields[i]
+			if src.Type() != types[i] {
+				field.Extend(src.Len())
+				continue
+			}
+			for j := 0; j < src.Len(); j++ {
+				field.Append(src.At(j))
+			}
+		}
+		merged.Fields = append(merged.Fields, field)
+	}
+	return []*data.Frame{merged}
+}
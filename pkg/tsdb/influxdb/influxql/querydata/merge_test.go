@@ -0,0 +1,86 @@
+package querydata
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+func TestMergeResponses(t *testing.T) {
+	query := &models.Query{RawQuery: "q1;\nq2"}
+	first := ResponseParse(io.NopCloser(strings.NewReader(`{"results":[{"series":[
+		{"name":"cpu","tags":{"host":"a"},"columns":["time","usage","idle"],"values":[[1000,1,9],[2000,2,8]]},
+		{"name":"cpu","tags":{"host":"b"},"columns":["time","usage","idle"],"values":[[1000,3,7]]}
+	]}]}`)), 200, query)
+	second := ResponseParse(io.NopCloser(strings.NewReader(`{"results":[{"series":[
+		{"name":"cpu","tags":{"host":"a"},"columns":["time","usage","idle"],"values":[[3000,4,6]]},
+		{"name":"cpu","tags":{"host":"c"},"columns":["time","usage","idle"],"values":[[3000,5,5]]}
+	]}]}`)), 200, query)
+	require.NoError(t, first.Error)
+	require.NoError(t, second.Error)
+
+	merged := MergeResponses([]*backend.DataResponse{first, second}, query)
+	require.Len(t, merged.Frames, 6)
+	require.Equal(t, "q1;\nq2", merged.Frames[0].Meta.ExecutedQueryString)
+
+	usageA := merged.Frames[0]
+	require.Equal(t, "a", usageA.Fields[1].Labels["host"])
+	require.Equal(t, 3, usageA.Rows())
+	require.Equal(t, time.UnixMilli(3000).UTC(), usageA.Fields[0].At(2).(time.Time).UTC())
+	require.Equal(t, 4.0, *usageA.Fields[1].At(2).(*float64))
+
+	// The time field is shared by the frames of a series in the parsed
+	// response, merging must not append to it twice.
+	idleA := merged.Frames[1]
+	require.Equal(t, 3, idleA.Rows())
+	require.Equal(t, 6.0, *idleA.Fields[1].At(2).(*float64))
+
+	usageB := merged.Frames[2]
+	require.Equal(t, "b", usageB.Fields[1].Labels["host"])
+	require.Equal(t, 1, usageB.Rows())
+
+	usageC := merged.Frames[4]
+	require.Equal(t, "c", usageC.Fields[1].Labels["host"])
+	require.Equal(t, 1, usageC.Rows())
+}
+
+func TestMergeResponsesWithNullFields(t *testing.T) {
+	query := &models.Query{}
+	first := ResponseParse(io.NopCloser(strings.NewReader(`{"results":[{"series":[
+		{"name":"cpu","columns":["time","usage"],"values":[[1000,null]]}
+	]}]}`)), 200, query)
+	second := ResponseParse(io.NopCloser(strings.NewReader(`{"results":[{"series":[
+		{"name":"cpu","columns":["time","usage"],"values":[[2000,2]]}
+	]}]}`)), 200, query)
+
+	merged := MergeResponses([]*backend.DataResponse{first, second}, query)
+	require.Len(t, merged.Frames, 1)
+	require.Equal(t, 2, merged.Frames[0].Rows())
+	require.Nil(t, merged.Frames[0].Fields[1].At(0))
+	require.Equal(t, 2.0, *merged.Frames[0].Fields[1].At(1).(*float64))
+}
+
+func TestMergeResponsesKeepsMeta(t *testing.T) {
+	query := &models.Query{RawQuery: "q1;\nq2"}
+	notice := data.Notice{Severity: data.NoticeSeverityWarning, Text: "partial data"}
+	first := &backend.DataResponse{Frames: data.Frames{
+		data.NewFrame("cpu", data.NewField("time", nil, []time.Time{time.UnixMilli(1000)})).
+			SetMeta(&data.FrameMeta{ExecutedQueryString: "q1", Notices: []data.Notice{notice}}),
+	}}
+	second := &backend.DataResponse{Frames: data.Frames{
+		data.NewFrame("cpu", data.NewField("time", nil, []time.Time{time.UnixMilli(2000)})).
+			SetMeta(&data.FrameMeta{ExecutedQueryString: "q2", Notices: []data.Notice{notice, {Text: "other"}}}),
+	}}
+
+	merged := MergeResponses([]*backend.DataResponse{first, second}, query)
+	require.Len(t, merged.Frames, 1)
+	require.Equal(t, "q1;\nq2", merged.Frames[0].Meta.ExecutedQueryString)
+	require.Equal(t, []data.Notice{notice, {Text: "other"}}, merged.Frames[0].Meta.Notices)
+}
@@ -343,6 +343,9 @@ func transformToExemplars(frames data.Frames) []models.Exemplar {
 	var exemplars []models.Exemplar
 
 	for _, frame := range frames {
+		if len(frame.Fields) < 2 {
+			continue
+		}
 		// Assuming that the frame's first field is time and the second field is value
 		timeField := frame.Fields[0]
 		valueField := frame.Fields[1]
@@ -351,19 +354,27 @@ func transformToExemplars(frames data.Frames) []models.Exemplar {
 		seriesLabels := valueField.Labels
 
 		for i := 0; i < frame.Rows(); i++ {
-			timestamp, ok := timeField.At(i).(time.Time) // Cast to time.Time, ensure this is correct
+			timestamp, ok := timeField.At(i).(time.Time)
 			if !ok {
-				continue // or handle the error
+				continue
 			}
 
-			value, ok := valueField.At(i).(float64) // Cast to float64, ensure this is correct
-			if !ok {
-				continue // or handle the error
+			var value float64
+			switch v := valueField.At(i).(type) {
+			case float64:
+				value = v
+			case *float64:
+				if v == nil {
+					continue
+				}
+				value = *v
+			default:
+				continue
 			}
 
 			exemplar := models.Exemplar{
-				SeriesLabels: seriesLabels, // Use the labels we got from the frame field
-				Fields:       frame.Fields, // You can include all fields from the frame or filter as needed
+				SeriesLabels: seriesLabels,
+				Fields:       frame.Fields,
 				RowIdx:       i,
 				Value:        value,
 				Timestamp:    timestamp,
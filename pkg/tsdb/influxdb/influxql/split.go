@@ -0,0 +1,223 @@
+package influxql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql/querydata"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+const (
+	defaultSplitConcurrency = 4
+	// maxShards bounds the number of requests a single query is split into,
+	// shards are made longer when the configured size would exceed it.
+	maxShards = 100
+)
+
+var (
+	// Queries whose results depend on the whole time range can not be split.
+	regexpLimit           = regexp.MustCompile(`(?i)\b(s?limit|s?offset)\s+\d`)
+	regexpOrderByTimeDesc = regexp.MustCompile(`(?i)\border\s+by\s+time\s+desc\b`)
+	regexpSubquery        = regexp.MustCompile(`(?i)\bfrom\s*\(`)
+	regexpGroupByTime     = regexp.MustCompile(`(?i)\bgroup\s+by\b[^;]*\btime\s*\(`)
+	// regexpGroupByTimeInterval captures the interval and the offset of the
+	// buckets of a GROUP BY time() clause.
+	regexpGroupByTimeInterval = regexp.MustCompile(`(?i)\bgroup\s+by\b[^;]*?\btime\s*\(\s*([^,)\s]*)\s*(,[^)]*)?\)`)
+	regexpAggregation         = regexp.MustCompile(`(?i)\b(count|distinct|integral|mean|median|mode|spread|stddev|sum|bottom|first|last|max|min|percentile|sample|top)\s*\(`)
+	// Transformations which use the points before the start of a shard.
+	regexpTransformation = regexp.MustCompile(`(?i)\b(derivative|non_negative_derivative|difference|non_negative_difference|moving_average|cumulative_sum|elapsed|holt_winters|holt_winters_with_fit)\s*\(`)
+	// Fills which carry values of the buckets before the start of a shard.
+	regexpFillCarry = regexp.MustCompile(`(?i)\bfill\s*\(\s*(previous|linear)\s*\)`)
+)
+
+// shardDuration returns the length of the shards the time range of query is
+// split into, or 0 when the query is not split. builtQuery is the query as it
+// would be sent without splitting.
+//
+// The length is either configured directly or estimated from the maximum
+// number of points per shard, assuming MaxSeries series with one point per
+// interval. It is rounded up to a multiple of the GROUP BY time() intervals of
+// the query, or of the query interval when it has none, so that no bucket
+// straddles two shards.
+func shardDuration(dsInfo *models.DatasourceInfo, query *models.Query, builtQuery string) (time.Duration, error) {
+	var shard time.Duration
+	if dsInfo.QuerySplitDuration != "" {
+		d, err := gtime.ParseDuration(dsInfo.QuerySplitDuration)
+		if err != nil {
+			return 0, fmt.Errorf("invalid query split duration: %w", err)
+		}
+		shard = d
+	}
+	if dsInfo.QuerySplitMaxPoints > 0 && dsInfo.MaxSeries > 0 {
+		points := dsInfo.QuerySplitMaxPoints / dsInfo.MaxSeries
+		if points < 1 {
+			points = 1
+		}
+		if d := time.Duration(points) * query.Interval; shard == 0 || d < shard {
+			shard = d
+		}
+	}
+	if shard <= 0 {
+		return 0, nil
+	}
+
+	align := query.Interval
+	if intervals, ok := groupByTimeIntervals(builtQuery); ok && len(intervals) > 0 {
+		align = intervals[0]
+		for _, interval := range intervals[1:] {
+			align = lcm(align, interval)
+		}
+	}
+	if align <= 0 {
+		return shard, nil
+	}
+	if rem := shard % align; rem != 0 {
+		shard += align - rem
+	}
+	return shard, nil
+}
+
+// groupByTimeIntervals returns the intervals of the GROUP BY time() clauses of
+// builtQuery. ok is false when an interval can not be parsed or the buckets
+// are offset, the shards can not be aligned to the buckets then.
+func groupByTimeIntervals(builtQuery string) ([]time.Duration, bool) {
+	matches := regexpGroupByTimeInterval.FindAllStringSubmatch(builtQuery, -1)
+	if len(matches) != len(regexpGroupByTime.FindAllStringIndex(builtQuery, -1)) {
+		return nil, false
+	}
+	intervals := make([]time.Duration, 0, len(matches))
+	for _, m := range matches {
+		if m[2] != "" {
+			return nil, false
+		}
+		d, err := gtime.ParseDuration(m[1])
+		if err != nil || d <= 0 {
+			return nil, false
+		}
+		intervals = append(intervals, d)
+	}
+	return intervals, true
+}
+
+func lcm(a, b time.Duration) time.Duration {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
+
+// isSplittable reports whether the results of query can be computed shard by
+// shard and concatenated. builtQuery is the query as it would be sent without
+// splitting.
+func isSplittable(query *models.Query, builtQuery string) bool {
+	if query.UseRawQuery && query.RawQuery != "" && !strings.Contains(query.RawQuery, "$timeFilter") {
+		return false
+	}
+	if query.Limit != "" || query.Slimit != "" || strings.EqualFold(query.OrderByTime, "desc") {
+		return false
+	}
+	if regexpLimit.MatchString(builtQuery) || regexpOrderByTimeDesc.MatchString(builtQuery) ||
+		regexpSubquery.MatchString(builtQuery) || regexpTransformation.MatchString(builtQuery) ||
+		regexpFillCarry.MatchString(builtQuery) {
+		return false
+	}
+	if !regexpGroupByTime.MatchString(builtQuery) {
+		return !regexpAggregation.MatchString(builtQuery)
+	}
+	_, ok := groupByTimeIntervals(builtQuery)
+	return ok
+}
+
+// splitTimeRange splits tr in consecutive ranges which end at the multiples
+// of shard since the Unix epoch, like the GROUP BY time buckets of InfluxDB.
+// Shards are made a multiple of shard longer when there would be more than
+// maxShards of them.
+func splitTimeRange(tr backend.TimeRange, shard time.Duration) []backend.TimeRange {
+	if span := tr.To.Sub(tr.From); span > shard*maxShards {
+		shard *= (span + shard*maxShards - 1) / (shard * maxShards)
+	}
+
+	var ranges []backend.TimeRange
+	from := tr.From
+	for from.Before(tr.To) {
+		to := time.Unix(0, (from.UnixNano()/int64(shard)+1)*int64(shard))
+		if !to.Before(tr.To) {
+			to = tr.To
+		}
+		ranges = append(ranges, backend.TimeRange{From: from, To: to})
+		from = to
+	}
+	if len(ranges) == 0 {
+		ranges = append(ranges, tr)
+	}
+	return ranges
+}
+
+// buildShardQueries builds one query per range. Only the last range includes
+// its end, so the points at the shard boundaries are returned once.
+func buildShardQueries(req *backend.QueryDataRequest, query *models.Query, ranges []backend.TimeRange) ([]*models.Query, error) {
+	shardQueries := make([]*models.Query, len(ranges))
+	for i, tr := range ranges {
+		rawQuery, err := query.BuildRange(req, tr, i == len(ranges)-1)
+		if err != nil {
+			return nil, err
+		}
+		shardQuery := *query
+		shardQuery.RawQuery = rawQuery
+		shardQueries[i] = &shardQuery
+	}
+	return shardQueries, nil
+}
+
+// executeSplit runs the queries of the shards of a query, with at most
+// QuerySplitConcurrency requests at a time, and merges their results.
+func executeSplit(ctx context.Context, tracer trace.Tracer, dsInfo *models.DatasourceInfo, logger log.Logger, query *models.Query, shardQueries []*models.Query, isStreamingParserEnabled bool) (backend.DataResponse, error) {
+	concurrency := dsInfo.QuerySplitConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultSplitConcurrency
+	}
+
+	responses := make([]*backend.DataResponse, len(shardQueries))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for i, shardQuery := range shardQueries {
+		i, shardQuery := i, shardQuery
+		g.Go(func() error {
+			request, err := createRequest(gctx, logger, dsInfo, shardQuery.RawQuery, shardQuery.Policy)
+			if err != nil {
+				return err
+			}
+			resp, err := execute(gctx, tracer, dsInfo, logger, shardQuery, request, isStreamingParserEnabled)
+			if err != nil {
+				return err
+			}
+			if resp.Error != nil {
+				return resp.Error
+			}
+			responses[i] = &resp
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return backend.DataResponse{}, err
+	}
+
+	rawQueries := make([]string, len(shardQueries))
+	for i, shardQuery := range shardQueries {
+		rawQueries[i] = shardQuery.RawQuery
+	}
+	query.RawQuery = strings.Join(rawQueries, ";\n")
+
+	return *querydata.MergeResponses(responses, query), nil
+}
@@ -0,0 +1,170 @@
+package influxql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+func TestShardDuration(t *testing.T) {
+	query := &models.Query{Interval: time.Minute}
+
+	shard, err := shardDuration(&models.DatasourceInfo{}, query, "")
+	require.NoError(t, err)
+	require.Zero(t, shard)
+
+	shard, err = shardDuration(&models.DatasourceInfo{QuerySplitDuration: "1d"}, query, "")
+	require.NoError(t, err)
+	require.Equal(t, 24*time.Hour, shard)
+
+	// 10000 points for 100 series is 100 intervals per shard
+	shard, err = shardDuration(&models.DatasourceInfo{QuerySplitDuration: "1d", QuerySplitMaxPoints: 10000, MaxSeries: 100}, query, "")
+	require.NoError(t, err)
+	require.Equal(t, 100*time.Minute, shard)
+
+	shard, err = shardDuration(&models.DatasourceInfo{QuerySplitDuration: "90s"}, query, "")
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Minute, shard)
+
+	_, err = shardDuration(&models.DatasourceInfo{QuerySplitDuration: "one day"}, query, "")
+	require.Error(t, err)
+
+	// shards are aligned to the GROUP BY time() buckets
+	shard, err = shardDuration(&models.DatasourceInfo{QuerySplitDuration: "85m"}, query, `SELECT mean("value") FROM "cpu" WHERE time > now() - 1d GROUP BY time(1h)`)
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Hour, shard)
+
+	shard, err = shardDuration(&models.DatasourceInfo{QuerySplitDuration: "85m"}, query, `SELECT mean("value") FROM "cpu" WHERE time > now() - 1d GROUP BY time(40m); SELECT max("value") FROM "mem" WHERE time > now() - 1d GROUP BY time(1h)`)
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Hour, shard)
+}
+
+func TestIsSplittable(t *testing.T) {
+	tests := map[string]bool{
+		`SELECT "value" FROM "cpu" WHERE $timeFilter`:                                        true,
+		`SELECT mean("value") FROM "cpu" WHERE $timeFilter GROUP BY time(1m), "host"`:        true,
+		`SELECT mean("value") FROM "cpu" WHERE $timeFilter GROUP BY time(1h, 15m)`:           false,
+		`SELECT mean("value") FROM "cpu" WHERE $timeFilter`:                                  false,
+		`SELECT "value" FROM "cpu"`:                                                          false,
+		`SELECT "value" FROM "cpu" WHERE $timeFilter LIMIT 10`:                               false,
+		`SELECT "value" FROM "cpu" WHERE $timeFilter ORDER BY time DESC`:                     false,
+		`SELECT derivative("value") FROM "cpu" WHERE $timeFilter`:                            false,
+		`SELECT "max" FROM (SELECT max("value") FROM "cpu" WHERE $timeFilter)`:               false,
+		`SELECT mean("value") FROM "cpu" WHERE $timeFilter GROUP BY time(1m) fill(null)`:     true,
+		`SELECT mean("value") FROM "cpu" WHERE $timeFilter GROUP BY time(1m) fill(previous)`: false,
+		`SELECT mean("value") FROM "cpu" WHERE $timeFilter GROUP BY time(1m) fill(linear)`:   false,
+	}
+	for rawQuery, expected := range tests {
+		query := &models.Query{UseRawQuery: true, RawQuery: rawQuery}
+		require.Equal(t, expected, isSplittable(query, rawQuery), rawQuery)
+	}
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	t.Run("splits at multiples of the shard duration", func(t *testing.T) {
+		tr := backend.TimeRange{
+			From: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+			To:   time.Date(2023, 1, 3, 6, 0, 0, 0, time.UTC),
+		}
+		ranges := splitTimeRange(tr, 24*time.Hour)
+		require.Equal(t, []backend.TimeRange{
+			{From: tr.From, To: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{From: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), To: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)},
+			{From: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), To: tr.To},
+		}, toUTC(ranges))
+	})
+
+	t.Run("limits the number of shards", func(t *testing.T) {
+		tr := backend.TimeRange{
+			From: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		ranges := splitTimeRange(tr, time.Hour)
+		require.LessOrEqual(t, len(ranges), maxShards)
+		require.Equal(t, tr.From, ranges[0].From)
+		require.Equal(t, tr.To, ranges[len(ranges)-1].To)
+		for i := 1; i < len(ranges); i++ {
+			require.Equal(t, ranges[i-1].To, ranges[i].From)
+			require.Zero(t, ranges[i].From.UnixNano()%int64(time.Hour))
+		}
+	})
+}
+
+func TestQuerySplitsLongTimeRanges(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		mu.Lock()
+		queries = append(queries, q)
+		mu.Unlock()
+
+		var body string
+		switch q {
+		case `SELECT "value" FROM "cpu" WHERE time >= 1672531200000ms and time < 1672617600000ms`:
+			body = `{"results":[{"series":[{"name":"cpu","columns":["time","value"],"values":[[1672531200000,1]]}]}]}`
+		case `SELECT "value" FROM "cpu" WHERE time >= 1672617600000ms and time <= 1672704000000ms`:
+			body = `{"results":[{"series":[{"name":"cpu","columns":["time","value"],"values":[[1672617600000,2],[1672704000000,3]]}]}]}`
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+			body = `{"error":"unexpected query"}`
+		}
+		_, _ = rw.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dsInfo := &models.DatasourceInfo{
+		HTTPClient:         srv.Client(),
+		URL:                srv.URL,
+		HTTPMode:           "GET",
+		MaxSeries:          1000,
+		QuerySplitDuration: "1d",
+	}
+	req := &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{
+				RefID:    "A",
+				Interval: time.Minute,
+				TimeRange: backend.TimeRange{
+					From: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+					To:   time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+				},
+				JSON: []byte(`{"rawQuery": true, "query": "SELECT \"value\" FROM \"cpu\" WHERE $timeFilter"}`),
+			},
+		},
+	}
+
+	for _, streaming := range []bool{false, true} {
+		queries = nil
+		features := featuremgmt.WithFeatures(featuremgmt.FlagInfluxqlStreamingParser, streaming)
+		resp, err := Query(context.Background(), tracing.InitializeTracerForTest(), dsInfo, req, features)
+		require.NoError(t, err)
+
+		respA := resp.Responses["A"]
+		require.NoError(t, respA.Error)
+		require.Len(t, queries, 2)
+		require.Len(t, respA.Frames, 1)
+		require.Equal(t, 3, respA.Frames[0].Rows())
+		for i, expected := range []float64{1, 2, 3} {
+			require.Equal(t, expected, *respA.Frames[0].Fields[1].At(i).(*float64))
+		}
+	}
+}
+
+func toUTC(ranges []backend.TimeRange) []backend.TimeRange {
+	for i := range ranges {
+		ranges[i].From = ranges[i].From.UTC()
+		ranges[i].To = ranges[i].To.UTC()
+	}
+	return ranges
+}
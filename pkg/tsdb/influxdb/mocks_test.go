@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -87,9 +88,14 @@ func (f *fakeInstance) Do(_ context.Context, _ backend.PluginContext, _ instance
 type RoundTripper struct {
 	Body     string
 	FileName string // filename (relative path of where it is being called)
+	// OnRequest is called with every request when it is set.
+	OnRequest func(req *http.Request)
 }
 
 func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.OnRequest != nil {
+		rt.OnRequest(req)
+	}
 	res := &http.Response{
 		StatusCode: http.StatusOK,
 		Status:     "200 OK",
@@ -113,7 +119,7 @@ func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 func GetMockService(version string, rt RoundTripper) *Service {
-	return &Service{
+	s := &Service{
 		im: &fakeInstance{
 			version:          version,
 			fakeRoundTripper: rt,
@@ -123,7 +129,11 @@ func GetMockService(version string, rt RoundTripper) *Service {
 				featuremgmt.FlagInfluxqlStreamingParser: false,
 			},
 		},
+		schemaCache: newSchemaCache(schemaCacheTTL),
 	}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+
+	return s
 }
 
 type fakeFeatureToggles struct {
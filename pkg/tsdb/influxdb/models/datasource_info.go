@@ -23,6 +23,12 @@ type DatasourceInfo struct {
 	Organization  string `json:"organization"`
 	MaxSeries     int    `json:"maxSeries"`
 
+	// InfluxQL query splitting, disabled unless a shard duration or a
+	// maximum number of points per shard is set
+	QuerySplitDuration    string `json:"querySplitDuration"`
+	QuerySplitMaxPoints   int    `json:"querySplitMaxPoints"`
+	QuerySplitConcurrency int    `json:"querySplitConcurrency"`
+
 	// Flight SQL metadata
 	Metadata []map[string]string `json:"metadata"`
 	// FlightSQL grpc connection
@@ -30,4 +36,7 @@ type DatasourceInfo struct {
 
 	// Exemplar settings
 	ExemplarTraceIdDestinations []ExemplarSetting `json:"exemplarTraceIdDestinations"`
+	// ExemplarSampler is the name of the sampler which reduces the exemplars
+	// of a query, all exemplars are returned when it is empty
+	ExemplarSampler string `json:"exemplarSampler"`
 }
@@ -28,6 +28,7 @@ func QueryParse(query backend.DataQuery) (*Query, error) {
 	orderByTime := model.Get("orderByTime").MustString("")
 	measurement := model.Get("measurement").MustString("")
 	resultFormat := model.Get("resultFormat").MustString("")
+	exemplar := model.Get("exemplar").MustBool(false)
 
 	tags, err := parseTags(model)
 	if err != nil {
@@ -68,6 +69,7 @@ func QueryParse(query backend.DataQuery) (*Query, error) {
 		Slimit:       slimit,
 		OrderByTime:  orderByTime,
 		ResultFormat: resultFormat,
+		Exemplar:     exemplar,
 	}, nil
 }
 
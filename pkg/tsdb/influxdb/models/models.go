@@ -22,6 +22,9 @@ type Query struct {
 	OrderByTime  string
 	RefID        string
 	ResultFormat string
+	// Exemplar returns the points of the query as a single exemplar frame,
+	// reduced by the exemplar sampler of the datasource.
+	Exemplar bool
 }
 
 type Tag struct {
@@ -18,6 +18,13 @@ var (
 )
 
 func (query *Query) Build(queryContext *backend.QueryDataRequest) (string, error) {
+	return query.BuildRange(queryContext, queryContext.Queries[0].TimeRange, true)
+}
+
+// BuildRange builds the query for the time range tr instead of the time range
+// of the request. The end of tr is excluded unless includeTo is set, so that
+// adjacent ranges do not both return the points at their boundary.
+func (query *Query) BuildRange(queryContext *backend.QueryDataRequest, tr backend.TimeRange, includeTo bool) (string, error) {
 	var res string
 	if query.UseRawQuery && query.RawQuery != "" {
 		res = query.RawQuery
@@ -25,7 +32,7 @@ func (query *Query) Build(queryContext *backend.QueryDataRequest) (string, error
 		res = query.renderSelectors(queryContext)
 		res += query.renderMeasurement()
 		res += query.renderWhereClause()
-		res += query.renderTimeFilter(queryContext)
+		res += query.renderTimeRangeFilter(tr, includeTo)
 		res += query.renderGroupBy(queryContext)
 		res += query.renderOrderByTime()
 		res += query.renderLimit()
@@ -36,7 +43,7 @@ func (query *Query) Build(queryContext *backend.QueryDataRequest) (string, error
 	intervalText := intervalv2.FormatDuration(query.Interval)
 	intervalMs := int64(query.Interval / time.Millisecond)
 
-	res = strings.ReplaceAll(res, "$timeFilter", query.renderTimeFilter(queryContext))
+	res = strings.ReplaceAll(res, "$timeFilter", query.renderTimeRangeFilter(tr, includeTo))
 	res = strings.ReplaceAll(res, "$interval", intervalText)
 	res = strings.ReplaceAll(res, "$__interval_ms", strconv.FormatInt(intervalMs, 10))
 	res = strings.ReplaceAll(res, "$__interval", intervalText)
@@ -136,7 +143,14 @@ func (query *Query) renderTags() []string {
 }
 
 func (query *Query) renderTimeFilter(queryContext *backend.QueryDataRequest) string {
-	from, to := epochMStoInfluxTime(&queryContext.Queries[0].TimeRange)
+	return query.renderTimeRangeFilter(queryContext.Queries[0].TimeRange, true)
+}
+
+func (query *Query) renderTimeRangeFilter(tr backend.TimeRange, includeTo bool) string {
+	from, to := epochMStoInfluxTime(&tr)
+	if !includeTo {
+		return fmt.Sprintf("time >= %s and time < %s", from, to)
+	}
 	return fmt.Sprintf("time >= %s and time <= %s", from, to)
 }
 
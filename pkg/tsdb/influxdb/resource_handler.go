@@ -0,0 +1,427 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/flux"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/fsql"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+const (
+	schemaRefID    = "schema"
+	schemaCacheTTL = time.Minute
+	// schemaTagValuesLimit bounds the number of tag values returned by SQL
+	// lookups, which read them from the data instead of an index.
+	schemaTagValuesLimit = 1000
+	// schemaTagValuesLookback is the time range of the data SQL tag values
+	// are read from.
+	schemaTagValuesLookback = 24 * time.Hour
+)
+
+var errMissingParameter = errors.New("missing required parameter")
+
+// schemaLookup returns the values of one kind of schema object (buckets,
+// measurements, tag keys...) for the datasource.
+type schemaLookup func(ctx context.Context, s *Service, dsInfo *models.DatasourceInfo, params url.Values) ([]string, error)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/buckets", s.handleSchemaReq(lookupBuckets))
+	mux.HandleFunc("/measurements", s.handleSchemaReq(lookupMeasurements))
+	mux.HandleFunc("/tag-keys", s.handleSchemaReq(lookupTagKeys))
+	mux.HandleFunc("/tag-values", s.handleSchemaReq(lookupTagValues))
+	mux.HandleFunc("/field-keys", s.handleSchemaReq(lookupFieldKeys))
+	return mux
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return s.resourceHandler.CallResource(ctx, req, sender)
+}
+
+func (s *Service) handleSchemaReq(lookup schemaLookup) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logger := logger.FromContext(ctx)
+
+		if req.Method != http.MethodGet {
+			writeResponse(rw, http.StatusMethodNotAllowed, fmt.Sprintf("invalid HTTP method: %s", req.Method))
+			return
+		}
+
+		pluginContext := httpadapter.PluginConfigFromContext(ctx)
+		dsInfo, err := s.getDSInfo(ctx, pluginContext)
+		if err != nil {
+			logger.Error("Failed to get data source info", "error", err)
+			writeResponse(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		params := req.URL.Query()
+		key := schemaCacheKey(pluginContext, req.URL)
+		values, ok := s.schemaCache.get(key)
+		if !ok {
+			values, err = lookup(ctx, s, dsInfo, params)
+			if err != nil {
+				if errors.Is(err, errMissingParameter) {
+					writeResponse(rw, http.StatusBadRequest, err.Error())
+					return
+				}
+				logger.Error("Failed to look up schema", "error", err, "path", req.URL.Path, "version", dsInfo.Version)
+				writeResponse(rw, http.StatusInternalServerError, err.Error())
+				return
+			}
+			s.schemaCache.set(key, values)
+		}
+
+		body, err := json.Marshal(values)
+		if err != nil {
+			writeResponse(rw, http.StatusInternalServerError, err.Error())
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		writeResponseBytes(rw, http.StatusOK, body)
+	}
+}
+
+func lookupBuckets(ctx context.Context, s *Service, dsInfo *models.DatasourceInfo, _ url.Values) ([]string, error) {
+	switch dsInfo.Version {
+	case influxVersionFlux:
+		return runFluxSchemaQuery(ctx, dsInfo, `buckets()`, "name")
+	case influxVersionInfluxQL:
+		// Lookups are bound to the configured database, other databases of
+		// the server are not exposed.
+		if dsInfo.DbName != "" {
+			return []string{dsInfo.DbName}, nil
+		}
+		return []string{}, nil
+	case influxVersionSQL:
+		// A FlightSQL connection is bound to a single database, which is
+		// configured through the metadata sent with every request.
+		for _, md := range dsInfo.Metadata {
+			for _, k := range []string{"database", "bucket-name", "bucket"} {
+				if v := md[k]; v != "" {
+					return []string{v}, nil
+				}
+			}
+		}
+		if dsInfo.DbName != "" {
+			return []string{dsInfo.DbName}, nil
+		}
+		return []string{}, nil
+	default:
+		return nil, fmt.Errorf("unknown influxdb version")
+	}
+}
+
+func lookupMeasurements(ctx context.Context, s *Service, dsInfo *models.DatasourceInfo, params url.Values) ([]string, error) {
+	bucket := params.Get("bucket")
+	switch dsInfo.Version {
+	case influxVersionFlux:
+		q := fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurements(bucket: %s)", flux.StringLiteral(fluxBucket(dsInfo, bucket)))
+		return runFluxSchemaQuery(ctx, dsInfo, q, "_value")
+	case influxVersionInfluxQL:
+		return runInfluxQLSchemaQuery(ctx, s, dsInfo, `SHOW MEASUREMENTS`, "name")
+	case influxVersionSQL:
+		q := `SELECT DISTINCT table_name FROM information_schema.columns WHERE table_schema = 'iox' ORDER BY table_name`
+		return runSQLSchemaQuery(ctx, dsInfo, q, "table_name")
+	default:
+		return nil, fmt.Errorf("unknown influxdb version")
+	}
+}
+
+func lookupTagKeys(ctx context.Context, s *Service, dsInfo *models.DatasourceInfo, params url.Values) ([]string, error) {
+	bucket, measurement := params.Get("bucket"), params.Get("measurement")
+	if measurement == "" {
+		return nil, fmt.Errorf("%w: measurement", errMissingParameter)
+	}
+
+	switch dsInfo.Version {
+	case influxVersionFlux:
+		q := fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurementTagKeys(bucket: %s, measurement: %s)",
+			flux.StringLiteral(fluxBucket(dsInfo, bucket)), flux.StringLiteral(measurement))
+		values, err := runFluxSchemaQuery(ctx, dsInfo, q, "_value")
+		if err != nil {
+			return nil, err
+		}
+		// schema.measurementTagKeys also returns the internal columns, which
+		// are not tags users can filter on.
+		tagKeys := make([]string, 0, len(values))
+		for _, v := range values {
+			if !strings.HasPrefix(v, "_") {
+				tagKeys = append(tagKeys, v)
+			}
+		}
+		return tagKeys, nil
+	case influxVersionInfluxQL:
+		q := fmt.Sprintf(`SHOW TAG KEYS FROM %s`, influxQLIdentifier(measurement))
+		return runInfluxQLSchemaQuery(ctx, s, dsInfo, q, "tagKey")
+	case influxVersionSQL:
+		// In IOx tags are stored as dictionary encoded string columns.
+		q := fmt.Sprintf(`SELECT column_name FROM information_schema.columns WHERE table_schema = 'iox' AND table_name = %s AND data_type = 'Dictionary(Int32, Utf8)' ORDER BY column_name`,
+			sqlString(measurement))
+		return runSQLSchemaQuery(ctx, dsInfo, q, "column_name")
+	default:
+		return nil, fmt.Errorf("unknown influxdb version")
+	}
+}
+
+func lookupTagValues(ctx context.Context, s *Service, dsInfo *models.DatasourceInfo, params url.Values) ([]string, error) {
+	bucket, measurement, tagKey := params.Get("bucket"), params.Get("measurement"), params.Get("key")
+	if measurement == "" {
+		return nil, fmt.Errorf("%w: measurement", errMissingParameter)
+	}
+	if tagKey == "" {
+		return nil, fmt.Errorf("%w: key", errMissingParameter)
+	}
+
+	switch dsInfo.Version {
+	case influxVersionFlux:
+		q := fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurementTagValues(bucket: %s, measurement: %s, tag: %s)",
+			flux.StringLiteral(fluxBucket(dsInfo, bucket)), flux.StringLiteral(measurement), flux.StringLiteral(tagKey))
+		return runFluxSchemaQuery(ctx, dsInfo, q, "_value")
+	case influxVersionInfluxQL:
+		q := fmt.Sprintf(`SHOW TAG VALUES FROM %s WITH KEY = %s`, influxQLIdentifier(measurement), influxQLIdentifier(tagKey))
+		return runInfluxQLSchemaQuery(ctx, s, dsInfo, q, "value")
+	case influxVersionSQL:
+		q := fmt.Sprintf(`SELECT DISTINCT %s AS value FROM %s WHERE %s IS NOT NULL AND time >= now() - INTERVAL '%d seconds' ORDER BY value LIMIT %d`,
+			sqlIdentifier(tagKey), sqlIdentifier(measurement), sqlIdentifier(tagKey), int(schemaTagValuesLookback.Seconds()), schemaTagValuesLimit)
+		return runSQLSchemaQuery(ctx, dsInfo, q, "value")
+	default:
+		return nil, fmt.Errorf("unknown influxdb version")
+	}
+}
+
+func lookupFieldKeys(ctx context.Context, s *Service, dsInfo *models.DatasourceInfo, params url.Values) ([]string, error) {
+	bucket, measurement := params.Get("bucket"), params.Get("measurement")
+	if measurement == "" {
+		return nil, fmt.Errorf("%w: measurement", errMissingParameter)
+	}
+
+	switch dsInfo.Version {
+	case influxVersionFlux:
+		q := fmt.Sprintf("import \"influxdata/influxdb/schema\"\nschema.measurementFieldKeys(bucket: %s, measurement: %s)",
+			flux.StringLiteral(fluxBucket(dsInfo, bucket)), flux.StringLiteral(measurement))
+		return runFluxSchemaQuery(ctx, dsInfo, q, "_value")
+	case influxVersionInfluxQL:
+		q := fmt.Sprintf(`SHOW FIELD KEYS FROM %s`, influxQLIdentifier(measurement))
+		return runInfluxQLSchemaQuery(ctx, s, dsInfo, q, "fieldKey")
+	case influxVersionSQL:
+		q := fmt.Sprintf(`SELECT column_name FROM information_schema.columns WHERE table_schema = 'iox' AND table_name = %s AND data_type <> 'Dictionary(Int32, Utf8)' AND column_name <> 'time' ORDER BY column_name`,
+			sqlString(measurement))
+		return runSQLSchemaQuery(ctx, dsInfo, q, "column_name")
+	default:
+		return nil, fmt.Errorf("unknown influxdb version")
+	}
+}
+
+func runFluxSchemaQuery(ctx context.Context, dsInfo *models.DatasourceInfo, query string, column string) ([]string, error) {
+	q, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := flux.Query(ctx, dsInfo, backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{
+				RefID: schemaRefID,
+				JSON:  q,
+				TimeRange: backend.TimeRange{
+					From: time.Now().AddDate(0, 0, -1),
+					To:   time.Now(),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return valuesFromResponse(resp, column)
+}
+
+// runInfluxQLSchemaQuery runs query against the configured database, the
+// bucket parameter of the request is ignored.
+func runInfluxQLSchemaQuery(ctx context.Context, s *Service, dsInfo *models.DatasourceInfo, query string, column string) ([]string, error) {
+	q, err := json.Marshal(map[string]any{"query": query, "rawQuery": true, "resultFormat": "table"})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := influxql.Query(ctx, tracing.DefaultTracer(), dsInfo, &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{
+				RefID: schemaRefID,
+				JSON:  q,
+			},
+		},
+	}, s.features)
+	if err != nil {
+		return nil, err
+	}
+	return valuesFromResponse(resp, column)
+}
+
+func runSQLSchemaQuery(ctx context.Context, dsInfo *models.DatasourceInfo, query string, column string) ([]string, error) {
+	q, err := json.Marshal(map[string]string{"rawSql": query, "format": "table"})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := fsql.Query(ctx, dsInfo, backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{
+				RefID: schemaRefID,
+				JSON:  q,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return valuesFromResponse(resp, column)
+}
+
+// valuesFromResponse collects the distinct, sorted values of the named column
+// across all the frames of the schema query response.
+func valuesFromResponse(resp *backend.QueryDataResponse, column string) ([]string, error) {
+	res, ok := resp.Responses[schemaRefID]
+	if !ok {
+		return nil, fmt.Errorf("no response for schema query")
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	seen := make(map[string]struct{})
+	values := make([]string, 0)
+	for _, frame := range res.Frames {
+		field, _ := frame.FieldByName(column)
+		if field == nil {
+			continue
+		}
+		for i := 0; i < field.Len(); i++ {
+			v, ok := field.ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			str := fmt.Sprintf("%v", v)
+			if _, exists := seen[str]; exists {
+				continue
+			}
+			seen[str] = struct{}{}
+			values = append(values, str)
+		}
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+func fluxBucket(dsInfo *models.DatasourceInfo, bucket string) string {
+	if bucket != "" {
+		return bucket
+	}
+	return dsInfo.DefaultBucket
+}
+
+var influxQLIdentifierReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func influxQLIdentifier(s string) string {
+	return `"` + influxQLIdentifierReplacer.Replace(s) + `"`
+}
+
+func sqlIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func sqlString(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
+
+// schemaCacheKey identifies a lookup. The schema visible to a user can
+// depend on their identity when their credentials are forwarded to the
+// datasource, so the user is part of the key.
+func schemaCacheKey(pluginCtx backend.PluginContext, u *url.URL) string {
+	var uid string
+	var updated int64
+	if ds := pluginCtx.DataSourceInstanceSettings; ds != nil {
+		uid = ds.UID
+		updated = ds.Updated.UnixNano()
+	}
+	var login string
+	if pluginCtx.User != nil {
+		login = pluginCtx.User.Login
+	}
+	return fmt.Sprintf("%d/%s/%d/%s%s?%s", pluginCtx.OrgID, uid, updated, url.PathEscape(login), u.Path, u.Query().Encode())
+}
+
+type schemaCacheEntry struct {
+	values  []string
+	expires time.Time
+}
+
+// schemaCache keeps schema lookups for a short while, as the editors tend to
+// request the same values over and over while a query is being built.
+type schemaCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]schemaCacheEntry
+}
+
+func newSchemaCache(ttl time.Duration) *schemaCache {
+	return &schemaCache{
+		ttl:     ttl,
+		entries: make(map[string]schemaCacheEntry),
+	}
+}
+
+func (c *schemaCache) get(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.values, true
+}
+
+func (c *schemaCache) set(key string, values []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	// drop the expired entries so the cache does not grow unbounded
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = schemaCacheEntry{values: values, expires: now.Add(c.ttl)}
+}
+
+func writeResponseBytes(rw http.ResponseWriter, code int, msg []byte) {
+	rw.WriteHeader(code)
+	_, err := rw.Write(msg)
+	if err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeResponse(rw http.ResponseWriter, code int, msg string) {
+	writeResponseBytes(rw, code, []byte(msg))
+}
@@ -0,0 +1,159 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func callSchemaResource(t *testing.T, s *Service, resourceURL string) *backend.CallResourceResponse {
+	t.Helper()
+	path, _, _ := strings.Cut(resourceURL, "?")
+	sender := &fakeSender{}
+	err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+		PluginContext: backend.PluginContext{
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "influx"},
+		},
+		Method: http.MethodGet,
+		Path:   path,
+		URL:    resourceURL,
+	}, sender)
+	require.NoError(t, err)
+	require.NotNil(t, sender.resp)
+	return sender.resp
+}
+
+func Test_CallResource(t *testing.T) {
+	t.Run("should list measurements for InfluxQL", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{
+			Body: `{"results": [{"series": [{"columns": ["name"],"name": "measurements","values": [["disk"],["cpu"],["mem"]]}],"statement_id": 0}]}`,
+		})
+		resp := callSchemaResource(t, s, "measurements")
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var values []string
+		require.NoError(t, json.Unmarshal(resp.Body, &values))
+		assert.Equal(t, []string{"cpu", "disk", "mem"}, values)
+	})
+
+	t.Run("should list tag values for InfluxQL", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{
+			FileName: "./influxql/testdata/show_tag_values_response.json",
+		})
+		resp := callSchemaResource(t, s, "tag-values?measurement=cpu&key=cpu")
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var values []string
+		require.NoError(t, json.Unmarshal(resp.Body, &values))
+		assert.Len(t, values, 11)
+		assert.Equal(t, "cpu-total", values[0])
+	})
+
+	t.Run("should list tag keys for Flux without internal columns", func(t *testing.T) {
+		s := GetMockService(influxVersionFlux, RoundTripper{
+			Body: `#datatype,string,long,string
+#group,false,false,false
+#default,_result,,
+,result,table,_value
+,,0,_field
+,,0,_measurement
+,,0,host
+,,0,cpu`,
+		})
+		resp := callSchemaResource(t, s, "tag-keys?measurement=cpu")
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var values []string
+		require.NoError(t, json.Unmarshal(resp.Body, &values))
+		assert.Equal(t, []string{"cpu", "host"}, values)
+	})
+
+	t.Run("should list buckets for Flux", func(t *testing.T) {
+		s := GetMockService(influxVersionFlux, RoundTripper{
+			Body: `#datatype,string,long,string,string,string,string,long
+#group,false,false,false,false,true,false,false
+#default,_result,,,,,,
+,result,table,name,id,organizationID,retentionPolicy,retentionPeriod
+,,0,_monitoring,effbe6d547e1c085,c678d3a458299f4e,,604800000000000
+,,0,_tasks,9ac37d3047b0970c,c678d3a458299f4e,,259200000000000
+,,0,mybucket,98184c45c69fc01e,c678d3a458299f4e,,0`,
+		})
+		resp := callSchemaResource(t, s, "buckets")
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var values []string
+		require.NoError(t, json.Unmarshal(resp.Body, &values))
+		assert.Equal(t, []string{"_monitoring", "_tasks", "mybucket"}, values)
+	})
+
+	t.Run("should fail when measurement is missing", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{})
+		resp := callSchemaResource(t, s, "field-keys")
+		assert.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("should only use the configured database for InfluxQL", func(t *testing.T) {
+		var databases []string
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{
+			Body: `{"results": [{"series": [{"columns": ["name"],"name": "measurements","values": [["cpu"]]}],"statement_id": 0}]}`,
+			OnRequest: func(req *http.Request) {
+				databases = append(databases, req.URL.Query().Get("db"))
+			},
+		})
+		resp := callSchemaResource(t, s, "measurements?bucket=other")
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.Equal(t, []string{"testdb"}, databases)
+
+		resp = callSchemaResource(t, s, "buckets")
+		require.Equal(t, http.StatusOK, resp.Status)
+		var values []string
+		require.NoError(t, json.Unmarshal(resp.Body, &values))
+		assert.Equal(t, []string{"testdb"}, values)
+		assert.Len(t, databases, 1)
+	})
+
+	t.Run("should cache schema lookups", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{
+			Body: `{"results": [{"series": [{"columns": ["name"],"name": "measurements","values": [["cpu"]]}],"statement_id": 0}]}`,
+		})
+		resp := callSchemaResource(t, s, "measurements")
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		s.im = &fakeInstance{version: influxVersionInfluxQL, fakeRoundTripper: RoundTripper{
+			Body: `{"results": [{"series": [{"columns": ["name"],"name": "measurements","values": [["mem"]]}],"statement_id": 0}]}`,
+		}}
+		resp = callSchemaResource(t, s, "measurements")
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var values []string
+		require.NoError(t, json.Unmarshal(resp.Body, &values))
+		assert.Equal(t, []string{"cpu"}, values)
+	})
+}
+
+func Test_schemaCacheKey(t *testing.T) {
+	u, err := url.Parse("buckets?bucket=b")
+	require.NoError(t, err)
+	ds := &backend.DataSourceInstanceSettings{UID: "influx"}
+
+	key := schemaCacheKey(backend.PluginContext{OrgID: 1, User: &backend.User{Login: "alice"}, DataSourceInstanceSettings: ds}, u)
+	assert.Equal(t, key, schemaCacheKey(backend.PluginContext{OrgID: 1, User: &backend.User{Login: "alice"}, DataSourceInstanceSettings: ds}, u))
+	assert.NotEqual(t, key, schemaCacheKey(backend.PluginContext{OrgID: 1, User: &backend.User{Login: "bob"}, DataSourceInstanceSettings: ds}, u))
+	assert.NotEqual(t, key, schemaCacheKey(backend.PluginContext{OrgID: 2, User: &backend.User{Login: "alice"}, DataSourceInstanceSettings: ds}, u))
+}
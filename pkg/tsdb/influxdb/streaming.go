@@ -0,0 +1,297 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	streamPathPrefix      = "stream/"
+	defaultStreamInterval = 5 * time.Second
+	minStreamInterval     = time.Second
+	defaultStreamLookback = 5 * time.Minute
+)
+
+// streamQuery holds the streaming options sent along with the regular
+// query model when subscribing to a channel.
+type streamQuery struct {
+	RefID          string `json:"refId"`
+	IntervalMs     int64  `json:"intervalMs"`
+	MaxDataPoints  int64  `json:"maxDataPoints"`
+	StreamInterval string `json:"streamInterval"`
+	StreamLookback string `json:"streamLookback"`
+}
+
+func parseStreamQuery(raw json.RawMessage) (*streamQuery, time.Duration, time.Duration, error) {
+	q := &streamQuery{}
+	if err := json.Unmarshal(raw, q); err != nil {
+		return nil, 0, 0, fmt.Errorf("error reading stream query: %w", err)
+	}
+	if q.RefID == "" {
+		q.RefID = "A"
+	}
+
+	interval := defaultStreamInterval
+	if q.StreamInterval != "" {
+		d, err := time.ParseDuration(q.StreamInterval)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("invalid stream interval: %w", err)
+		}
+		interval = d
+	}
+	if interval < minStreamInterval {
+		interval = minStreamInterval
+	}
+
+	lookback := defaultStreamLookback
+	if q.StreamLookback != "" {
+		d, err := time.ParseDuration(q.StreamLookback)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("invalid stream lookback: %w", err)
+		}
+		lookback = d
+	}
+
+	return q, interval, lookback, nil
+}
+
+func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	// Expect stream/${key}
+	if !strings.HasPrefix(req.Path, streamPathPrefix) {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, fmt.Errorf("expected stream in channel path")
+	}
+
+	switch dsInfo.Version {
+	case influxVersionFlux, influxVersionInfluxQL, influxVersionSQL:
+	default:
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, fmt.Errorf("unknown influxdb version")
+	}
+
+	if _, _, _, err := parseStreamQuery(req.Data); err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	return &backend.SubscribeStreamResponse{
+		Status: backend.SubscribeStreamStatusOK,
+	}, nil
+}
+
+func (s *Service) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
+
+// RunStream polls InfluxDB for the channel query. Single instance for each
+// channel, so the results are shared with all the listeners.
+//
+// Every poll only pushes the rows of a series newer than the last timestamp
+// sent for that series, so a lagging series is not cut off by the others.
+// Aggregated queries will therefore not update a partially filled last bucket.
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	logger := logger.FromContext(ctx)
+
+	query, interval, lookback, err := parseStreamQuery(req.Data)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Starting InfluxDB stream", "path", req.Path, "interval", interval, "lookback", lookback)
+
+	poller := &streamPoller{
+		s:         s,
+		pluginCtx: req.PluginContext,
+		query:     query,
+		raw:       req.Data,
+		lookback:  lookback,
+		start:     time.Now().Add(-lookback),
+		lastSeen:  map[string]time.Time{},
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		frames, err := poller.poll(ctx, time.Now())
+		if err != nil {
+			// keep the stream alive, the next poll might succeed
+			logger.Warn("InfluxDB stream query failed", "path", req.Path, "error", err)
+		}
+		for _, frame := range frames {
+			if err := sender.SendFrame(frame, data.IncludeAll); err != nil {
+				logger.Error("Failed to send stream frame", "path", req.Path, "error", err)
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Debug("Stop streaming (context canceled)", "path", req.Path)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+type streamPoller struct {
+	s         *Service
+	pluginCtx backend.PluginContext
+	query     *streamQuery
+	raw       json.RawMessage
+	lookback  time.Duration
+
+	// start is the timestamp after which the rows of a series which has not
+	// been seen yet are pushed
+	start time.Time
+	// lastSeen is the latest timestamp pushed to the subscribers, per series
+	lastSeen map[string]time.Time
+}
+
+// poll runs the query from the oldest last seen timestamp up to now and
+// returns the frames restricted to the rows that have not been sent yet.
+func (p *streamPoller) poll(ctx context.Context, now time.Time) (data.Frames, error) {
+	minFrom := now.Add(-p.lookback)
+	// series which stopped returning data don't hold the range back
+	for key, t := range p.lastSeen {
+		if t.Before(minFrom) {
+			delete(p.lastSeen, key)
+		}
+	}
+
+	from := p.start
+	if len(p.lastSeen) > 0 {
+		from = now
+		for _, t := range p.lastSeen {
+			if t.Before(from) {
+				from = t
+			}
+		}
+	}
+	// don't let the range grow unbounded when the query stops returning data
+	if from.Before(minFrom) {
+		from = minFrom
+	}
+
+	resp, err := p.s.QueryData(ctx, &backend.QueryDataRequest{
+		PluginContext: p.pluginCtx,
+		Queries: []backend.DataQuery{
+			{
+				RefID:         p.query.RefID,
+				JSON:          p.raw,
+				Interval:      time.Duration(p.query.IntervalMs) * time.Millisecond,
+				MaxDataPoints: p.query.MaxDataPoints,
+				TimeRange: backend.TimeRange{
+					From: from,
+					To:   now,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := resp.Responses[p.query.RefID]
+	if !ok {
+		return nil, fmt.Errorf("no response for stream query")
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return framesAfter(res.Frames, p.lastSeen, p.start)
+}
+
+// framesAfter drops the rows of each frame which are not newer than the last
+// timestamp seen for its series, or since for a series seen for the first
+// time, and returns the remaining non-empty frames. lastSeen is updated with
+// the latest timestamp of each series.
+// Frames without a time field can not be streamed incrementally and are skipped.
+func framesAfter(frames data.Frames, lastSeen map[string]time.Time, since time.Time) (data.Frames, error) {
+	result := make(data.Frames, 0, len(frames))
+
+	for _, frame := range frames {
+		timeIndices := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+		if len(timeIndices) == 0 {
+			continue
+		}
+
+		key := seriesKey(frame)
+		after, ok := lastSeen[key]
+		if !ok {
+			after = since
+		}
+		latest := after
+
+		filtered, err := frame.FilterRowsByField(timeIndices[0], func(v any) (bool, error) {
+			t, ok := timeValue(v)
+			if !ok || !t.After(after) {
+				return false, nil
+			}
+			if t.After(latest) {
+				latest = t
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if filtered.Rows() == 0 {
+			continue
+		}
+		lastSeen[key] = latest
+		filtered.Meta = frame.Meta
+		result = append(result, filtered)
+	}
+
+	return result, nil
+}
+
+// seriesKey identifies the series of a frame by its name and the names and
+// labels of its value fields.
+func seriesKey(frame *data.Frame) string {
+	var b strings.Builder
+	b.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		if field.Type().Time() {
+			continue
+		}
+		b.WriteString("\x00")
+		b.WriteString(field.Name)
+		b.WriteString(field.Labels.String())
+	}
+	return b.String()
+}
+
+func timeValue(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, true
+	default:
+		return time.Time{}, false
+	}
+}
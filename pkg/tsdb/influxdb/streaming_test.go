@@ -0,0 +1,123 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SubscribeStream(t *testing.T) {
+	t.Run("should allow stream channels", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{})
+		res, err := s.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			Path: "stream/abc",
+			Data: json.RawMessage(`{"query": "SELECT value FROM cpu WHERE $timeFilter", "rawQuery": true, "streamInterval": "10s"}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, backend.SubscribeStreamStatusOK, res.Status)
+	})
+
+	t.Run("should reject unknown channel paths", func(t *testing.T) {
+		s := GetMockService(influxVersionInfluxQL, RoundTripper{})
+		res, err := s.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			Path: "tail/abc",
+			Data: json.RawMessage(`{}`),
+		})
+		require.Error(t, err)
+		assert.Equal(t, backend.SubscribeStreamStatusNotFound, res.Status)
+	})
+
+	t.Run("should reject invalid stream interval", func(t *testing.T) {
+		s := GetMockService(influxVersionFlux, RoundTripper{})
+		res, err := s.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			Path: "stream/abc",
+			Data: json.RawMessage(`{"streamInterval": "often"}`),
+		})
+		require.Error(t, err)
+		assert.Equal(t, backend.SubscribeStreamStatusNotFound, res.Status)
+	})
+}
+
+func Test_streamPoller(t *testing.T) {
+	s := GetMockService(influxVersionInfluxQL, RoundTripper{
+		Body: `{"results": [{"series": [{"name": "cpu", "columns": ["time", "value"], "values": [[1000, 1], [2000, 2], [3000, 3]]}], "statement_id": 0}]}`,
+	})
+	raw := json.RawMessage(`{"refId": "A", "query": "SELECT value FROM cpu WHERE $timeFilter", "rawQuery": true, "resultFormat": "time_series"}`)
+	query, _, lookback, err := parseStreamQuery(raw)
+	require.NoError(t, err)
+
+	p := &streamPoller{
+		s:        s,
+		query:    query,
+		raw:      raw,
+		lookback: lookback,
+		start:    time.UnixMilli(1500),
+		lastSeen: map[string]time.Time{},
+	}
+
+	frames, err := p.poll(context.Background(), time.UnixMilli(4000))
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, 2, frames[0].Rows())
+	require.Len(t, p.lastSeen, 1)
+	for _, lastSeen := range p.lastSeen {
+		assert.Equal(t, time.UnixMilli(3000).UTC(), lastSeen.UTC())
+	}
+
+	frames, err = p.poll(context.Background(), time.UnixMilli(5000))
+	require.NoError(t, err)
+	assert.Len(t, frames, 0)
+}
+
+func Test_framesAfter(t *testing.T) {
+	t1, t2, t3 := time.UnixMilli(1000), time.UnixMilli(2000), time.UnixMilli(3000)
+	frames := data.Frames{
+		data.NewFrame("a",
+			data.NewField("time", nil, []time.Time{t1, t2, t3}),
+			data.NewField("value", nil, []float64{1, 2, 3}),
+		),
+		data.NewFrame("b",
+			data.NewField("time", nil, []*time.Time{&t1, nil}),
+			data.NewField("value", nil, []float64{1, 2}),
+		),
+		data.NewFrame("no-time",
+			data.NewField("value", nil, []float64{1}),
+		),
+	}
+
+	lastSeen := map[string]time.Time{}
+	result, err := framesAfter(frames, lastSeen, t1)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "a", result[0].Name)
+	assert.Equal(t, 2, result[0].Rows())
+	assert.Equal(t, t3, lastSeen[seriesKey(frames[0])])
+
+	t.Run("should keep the rows of a lagging series", func(t *testing.T) {
+		t4 := time.UnixMilli(4000)
+		frames := data.Frames{
+			data.NewFrame("a",
+				data.NewField("time", nil, []time.Time{t3, t4}),
+				data.NewField("value", nil, []float64{3, 4}),
+			),
+			data.NewFrame("c",
+				data.NewField("time", nil, []time.Time{t2}),
+				data.NewField("value", data.Labels{"host": "c"}, []float64{2}),
+			),
+		}
+
+		result, err := framesAfter(frames, lastSeen, t1)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, 1, result[0].Rows())
+		assert.Equal(t, t4, lastSeen[seriesKey(frames[0])])
+		assert.Equal(t, 1, result[1].Rows())
+		assert.Equal(t, t2, lastSeen[seriesKey(frames[1])])
+	})
+}
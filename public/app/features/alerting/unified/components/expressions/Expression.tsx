@@ -4,10 +4,13 @@ import React, { FC, useCallback, useState } from 'react';
 
 import { DataFrame, dateTimeFormat, GrafanaTheme2, isTimeSeriesFrames, LoadingState, PanelData } from '@grafana/data';
 import { AutoSizeInput, Button, clearButtonStyles, IconButton, useStyles2, Stack } from '@grafana/ui';
+import { Anomaly } from 'app/features/expressions/components/Anomaly';
 import { ClassicConditions } from 'app/features/expressions/components/ClassicConditions';
+import { Forecast } from 'app/features/expressions/components/Forecast';
 import { Math } from 'app/features/expressions/components/Math';
 import { Reduce } from 'app/features/expressions/components/Reduce';
 import { Resample } from 'app/features/expressions/components/Resample';
+import { SqlExpr } from 'app/features/expressions/components/SqlExpr';
 import { Threshold } from 'app/features/expressions/components/Threshold';
 import {
   ExpressionQuery,
@@ -87,6 +90,15 @@ export const Expression: FC<ExpressionProps> = ({
         case ExpressionQueryType.threshold:
           return <Threshold onChange={onChangeQuery} query={query} labelWidth={'auto'} refIds={availableRefIds} />;
 
+        case ExpressionQueryType.sql:
+          return <SqlExpr onChange={onChangeQuery} query={query} labelWidth={'auto'} onRunQuery={() => {}} />;
+
+        case ExpressionQueryType.anomaly:
+          return <Anomaly onChange={onChangeQuery} query={query} labelWidth={'auto'} refIds={availableRefIds} />;
+
+        case ExpressionQueryType.forecast:
+          return <Forecast onChange={onChangeQuery} query={query} labelWidth={'auto'} refIds={availableRefIds} />;
+
         default:
           return <>Expression not supported: {query.type}</>;
       }
@@ -3,10 +3,13 @@ import React, { useCallback, useEffect, useRef } from 'react';
 import { DataSourceApi, QueryEditorProps, SelectableValue } from '@grafana/data';
 import { InlineField, Select } from '@grafana/ui';
 
+import { Anomaly } from './components/Anomaly';
 import { ClassicConditions } from './components/ClassicConditions';
+import { Forecast } from './components/Forecast';
 import { Math } from './components/Math';
 import { Reduce } from './components/Reduce';
 import { Resample } from './components/Resample';
+import { SqlExpr } from './components/SqlExpr';
 import { Threshold } from './components/Threshold';
 import { ExpressionQuery, ExpressionQueryType, expressionTypes } from './types';
 import { getDefaults } from './utils/expressionTypes';
@@ -27,6 +30,9 @@ function useExpressionsCache() {
       case ExpressionQueryType.reduce:
       case ExpressionQueryType.resample:
       case ExpressionQueryType.threshold:
+      case ExpressionQueryType.sql:
+      case ExpressionQueryType.anomaly:
+      case ExpressionQueryType.forecast:
         return expressionCache.current[queryType];
       case ExpressionQueryType.classic:
         return undefined;
@@ -43,9 +49,17 @@ function useExpressionsCache() {
       case ExpressionQueryType.reduce:
       case ExpressionQueryType.resample:
       case ExpressionQueryType.resample:
+      case ExpressionQueryType.anomaly:
+      case ExpressionQueryType.forecast:
         expressionCache.current.reduce = value;
         expressionCache.current.resample = value;
         expressionCache.current.threshold = value;
+        expressionCache.current.anomaly = value;
+        expressionCache.current.forecast = value;
+        break;
+
+      case ExpressionQueryType.sql:
+        expressionCache.current.sql = value;
         break;
     }
   }, []);
@@ -89,6 +103,15 @@ export function ExpressionQueryEditor(props: Props) {
 
       case ExpressionQueryType.threshold:
         return <Threshold onChange={onChange} query={query} labelWidth={labelWidth} refIds={refIds} />;
+
+      case ExpressionQueryType.sql:
+        return <SqlExpr onChange={onChange} query={query} labelWidth={labelWidth} onRunQuery={onRunQuery} />;
+
+      case ExpressionQueryType.anomaly:
+        return <Anomaly query={query} labelWidth={labelWidth} onChange={onChange} refIds={refIds} />;
+
+      case ExpressionQueryType.forecast:
+        return <Forecast query={query} labelWidth={labelWidth} onChange={onChange} refIds={refIds} />;
     }
   };
 
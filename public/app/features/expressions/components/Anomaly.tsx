@@ -0,0 +1,84 @@
+import React, { ChangeEvent } from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
+
+import { AnomalyAlgorithm, anomalyAlgorithms, ExpressionQuery } from '../types';
+
+interface Props {
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  labelWidth?: number | 'auto';
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Anomaly = ({ labelWidth = 'auto', onChange, refIds, query }: Props) => {
+  const algorithm = anomalyAlgorithms.find((o) => o.value === query.algorithm);
+  const isSeasonal = query.algorithm === AnomalyAlgorithm.Seasonal;
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onSelectAlgorithm = (value: SelectableValue<AnomalyAlgorithm>) => {
+    onChange({ ...query, algorithm: value.value });
+  };
+
+  const onWindowChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, window: event.target.value });
+  };
+
+  const onSeasonChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, season: event.target.value });
+  };
+
+  const onSensitivityChange = (event: ChangeEvent<HTMLInputElement>) => {
+    const value = event.target.valueAsNumber;
+    onChange({ ...query, sensitivity: isNaN(value) ? undefined : value });
+  };
+
+  const onBandsChange = (event: React.FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, bands: event.currentTarget.checked });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Input" labelWidth={labelWidth}>
+          <Select onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+        <InlineField label="Algorithm">
+          <Select options={anomalyAlgorithms} value={algorithm} onChange={onSelectAlgorithm} width={30} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        {isSeasonal ? (
+          <InlineField
+            label="Season"
+            labelWidth={labelWidth}
+            tooltip="Duration of a season, for example 1d. The series must contain at least three seasons of points."
+          >
+            <Input onChange={onSeasonChange} value={query.season} width={15} />
+          </InlineField>
+        ) : (
+          <InlineField
+            label="Window"
+            labelWidth={labelWidth}
+            tooltip="Duration of the previous points used as baseline, for example 1h"
+          >
+            <Input onChange={onWindowChange} value={query.window} width={15} />
+          </InlineField>
+        )}
+        <InlineField label="Sensitivity" tooltip="Number of deviations from the baseline above which a point is an anomaly">
+          <Input type="number" onChange={onSensitivityChange} value={query.sensitivity} placeholder="3" width={10} />
+        </InlineField>
+        <InlineField
+          label="Show bands"
+          tooltip="Also return the upper and lower bands of expected values, with the anomaly_band label. Leave disabled when the expression is used in an alert condition."
+        >
+          <InlineSwitch value={query.bands ?? false} onChange={onBandsChange} />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
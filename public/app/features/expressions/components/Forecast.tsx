@@ -0,0 +1,68 @@
+import React, { ChangeEvent } from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+
+import { ExpressionQuery, ForecastModel, forecastModels, ForecastOutput, forecastOutputs } from '../types';
+
+interface Props {
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  labelWidth?: number | 'auto';
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Forecast = ({ labelWidth = 'auto', onChange, refIds, query }: Props) => {
+  const model = forecastModels.find((o) => o.value === query.model);
+  const output = forecastOutputs.find((o) => o.value === query.output);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onSelectModel = (value: SelectableValue<ForecastModel>) => {
+    onChange({ ...query, model: value.value, season: value.value === ForecastModel.Linear ? undefined : query.season });
+  };
+
+  const onSelectOutput = (value: SelectableValue<ForecastOutput>) => {
+    onChange({ ...query, output: value.value });
+  };
+
+  const onHorizonChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, horizon: event.target.value });
+  };
+
+  const onSeasonChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, season: event.target.value });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Input" labelWidth={labelWidth}>
+          <Select onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+        <InlineField label="Model">
+          <Select options={forecastModels} value={model} onChange={onSelectModel} width={20} />
+        </InlineField>
+        {query.model === ForecastModel.HoltWinters && (
+          <InlineField label="Season" tooltip="Duration of a season, for example 1d. Leave empty for no season.">
+            <Input onChange={onSeasonChange} value={query.season} width={15} />
+          </InlineField>
+        )}
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField
+          label="Horizon"
+          labelWidth={labelWidth}
+          tooltip="How far after the evaluation time to predict, for example 4h"
+        >
+          <Input onChange={onHorizonChange} value={query.horizon} width={15} />
+        </InlineField>
+        <InlineField label="Output">
+          <Select options={forecastOutputs} value={output} onChange={onSelectOutput} width={20} />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
@@ -87,6 +87,27 @@ export const Math = ({ labelWidth, onChange, query, onRunQuery }: Props) => {
                       name="floor"
                       description="rounds the number down to the nearest integer value. It's able to operate on series or escalar values."
                     />
+                    <DocumentedFunction
+                      name="rate, increase"
+                      description="return the per-second rate or the increase of counters between consecutive points of series, handling counter resets"
+                    />
+                    <DocumentedFunction
+                      name="delta, derivative"
+                      description="return the difference or the per-second rate of change between consecutive points of series"
+                    />
+                    <DocumentedFunction
+                      name="moving_avg"
+                      description='returns the average of the points of series in a time window ending at each point, e.g. moving_avg($A, "5m")'
+                    />
+                    <DocumentedFunction name="cumsum" description="returns the running sum of the points of series" />
+                    <DocumentedFunction
+                      name="shift"
+                      description='moves the points of series in time by a duration, e.g. $A - shift($A, "1d")'
+                    />
+                    <DocumentedFunction
+                      name="clamp_min, clamp_max"
+                      description="limit the values of a number or a series to a minimum or a maximum, e.g. clamp_min($A, 0)"
+                    />
                   </div>
                 </div>
               }
@@ -1,7 +1,7 @@
 import React, { ChangeEvent } from 'react';
 
 import { SelectableValue } from '@grafana/data';
-import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
 
 import { downsamplingTypes, ExpressionQuery, upsamplingTypes } from '../types';
 
@@ -32,6 +32,14 @@ export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props
     onChange({ ...query, upsampler: value.value });
   };
 
+  const onAlignWindowsChange = (event: React.FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, settings: { ...query.settings, alignWindows: event.currentTarget.checked } });
+  };
+
+  const onTimezoneChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, settings: { ...query.settings, timezone: event.target.value } });
+  };
+
   return (
     <>
       <InlineFieldRow>
@@ -50,6 +58,20 @@ export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props
           <Select options={upsamplingTypes} value={upsampler} onChange={onSelectUpsampler} width={25} />
         </InlineField>
       </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField
+          label="Align windows"
+          labelWidth={labelWidth}
+          tooltip="Align the windows to the start of the minute, hour or day instead of the start of the time range, so that series resampled by different expressions line up"
+        >
+          <InlineSwitch value={query.settings?.alignWindows ?? false} onChange={onAlignWindowsChange} />
+        </InlineField>
+        {query.settings?.alignWindows && (
+          <InlineField label="Timezone" tooltip="IANA timezone of the calendar, for example Europe/Berlin">
+            <Input onChange={onTimezoneChange} value={query.settings?.timezone ?? ''} placeholder="UTC" width={25} />
+          </InlineField>
+        )}
+      </InlineFieldRow>
     </>
   );
 };
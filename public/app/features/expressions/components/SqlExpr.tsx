@@ -0,0 +1,42 @@
+import React from 'react';
+
+import { CodeEditor, InlineField } from '@grafana/ui';
+
+import { ExpressionQuery } from '../types';
+
+interface Props {
+  labelWidth: number | 'auto';
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+  onRunQuery: () => void;
+}
+
+const initialQuery = 'SELECT * FROM A';
+
+export const SqlExpr = ({ labelWidth, onChange, query, onRunQuery }: Props) => {
+  const onEditorChange = (expression: string) => {
+    onChange({ ...query, expression });
+  };
+
+  return (
+    <InlineField
+      label="Query"
+      labelWidth={labelWidth}
+      tooltip="SQL query over the results of other queries and expressions. Each of them is a table named after its refId, e.g. SELECT * FROM A JOIN B ON A.host = B.host"
+      grow={true}
+    >
+      <CodeEditor
+        value={query.expression || initialQuery}
+        language="sql"
+        height={200}
+        width="100%"
+        showMiniMap={false}
+        onBlur={(expression) => {
+          onEditorChange(expression);
+          onRunQuery();
+        }}
+        onSave={onEditorChange}
+      />
+    </InlineField>
+  );
+};
@@ -1,4 +1,5 @@
 import { DataQuery, ReducerID, SelectableValue } from '@grafana/data';
+import { config } from '@grafana/runtime';
 
 import { EvalFunction } from '../alerting/state/alertDef';
 
@@ -13,6 +14,9 @@ export enum ExpressionQueryType {
   resample = 'resample',
   classic = 'classic_conditions',
   threshold = 'threshold',
+  sql = 'sql',
+  anomaly = 'anomaly',
+  forecast = 'forecast',
 }
 
 export const getExpressionLabel = (type: ExpressionQueryType) => {
@@ -27,6 +31,12 @@ export const getExpressionLabel = (type: ExpressionQueryType) => {
       return 'Classic condition';
     case ExpressionQueryType.threshold:
       return 'Threshold';
+    case ExpressionQueryType.sql:
+      return 'SQL';
+    case ExpressionQueryType.anomaly:
+      return 'Anomaly detection';
+    case ExpressionQueryType.forecast:
+      return 'Forecast';
   }
 };
 
@@ -59,6 +69,27 @@ export const expressionTypes: Array<SelectableValue<ExpressionQueryType>> = [
     description:
       'Takes one or more time series returned from a query or an expression and checks if any of the series match the threshold condition.',
   },
+  {
+    value: ExpressionQueryType.anomaly,
+    label: 'Anomaly detection',
+    description:
+      'Compares each point of time series with a baseline computed from the previous points and returns 1 for anomalies and 0 for other points.',
+  },
+  {
+    value: ExpressionQueryType.forecast,
+    label: 'Forecast',
+    description:
+      'Predicts the values of time series with a model fitted with their points, for example to alert before a disk is full.',
+  },
+  ...(config.featureToggles.sqlExpressions
+    ? [
+        {
+          value: ExpressionQueryType.sql,
+          label: 'SQL',
+          description: 'Joins, filters and groups the results of queries and expressions with a SQL query.',
+        },
+      ]
+    : []),
 ];
 
 export const reducerTypes: Array<SelectableValue<string>> = [
@@ -68,6 +99,31 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'p90', label: '90th percentile', description: 'Get the 90th percentile value' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile value' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile value' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of the values' },
+  { value: ReducerID.variance, label: 'Variance', description: 'Get the variance of the values' },
+  { value: ReducerID.range, label: 'Range', description: 'Get the difference between the maximum and minimum values' },
+  { value: ReducerID.diff, label: 'Diff', description: 'Get the difference between the last and first values' },
+  {
+    value: 'diff_abs',
+    label: 'Diff (absolute)',
+    description: 'Get the absolute difference between the last and first values',
+  },
+  {
+    value: 'percent_diff',
+    label: 'Percent diff',
+    description: 'Get the difference between the last and first values as a percentage of the first value',
+  },
+  {
+    value: 'percent_diff_abs',
+    label: 'Percent diff (absolute)',
+    description: 'Get the absolute difference between the last and first values as a percentage of the first value',
+  },
+  { value: 'count_non_null', label: 'Count non-null', description: 'Get the number of non-null values' },
 ];
 
 export enum ReducerMode {
@@ -100,12 +156,68 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Fill with the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
+  { value: ReducerID.first, label: 'First', description: 'Fill with the first value' },
+  { value: 'median', label: 'Median', description: 'Fill with the median value' },
+  { value: ReducerID.count, label: 'Count', description: 'Fill with the number of values' },
 ];
 
 export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'pad', label: 'pad', description: 'fill with the last known value' },
   { value: 'backfilling', label: 'backfilling', description: 'fill with the next known value' },
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
+  { value: 'linear', label: 'linear', description: 'fill with the linear interpolation of the known values around' },
+];
+
+export enum AnomalyAlgorithm {
+  ZScore = 'zscore',
+  MAD = 'mad',
+  Seasonal = 'seasonal',
+}
+
+export const anomalyAlgorithms: Array<SelectableValue<AnomalyAlgorithm>> = [
+  {
+    value: AnomalyAlgorithm.ZScore,
+    label: 'Z-score',
+    description: 'Distance to the mean of the points in the window, in standard deviations',
+  },
+  {
+    value: AnomalyAlgorithm.MAD,
+    label: 'Median absolute deviation',
+    description: 'Distance to the median of the points in the window, less sensitive to past outliers',
+  },
+  {
+    value: AnomalyAlgorithm.Seasonal,
+    label: 'Seasonal',
+    description: 'Distance to the value predicted by a Holt-Winters model of the season',
+  },
+];
+
+export enum ForecastModel {
+  Linear = 'linear',
+  HoltWinters = 'holt_winters',
+}
+
+export const forecastModels: Array<SelectableValue<ForecastModel>> = [
+  {
+    value: ForecastModel.Linear,
+    label: 'Linear',
+    description: 'Linear regression of the values over time, like predict_linear in Prometheus',
+  },
+  {
+    value: ForecastModel.HoltWinters,
+    label: 'Holt-Winters',
+    description: 'Exponential smoothing of the level and trend, and of the season when set',
+  },
+];
+
+export enum ForecastOutput {
+  Value = 'value',
+  Series = 'series',
+}
+
+export const forecastOutputs: Array<SelectableValue<ForecastOutput>> = [
+  { value: ForecastOutput.Value, label: 'Value', description: 'The value predicted at the horizon' },
+  { value: ForecastOutput.Series, label: 'Series', description: 'The values predicted until the horizon' },
 ];
 
 export const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [
@@ -126,6 +238,13 @@ export interface ExpressionQuery extends DataQuery {
   window?: string;
   downsampler?: string;
   upsampler?: string;
+  algorithm?: AnomalyAlgorithm;
+  season?: string;
+  sensitivity?: number;
+  bands?: boolean;
+  model?: ForecastModel;
+  horizon?: string;
+  output?: ForecastOutput;
   conditions?: ClassicCondition[];
   settings?: ExpressionQuerySettings;
 }
@@ -133,6 +252,8 @@ export interface ExpressionQuery extends DataQuery {
 export interface ExpressionQuerySettings {
   mode?: ReducerMode;
   replaceWithValue?: number;
+  alignWindows?: boolean;
+  timezone?: string;
 }
 
 export interface ClassicCondition {
@@ -1,7 +1,14 @@
 import { ReducerID } from '@grafana/data';
 
 import { EvalFunction } from '../../alerting/state/alertDef';
-import { ClassicCondition, ExpressionQuery, ExpressionQueryType } from '../types';
+import {
+  AnomalyAlgorithm,
+  ClassicCondition,
+  ExpressionQuery,
+  ExpressionQueryType,
+  ForecastModel,
+  ForecastOutput,
+} from '../types';
 
 export const getDefaults = (query: ExpressionQuery) => {
   switch (query.type) {
@@ -28,6 +35,34 @@ export const getDefaults = (query: ExpressionQuery) => {
       query.expression = undefined;
       break;
 
+    case ExpressionQueryType.forecast:
+      if (!query.model) {
+        query.model = ForecastModel.Linear;
+      }
+
+      if (!query.horizon) {
+        query.horizon = '4h';
+      }
+
+      if (!query.output) {
+        query.output = ForecastOutput.Value;
+      }
+
+      query.reducer = undefined;
+      break;
+
+    case ExpressionQueryType.anomaly:
+      if (!query.algorithm) {
+        query.algorithm = AnomalyAlgorithm.ZScore;
+      }
+
+      if (!query.window) {
+        query.window = '1h';
+      }
+
+      query.reducer = undefined;
+      break;
+
     case ExpressionQueryType.classic:
       if (!query.conditions) {
         query.conditions = [defaultCondition];
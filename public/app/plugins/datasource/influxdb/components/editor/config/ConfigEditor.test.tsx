@@ -78,6 +78,15 @@ describe('ConfigEditor', () => {
     expect(screen.queryByLabelText('Password')).toBeInTheDocument();
   });
 
+  it('should show the exemplar sampler for influxQL', () => {
+    setup({
+      jsonData: {
+        exemplarSampler: 'step',
+      },
+    });
+    expect(screen.getByText('Step')).toBeInTheDocument();
+  });
+
   it('influxQL options should not show up if version is defined as flux', () => {
     setup({
       jsonData: {
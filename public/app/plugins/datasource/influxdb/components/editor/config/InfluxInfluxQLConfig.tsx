@@ -9,6 +9,7 @@ import {
   onUpdateDatasourceOption,
   onUpdateDatasourceSecureJsonDataOption,
   SelectableValue,
+  updateDatasourcePluginJsonDataOption,
   updateDatasourcePluginResetOption,
 } from '@grafana/data';
 import { GrafanaTheme2 } from '@grafana/data/src/themes';
@@ -21,6 +22,11 @@ const httpModes: SelectableValue[] = [
   { label: 'POST', value: 'POST' },
 ];
 
+const exemplarSamplers: SelectableValue[] = [
+  { label: 'None', value: 'none', description: 'Return every exemplar' },
+  { label: 'Step', value: 'step', description: 'Keep one exemplar per step and series, and the outliers' },
+];
+
 const WIDTH_SHORT = 20;
 
 export type Props = DataSourcePluginOptionsEditorProps<InfluxOptions, InfluxSecureJsonData>;
@@ -140,6 +146,94 @@ export const InfluxInfluxQLConfig = (props: Props) => {
           onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
         />
       </Field>
+
+      <Field
+        horizontal
+        label={
+          <InlineLabel
+            width={WIDTH_SHORT}
+            tooltip="Split queries over long time ranges into several queries of at most this duration, for example 7d. Queries which can not be split, like queries with a LIMIT, are sent as is."
+          >
+            Query split duration
+          </InlineLabel>
+        }
+        className={styles.horizontalField}
+      >
+        <Input
+          className="width-20"
+          placeholder="disabled"
+          value={options.jsonData.querySplitDuration || ''}
+          onChange={onUpdateDatasourceJsonDataOption(props, 'querySplitDuration')}
+        />
+      </Field>
+      <Field
+        horizontal
+        label={
+          <InlineLabel
+            width={WIDTH_SHORT}
+            tooltip="Split queries so that each of them selects at most this many points, estimated from the max series and the query interval. Set it below the max-select-point setting of InfluxDB."
+          >
+            Max points per query
+          </InlineLabel>
+        }
+        className={styles.horizontalField}
+      >
+        <Input
+          className="width-20"
+          type="number"
+          placeholder="disabled"
+          value={options.jsonData.querySplitMaxPoints ?? ''}
+          onChange={(event: { currentTarget: { value: string } }) => {
+            const val = parseInt(event.currentTarget.value, 10);
+            updateDatasourcePluginJsonDataOption(props, 'querySplitMaxPoints', Number.isFinite(val) ? val : undefined);
+          }}
+        />
+      </Field>
+      <Field
+        horizontal
+        label={
+          <InlineLabel width={WIDTH_SHORT} tooltip="The number of split queries sent at the same time. Defaults to 4.">
+            Split concurrency
+          </InlineLabel>
+        }
+        className={styles.horizontalField}
+      >
+        <Input
+          className="width-20"
+          type="number"
+          placeholder="4"
+          value={options.jsonData.querySplitConcurrency ?? ''}
+          onChange={(event: { currentTarget: { value: string } }) => {
+            const val = parseInt(event.currentTarget.value, 10);
+            updateDatasourcePluginJsonDataOption(
+              props,
+              'querySplitConcurrency',
+              Number.isFinite(val) ? val : undefined
+            );
+          }}
+        />
+      </Field>
+      <Field
+        horizontal
+        label={
+          <InlineLabel
+            width={WIDTH_SHORT}
+            tooltip="Reduces the exemplars returned by exemplar queries. Step keeps one exemplar per query interval and series, along with the outliers."
+          >
+            Exemplar sampler
+          </InlineLabel>
+        }
+        htmlFor={`${htmlPrefix}-exemplar-sampler`}
+        className={styles.horizontalField}
+      >
+        <Select
+          inputId={`${htmlPrefix}-exemplar-sampler`}
+          className="width-20"
+          value={exemplarSamplers.find((sampler) => sampler.value === (options.jsonData.exemplarSampler || 'none'))}
+          options={exemplarSamplers}
+          onChange={onUpdateDatasourceJsonDataOptionSelect(props, 'exemplarSampler')}
+        />
+      </Field>
     </>
   );
 };
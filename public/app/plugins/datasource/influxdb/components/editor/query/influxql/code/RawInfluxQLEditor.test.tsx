@@ -64,6 +64,15 @@ describe('RawInfluxQLEditor', () => {
     expect(onChange).toHaveBeenCalledWith({ ...query, resultFormat: 'time_series' });
   });
 
+  it('should call onChange immediately when exemplar changes', async () => {
+    const onChange = jest.fn();
+    render(<RawInfluxQLEditor onRunQuery={() => null} onChange={onChange} query={query} />);
+
+    await userEvent.click(screen.getByLabelText('Exemplars'));
+
+    expect(onChange).toHaveBeenCalledWith({ ...query, exemplar: true });
+  });
+
   it('should only call onChange on blur when query changes', async () => {
     const onChange = jest.fn();
     render(<RawInfluxQLEditor onRunQuery={() => null} onChange={onChange} query={query} />);
@@ -1,6 +1,6 @@
 import React, { useId } from 'react';
 
-import { HorizontalGroup, InlineFormLabel, Input, Select, TextArea } from '@grafana/ui';
+import { HorizontalGroup, InlineFormLabel, InlineSwitch, Input, Select, TextArea } from '@grafana/ui';
 
 import { InfluxQuery } from '../../../../../types';
 import { DEFAULT_RESULT_FORMAT, RESULT_FORMATS } from '../../../constants';
@@ -12,14 +12,15 @@ type Props = {
   onRunQuery: () => void;
 };
 
-// we handle 3 fields: "query", "alias", "resultFormat"
-// "resultFormat" changes are applied immediately
+// we handle 4 fields: "query", "alias", "resultFormat", "exemplar"
+// "resultFormat" and "exemplar" changes are applied immediately
 // "query" and "alias" changes only happen on onblur
 export const RawInfluxQLEditor = ({ query, onChange, onRunQuery }: Props): JSX.Element => {
   const [currentQuery, setCurrentQuery] = useShadowedState(query.query);
   const [currentAlias, setCurrentAlias] = useShadowedState(query.alias);
   const aliasElementId = useId();
   const selectElementId = useId();
+  const exemplarElementId = useId();
 
   const resultFormat = query.resultFormat ?? DEFAULT_RESULT_FORMAT;
 
@@ -69,6 +70,17 @@ export const RawInfluxQLEditor = ({ query, onChange, onRunQuery }: Props): JSX.E
           }}
           value={currentAlias ?? ''}
         />
+        <InlineFormLabel htmlFor={exemplarElementId} tooltip="Return the points of the query as exemplars">
+          Exemplars
+        </InlineFormLabel>
+        <InlineSwitch
+          id={exemplarElementId}
+          value={query.exemplar ?? false}
+          onChange={(e) => {
+            onChange({ ...query, exemplar: e.currentTarget.checked });
+            onRunQuery();
+          }}
+        />
       </HorizontalGroup>
     </div>
   );
@@ -2,7 +2,7 @@ import { css } from '@emotion/css';
 import React, { useId, useMemo } from 'react';
 
 import { GrafanaTheme2 } from '@grafana/data';
-import { InlineLabel, SegmentSection, useStyles2 } from '@grafana/ui';
+import { InlineLabel, InlineSwitch, SegmentSection, useStyles2 } from '@grafana/ui';
 
 import InfluxDatasource from '../../../../../datasource';
 import {
@@ -45,6 +45,7 @@ type Props = {
 export const VisualInfluxQLEditor = (props: Props): JSX.Element => {
   const uniqueId = useId();
   const formatAsId = `influxdb-qe-format-as-${uniqueId}`;
+  const exemplarId = `influxdb-qe-exemplar-${uniqueId}`;
   const orderByTimeId = `influxdb-qe-order-by${uniqueId}`;
 
   const styles = useStyles2(getStyles);
@@ -241,6 +242,15 @@ export const VisualInfluxQLEditor = (props: Props): JSX.Element => {
           </>
         )}
       </SegmentSection>
+      <SegmentSection htmlFor={exemplarId} label="EXEMPLARS" fill={true}>
+        <InlineSwitch
+          id={exemplarId}
+          value={query.exemplar ?? false}
+          onChange={(e) => {
+            onAppliedChange({ ...query, exemplar: e.currentTarget.checked });
+          }}
+        />
+      </SegmentSection>
     </div>
   );
 };
@@ -40,7 +40,7 @@ import { QueryFormat, SQLQuery } from '../../../features/plugins/sql';
 import { AnnotationEditor } from './components/editor/annotation/AnnotationEditor';
 import { FluxQueryEditor } from './components/editor/query/flux/FluxQueryEditor';
 import { BROWSER_MODE_DISABLED_MESSAGE } from './constants';
-import { toRawSql } from './fsql/sqlUtil';
+import { quoteLiteral, toRawSql } from './fsql/sqlUtil';
 import InfluxQueryModel from './influx_query_model';
 import InfluxSeries from './influx_series';
 import { buildMetadataQuery } from './influxql_query_builder';
@@ -267,6 +267,7 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
       adhocFilters: this.templateSrv.getAdhocFilters(this.name) ?? [],
       query: this.templateSrv.replace(query.query ?? '', scopedVars, this.interpolateQueryExpr), // The raw query text
       rawSql: this.templateSrv.replace(query.rawSql ?? '', scopedVars, this.interpolateQueryExpr), // The raw query text
+      parameters: this.sqlParameters(query.rawSql ?? '', scopedVars),
       alias: this.templateSrv.replace(query.alias ?? '', scopedVars),
       limit: this.templateSrv.replace(query.limit?.toString() ?? '', scopedVars, this.interpolateQueryExpr),
       measurement: this.templateSrv.replace(query.measurement ?? '', scopedVars, this.interpolateQueryExpr),
@@ -276,6 +277,33 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
     };
   }
 
+  // Variables referenced with $__param(name) are sent as bound parameters
+  // of the SQL query instead of being interpolated into the query text.
+  sqlParameters(rawSql: string, scopedVars: ScopedVars): Record<string, string> | undefined {
+    const matches = [...rawSql.matchAll(/\$__param\(\s*(\w+)\s*\)/g)];
+    if (matches.length === 0) {
+      return undefined;
+    }
+    const parameters: Record<string, string> = {};
+    for (const [, name] of matches) {
+      parameters[name] = this.templateSrv.replace(`$${name}`, scopedVars, this.interpolateSqlParameter);
+    }
+    return parameters;
+  }
+
+  // Parameters are bound, so single values are sent as they are and multi
+  // values are sent as a list of quoted literals.
+  interpolateSqlParameter(value: string | string[] = [], variable: Partial<CustomFormatterVariable>) {
+    if (typeof value === 'string') {
+      if (variable.multi || variable.includeAll) {
+        return quoteLiteral(value);
+      }
+      return value;
+    }
+
+    return value.map((val) => quoteLiteral(val)).join(',');
+  }
+
   interpolateQueryExpr(value: string | string[] = [], variable: Partial<CustomFormatterVariable>) {
     // if no multi or include all do not regexEscape
     if (!variable.multi && !variable.includeAll) {
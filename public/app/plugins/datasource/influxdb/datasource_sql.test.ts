@@ -39,6 +39,13 @@ describe('InfluxDB SQL Support', () => {
       expect(replaceMock.mock.calls[1][0]).toBe(
         `SELECT "$interpolationVar2", time FROM iox.$interpolationVar WHERE time >= $__timeFrom AND time <= $__timeTo`
       );
+      expect(replaceMock.mock.calls[1][2]).toBe(ds.interpolateQueryExpr);
+    });
+
+    it('should quote multi values of $__param variables', () => {
+      expect(ds.interpolateSqlParameter("one' or '1'='1", { multi: false, includeAll: false })).toBe("one' or '1'='1");
+      expect(ds.interpolateSqlParameter("it's", { multi: true, includeAll: false })).toBe("'it''s'");
+      expect(ds.interpolateSqlParameter(['a', "b'"], { multi: true, includeAll: false })).toBe("'a','b'''");
     });
   });
 });
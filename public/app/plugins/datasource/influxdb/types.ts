@@ -22,6 +22,14 @@ export interface InfluxOptions extends DataSourceJsonData {
 
   dbName?: string;
 
+  // InfluxQL exemplar sampler: none or step
+  exemplarSampler?: string;
+
+  // InfluxQL query splitting
+  querySplitDuration?: string;
+  querySplitMaxPoints?: number;
+  querySplitConcurrency?: number;
+
   // With Flux
   organization?: string;
   defaultBucket?: string;
@@ -97,6 +105,8 @@ export interface InfluxQuery extends DataQuery {
 
   textEditor?: boolean;
   adhocFilters?: AdHocVariableFilter[];
+  // return the points of the query as exemplars
+  exemplar?: boolean;
 }
 
 export type MetadataQueryType = 'TAG_KEYS' | 'TAG_VALUES' | 'MEASUREMENTS' | 'FIELDS' | 'RETENTION_POLICIES';